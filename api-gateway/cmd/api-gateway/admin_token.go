@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sonuudigital/microservices/shared/auth"
+)
+
+const adminTokenCommand = "admin-token"
+
+// runAdminToken prints a token for the admin endpoints of the services'
+// metrics servers, signed with the internal identity token key:
+//
+//	api-gateway admin-token -operator alice [-ttl 15m]
+func runAdminToken(args []string) error {
+	fs := flag.NewFlagSet(adminTokenCommand, flag.ExitOnError)
+	operator := fs.String("operator", "", "name of the operator, logged with every admin request")
+	ttl := fs.Duration("ttl", auth.DefaultAdminTokenTTL, "lifetime of the token")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *operator == "" {
+		return fmt.Errorf("-operator is required")
+	}
+	if *ttl <= 0 {
+		return fmt.Errorf("invalid -ttl: %s", *ttl)
+	}
+
+	signer, err := auth.LoadIdentityTokenSignerFromEnv()
+	if err != nil {
+		return err
+	}
+
+	token, err := signer.SignAdmin(*operator, *ttl)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, token)
+	return err
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"os"
 	"strconv"
@@ -15,13 +16,28 @@ import (
 	"github.com/sonuudigital/microservices/api-gateway/internal/router"
 	"github.com/sonuudigital/microservices/shared/auth"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
+	"github.com/sonuudigital/microservices/shared/tracing"
 	"github.com/sonuudigital/microservices/shared/web"
+	"github.com/sonuudigital/microservices/shared/web/health"
 
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	logger := logs.NewSlogLogger()
+	// The token is the only output of admin-token, so it runs before the
+	// logger, which writes to stdout, is created.
+	if len(os.Args) > 1 && os.Args[1] == adminTokenCommand {
+		_ = godotenv.Load()
+		if err := runAdminToken(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create admin token: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger := logs.NewSlogLogger(logs.WithServiceName("api-gateway"))
 	err := godotenv.Load()
 	if err == nil {
 		logger.Info("loaded environment variables from .env file")
@@ -29,6 +45,22 @@ func main() {
 		logger.Info("no .env file found, using environment variables")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "api-gateway", logger)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	adminAuth, err := auth.LoadAdminAuthFromEnv(logger)
+	if err != nil {
+		logger.Error("failed to load admin auth", "error", err)
+		os.Exit(1)
+	}
+
+	metricsServer := metrics.StartServer(logger, metrics.WithAdminAuth(adminAuth))
+	defer metricsServer.Close()
+
 	logger.Info("starting api-gateway")
 
 	jwtManager := initializeJWTManager(logger)
@@ -38,12 +70,30 @@ func main() {
 		os.Exit(1)
 	}
 
+	identitySigner, err := auth.LoadIdentityTokenSignerFromEnv()
+	if err != nil {
+		logger.Error("failed to load identity token signer", "error", err)
+		os.Exit(1)
+	}
+
+	tlsConfig, err := web.LoadTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("failed to load TLS config", "error", err)
+		os.Exit(1)
+	}
+
+	clientCreds, err := web.NewGRPCClientCredentials(tlsConfig, logger)
+	if err != nil {
+		logger.Error("failed to create gRPC client credentials", "error", err)
+		os.Exit(1)
+	}
+
 	clients, err := clients.NewGRPCClient(clients.ClientURL{
 		UserServiceURL:    os.Getenv("USER_SERVICE_GRPC_URL"),
 		ProductServiceURL: os.Getenv("PRODUCT_SERVICE_GRPC_URL"),
 		CartServiceURL:    os.Getenv("CART_SERVICE_GRPC_URL"),
 		OrderServiceURL:   os.Getenv("ORDER_SERVICE_GRPC_URL"),
-	})
+	}, clientCreds, tracing.GRPCDialOption(), grpc.WithChainUnaryInterceptor(metrics.UnaryClientInterceptor(), logs.UnaryClientInterceptor()), grpc.WithUnaryInterceptor(auth.SigningUnaryClientInterceptor(identitySigner)))
 	if err != nil {
 		logger.Error("failed to create gRPC clients", "error", err.Error())
 		os.Exit(1)
@@ -61,14 +111,21 @@ func main() {
 		os.Exit(1)
 	}
 
-	handler, err := router.New(logger, jwtManager, rateLimiterMiddleware, clients, searchHandler)
+	readinessChecker := health.NewChecker(logger, health.DefaultCheckInterval,
+		append(clients.HealthChecks(), health.HTTPCheck("search-service", nil, searchServiceURL.JoinPath("/api/readyz").String()))...,
+	)
+	checkerCtx, stopChecker := context.WithCancel(context.Background())
+	defer stopChecker()
+	go readinessChecker.Start(checkerCtx)
+
+	handler, err := router.New(logger, jwtManager, rateLimiterMiddleware, clients, searchHandler, health.ReadinessHandler(readinessChecker))
 	if err != nil {
 		logger.Error("failed to configure routes", "error", err)
 		os.Exit(1)
 	}
 	logger.Info("routes configured successfully")
 
-	srv, err := web.InitializeServer(os.Getenv("PORT"), handler, logger)
+	srv, err := web.InitializeServer(os.Getenv("PORT"), tracing.HTTPHandler(handler, "api-gateway"), logger)
 	if err != nil {
 		logger.Error("failed to initialize server", "error", err)
 		os.Exit(1)
@@ -8,8 +8,8 @@ import (
 	product_categoriesv1 "github.com/sonuudigital/microservices/gen/product-categories/v1"
 	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
 	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
+	"github.com/sonuudigital/microservices/shared/web/health"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type GRPCClientError struct {
@@ -34,30 +34,35 @@ type GRPCClient struct {
 	product_categoriesv1.ProductCategoriesServiceClient
 	cartv1.CartServiceClient
 	orderv1.OrderServiceClient
+
+	userConn    *grpc.ClientConn
+	productConn *grpc.ClientConn
+	cartConn    *grpc.ClientConn
+	orderConn   *grpc.ClientConn
 }
 
-func NewGRPCClient(urls ClientURL) (*GRPCClient, error) {
-	userServiceClient, err := grpc.NewClient(urls.UserServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewGRPCClient(urls ClientURL, opts ...grpc.DialOption) (*GRPCClient, error) {
+	userServiceClient, err := grpc.NewClient(urls.UserServiceURL, opts...)
 	if err != nil {
 		return nil, &GRPCClientError{ServiceName: "User Service", Err: err}
 	}
 
-	productServiceClient, err := grpc.NewClient(urls.ProductServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	productServiceClient, err := grpc.NewClient(urls.ProductServiceURL, opts...)
 	if err != nil {
 		return nil, &GRPCClientError{ServiceName: "Product Service", Err: err}
 	}
 
-	productCategoriesServiceClient, err := grpc.NewClient(urls.ProductServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	productCategoriesServiceClient, err := grpc.NewClient(urls.ProductServiceURL, opts...)
 	if err != nil {
 		return nil, &GRPCClientError{ServiceName: "Product Categories Service", Err: err}
 	}
 
-	cartServiceClient, err := grpc.NewClient(urls.CartServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	cartServiceClient, err := grpc.NewClient(urls.CartServiceURL, opts...)
 	if err != nil {
 		return nil, &GRPCClientError{ServiceName: "Cart Service", Err: err}
 	}
 
-	orderServiceClient, err := grpc.NewClient(urls.OrderServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	orderServiceClient, err := grpc.NewClient(urls.OrderServiceURL, opts...)
 	if err != nil {
 		return nil, &GRPCClientError{ServiceName: "Order Service", Err: err}
 	}
@@ -68,5 +73,18 @@ func NewGRPCClient(urls ClientURL) (*GRPCClient, error) {
 		ProductCategoriesServiceClient: product_categoriesv1.NewProductCategoriesServiceClient(productCategoriesServiceClient),
 		CartServiceClient:              cartv1.NewCartServiceClient(cartServiceClient),
 		OrderServiceClient:             orderv1.NewOrderServiceClient(orderServiceClient),
+		userConn:                       userServiceClient,
+		productConn:                    productServiceClient,
+		cartConn:                       cartServiceClient,
+		orderConn:                      orderServiceClient,
 	}, nil
 }
+
+func (c *GRPCClient) HealthChecks() []health.Check {
+	return []health.Check{
+		health.GRPCCheck("user-service", c.userConn, "user-service"),
+		health.GRPCCheck("product-service", c.productConn, "product-service"),
+		health.GRPCCheck("cart-service", c.cartConn, "cart-service"),
+		health.GRPCCheck("order-service", c.orderConn, "order-service"),
+	}
+}
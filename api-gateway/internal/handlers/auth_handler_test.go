@@ -24,6 +24,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 const (
@@ -59,6 +60,22 @@ func (m *mockUserServiceClient) GetUserByID(ctx context.Context, in *userv1.GetU
 	return args.Get(0).(*userv1.User), args.Error(1)
 }
 
+func (m *mockUserServiceClient) UpdateUser(ctx context.Context, in *userv1.UpdateUserRequest, opts ...grpc.CallOption) (*userv1.User, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userv1.User), args.Error(1)
+}
+
+func (m *mockUserServiceClient) DeleteUser(ctx context.Context, in *userv1.DeleteUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*emptypb.Empty), args.Error(1)
+}
+
 func TestLoginHandler(t *testing.T) {
 	logger := logs.NewSlogLogger()
 
@@ -33,17 +33,13 @@ type AddProductToCartRequest struct {
 }
 
 func (h *CartHandler) GetCartHandler(w http.ResponseWriter, r *http.Request) {
-	claims, ok := middlewares.GetUserClaims(r)
+	_, ok := middlewares.GetUserClaims(r)
 	if !ok {
 		web.RespondWithError(w, h.logger, r, http.StatusUnauthorized, "Unauthorized", userClaimsNotFoundErrMsg)
 		return
 	}
 
-	grpcReq := &cartv1.GetCartRequest{
-		UserId: claims.Subject,
-	}
-
-	res, err := h.cartClient.GetCart(r.Context(), grpcReq)
+	res, err := h.cartClient.GetCart(r.Context(), &cartv1.GetCartRequest{})
 	if err != nil {
 		st, _ := status.FromError(err)
 		h.logger.Error("failed to get cart via grpc", "error", st.Message())
@@ -70,7 +66,6 @@ func (h *CartHandler) AddProductToCartHandler(w http.ResponseWriter, r *http.Req
 	h.logger.Debug("adding product to cart", "userId", claims.Subject, "productId", req.ProductID, "quantity", req.Quantity)
 
 	grpcReq := &cartv1.AddProductToCartRequest{
-		UserId:    claims.Subject,
 		ProductId: req.ProductID,
 		Quantity:  req.Quantity,
 	}
@@ -88,7 +83,7 @@ func (h *CartHandler) AddProductToCartHandler(w http.ResponseWriter, r *http.Req
 }
 
 func (h *CartHandler) RemoveProductFromCartHandler(w http.ResponseWriter, r *http.Request) {
-	claims, ok := middlewares.GetUserClaims(r)
+	_, ok := middlewares.GetUserClaims(r)
 	if !ok {
 		web.RespondWithError(w, h.logger, r, http.StatusUnauthorized, "Unauthorized", userClaimsNotFoundErrMsg)
 		return
@@ -97,7 +92,6 @@ func (h *CartHandler) RemoveProductFromCartHandler(w http.ResponseWriter, r *htt
 	productID := r.PathValue("productId")
 
 	grpcReq := &cartv1.RemoveProductFromCartRequest{
-		UserId:    claims.Subject,
 		ProductId: productID,
 	}
 
@@ -113,17 +107,13 @@ func (h *CartHandler) RemoveProductFromCartHandler(w http.ResponseWriter, r *htt
 }
 
 func (h *CartHandler) ClearCartHandler(w http.ResponseWriter, r *http.Request) {
-	claims, ok := middlewares.GetUserClaims(r)
+	_, ok := middlewares.GetUserClaims(r)
 	if !ok {
 		web.RespondWithError(w, h.logger, r, http.StatusUnauthorized, "Unauthorized", userClaimsNotFoundErrMsg)
 		return
 	}
 
-	grpcReq := &cartv1.ClearCartRequest{
-		UserId: claims.Subject,
-	}
-
-	_, err := h.cartClient.ClearCart(r.Context(), grpcReq)
+	_, err := h.cartClient.ClearCart(r.Context(), &cartv1.ClearCartRequest{})
 	if err != nil {
 		st, _ := status.FromError(err)
 		h.logger.Error("failed to clear cart via grpc", "error", st.Message())
@@ -135,17 +125,13 @@ func (h *CartHandler) ClearCartHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *CartHandler) DeleteCartHandler(w http.ResponseWriter, r *http.Request) {
-	claims, ok := middlewares.GetUserClaims(r)
+	_, ok := middlewares.GetUserClaims(r)
 	if !ok {
 		web.RespondWithError(w, h.logger, r, http.StatusUnauthorized, "Unauthorized", userClaimsNotFoundErrMsg)
 		return
 	}
 
-	grpcReq := &cartv1.DeleteCartRequest{
-		UserId: claims.Subject,
-	}
-
-	_, err := h.cartClient.DeleteCart(r.Context(), grpcReq)
+	_, err := h.cartClient.DeleteCart(r.Context(), &cartv1.DeleteCartRequest{})
 	if err != nil {
 		st, _ := status.FromError(err)
 		h.logger.Error("failed to delete cart via grpc", "error", st.Message())
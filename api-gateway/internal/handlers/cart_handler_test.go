@@ -31,7 +31,7 @@ const (
 	userEmailTest   = "test@test.com"
 	productIDTest   = "product-123"
 	apiCartsURLPath = "/api/carts"
-	bearerWithSpace = "Bearer "
+	cookieNameTest  = "auth_token"
 )
 
 type mockCartServiceClient struct {
@@ -79,6 +79,8 @@ func (m *mockCartServiceClient) DeleteCart(ctx context.Context, in *cartv1.Delet
 }
 
 func setupCartTest(t *testing.T) (*httptest.Server, *mockCartServiceClient, *auth.JWTManager) {
+	t.Setenv("COOKIE_AUTH_NAME", cookieNameTest)
+
 	logger := logs.NewSlogLogger()
 	mockClient := new(mockCartServiceClient)
 	cartHandler := handlers.NewCartHandler(logger, mockClient)
@@ -111,6 +113,13 @@ func setupCartTest(t *testing.T) (*httptest.Server, *mockCartServiceClient, *aut
 	return server, mockClient, jwtManager
 }
 
+func withPrincipal(userID string) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		principal, ok := auth.PrincipalFromContext(ctx)
+		return ok && principal.UserID == userID
+	})
+}
+
 func TestGetCartHandler(t *testing.T) {
 	server, mockClient, jwtManager := setupCartTest(t)
 
@@ -118,11 +127,11 @@ func TestGetCartHandler(t *testing.T) {
 		token, err := jwtManager.GenerateToken(userIDTest, userEmailTest)
 		assert.NoError(t, err)
 
-		mockClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{UserId: userIDTest}).
+		mockClient.On("GetCart", withPrincipal(userIDTest), &cartv1.GetCartRequest{}).
 			Return(&cartv1.GetCartResponse{Id: cartIDTest, UserId: userIDTest}, nil).Once()
 
 		req, _ := http.NewRequest("GET", server.URL+apiCartsURLPath, nil)
-		req.Header.Set("Authorization", bearerWithSpace+token)
+		req.AddCookie(&http.Cookie{Name: cookieNameTest, Value: token})
 
 		client := &http.Client{}
 		resp, err := client.Do(req)
@@ -147,11 +156,11 @@ func TestAddProductToCartHandler(t *testing.T) {
 		token, err := jwtManager.GenerateToken(userIDTest, userEmailTest)
 		assert.NoError(t, err)
 
-		mockClient.On("AddProductToCart", mock.Anything, mock.Anything).Return(&cartv1.AddProductToCartResponse{}, nil).Once()
+		mockClient.On("AddProductToCart", withPrincipal(userIDTest), mock.Anything).Return(&cartv1.AddProductToCartResponse{}, nil).Once()
 
 		req, _ := http.NewRequest("POST", server.URL+"/api/carts/products", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", bearerWithSpace+token)
+		req.AddCookie(&http.Cookie{Name: cookieNameTest, Value: token})
 
 		client := &http.Client{}
 		resp, err := client.Do(req)
@@ -170,11 +179,11 @@ func TestRemoveProductFromCartHandler(t *testing.T) {
 		token, err := jwtManager.GenerateToken(userIDTest, userEmailTest)
 		assert.NoError(t, err)
 
-		mockClient.On("RemoveProductFromCart", mock.Anything, &cartv1.RemoveProductFromCartRequest{UserId: userIDTest, ProductId: productIDTest}).
+		mockClient.On("RemoveProductFromCart", withPrincipal(userIDTest), &cartv1.RemoveProductFromCartRequest{ProductId: productIDTest}).
 			Return(&emptypb.Empty{}, nil).Once()
 
 		req, _ := http.NewRequest("DELETE", server.URL+"/api/carts/products/"+productIDTest, nil)
-		req.Header.Set("Authorization", bearerWithSpace+token)
+		req.AddCookie(&http.Cookie{Name: cookieNameTest, Value: token})
 
 		client := &http.Client{}
 		resp, err := client.Do(req)
@@ -193,11 +202,11 @@ func TestClearCartHandler(t *testing.T) {
 		token, err := jwtManager.GenerateToken(userIDTest, userEmailTest)
 		assert.NoError(t, err)
 
-		mockClient.On("ClearCart", mock.Anything, &cartv1.ClearCartRequest{UserId: userIDTest}).
+		mockClient.On("ClearCart", withPrincipal(userIDTest), &cartv1.ClearCartRequest{}).
 			Return(&emptypb.Empty{}, nil).Once()
 
 		req, _ := http.NewRequest("DELETE", server.URL+apiCartsURLPath, nil)
-		req.Header.Set("Authorization", bearerWithSpace+token)
+		req.AddCookie(&http.Cookie{Name: cookieNameTest, Value: token})
 
 		client := &http.Client{}
 		resp, err := client.Do(req)
@@ -23,15 +23,13 @@ func NewOrderHandler(logger logs.Logger, orderClient orderv1.OrderServiceClient)
 }
 
 func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
-	claims, ok := middlewares.GetUserClaims(r)
+	_, ok := middlewares.GetUserClaims(r)
 	if !ok {
 		web.RespondWithError(w, h.logger, r, http.StatusUnauthorized, "Unauthorized", userClaimsNotFoundErrMsg)
 		return
 	}
 
-	order, err := h.orderClient.CreateOrder(r.Context(), &orderv1.CreateOrderRequest{
-		UserId: claims.Subject,
-	})
+	order, err := h.orderClient.CreateOrder(r.Context(), &orderv1.CreateOrderRequest{})
 	if err != nil {
 		st, _ := status.FromError(err)
 		h.logger.Error("failed to create order via grpc", "error", st.Message())
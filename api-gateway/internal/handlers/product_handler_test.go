@@ -93,6 +93,14 @@ func (m *mockProductServiceClient) UpdateStockBatch(ctx context.Context, in *pro
 	return args.Get(0).(*emptypb.Empty), args.Error(1)
 }
 
+func (m *mockProductServiceClient) StreamProducts(ctx context.Context, in *productv1.StreamProductsRequest, opts ...grpc.CallOption) (productv1.ProductService_StreamProductsClient, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(productv1.ProductService_StreamProductsClient), args.Error(1)
+}
+
 func TestGetProductHandler(t *testing.T) {
 	logger := logs.NewSlogLogger()
 	mockClient := new(mockProductServiceClient)
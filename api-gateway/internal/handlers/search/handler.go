@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+
+	"github.com/sonuudigital/microservices/shared/tracing"
 )
 
 var (
@@ -17,6 +19,7 @@ func NewSearchHandler(targetURL *url.URL) (http.Handler, error) {
 	}
 
 	proxy := httputil.NewSingleHostReverseProxy(targetURL)
+	proxy.Transport = tracing.HTTPTransport(http.DefaultTransport)
 
 	originalDirector := proxy.Director
 	proxy.Director = func(req *http.Request) {
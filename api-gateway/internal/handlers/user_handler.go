@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/sonuudigital/microservices/api-gateway/internal/middlewares"
 	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
 	"github.com/sonuudigital/microservices/shared/logs"
 	"github.com/sonuudigital/microservices/shared/web"
@@ -28,6 +29,11 @@ type CreateUserRequest struct {
 	Password string `json:"password"`
 }
 
+type UpdateUserRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
 func (h *UserHandler) CreateUserHandler(w http.ResponseWriter, r *http.Request) {
 	var req CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -73,3 +79,48 @@ func (h *UserHandler) GetUserByIDHandler(w http.ResponseWriter, r *http.Request)
 
 	web.RespondWithJSON(w, h.logger, http.StatusOK, res)
 }
+
+func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
+	_, ok := middlewares.GetUserClaims(r)
+	if !ok {
+		web.RespondWithError(w, h.logger, r, http.StatusUnauthorized, "Unauthorized", userClaimsNotFoundErrMsg)
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		web.RespondWithError(w, h.logger, r, http.StatusBadRequest, "Invalid Request Body", err.Error())
+		return
+	}
+
+	res, err := h.userClient.UpdateUser(r.Context(), &userv1.UpdateUserRequest{
+		Username: req.Username,
+		Email:    req.Email,
+	})
+	if err != nil {
+		st, _ := status.FromError(err)
+		h.logger.Error("failed to update user via grpc", "error", st.Message())
+		web.RespondWithGRPCError(w, r, st, h.logger)
+		return
+	}
+
+	web.RespondWithJSON(w, h.logger, http.StatusOK, res)
+}
+
+func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	_, ok := middlewares.GetUserClaims(r)
+	if !ok {
+		web.RespondWithError(w, h.logger, r, http.StatusUnauthorized, "Unauthorized", userClaimsNotFoundErrMsg)
+		return
+	}
+
+	_, err := h.userClient.DeleteUser(r.Context(), &userv1.DeleteUserRequest{})
+	if err != nil {
+		st, _ := status.FromError(err)
+		h.logger.Error("failed to delete user via grpc", "error", st.Message())
+		web.RespondWithGRPCError(w, r, st, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
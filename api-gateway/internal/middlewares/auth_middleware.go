@@ -37,6 +37,7 @@ func AuthMiddleware(jwtManager *auth.JWTManager, logger logs.Logger) func(http.H
 			}
 
 			ctx := context.WithValue(r.Context(), userClaimsKey, claims)
+			ctx = auth.ContextWithPrincipal(ctx, auth.Principal{UserID: claims.Subject, Email: claims.Email})
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware keeps the X-Request-ID sent by the client, or generates
+// one, and stores it in the request context so it is logged by every service
+// handling the request.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(logs.RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(logs.RequestIDHeader, requestID)
+		ctx := logs.ContextWithRequestID(r.Context(), requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
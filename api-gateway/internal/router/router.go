@@ -8,17 +8,20 @@ import (
 	"github.com/sonuudigital/microservices/api-gateway/internal/middlewares"
 	"github.com/sonuudigital/microservices/shared/auth"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
+	"github.com/sonuudigital/microservices/shared/tracing"
 )
 
 type authMiddleware func(http.Handler) http.Handler
 
-func New(logger logs.Logger, jwtManager *auth.JWTManager, rateLimiter *middlewares.RateLimiterMiddleware, clients *clients.GRPCClient, searchHandler http.Handler) (http.Handler, error) {
+func New(logger logs.Logger, jwtManager *auth.JWTManager, rateLimiter *middlewares.RateLimiterMiddleware, clients *clients.GRPCClient, searchHandler, readinessHandler http.Handler) (http.Handler, error) {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("GET /api/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("gateway is healthy"))
 	})
+	mux.Handle("GET /api/readyz", readinessHandler)
 
 	authMw := middlewares.AuthMiddleware(jwtManager, logger)
 	authHandler := handlers.NewAuthHandler(logger, jwtManager, clients.UserServiceClient)
@@ -35,8 +38,10 @@ func New(logger logs.Logger, jwtManager *auth.JWTManager, rateLimiter *middlewar
 	configOrderRoutes(mux, orderHandler, authMw)
 	configSearchRoutes(mux, searchHandler)
 
-	var handler http.Handler = mux
+	var handler http.Handler = tracing.RouteSpanName(mux)
 	handler = rateLimiter.Middleware(handler)
+	handler = middlewares.RequestIDMiddleware(handler)
+	handler = metrics.HTTPMiddleware(mux, handler)
 
 	return handler, nil
 }
@@ -44,6 +49,8 @@ func New(logger logs.Logger, jwtManager *auth.JWTManager, rateLimiter *middlewar
 func configAuthAndUserRoutes(mux *http.ServeMux, authHandler *handlers.AuthHandler, userHandler *handlers.UserHandler, authMiddleware authMiddleware) {
 	mux.Handle("GET /api/users/{id}", authMiddleware(http.HandlerFunc(userHandler.GetUserByIDHandler)))
 	mux.HandleFunc("POST /api/users", userHandler.CreateUserHandler)
+	mux.Handle("PUT /api/users", authMiddleware(http.HandlerFunc(userHandler.UpdateUserHandler)))
+	mux.Handle("DELETE /api/users", authMiddleware(http.HandlerFunc(userHandler.DeleteUserHandler)))
 	mux.HandleFunc("POST /api/auth/login", authHandler.LoginHandler)
 	mux.HandleFunc("POST /api/auth/logout", authHandler.LogoutHandler)
 }
@@ -78,4 +85,5 @@ func configOrderRoutes(mux *http.ServeMux, orderHandler *handlers.OrderHandler,
 
 func configSearchRoutes(mux *http.ServeMux, searchHandler http.Handler) {
 	mux.Handle("GET /api/search/products", searchHandler)
+	mux.Handle("GET /api/search/suggest", searchHandler)
 }
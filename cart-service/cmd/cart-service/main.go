@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -18,9 +17,13 @@ import (
 	grpc_server "github.com/sonuudigital/microservices/cart-service/internal/grpc"
 	"github.com/sonuudigital/microservices/cart-service/internal/repository"
 	cartv1 "github.com/sonuudigital/microservices/gen/cart/v1"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/inbox"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"github.com/sonuudigital/microservices/shared/postgres"
 	"github.com/sonuudigital/microservices/shared/rabbitmq"
+	"github.com/sonuudigital/microservices/shared/tracing"
 	"github.com/sonuudigital/microservices/shared/web"
 	"github.com/sonuudigital/microservices/shared/web/health"
 	"golang.org/x/sync/errgroup"
@@ -28,7 +31,7 @@ import (
 )
 
 func main() {
-	logger := logs.NewSlogLogger()
+	logger := logs.NewSlogLogger(logs.WithServiceName("cart-service"))
 	err := godotenv.Load()
 	if err == nil {
 		logger.Info("loaded environment variables from .env file")
@@ -36,6 +39,29 @@ func main() {
 		logger.Info("no .env file found, using environment variables")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "cart-service", logger)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	adminAuth, err := auth.LoadAdminAuthFromEnv(logger)
+	if err != nil {
+		logger.Error("failed to load admin auth", "error", err)
+		os.Exit(1)
+	}
+
+	metricsServer := metrics.StartServer(logger, metrics.WithAdminAuth(adminAuth))
+	defer metricsServer.Close()
+
+	// The inbox table must exist before the service migrations, which
+	// backfill it from processed_events.
+	if err := inbox.Migrate(os.Getenv("DATABASE_URL")); err != nil {
+		logger.Error("failed to migrate inbox", "error", err)
+		os.Exit(1)
+	}
+
 	pgDb, err := postgres.InitializePostgresDB()
 	if err != nil {
 		logger.Error("error connecting to database", "error", err)
@@ -51,7 +77,7 @@ func main() {
 	}
 	logger.Info("redis connected successfully")
 
-	rabbitmq, err := initializeRabbitMQ(logger)
+	rabbitmq, err := rabbitmq.NewServiceClientFromEnv(logger, "cart-service")
 	if err != nil {
 		logger.Error("failed to initialize RabbitMQ", "error", err)
 		os.Exit(1)
@@ -64,13 +90,15 @@ func main() {
 	g, gCtx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
-		return startRabbitMQConsumer(gCtx, logger, rabbitmq, repository.NewPostgreSQLOrderCreatedConsumerRepository(pgDb), redisClient)
+		return startRabbitMQConsumer(gCtx, logger, rabbitmq, pgDb, repository.NewPostgreSQLOrderCreatedConsumerRepository(), redisClient)
 	})
 
 	g.Go(func() error {
-		return startGRPCServer(gCtx, pgDb, redisClient, logger)
+		return startGRPCServer(gCtx, pgDb, redisClient, rabbitmq, logger)
 	})
 
+	go startInboxRetentionJob(gCtx, logger, pgDb)
+
 	if err := g.Wait(); err != nil {
 		logger.Error("application exited with error", "error", err)
 		os.Exit(1)
@@ -100,26 +128,22 @@ func initializeRedisClient() (*redis.Client, error) {
 	return client, nil
 }
 
-func initializeRabbitMQ(logger logs.Logger) (*rabbitmq.RabbitMQ, error) {
-	rabbitmqURL := os.Getenv("RABBITMQ_URL")
-	if rabbitmqURL == "" {
-		return nil, fmt.Errorf("RABBITMQ_URL is not set")
+func startGRPCServer(ctx context.Context, pgDb *pgxpool.Pool, redisClient *redis.Client, rabbitmq *rabbitmq.Client, logger logs.Logger) error {
+	productServiceGrpcURL := os.Getenv("PRODUCT_SERVICE_GRPC_URL")
+	if productServiceGrpcURL == "" {
+		return fmt.Errorf("PRODUCT_SERVICE_GRPC_URL is not set")
 	}
-
-	rabbitmq, err := rabbitmq.NewConnection(logger, rabbitmqURL)
+	tlsConfig, err := web.LoadTLSConfigFromEnv()
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to load TLS config: %w", err)
 	}
 
-	return rabbitmq, nil
-}
-
-func startGRPCServer(ctx context.Context, pgDb *pgxpool.Pool, redisClient *redis.Client, logger logs.Logger) error {
-	productServiceGrpcURL := os.Getenv("PRODUCT_SERVICE_GRPC_URL")
-	if productServiceGrpcURL == "" {
-		return fmt.Errorf("PRODUCT_SERVICE_GRPC_URL is not set")
+	clientCreds, err := web.NewGRPCClientCredentials(tlsConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC client credentials: %w", err)
 	}
-	productClient, err := clients.NewProductClient(productServiceGrpcURL, logger)
+
+	productClient, err := clients.NewProductClient(productServiceGrpcURL, logger, clientCreds, tracing.GRPCDialOption(), grpc.WithChainUnaryInterceptor(metrics.UnaryClientInterceptor(), logs.UnaryClientInterceptor()))
 	if err != nil {
 		return fmt.Errorf("failed to create product client: %w", err)
 	}
@@ -135,27 +159,50 @@ func startGRPCServer(ctx context.Context, pgDb *pgxpool.Pool, redisClient *redis
 		return fmt.Errorf("failed to listen for gRPC: %w", err)
 	}
 
+	serverOpts, err := web.NewGRPCServerCredentials(tlsConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC server credentials: %w", err)
+	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logs.UnaryServerInterceptor()))
+
+	identityVerifier, err := auth.LoadIdentityTokenVerifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load identity token verifier: %w", err)
+	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(identityVerifier, logger)))
+
 	queries := repository.New(pgDb)
-	grpcServer := grpc.NewServer()
+	serverOpts = append(serverOpts, tracing.GRPCServerOption())
+	grpcServer := grpc.NewServer(serverOpts...)
 	cartServer := grpc_server.NewGRPCServer(queries, productClient, redisClient, logger)
 	cartv1.RegisterCartServiceServer(grpcServer, cartServer)
 
-	health.StartGRPCHealthCheckService(grpcServer, "cart-service", func(ctx context.Context) error {
-		dbErr := pgDb.Ping(ctx)
-		redisErr := redisClient.Ping(ctx).Err()
-
-		if dbErr == nil && redisErr == nil {
-			return nil
-		} else {
-			return errors.Join(dbErr, redisErr)
-		}
-	})
+	checker := health.NewChecker(logger, health.DefaultCheckInterval,
+		health.NewCheck("postgres", health.DefaultCheckTimeout, pgDb.Ping),
+		health.NewCheck("redis", health.DefaultCheckTimeout, func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}),
+		health.NewCheck("rabbitmq", health.DefaultCheckTimeout, func(context.Context) error {
+			return rabbitmq.Ping()
+		}),
+		health.GRPCCheck("product-service", productClient.Conn(), "product-service"),
+	)
+	health.StartGRPCHealthCheckService(ctx, grpcServer, "cart-service", checker)
 
 	return web.StartGRPCServerAndWaitForShutdown(ctx, grpcServer, lis, logger)
 }
 
-func startRabbitMQConsumer(ctx context.Context, logger logs.Logger, rabbitmq *rabbitmq.RabbitMQ, repo events.OrderCreatedConsumerRepository, redisClient *redis.Client) error {
-	orderCreatedConsumer := events.NewOrderCreatedConsumer(logger, rabbitmq, repo, redisClient)
+func startInboxRetentionJob(ctx context.Context, logger logs.Logger, db *pgxpool.Pool) {
+	policy, err := inbox.RetentionPolicyFromEnv()
+	if err != nil {
+		logger.Error("failed to get inbox retention policy from env", "error", err)
+		os.Exit(1)
+	}
+	inbox.NewRetentionJob(logger, db, policy).Start(ctx)
+}
+
+func startRabbitMQConsumer(ctx context.Context, logger logs.Logger, rabbitmq *rabbitmq.Client, db inbox.TxBeginner, repo events.OrderCreatedConsumerRepository, redisClient *redis.Client) error {
+	orderCreatedConsumer := events.NewOrderCreatedConsumer(logger, rabbitmq, db, repo, redisClient)
 	logger.Info("starting OrderCreatedConsumer")
 
 	if err := orderCreatedConsumer.Start(ctx); err != nil {
@@ -8,26 +8,31 @@ import (
 	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
 	"github.com/sonuudigital/microservices/shared/logs"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type ProductClient struct {
+	conn   *grpc.ClientConn
 	client productv1.ProductServiceClient
 	logger logs.Logger
 }
 
-func NewProductClient(grpcAddr string, logger logs.Logger) (*ProductClient, error) {
-	conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewProductClient(grpcAddr string, logger logs.Logger, opts ...grpc.DialOption) (*ProductClient, error) {
+	conn, err := grpc.NewClient(grpcAddr, opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ProductClient{
+		conn:   conn,
 		logger: logger,
 		client: productv1.NewProductServiceClient(conn),
 	}, nil
 }
 
+func (c *ProductClient) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
 func (c *ProductClient) GetProductsByIDs(ctx context.Context, ids []string) (map[string]grpc_server.Product, error) {
 	if ctx.Err() != nil {
 		return nil, fmt.Errorf("request to product service canceled or timed out: %w", ctx.Err())
@@ -0,0 +1,5 @@
+DELETE FROM inbox_events
+WHERE consumer = 'cart_order_created_consumer'
+  AND event_id IN (
+    SELECT aggregate_id::TEXT FROM processed_events WHERE event_name = 'order_created_exchange'
+  );
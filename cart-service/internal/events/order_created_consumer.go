@@ -2,125 +2,78 @@ package events
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/rabbitmq/amqp091-go"
 	"github.com/redis/go-redis/v9"
-	"github.com/sonuudigital/microservices/cart-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/broker"
 	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/inbox"
 	"github.com/sonuudigital/microservices/shared/logs"
-	"github.com/sonuudigital/microservices/shared/rabbitmq"
 )
 
 const (
-	exchangeName        = "order_created_exchange"
-	queueName           = "cart_queue"
 	consumerName        = "cart_order_created_consumer"
 	redisCartPrefix     = "cart:"
 	redisContextTimeout = time.Second * 3
 )
 
 type OrderCreatedConsumerRepository interface {
-	GetProcessedEventByAggregateIDAndEventName(ctx context.Context, arg repository.GetProcessedEventByAggregateIDAndEventNameParams) (repository.ProcessedEvent, error)
-	DeleteCartAndCreateProcessedEvent(ctx context.Context, event *events.OrderCreatedEvent, eventName string) error
+	DeleteCartByUserID(ctx context.Context, tx pgx.Tx, userID string) error
 }
 
 type OrderCreatedConsumer struct {
 	logger      logs.Logger
-	rabbitmq    *rabbitmq.RabbitMQ
+	subscriber  broker.Subscriber
+	inbox       *inbox.Inbox
 	repo        OrderCreatedConsumerRepository
 	redisClient *redis.Client
 }
 
-func NewOrderCreatedConsumer(logger logs.Logger, rabbitmq *rabbitmq.RabbitMQ, repo OrderCreatedConsumerRepository, redisClient *redis.Client) *OrderCreatedConsumer {
+func NewOrderCreatedConsumer(logger logs.Logger, subscriber broker.Subscriber, db inbox.TxBeginner, repo OrderCreatedConsumerRepository, redisClient *redis.Client) *OrderCreatedConsumer {
 	return &OrderCreatedConsumer{
 		logger:      logger,
-		rabbitmq:    rabbitmq,
+		subscriber:  subscriber,
+		inbox:       inbox.New(logger, db, consumerName),
 		repo:        repo,
 		redisClient: redisClient,
 	}
 }
 
 func (occ *OrderCreatedConsumer) Start(ctx context.Context) error {
-	return occ.rabbitmq.Subscribe(ctx, exchangeName, queueName, consumerName, occ.handleOrderCreatedEvent)
-}
-
-func (occ *OrderCreatedConsumer) handleOrderCreatedEvent(ctx context.Context, d amqp091.Delivery) {
-	orderCreatedEvent, err := occ.unmarshalEvent(d.Body)
+	sub, err := events.Registry.Subscription(events.CartOrderCreatedQueueName)
 	if err != nil {
-		occ.logger.Error("failed to unmarshal OrderCreatedEvent", "error", err)
-		d.Nack(false, false)
-		return
+		return err
 	}
+	sub.Consumer = consumerName
+	sub.Handler = inbox.HandleKeyed(occ.inbox, orderCreatedKey, occ.handleOrderCreatedEvent)
+	return occ.subscriber.Subscribe(ctx, sub)
+}
 
-	if occ.shouldSkipProcessing(ctx, orderCreatedEvent.OrderID, d) {
-		return
-	}
+// orderCreatedKey deduplicates by order, so a republished payload without an
+// envelope is still recognised.
+func orderCreatedKey(event events.OrderCreatedEvent) string {
+	return event.OrderID
+}
 
+func (occ *OrderCreatedConsumer) handleOrderCreatedEvent(ctx context.Context, tx pgx.Tx, envelope events.Envelope, orderCreatedEvent events.OrderCreatedEvent) error {
 	occ.logger.Debug(
 		"received OrderCreatedEvent",
+		"eventId", envelope.ID,
 		"orderId", orderCreatedEvent.OrderID,
 		"userId", orderCreatedEvent.UserID,
 	)
 
-	err = occ.repo.DeleteCartAndCreateProcessedEvent(ctx, orderCreatedEvent, exchangeName)
-	if err != nil {
-		occ.logger.Error("failed to delete cart and create processed event", "error", err, "orderId", orderCreatedEvent.OrderID)
-		d.Nack(false, true)
-		return
+	if err := occ.repo.DeleteCartByUserID(ctx, tx, orderCreatedEvent.UserID); err != nil {
+		occ.logger.Error("failed to delete cart", "error", err, "orderId", orderCreatedEvent.OrderID)
+		return broker.Retryable(fmt.Errorf("failed to delete cart: %w", err))
 	}
 
 	go occ.deleteCartCache(orderCreatedEvent.UserID)
 
 	occ.logger.Info("cleared cart after order creation", "userId", orderCreatedEvent.UserID, "orderId", orderCreatedEvent.OrderID)
-	d.Ack(false)
-}
-
-func (occ *OrderCreatedConsumer) unmarshalEvent(body []byte) (*events.OrderCreatedEvent, error) {
-	var orderCreatedEvent events.OrderCreatedEvent
-	if err := json.Unmarshal(body, &orderCreatedEvent); err != nil {
-		return nil, err
-	}
-	return &orderCreatedEvent, nil
-}
-
-func (occ *OrderCreatedConsumer) shouldSkipProcessing(ctx context.Context, orderID string, d amqp091.Delivery) bool {
-	processed, err := occ.isEventProcessed(ctx, orderID, exchangeName)
-	if err != nil {
-		occ.logger.Error("failed to check if event is already processed", "error", err, "orderId", orderID)
-		d.Nack(false, true)
-		return true
-	}
-	if processed {
-		occ.logger.Info("event already processed, acknowledging without reprocessing", "orderId", orderID)
-		d.Ack(false)
-		return true
-	}
-	return false
-}
-
-func (occ *OrderCreatedConsumer) isEventProcessed(ctx context.Context, aggregateID string, eventName string) (bool, error) {
-	var aggregateUUID pgtype.UUID
-	if err := aggregateUUID.Scan(aggregateID); err != nil {
-		return false, err
-	}
-
-	processedEvent, err := occ.repo.GetProcessedEventByAggregateIDAndEventName(ctx, repository.GetProcessedEventByAggregateIDAndEventNameParams{
-		AggregateID: aggregateUUID,
-		EventName:   eventName,
-	})
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return false, nil
-		}
-		return false, err
-	}
-
-	return processedEvent.ID.Valid, nil
+	return nil
 }
 
 func (occ *OrderCreatedConsumer) deleteCartCache(userID string) {
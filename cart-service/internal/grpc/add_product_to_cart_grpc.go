@@ -7,6 +7,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/sonuudigital/microservices/cart-service/internal/repository"
 	cartv1 "github.com/sonuudigital/microservices/gen/cart/v1"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -16,11 +17,16 @@ func (s *GRPCServer) AddProductToCart(ctx context.Context, req *cartv1.AddProduc
 		return nil, status.FromContextError(err).Err()
 	}
 
-	s.logger.Debug("AddProductToCart called", "userId", req.UserId, "productId", req.ProductId, "quantity", req.Quantity)
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("AddProductToCart called", "userId", principal.UserID, "productId", req.ProductId, "quantity", req.Quantity)
 
 	var userUUID pgtype.UUID
-	if err := userUUID.Scan(req.UserId); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", req.UserId)
+	if err := userUUID.Scan(principal.UserID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", principal.UserID)
 	}
 
 	cart, wasCreated, err := s.getOrCreateCartByUserID(ctx, userUUID)
@@ -36,13 +42,13 @@ func (s *GRPCServer) AddProductToCart(ctx context.Context, req *cartv1.AddProduc
 		}
 
 		if len(existingProducts) > 0 {
-			s.logger.Warn("cart was recreated and had products, aborting add product operation", "userId", req.UserId, "cartId", cart.ID.String(), "productsCount", len(existingProducts))
+			s.logger.Warn("cart was recreated and had products, aborting add product operation", "userId", principal.UserID, "cartId", cart.ID.String(), "productsCount", len(existingProducts))
 			return nil, status.Errorf(codes.Aborted, "cart has expired and was cleared, please try again")
 		}
 
-		s.logger.Debug("new cart created for user", "userId", req.UserId, "cartId", cart.ID.String())
+		s.logger.Debug("new cart created for user", "userId", principal.UserID, "cartId", cart.ID.String())
 	} else {
-		s.logger.Debug("using existing cart", "userId", req.UserId, "cartId", cart.ID.String())
+		s.logger.Debug("using existing cart", "userId", principal.UserID, "cartId", cart.ID.String())
 	}
 
 	productsMap, err := s.productFetcher.GetProductsByIDs(ctx, []string{req.ProductId})
@@ -80,9 +86,9 @@ func (s *GRPCServer) AddProductToCart(ctx context.Context, req *cartv1.AddProduc
 		return nil, status.Errorf(codes.Internal, "failed to add or update product in cart: %v", err)
 	}
 
-	s.logger.Debug("product added to cart successfully", "userId", req.UserId, "cartId", cart.ID.String(), "productId", req.ProductId, "quantity", cartProduct.Quantity)
+	s.logger.Debug("product added to cart successfully", "userId", principal.UserID, "cartId", cart.ID.String(), "productId", req.ProductId, "quantity", cartProduct.Quantity)
 
-	go s.deleteCartCache(req.UserId)
+	go s.deleteCartCache(principal.UserID)
 
 	return &cartv1.AddProductToCartResponse{
 		Id:        cartProduct.ID.String(),
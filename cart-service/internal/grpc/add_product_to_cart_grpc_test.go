@@ -1,7 +1,6 @@
 package grpc_test
 
 import (
-	"context"
 	"testing"
 	"time"
 
@@ -24,7 +23,6 @@ func TestAddProductToCart(t *testing.T) {
 	server := grpc_server.NewGRPCServer(mockQuerier, mockProductFetcher, redisClient, logs.NewSlogLogger())
 
 	req := &cartv1.AddProductToCartRequest{
-		UserId:    uuidTest,
 		ProductId: productIDTest,
 		Quantity:  1,
 	}
@@ -56,7 +54,7 @@ func TestAddProductToCart(t *testing.T) {
 	redisMock.MatchExpectationsInOrder(false)
 	redisMock.ExpectDel(cartCachePrefix + uuidTest).SetVal(1)
 
-	_, err := server.AddProductToCart(context.Background(), req)
+	_, err := server.AddProductToCart(principalContext(), req)
 
 	assert.NoError(t, err)
 	mockQuerier.AssertExpectations(t)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgtype"
 	cartv1 "github.com/sonuudigital/microservices/gen/cart/v1"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -15,9 +16,14 @@ func (s *GRPCServer) ClearCart(ctx context.Context, req *cartv1.ClearCartRequest
 		return nil, status.FromContextError(err).Err()
 	}
 
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var userUUID pgtype.UUID
-	if err := userUUID.Scan(req.UserId); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", req.UserId)
+	if err := userUUID.Scan(principal.UserID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", principal.UserID)
 	}
 
 	_, wasRecreated, err := s.getOrCreateCartByUserID(ctx, userUUID)
@@ -32,7 +38,7 @@ func (s *GRPCServer) ClearCart(ctx context.Context, req *cartv1.ClearCartRequest
 		return nil, status.Errorf(codes.Internal, "failed to clear cart: %v", err)
 	}
 
-	go s.deleteCartCache(req.UserId)
+	go s.deleteCartCache(principal.UserID)
 
 	return &emptypb.Empty{}, nil
 }
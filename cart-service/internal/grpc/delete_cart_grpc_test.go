@@ -1,7 +1,6 @@
 package grpc_test
 
 import (
-	"context"
 	"testing"
 	"time"
 
@@ -22,7 +21,7 @@ func TestDeleteCart(t *testing.T) {
 	redisClient, redisMock := redismock.NewClientMock()
 	server := grpc_server.NewGRPCServer(mockQuerier, nil, redisClient, logs.NewSlogLogger())
 
-	req := &cartv1.DeleteCartRequest{UserId: uuidTest}
+	req := &cartv1.DeleteCartRequest{}
 
 	var userUUID pgtype.UUID
 	_ = userUUID.Scan(uuidTest)
@@ -41,7 +40,7 @@ func TestDeleteCart(t *testing.T) {
 	redisMock.MatchExpectationsInOrder(false)
 	redisMock.ExpectDel(cartCachePrefix + uuidTest).SetVal(1)
 
-	_, err := server.DeleteCart(context.Background(), req)
+	_, err := server.DeleteCart(principalContext(), req)
 
 	assert.NoError(t, err)
 	mockQuerier.AssertExpectations(t)
@@ -7,6 +7,8 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/redis/go-redis/v9"
 	cartv1 "github.com/sonuudigital/microservices/gen/cart/v1"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -16,19 +18,24 @@ func (s *GRPCServer) GetCart(ctx context.Context, req *cartv1.GetCartRequest) (*
 		return nil, status.FromContextError(err).Err()
 	}
 
-	s.logger.Debug("GetCart called", "userId", req.UserId)
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	cachedResp, err := s.checkCartCache(ctx, req.UserId)
+	s.logger.Debug("GetCart called", "userId", principal.UserID)
+
+	cachedResp, err := s.checkCartCache(ctx, principal.UserID)
 	if err != nil {
-		s.logger.Error("failed to check cart cache", "userID", req.UserId, "error", err)
+		s.logger.Error("failed to check cart cache", "userID", principal.UserID, "error", err)
 	} else if cachedResp != nil {
-		s.logger.Debug("cart found in cache", "userId", req.UserId, "productsCount", len(cachedResp.Products))
+		s.logger.Debug("cart found in cache", "userId", principal.UserID, "productsCount", len(cachedResp.Products))
 		return cachedResp, nil
 	}
 
 	var uid pgtype.UUID
-	if err := uid.Scan(req.UserId); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", req.UserId)
+	if err := uid.Scan(principal.UserID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", principal.UserID)
 	}
 
 	cart, wasRecreated, err := s.getOrCreateCartByUserID(ctx, uid)
@@ -37,9 +44,9 @@ func (s *GRPCServer) GetCart(ctx context.Context, req *cartv1.GetCartRequest) (*
 	}
 
 	if wasRecreated {
-		s.logger.Info("created new cart for user", "userId", req.UserId, "cartId", cart.ID.String())
+		s.logger.Info("created new cart for user", "userId", principal.UserID, "cartId", cart.ID.String())
 	} else {
-		s.logger.Debug("retrieved existing cart", "userId", req.UserId, "cartId", cart.ID.String())
+		s.logger.Debug("retrieved existing cart", "userId", principal.UserID, "cartId", cart.ID.String())
 	}
 
 	cartProducts, err := s.queries.GetCartProductsByCartID(ctx, cart.ID)
@@ -91,9 +98,9 @@ func (s *GRPCServer) GetCart(ctx context.Context, req *cartv1.GetCartRequest) (*
 		TotalPrice: totalPrice,
 	}
 
-	s.logger.Debug("returning cart", "userId", req.UserId, "cartId", cart.ID.String(), "productsCount", len(grpcCartProducts), "totalPrice", totalPrice)
+	s.logger.Debug("returning cart", "userId", principal.UserID, "cartId", cart.ID.String(), "productsCount", len(grpcCartProducts), "totalPrice", totalPrice)
 
-	go s.cacheGetCartResponse(req.UserId, grpcGetCartResponse)
+	go s.cacheGetCartResponse(principal.UserID, grpcGetCartResponse)
 
 	return grpcGetCartResponse, nil
 }
@@ -106,16 +113,21 @@ func (s *GRPCServer) checkCartCache(ctx context.Context, userID string) (*cartv1
 	jsonResp, err := s.redisClient.Get(ctx, cacheKey).Result()
 	if err != nil {
 		if err == redis.Nil {
+			metrics.CacheMiss(cartCacheName)
 			return nil, nil
 		}
+		metrics.CacheError(cartCacheName)
 		return nil, err
 	}
 
 	var resp *cartv1.GetCartResponse
 	if err := json.Unmarshal([]byte(jsonResp), &resp); err != nil {
+		metrics.CacheError(cartCacheName)
 		return nil, err
 	}
 
+	metrics.CacheHit(cartCacheName)
+
 	return resp, nil
 }
 
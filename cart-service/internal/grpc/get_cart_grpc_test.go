@@ -14,6 +14,8 @@ import (
 	"github.com/sonuudigital/microservices/shared/logs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGetCart(t *testing.T) {
@@ -25,7 +27,7 @@ func TestGetCart(t *testing.T) {
 		redisClient, redisMock := redismock.NewClientMock()
 		server := grpc_server.NewGRPCServer(mockQuerier, mockProductFetcher, redisClient, logs.NewSlogLogger())
 
-		req := &cartv1.GetCartRequest{UserId: uuidTest}
+		req := &cartv1.GetCartRequest{}
 
 		var userUUID pgtype.UUID
 		_ = userUUID.Scan(uuidTest)
@@ -47,7 +49,7 @@ func TestGetCart(t *testing.T) {
 		redisMock.MatchExpectationsInOrder(false)
 		redisMock.ExpectSet(cartCachePrefix+uuidTest, mock.Anything, 15*time.Minute).SetVal("OK")
 
-		resp, err := server.GetCart(context.Background(), req)
+		resp, err := server.GetCart(principalContext(), req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
@@ -64,7 +66,7 @@ func TestGetCart(t *testing.T) {
 		redisClient, redisMock := redismock.NewClientMock()
 		server := grpc_server.NewGRPCServer(mockQuerier, mockProductFetcher, redisClient, logs.NewSlogLogger())
 
-		req := &cartv1.GetCartRequest{UserId: uuidTest}
+		req := &cartv1.GetCartRequest{}
 
 		var cartUUID pgtype.UUID
 		_ = cartUUID.Scan(cartUUIDTest)
@@ -73,7 +75,7 @@ func TestGetCart(t *testing.T) {
 
 		redisMock.ExpectGet(cartCachePrefix + uuidTest).SetVal(cachedCart)
 
-		resp, err := server.GetCart(context.Background(), req)
+		resp, err := server.GetCart(principalContext(), req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
@@ -90,7 +92,7 @@ func TestGetCart(t *testing.T) {
 		redisClient, redisMock := redismock.NewClientMock()
 		server := grpc_server.NewGRPCServer(mockQuerier, mockProductFetcher, redisClient, logs.NewSlogLogger())
 
-		req := &cartv1.GetCartRequest{UserId: uuidTest}
+		req := &cartv1.GetCartRequest{}
 
 		var userUUID pgtype.UUID
 		_ = userUUID.Scan(uuidTest)
@@ -113,7 +115,7 @@ func TestGetCart(t *testing.T) {
 		redisMock.MatchExpectationsInOrder(false)
 		redisMock.ExpectSet(cartCachePrefix+uuidTest, mock.Anything, 15*time.Minute).SetVal("OK")
 
-		resp, err := server.GetCart(context.Background(), req)
+		resp, err := server.GetCart(principalContext(), req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, resp)
@@ -123,4 +125,16 @@ func TestGetCart(t *testing.T) {
 		assert.Equal(t, 0.0, resp.TotalPrice)
 		mockQuerier.AssertExpectations(t)
 	})
+	t.Run("Reject call without principal", func(t *testing.T) {
+		mockQuerier := new(MockQuerier)
+		mockProductFetcher := new(MockProductFetcher)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockQuerier, mockProductFetcher, redisClient, logs.NewSlogLogger())
+
+		resp, err := server.GetCart(context.Background(), &cartv1.GetCartRequest{UserId: uuidTest})
+
+		assert.Nil(t, resp)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		mockQuerier.AssertNotCalled(t, "GetCartByUserID", mock.Anything, mock.Anything)
+	})
 }
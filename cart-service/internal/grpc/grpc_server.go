@@ -17,6 +17,7 @@ import (
 
 const (
 	redisCartPrefix     = "cart:"
+	cartCacheName       = "cart"
 	redisCacheTTL       = time.Minute * 15
 	redisContextTimeout = time.Second * 3
 )
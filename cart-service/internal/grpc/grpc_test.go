@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/sonuudigital/microservices/cart-service/internal/grpc"
 	"github.com/sonuudigital/microservices/cart-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -16,6 +17,10 @@ const (
 	cartCachePrefix = "cart:"
 )
 
+func principalContext() context.Context {
+	return auth.ContextWithPrincipal(context.Background(), auth.Principal{UserID: uuidTest})
+}
+
 type MockQuerier struct {
 	mock.Mock
 }
@@ -55,15 +60,6 @@ func (m *MockQuerier) ClearCartProductsByUserID(ctx context.Context, userID pgty
 	return m.Called(ctx, userID).Error(0)
 }
 
-func (m *MockQuerier) CreateProcessedEvent(ctx context.Context, arg repository.CreateProcessedEventParams) error {
-	return m.Called(ctx, arg).Error(0)
-}
-
-func (m *MockQuerier) GetProcessedEventByAggregateIDAndEventName(ctx context.Context, arg repository.GetProcessedEventByAggregateIDAndEventNameParams) (repository.ProcessedEvent, error) {
-	args := m.Called(ctx, arg)
-	return args.Get(0).(repository.ProcessedEvent), args.Error(1)
-}
-
 type MockProductFetcher struct {
 	mock.Mock
 }
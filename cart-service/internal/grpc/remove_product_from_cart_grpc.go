@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/sonuudigital/microservices/cart-service/internal/repository"
 	cartv1 "github.com/sonuudigital/microservices/gen/cart/v1"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -16,9 +17,14 @@ func (s *GRPCServer) RemoveProductFromCart(ctx context.Context, req *cartv1.Remo
 		return nil, status.FromContextError(err).Err()
 	}
 
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var userUUID pgtype.UUID
-	if err := userUUID.Scan(req.UserId); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", req.UserId)
+	if err := userUUID.Scan(principal.UserID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", principal.UserID)
 	}
 
 	_, wasRecreated, err := s.getOrCreateCartByUserID(ctx, userUUID)
@@ -43,7 +49,7 @@ func (s *GRPCServer) RemoveProductFromCart(ctx context.Context, req *cartv1.Remo
 		return nil, status.Errorf(codes.Internal, "failed to remove product from cart: %v", err)
 	}
 
-	go s.deleteCartCache(req.UserId)
+	go s.deleteCartCache(principal.UserID)
 
 	return &emptypb.Empty{}, nil
 }
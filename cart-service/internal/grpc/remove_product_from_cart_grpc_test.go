@@ -1,7 +1,6 @@
 package grpc_test
 
 import (
-	"context"
 	"testing"
 	"time"
 
@@ -23,7 +22,6 @@ func TestRemoveProductFromCart(t *testing.T) {
 	server := grpc_server.NewGRPCServer(mockQuerier, nil, redisClient, logs.NewSlogLogger())
 
 	req := &cartv1.RemoveProductFromCartRequest{
-		UserId:    uuidTest,
 		ProductId: productIDTest,
 	}
 
@@ -46,7 +44,7 @@ func TestRemoveProductFromCart(t *testing.T) {
 	redisMock.MatchExpectationsInOrder(false)
 	redisMock.ExpectDel(cartCachePrefix + uuidTest).SetVal(1)
 
-	_, err := server.RemoveProductFromCart(context.Background(), req)
+	_, err := server.RemoveProductFromCart(principalContext(), req)
 
 	assert.NoError(t, err)
 	mockQuerier.AssertExpectations(t)
@@ -28,6 +28,7 @@ const (
 	ProductService_DeleteProduct_FullMethodName           = "/product.v1.ProductService/DeleteProduct"
 	ProductService_GetProductsByCategoryID_FullMethodName = "/product.v1.ProductService/GetProductsByCategoryID"
 	ProductService_UpdateStockBatch_FullMethodName        = "/product.v1.ProductService/UpdateStockBatch"
+	ProductService_StreamProducts_FullMethodName          = "/product.v1.ProductService/StreamProducts"
 )
 
 // ProductServiceClient is the client API for ProductService service.
@@ -42,6 +43,7 @@ type ProductServiceClient interface {
 	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	GetProductsByCategoryID(ctx context.Context, in *GetProductsByCategoryIDRequest, opts ...grpc.CallOption) (*GetProductsByCategoryIDResponse, error)
 	UpdateStockBatch(ctx context.Context, in *UpdateStockBatchRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	StreamProducts(ctx context.Context, in *StreamProductsRequest, opts ...grpc.CallOption) (ProductService_StreamProductsClient, error)
 }
 
 type productServiceClient struct {
@@ -132,6 +134,39 @@ func (c *productServiceClient) UpdateStockBatch(ctx context.Context, in *UpdateS
 	return out, nil
 }
 
+func (c *productServiceClient) StreamProducts(ctx context.Context, in *StreamProductsRequest, opts ...grpc.CallOption) (ProductService_StreamProductsClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ProductService_ServiceDesc.Streams[0], ProductService_StreamProducts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &productServiceStreamProductsClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ProductService_StreamProductsClient interface {
+	Recv() (*Product, error)
+	grpc.ClientStream
+}
+
+type productServiceStreamProductsClient struct {
+	grpc.ClientStream
+}
+
+func (x *productServiceStreamProductsClient) Recv() (*Product, error) {
+	m := new(Product)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ProductServiceServer is the server API for ProductService service.
 // All implementations must embed UnimplementedProductServiceServer
 // for forward compatibility
@@ -144,6 +179,7 @@ type ProductServiceServer interface {
 	DeleteProduct(context.Context, *DeleteProductRequest) (*emptypb.Empty, error)
 	GetProductsByCategoryID(context.Context, *GetProductsByCategoryIDRequest) (*GetProductsByCategoryIDResponse, error)
 	UpdateStockBatch(context.Context, *UpdateStockBatchRequest) (*emptypb.Empty, error)
+	StreamProducts(*StreamProductsRequest, ProductService_StreamProductsServer) error
 	mustEmbedUnimplementedProductServiceServer()
 }
 
@@ -175,6 +211,9 @@ func (UnimplementedProductServiceServer) GetProductsByCategoryID(context.Context
 func (UnimplementedProductServiceServer) UpdateStockBatch(context.Context, *UpdateStockBatchRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateStockBatch not implemented")
 }
+func (UnimplementedProductServiceServer) StreamProducts(*StreamProductsRequest, ProductService_StreamProductsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamProducts not implemented")
+}
 func (UnimplementedProductServiceServer) mustEmbedUnimplementedProductServiceServer() {}
 
 // UnsafeProductServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -332,6 +371,27 @@ func _ProductService_UpdateStockBatch_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductService_StreamProducts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamProductsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProductServiceServer).StreamProducts(m, &productServiceStreamProductsServer{ServerStream: stream})
+}
+
+type ProductService_StreamProductsServer interface {
+	Send(*Product) error
+	grpc.ServerStream
+}
+
+type productServiceStreamProductsServer struct {
+	grpc.ServerStream
+}
+
+func (x *productServiceStreamProductsServer) Send(m *Product) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // ProductService_ServiceDesc is the grpc.ServiceDesc for ProductService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -372,6 +432,12 @@ var ProductService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _ProductService_UpdateStockBatch_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamProducts",
+			Handler:       _ProductService_StreamProducts_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "product/v1/product.proto",
 }
@@ -6,39 +6,72 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
+	"github.com/sonuudigital/microservices/notification-service/internal/dedup"
 	"github.com/sonuudigital/microservices/notification-service/internal/email"
 	"github.com/sonuudigital/microservices/notification-service/internal/events"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/inbox"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"github.com/sonuudigital/microservices/shared/rabbitmq"
+	"github.com/sonuudigital/microservices/shared/tracing"
 )
 
 func main() {
-	logger := logs.NewSlogLogger()
+	logger := logs.NewSlogLogger(logs.WithServiceName("notification-service"))
 	err := godotenv.Load()
 	if err == nil {
 		logger.Info("loaded environment variables from .env file")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "notification-service", logger)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	adminAuth, err := auth.LoadAdminAuthFromEnv(logger)
+	if err != nil {
+		logger.Error("failed to load admin auth", "error", err)
+		os.Exit(1)
+	}
+
+	metricsServer := metrics.StartServer(logger, metrics.WithAdminAuth(adminAuth))
+	defer metricsServer.Close()
+
 	smtpSender, err := initializeSMTPSender(logger)
 	if err != nil {
 		logger.Error("failed to initialize SMTP sender", "error", err)
 		os.Exit(1)
 	}
 
-	rabbitmqConn, err := initializeRabbitMQ(logger)
+	rabbitmqConn, err := rabbitmq.NewServiceClientFromEnv(logger, "notification-service")
 	if err != nil {
 		logger.Error("failed to initialize RabbitMQ", "error", err)
 		os.Exit(1)
 	}
 	defer rabbitmqConn.Close()
 
+	dedupOpts, closeDedupStore, err := initializeDedupStore(logger)
+	if err != nil {
+		logger.Error("failed to initialize dedup store", "error", err)
+		os.Exit(1)
+	}
+	defer closeDedupStore()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	orderCreatedConsumer := events.NewOrderCreatedConsumer(logger, smtpSender, rabbitmqConn)
+	orderCreatedConsumer := events.NewOrderCreatedConsumer(logger, smtpSender, rabbitmqConn, dedupOpts...)
+	userRegisteredConsumer := events.NewUserRegisteredConsumer(logger, smtpSender, rabbitmqConn, dedupOpts...)
+	orderEventsConsumer := events.NewOrderEventsConsumer(logger, smtpSender, rabbitmqConn, dedupOpts...)
 
 	go func() {
 		sigChan := make(chan os.Signal, 1)
@@ -48,10 +81,33 @@ func main() {
 		cancel()
 	}()
 
-	logger.Info("starting OrderCreatedConsumer...")
-	if err := orderCreatedConsumer.Start(ctx); err != nil {
-		logger.Error("OrderCreatedConsumer stopped", "error", err)
-	}
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		logger.Info("starting OrderCreatedConsumer...")
+		if err := orderCreatedConsumer.Start(ctx); err != nil {
+			logger.Error("OrderCreatedConsumer stopped", "error", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		logger.Info("starting UserRegisteredConsumer...")
+		if err := userRegisteredConsumer.Start(ctx); err != nil {
+			logger.Error("UserRegisteredConsumer stopped", "error", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		logger.Info("starting OrderEventsConsumer...")
+		if err := orderEventsConsumer.Start(ctx); err != nil {
+			logger.Error("OrderEventsConsumer stopped", "error", err)
+		}
+	}()
+	wg.Wait()
 
 	logger.Info("service shut down gracefully")
 }
@@ -83,16 +139,38 @@ func initializeSMTPSender(logger logs.Logger) (*email.SMTPSender, error) {
 	return smtpSender, nil
 }
 
-func initializeRabbitMQ(logger logs.Logger) (*rabbitmq.RabbitMQ, error) {
-	rabbitmqURL := os.Getenv("RABBITMQ_URL")
-	if rabbitmqURL == "" {
-		return nil, fmt.Errorf("RABBITMQ_URL is not set")
+// initializeDedupStore keeps the IDs of sent notifications in Redis when
+// REDIS_URL is set. Without it they are only remembered in memory, so an
+// event redelivered after a restart sends its email again.
+func initializeDedupStore(logger logs.Logger) ([]inbox.DedupOption, func(), error) {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		logger.Warn("REDIS_URL is not set, notification dedup state is kept in memory only")
+		return nil, func() {}, nil
 	}
 
-	rabbitmqConn, err := rabbitmq.NewConnection(logger, rabbitmqURL)
+	opts, err := redis.ParseURL(redisURL)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("failed to parse REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	ttl := dedup.DefaultTTL
+	if value := os.Getenv("NOTIFICATION_DEDUP_TTL"); value != "" {
+		if ttl, err = time.ParseDuration(value); err != nil {
+			client.Close()
+			return nil, nil, fmt.Errorf("invalid NOTIFICATION_DEDUP_TTL: %w", err)
+		}
 	}
 
-	return rabbitmqConn, nil
+	logger.Info("notification dedup state is kept in redis", "ttl", ttl)
+	store := dedup.NewRedisSeenStore(client, ttl)
+	return []inbox.DedupOption{inbox.WithSeenStore(store)}, func() { client.Close() }, nil
 }
@@ -0,0 +1,44 @@
+package dedup
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix  = "notification:dedup:"
+	DefaultTTL = 7 * 24 * time.Hour
+)
+
+// RedisSeenStore keeps the IDs of handled events in Redis for ttl, so the
+// consumers do not send an email again for an event redelivered after a
+// restart.
+type RedisSeenStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisSeenStore(client *redis.Client, ttl time.Duration) *RedisSeenStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &RedisSeenStore{client: client, ttl: ttl}
+}
+
+func (s *RedisSeenStore) Seen(ctx context.Context, consumer, eventID string) (bool, error) {
+	n, err := s.client.Exists(ctx, key(consumer, eventID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *RedisSeenStore) MarkSeen(ctx context.Context, consumer, eventID string) error {
+	return s.client.Set(ctx, key(consumer, eventID), 1, s.ttl).Err()
+}
+
+func key(consumer, eventID string) string {
+	return keyPrefix + consumer + ":" + eventID
+}
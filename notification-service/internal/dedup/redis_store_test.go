@@ -0,0 +1,37 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisSeenStore(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := NewRedisSeenStore(client, time.Hour)
+	ctx := context.Background()
+
+	mock.ExpectExists("notification:dedup:consumer:event-1").SetVal(0)
+	seen, err := store.Seen(ctx, "consumer", "event-1")
+	require.NoError(t, err)
+	assert.False(t, seen)
+
+	mock.ExpectSet("notification:dedup:consumer:event-1", 1, time.Hour).SetVal("OK")
+	require.NoError(t, store.MarkSeen(ctx, "consumer", "event-1"))
+
+	mock.ExpectExists("notification:dedup:consumer:event-1").SetVal(1)
+	seen, err = store.Seen(ctx, "consumer", "event-1")
+	require.NoError(t, err)
+	assert.True(t, seen)
+
+	mock.ExpectExists("notification:dedup:consumer:event-2").SetErr(errors.New("connection refused"))
+	_, err = store.Seen(ctx, "consumer", "event-2")
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
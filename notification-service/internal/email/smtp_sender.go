@@ -25,6 +25,16 @@ func (s *SMTPSender) Send(data any) error {
 	switch event := data.(type) {
 	case events.OrderCreatedEvent:
 		return s.sendOrderCreated(event)
+	case events.UserEvent:
+		return s.sendWelcome(event)
+	case events.OrderPaidEvent:
+		return s.sendOrderStatus(event.UserEmail, event.OrderID, "Order Paid", "We received your payment of "+event.Amount+" and are preparing your order.")
+	case events.OrderCancelledEvent:
+		return s.sendOrderStatus(event.UserEmail, event.OrderID, "Order Cancelled", "Your order was cancelled: "+event.Reason+".")
+	case events.OrderShippedEvent:
+		return s.sendOrderStatus(event.UserEmail, event.OrderID, "Order Shipped", "Your order is on its way.")
+	case events.OrderDeliveredEvent:
+		return s.sendOrderStatus(event.UserEmail, event.OrderID, "Order Delivered", "Your order was delivered.")
 	default:
 		return fmt.Errorf("unsupported event type: %T", data)
 	}
@@ -45,3 +55,29 @@ func (s *SMTPSender) sendOrderCreated(event events.OrderCreatedEvent) error {
 	})
 	return s.dialer.DialAndSend(m)
 }
+
+func (s *SMTPSender) sendWelcome(event events.UserEvent) error {
+	m := gomail.NewMessage(func(m *gomail.Message) {
+		m.SetHeader("From", s.from)
+		m.SetHeader("To", event.Email)
+		m.SetHeader("Subject", "Welcome, "+event.Username+"!")
+		body := "Dear " + event.Username + ",\n\n"
+		body += "Thank you for signing up. Your account is ready to use.\n"
+		body += "\nWe are glad to have you with us!\n"
+		m.SetBody("text/plain", body)
+	})
+	return s.dialer.DialAndSend(m)
+}
+
+func (s *SMTPSender) sendOrderStatus(to, orderID, subject, text string) error {
+	m := gomail.NewMessage(func(m *gomail.Message) {
+		m.SetHeader("From", s.from)
+		m.SetHeader("To", to)
+		m.SetHeader("Subject", subject+": "+orderID)
+		body := "Dear User,\n\n"
+		body += text + "\n"
+		body += "\nWe appreciate your business!\n"
+		m.SetBody("text/plain", body)
+	})
+	return s.dialer.DialAndSend(m)
+}
@@ -2,16 +2,15 @@ package events
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 
-	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
 	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/inbox"
 	"github.com/sonuudigital/microservices/shared/logs"
 )
 
 const (
-	exchangeName string = "order_created_exchange"
-	queueName    string = "notification_queue"
 	consumerName string = "notification_order_created_consumer"
 )
 
@@ -19,47 +18,50 @@ type Sender interface {
 	Send(data any) error
 }
 
-type MessageSubscriber interface {
-	Subscribe(ctx context.Context, exchangeName, queueName, consumerName string, handler func(ctx context.Context, d amqp091.Delivery)) error
-}
-
 type OrderCreatedConsumer struct {
 	logger     logs.Logger
 	sender     Sender
-	subscriber MessageSubscriber
+	subscriber broker.Subscriber
+	dedup      *inbox.Deduplicator
 }
 
-func NewOrderCreatedConsumer(logger logs.Logger, sender Sender, subscriber MessageSubscriber) *OrderCreatedConsumer {
+func NewOrderCreatedConsumer(logger logs.Logger, sender Sender, subscriber broker.Subscriber, dedupOpts ...inbox.DedupOption) *OrderCreatedConsumer {
 	return &OrderCreatedConsumer{
 		logger:     logger,
 		sender:     sender,
 		subscriber: subscriber,
+		dedup:      inbox.NewDeduplicator(logger, consumerName, inbox.DefaultDedupWindow, dedupOpts...),
 	}
 }
 
 func (occ *OrderCreatedConsumer) Start(ctx context.Context) error {
-	return occ.subscriber.Subscribe(ctx, exchangeName, queueName, consumerName, occ.handleOrderCreatedEvent)
+	sub, err := events.Registry.Subscription(events.NotificationOrderCreatedQueueName)
+	if err != nil {
+		return err
+	}
+	sub.Consumer = consumerName
+	sub.Handler = occ.dedup.Wrap(occ.handleOrderCreatedEvent)
+	return occ.subscriber.Subscribe(ctx, sub)
 }
 
-func (occ *OrderCreatedConsumer) handleOrderCreatedEvent(ctx context.Context, d amqp091.Delivery) {
-	var orderCreatedEvent events.OrderCreatedEvent
-	if err := json.Unmarshal(d.Body, &orderCreatedEvent); err != nil {
+func (occ *OrderCreatedConsumer) handleOrderCreatedEvent(ctx context.Context, msg broker.Message) error {
+	envelope, orderCreatedEvent, err := events.Decode[events.OrderCreatedEvent](msg.Body)
+	if err != nil {
 		occ.logger.Error("failed to unmarshal OrderCreatedEvent", "error", err)
-		d.Nack(false, false)
-		return
+		return fmt.Errorf("failed to unmarshal OrderCreatedEvent: %w", err)
 	}
 
 	if err := occ.sender.Send(orderCreatedEvent); err != nil {
 		occ.logger.Error("failed to send notification for OrderCreatedEvent", "error", err)
-		d.Nack(false, true)
-		return
+		return broker.Retryable(fmt.Errorf("failed to send notification: %w", err))
 	}
 
 	occ.logger.Info(
 		"successfully processed OrderCreatedEvent",
+		"eventId", envelope.ID,
 		"orderId", orderCreatedEvent.OrderID,
 		"userId", orderCreatedEvent.UserID,
 		"userEmail", orderCreatedEvent.UserEmail,
 	)
-	d.Ack(false)
+	return nil
 }
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/broker/memory"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	mu       sync.Mutex
+	failures int
+	sent     []events.OrderCreatedEvent
+}
+
+func (s *fakeSender) Send(data any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("smtp unavailable")
+	}
+	s.sent = append(s.sent, data.(events.OrderCreatedEvent))
+	return nil
+}
+
+func (s *fakeSender) Sent() []events.OrderCreatedEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]events.OrderCreatedEvent(nil), s.sent...)
+}
+
+// retryFast subscribes with millisecond retry delays so retries finish
+// within the test.
+type retryFast struct {
+	broker.Subscriber
+}
+
+func (r retryFast) Subscribe(ctx context.Context, sub broker.Subscription) error {
+	sub.RetryDelays = []time.Duration{time.Millisecond}
+	return r.Subscriber.Subscribe(ctx, sub)
+}
+
+func startConsumer(t *testing.T, sender Sender) *memory.Broker {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	b := memory.New()
+	go func() { _ = NewOrderCreatedConsumer(logs.NewSlogLogger(), sender, retryFast{b}).Start(ctx) }()
+	return b
+}
+
+func publishOrderCreated(t *testing.T, b *memory.Broker, body []byte) {
+	t.Helper()
+	// The consumer binds its queue asynchronously, so publishing fails as
+	// unroutable until it has subscribed.
+	require.Eventually(t, func() bool {
+		return b.Publish(context.Background(), broker.Publishing{Exchange: events.OrderCreatedExchangeName, Kind: broker.ExchangeFanout, Body: body}) == nil
+	}, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, b.Wait(ctx))
+}
+
+func TestOrderCreatedConsumer(t *testing.T) {
+	event := events.OrderCreatedEvent{OrderID: "order-1", UserID: "user-1", UserEmail: "user@example.com"}
+	body, err := events.Wrap("/order-service", events.OrderCreatedEventType, event.OrderID, event)
+	require.NoError(t, err)
+
+	t.Run("SendsNotification", func(t *testing.T) {
+		sender := &fakeSender{}
+		b := startConsumer(t, sender)
+
+		publishOrderCreated(t, b, body)
+		publishOrderCreated(t, b, body)
+
+		assert.Equal(t, []events.OrderCreatedEvent{event}, sender.Sent(), "redelivery must not send twice")
+		assert.Empty(t, b.DeadLetters(events.NotificationOrderCreatedQueueName))
+	})
+
+	t.Run("RetriesSendFailures", func(t *testing.T) {
+		sender := &fakeSender{failures: 2}
+		b := startConsumer(t, sender)
+
+		publishOrderCreated(t, b, body)
+
+		assert.Len(t, sender.Sent(), 1)
+		assert.Empty(t, b.DeadLetters(events.NotificationOrderCreatedQueueName))
+	})
+
+	t.Run("DeadLettersInvalidEvents", func(t *testing.T) {
+		sender := &fakeSender{}
+		b := startConsumer(t, sender)
+
+		publishOrderCreated(t, b, []byte(`{invalid`))
+
+		assert.Empty(t, sender.Sent())
+		assert.Len(t, b.DeadLetters(events.NotificationOrderCreatedQueueName), 1)
+	})
+}
@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/inbox"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	orderEventsConsumerName string = "notification_order_events_consumer"
+)
+
+// OrderEventsConsumer emails customers when their order is paid, cancelled,
+// shipped or delivered. order.created is still handled by
+// OrderCreatedConsumer on the legacy fanout exchange, so it is skipped here.
+type OrderEventsConsumer struct {
+	logger     logs.Logger
+	sender     Sender
+	subscriber broker.Subscriber
+	dedup      *inbox.Deduplicator
+}
+
+func NewOrderEventsConsumer(logger logs.Logger, sender Sender, subscriber broker.Subscriber, dedupOpts ...inbox.DedupOption) *OrderEventsConsumer {
+	return &OrderEventsConsumer{
+		logger:     logger,
+		sender:     sender,
+		subscriber: subscriber,
+		dedup:      inbox.NewDeduplicator(logger, orderEventsConsumerName, inbox.DefaultDedupWindow, dedupOpts...),
+	}
+}
+
+func (oec *OrderEventsConsumer) Start(ctx context.Context) error {
+	sub, err := events.Registry.Subscription(events.NotificationOrderEventsQueueName)
+	if err != nil {
+		return err
+	}
+	sub.Consumer = orderEventsConsumerName
+	sub.Handler = oec.dedup.Wrap(oec.handleOrderEvent)
+	return oec.subscriber.Subscribe(ctx, sub)
+}
+
+func (oec *OrderEventsConsumer) handleOrderEvent(ctx context.Context, msg broker.Message) error {
+	envelope, err := events.ParseEnvelope(msg.Body)
+	if err != nil {
+		oec.logger.Error("failed to unmarshal order event", "error", err)
+		return fmt.Errorf("failed to unmarshal order event: %w", err)
+	}
+
+	var (
+		notification any
+		userEmail    string
+	)
+	switch envelope.Type {
+	case events.OrderPaidEventType:
+		notification, userEmail, err = decodeOrderEvent(msg.Body, func(e events.OrderPaidEvent) string { return e.UserEmail })
+	case events.OrderCancelledEventType:
+		notification, userEmail, err = decodeOrderEvent(msg.Body, func(e events.OrderCancelledEvent) string { return e.UserEmail })
+	case events.OrderShippedEventType:
+		notification, userEmail, err = decodeOrderEvent(msg.Body, func(e events.OrderShippedEvent) string { return e.UserEmail })
+	case events.OrderDeliveredEventType:
+		notification, userEmail, err = decodeOrderEvent(msg.Body, func(e events.OrderDeliveredEvent) string { return e.UserEmail })
+	default:
+		oec.logger.Debug("ignoring order event", "type", envelope.Type, "eventId", envelope.ID)
+		return nil
+	}
+	if err != nil {
+		oec.logger.Error("failed to unmarshal order event", "type", envelope.Type, "error", err)
+		return fmt.Errorf("failed to unmarshal %s: %w", envelope.Type, err)
+	}
+
+	if userEmail == "" {
+		// Orders placed before the email was stored on the order.
+		oec.logger.Warn("order event has no user email, skipping notification", "type", envelope.Type, "orderId", envelope.Subject)
+		return nil
+	}
+
+	if err := oec.sender.Send(notification); err != nil {
+		oec.logger.Error("failed to send order notification", "error", err, "type", envelope.Type)
+		return broker.Retryable(fmt.Errorf("failed to send order notification: %w", err))
+	}
+
+	oec.logger.Info(
+		"successfully processed order event",
+		"type", envelope.Type,
+		"eventId", envelope.ID,
+		"orderId", envelope.Subject,
+		"userEmail", userEmail,
+	)
+	return nil
+}
+
+func decodeOrderEvent[T any](body []byte, userEmail func(T) string) (any, string, error) {
+	_, event, err := events.Decode[T](body)
+	if err != nil {
+		return nil, "", err
+	}
+	return event, userEmail(event), nil
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/broker/memory"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []any
+}
+
+func (s *recordingSender) Send(data any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, data)
+	return nil
+}
+
+func (s *recordingSender) Sent() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]any(nil), s.sent...)
+}
+
+func TestOrderEventsConsumer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sender := &recordingSender{}
+	b := memory.New()
+	go func() { _ = NewOrderEventsConsumer(logs.NewSlogLogger(), sender, b).Start(ctx) }()
+
+	publish := func(eventType string, data any) {
+		t.Helper()
+		body, err := events.Wrap("/order-service", eventType, "order-1", data)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return b.Publish(context.Background(), broker.Publishing{Exchange: events.OrderExchangeName, Kind: broker.ExchangeTopic, RoutingKey: eventType, Body: body}) == nil
+		}, time.Second, time.Millisecond)
+	}
+
+	shipped := events.OrderShippedEvent{OrderID: "order-1", UserID: "user-1", UserEmail: "user@example.com"}
+	publish(events.OrderCreatedEventType, events.OrderCreatedEvent{OrderID: "order-1", UserEmail: "user@example.com"})
+	publish(events.OrderShippedEventType, shipped)
+	publish(events.OrderDeliveredEventType, events.OrderDeliveredEvent{OrderID: "order-1", UserID: "user-1"})
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer waitCancel()
+	require.NoError(t, b.Wait(waitCtx))
+
+	assert.Equal(t, []any{shipped}, sender.Sent(), "order.created is sent by OrderCreatedConsumer and events without an email are skipped")
+	assert.Empty(t, b.DeadLetters(events.NotificationOrderEventsQueueName))
+}
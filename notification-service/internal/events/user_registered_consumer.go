@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/inbox"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	userRegisteredConsumerName string = "notification_user_registered_consumer"
+)
+
+// UserRegisteredConsumer sends the welcome email to newly registered users.
+type UserRegisteredConsumer struct {
+	logger     logs.Logger
+	sender     Sender
+	subscriber broker.Subscriber
+	dedup      *inbox.Deduplicator
+}
+
+func NewUserRegisteredConsumer(logger logs.Logger, sender Sender, subscriber broker.Subscriber, dedupOpts ...inbox.DedupOption) *UserRegisteredConsumer {
+	return &UserRegisteredConsumer{
+		logger:     logger,
+		sender:     sender,
+		subscriber: subscriber,
+		dedup:      inbox.NewDeduplicator(logger, userRegisteredConsumerName, inbox.DefaultDedupWindow, dedupOpts...),
+	}
+}
+
+func (urc *UserRegisteredConsumer) Start(ctx context.Context) error {
+	sub, err := events.Registry.Subscription(events.NotificationUserRegisteredQueueName)
+	if err != nil {
+		return err
+	}
+	sub.Consumer = userRegisteredConsumerName
+	sub.Handler = urc.dedup.Wrap(urc.handleUserRegisteredEvent)
+	return urc.subscriber.Subscribe(ctx, sub)
+}
+
+func (urc *UserRegisteredConsumer) handleUserRegisteredEvent(ctx context.Context, msg broker.Message) error {
+	envelope, userEvent, err := events.Decode[events.UserEvent](msg.Body)
+	if err != nil {
+		urc.logger.Error("failed to unmarshal UserEvent", "error", err)
+		return fmt.Errorf("failed to unmarshal UserEvent: %w", err)
+	}
+
+	if err := urc.sender.Send(userEvent); err != nil {
+		urc.logger.Error("failed to send welcome email", "error", err)
+		return broker.Retryable(fmt.Errorf("failed to send welcome email: %w", err))
+	}
+
+	urc.logger.Info(
+		"successfully processed UserRegisteredEvent",
+		"eventId", envelope.ID,
+		"userId", userEvent.ID,
+		"userEmail", userEvent.Email,
+	)
+	return nil
+}
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/broker/memory"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type welcomeSender struct {
+	mu   sync.Mutex
+	sent []events.UserEvent
+}
+
+func (s *welcomeSender) Send(data any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, data.(events.UserEvent))
+	return nil
+}
+
+func (s *welcomeSender) Sent() []events.UserEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]events.UserEvent(nil), s.sent...)
+}
+
+func TestUserRegisteredConsumer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sender := &welcomeSender{}
+	b := memory.New()
+	go func() { _ = NewUserRegisteredConsumer(logs.NewSlogLogger(), sender, b).Start(ctx) }()
+
+	event := events.UserEvent{ID: "user-1", Username: "user", Email: "user@example.com"}
+	body, err := events.Wrap("/user-service", events.UserRegisteredEventType, event.ID, event)
+	require.NoError(t, err)
+
+	publish := func(routingKey string) error {
+		return b.Publish(context.Background(), broker.Publishing{Exchange: events.UserExchangeName, Kind: broker.ExchangeTopic, RoutingKey: routingKey, Body: body})
+	}
+	require.Eventually(t, func() bool { return publish(events.UserRegisteredRoutingKey) == nil }, time.Second, time.Millisecond)
+	require.NoError(t, publish(events.UserRegisteredRoutingKey))
+	assert.ErrorIs(t, publish(events.UserUpdatedRoutingKey), broker.ErrUnroutable, "only user.registered is bound")
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer waitCancel()
+	require.NoError(t, b.Wait(waitCtx))
+
+	assert.Equal(t, []events.UserEvent{event}, sender.Sent(), "redelivery must not send twice")
+}
@@ -7,9 +7,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
-	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
@@ -19,10 +17,14 @@ import (
 	"github.com/sonuudigital/microservices/order-service/internal/grpc/order"
 	"github.com/sonuudigital/microservices/order-service/internal/repository"
 	postgres_repo "github.com/sonuudigital/microservices/order-service/internal/repository/postgres"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/events"
 	"github.com/sonuudigital/microservices/shared/events/worker"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"github.com/sonuudigital/microservices/shared/postgres"
 	"github.com/sonuudigital/microservices/shared/rabbitmq"
+	"github.com/sonuudigital/microservices/shared/tracing"
 	"github.com/sonuudigital/microservices/shared/web"
 	"github.com/sonuudigital/microservices/shared/web/health"
 	"golang.org/x/sync/errgroup"
@@ -30,7 +32,7 @@ import (
 )
 
 func main() {
-	logger := logs.NewSlogLogger()
+	logger := logs.NewSlogLogger(logs.WithServiceName("order-service"))
 	err := godotenv.Load()
 	if err == nil {
 		logger.Info("loaded environment variables from .env file")
@@ -38,6 +40,13 @@ func main() {
 		logger.Info("no .env file found, using environment variables")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "order-service", logger)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
 	pgDb, err := postgres.InitializePostgresDB()
 	if err != nil {
 		logger.Error("error connecting to database", "error", err)
@@ -46,23 +55,41 @@ func main() {
 	logger.Info("database connected successfully")
 	defer pgDb.Close()
 
-	grpcClients, err := initializegRPCClients()
+	adminAuth, err := auth.LoadAdminAuthFromEnv(logger)
+	if err != nil {
+		logger.Error("failed to load admin auth", "error", err)
+		os.Exit(1)
+	}
+
+	metricsServer := metrics.StartServer(logger, metrics.WithAdminAuth(adminAuth), metrics.WithAdminHandler(
+		worker.OutboxAdminPath,
+		worker.NewOutboxAdminHandler(logger, postgres_repo.NewOutboxEventMessageRelayerRepository(pgDb)),
+	))
+	defer metricsServer.Close()
+
+	tlsConfig, err := web.LoadTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("failed to load TLS config", "error", err)
+		os.Exit(1)
+	}
+
+	grpcClients, err := initializegRPCClients(tlsConfig, logger)
 	if err != nil {
 		logger.Error("failed to initialize gRPC clients", "error", err)
 		os.Exit(1)
 	}
 
-	rabbitmq, err := initializeRabbitMQ(logger)
+	rabbitmq, err := rabbitmq.NewServiceClientFromEnv(logger, "order-service")
 	if err != nil {
 		logger.Error("failed to initialize RabbitMQ", "error", err)
 		os.Exit(1)
 	}
 	defer rabbitmq.Close()
 
-	initializeServicesAndWaitForShutdown(logger, rabbitmq, pgDb, grpcClients)
+	initializeServicesAndWaitForShutdown(logger, rabbitmq, pgDb, grpcClients, tlsConfig)
 }
 
-func initializeServicesAndWaitForShutdown(logger logs.Logger, rabbitmq *rabbitmq.RabbitMQ, pgDb *pgxpool.Pool, grpcClients *clients.Clients) {
+func initializeServicesAndWaitForShutdown(logger logs.Logger, rabbitmq *rabbitmq.Client, pgDb *pgxpool.Pool, grpcClients *clients.Clients, tlsConfig web.TLSConfig) {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
@@ -70,18 +97,23 @@ func initializeServicesAndWaitForShutdown(logger logs.Logger, rabbitmq *rabbitmq
 
 	orderRepo := repository.NewPostgreSQLOrderRepository(pgDb)
 
-	mrPollInterval, mrBatchSize, err := getMessageRelayerConfigFromEnv()
+	relayer, err := worker.NewOutboxEventMessageRelayerFromEnv(
+		logger,
+		worker.NewEventPublisher(rabbitmq, events.Registry),
+		postgres_repo.NewOutboxEventMessageRelayerRepository(pgDb),
+	)
 	if err != nil {
-		logger.Error("failed to get message relayer config", "error", err)
+		logger.Error("failed to configure message relayer", "error", err)
 		os.Exit(1)
 	}
-	go worker.NewOutboxEventMessageRelayer(
-		logger,
-		rabbitmq,
-		postgres_repo.NewOutboxEventMessageRelayerRepository(pgDb),
-		mrPollInterval,
-		mrBatchSize,
-	).Start(ctx)
+	go relayer.Start(gCtx)
+
+	retentionPolicy, err := worker.RetentionPolicyFromEnv()
+	if err != nil {
+		logger.Error("failed to get outbox retention policy", "error", err)
+		os.Exit(1)
+	}
+	go worker.NewOutboxRetentionJob(logger, postgres_repo.NewOutboxEventMessageRelayerRepository(pgDb), retentionPolicy).Start(gCtx)
 
 	g.Go(func() error {
 		stockUpdateFailedConsumer := consumers.NewStockUpdateFailedConsumer(logger, orderRepo, rabbitmq)
@@ -96,7 +128,31 @@ func initializeServicesAndWaitForShutdown(logger logs.Logger, rabbitmq *rabbitmq
 	})
 
 	g.Go(func() error {
-		return startGRPCServer(gCtx, logger, pgDb, grpcClients, orderRepo)
+		userEventsConsumer := consumers.NewUserEventsConsumer(logger, orderRepo, rabbitmq)
+		logger.Info("starting UserEventsConsumer")
+
+		if err := userEventsConsumer.Start(gCtx); err != nil {
+			return fmt.Errorf("UserEventsConsumer failed: %w", err)
+		}
+
+		logger.Info("UserEventsConsumer stopped gracefully")
+		return nil
+	})
+
+	g.Go(func() error {
+		paymentEventsConsumer := consumers.NewPaymentEventsConsumer(logger, orderRepo, rabbitmq)
+		logger.Info("starting PaymentEventsConsumer")
+
+		if err := paymentEventsConsumer.Start(gCtx); err != nil {
+			return fmt.Errorf("PaymentEventsConsumer failed: %w", err)
+		}
+
+		logger.Info("PaymentEventsConsumer stopped gracefully")
+		return nil
+	})
+
+	g.Go(func() error {
+		return startGRPCServer(gCtx, logger, pgDb, rabbitmq, grpcClients, orderRepo, tlsConfig)
 	})
 
 	if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
@@ -107,7 +163,7 @@ func initializeServicesAndWaitForShutdown(logger logs.Logger, rabbitmq *rabbitmq
 	logger.Info("application shut down gracefully")
 }
 
-func startGRPCServer(ctx context.Context, logger logs.Logger, pgDb *pgxpool.Pool, grpcClients *clients.Clients, orderRepo *repository.PostgreSQLOrderRepository) error {
+func startGRPCServer(ctx context.Context, logger logs.Logger, pgDb *pgxpool.Pool, rabbitmq *rabbitmq.Client, grpcClients *clients.Clients, orderRepo *repository.PostgreSQLOrderRepository, tlsConfig web.TLSConfig) error {
 	gRPCPort := os.Getenv("ORDER_SERVICE_GRPC_PORT")
 	if gRPCPort == "" {
 		return fmt.Errorf("ORDER_SERVICE_GRPC_PORT is not set")
@@ -118,27 +174,36 @@ func startGRPCServer(ctx context.Context, logger logs.Logger, pgDb *pgxpool.Pool
 		return fmt.Errorf("failed to listen for gRPC: %w", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	serverOpts, err := web.NewGRPCServerCredentials(tlsConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC server credentials: %w", err)
+	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logs.UnaryServerInterceptor()))
+
+	identityVerifier, err := auth.LoadIdentityTokenVerifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load identity token verifier: %w", err)
+	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(identityVerifier, logger)))
+
+	serverOpts = append(serverOpts, tracing.GRPCServerOption())
+	grpcServer := grpc.NewServer(serverOpts...)
 	orderGrpcServer := order.New(logger, orderRepo, grpcClients)
 	orderv1.RegisterOrderServiceServer(grpcServer, orderGrpcServer)
 
-	health.StartGRPCHealthCheckService(grpcServer, "order-service", func(ctx context.Context) error {
-		ctx, cancel := context.WithTimeout(ctx, time.Second*3)
-		defer cancel()
-
-		if err := pgDb.Ping(ctx); err != nil {
-			logger.Info("service is not healthy", "error", err)
-			return err
-		} else {
-			logger.Info("service is healthy and serving")
-			return nil
-		}
-	})
+	checks := []health.Check{
+		health.NewCheck("postgres", health.DefaultCheckTimeout, pgDb.Ping),
+		health.NewCheck("rabbitmq", health.DefaultCheckTimeout, func(context.Context) error {
+			return rabbitmq.Ping()
+		}),
+	}
+	checks = append(checks, grpcClients.HealthChecks()...)
+	health.StartGRPCHealthCheckService(ctx, grpcServer, "order-service", health.NewChecker(logger, health.DefaultCheckInterval, checks...))
 
 	return web.StartGRPCServerAndWaitForShutdown(ctx, grpcServer, lis, logger)
 }
 
-func initializegRPCClients() (*clients.Clients, error) {
+func initializegRPCClients(tlsConfig web.TLSConfig, logger logs.Logger) (*clients.Clients, error) {
 	cartServiceURL := os.Getenv("CART_SERVICE_GRPC_URL")
 	if cartServiceURL == "" {
 		return nil, fmt.Errorf("CART_SERVICE_GRPC_URL is not set")
@@ -154,49 +219,16 @@ func initializegRPCClients() (*clients.Clients, error) {
 		return nil, fmt.Errorf("USER_SERVICE_GRPC_URL is not set")
 	}
 
-	clientsURL := clients.NewClienstURL(cartServiceURL, paymentServiceURL, userServiceURL)
-	grpcClients, err := clients.NewClients(clientsURL)
+	creds, err := web.NewGRPCClientCredentials(tlsConfig, logger)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to create gRPC client credentials: %w", err)
 	}
 
-	return grpcClients, nil
-}
-
-func initializeRabbitMQ(logger logs.Logger) (*rabbitmq.RabbitMQ, error) {
-	rabbitmqURL := os.Getenv("RABBITMQ_URL")
-	if rabbitmqURL == "" {
-		return nil, fmt.Errorf("RABBITMQ_URL is not set")
-	}
-
-	rabbitmq, err := rabbitmq.NewConnection(logger, rabbitmqURL)
+	clientsURL := clients.NewClienstURL(cartServiceURL, paymentServiceURL, userServiceURL)
+	grpcClients, err := clients.NewClients(clientsURL, creds, tracing.GRPCDialOption(), grpc.WithChainUnaryInterceptor(metrics.UnaryClientInterceptor(), logs.UnaryClientInterceptor()), grpc.WithUnaryInterceptor(auth.PropagatingUnaryClientInterceptor()))
 	if err != nil {
 		return nil, err
 	}
 
-	return rabbitmq, nil
-}
-
-func getMessageRelayerConfigFromEnv() (time.Duration, int32, error) {
-	pollIntervalStr := os.Getenv("MESSAGE_RELAYER_POLL_INTERVAL")
-	if pollIntervalStr == "" {
-		pollIntervalStr = "5s"
-	}
-
-	pollInterval, err := time.ParseDuration(pollIntervalStr)
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid MESSAGE_RELAYER_POLL_INTERVAL: %w", err)
-	}
-
-	batchSizeStr := os.Getenv("MESSAGE_RELAYER_BATCH_SIZE")
-	if batchSizeStr == "" {
-		batchSizeStr = "25"
-	}
-
-	batchSize, err := strconv.Atoi(batchSizeStr)
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid MESSAGE_RELAYER_BATCH_SIZE: %w", err)
-	}
-
-	return pollInterval, int32(batchSize), nil
+	return grpcClients, nil
 }
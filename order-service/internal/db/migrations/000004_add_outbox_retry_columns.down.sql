@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_outbox_events_claim;
+ALTER TABLE outbox_events
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS attempts;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- Before lifecycle statuses an order had a single outbox event and no PAID or
+-- SHIPPED status. Refuse to roll back instead of deleting the later events and
+-- rewriting those orders.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM orders
+        JOIN order_statuses ON order_statuses.id = orders.status
+        WHERE order_statuses.name IN ('PAID', 'SHIPPED')
+    ) THEN
+        RAISE EXCEPTION 'cannot roll back: orders are PAID or SHIPPED';
+    END IF;
+
+    IF EXISTS (
+        SELECT 1
+        FROM outbox_events
+        GROUP BY aggregate_id
+        HAVING COUNT(*) > 1
+    ) THEN
+        RAISE EXCEPTION 'cannot roll back: orders have more than one outbox event';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_outbox_events_aggregate_id;
+ALTER TABLE outbox_events ADD CONSTRAINT outbox_events_aggregate_id_key UNIQUE (aggregate_id);
+
+DELETE FROM order_statuses WHERE name IN ('PAID', 'SHIPPED');
\ No newline at end of file
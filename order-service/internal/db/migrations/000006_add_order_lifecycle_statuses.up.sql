@@ -0,0 +1,8 @@
+INSERT INTO order_statuses (name)
+VALUES
+    ('PAID'),
+    ('SHIPPED')
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE outbox_events DROP CONSTRAINT IF EXISTS outbox_events_aggregate_id_key;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate_id ON outbox_events (aggregate_id);
\ No newline at end of file
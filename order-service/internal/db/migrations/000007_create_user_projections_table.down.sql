@@ -0,0 +1 @@
+DROP TABLE IF EXISTS user_projections;
\ No newline at end of file
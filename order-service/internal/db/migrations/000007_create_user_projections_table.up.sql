@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS user_projections (
+    id UUID PRIMARY KEY,
+    username TEXT NOT NULL,
+    email TEXT NOT NULL,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    version TIMESTAMP WITH TIME ZONE NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS user_email;
\ No newline at end of file
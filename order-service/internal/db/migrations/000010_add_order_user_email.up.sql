@@ -0,0 +1,7 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS user_email TEXT;
+
+UPDATE orders
+SET user_email = user_projections.email
+FROM user_projections
+WHERE user_projections.id = orders.user_id
+    AND orders.user_email IS NULL;
\ No newline at end of file
@@ -1,6 +1,6 @@
 -- name: CreateOrder :one
-INSERT INTO orders (user_id, total_amount)
-VALUES ($1, $2)
+INSERT INTO orders (user_id, user_email, total_amount)
+VALUES ($1, $2, $3)
 RETURNING *;
 
 -- name: GetOrderById :one
@@ -14,6 +14,13 @@ SET status = $2
 WHERE id = $1
 RETURNING *;
 
+-- name: TransitionOrderStatus :one
+UPDATE orders
+SET status = (SELECT s.id FROM order_statuses s WHERE s.name = sqlc.arg(to_status)::VARCHAR)
+WHERE orders.id = sqlc.arg(id)
+    AND orders.status IN (SELECT s.id FROM order_statuses s WHERE s.name = ANY(sqlc.arg(from_statuses)::VARCHAR[]))
+RETURNING *;
+
 -- name: GetOrderStatusByName :one
 SELECT id, name
 FROM order_statuses
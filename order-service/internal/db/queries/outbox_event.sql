@@ -1,6 +1,6 @@
 -- name: CreateOutboxEvent :exec
-INSERT INTO outbox_events (aggregate_id, event_name, payload)
-VALUES ($1, $2, $3);
+INSERT INTO outbox_events (aggregate_id, event_name, payload, trace_context)
+VALUES ($1, $2, $3, $4);
 
 -- name: UpdateOutboxEventStatus :exec
 UPDATE outbox_events
@@ -10,16 +10,96 @@ SET
 WHERE
     id = $1;
 
--- name: CancelOutboxEventStatusByAggregateID :exec
+-- name: CancelOutboxEventStatusByAggregateID :many
 UPDATE outbox_events
 SET
     status = 'CANCELLED'
 WHERE
-    aggregate_id = $1;
+    aggregate_id = $1
+    AND status = 'UNPUBLISHED'
+RETURNING event_name, attempts;
 
--- name: GetUnpublishedOutboxEvents :many
-SELECT *
+-- name: ClaimUnpublishedOutboxEvents :many
+UPDATE outbox_events
+SET
+    attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::FLOAT8)
+WHERE id IN (
+    SELECT candidates.id
+    FROM outbox_events AS candidates
+    WHERE candidates.status = 'UNPUBLISHED'
+        AND candidates.next_attempt_at <= NOW()
+        -- Keep the events of an aggregate in order: only claim an event once
+        -- every earlier event of its aggregate is published or cancelled, so
+        -- one that is claimed by another relayer, waiting for a retry or
+        -- FAILED holds back the rest.
+        AND NOT EXISTS (
+            SELECT 1
+            FROM outbox_events AS earlier
+            WHERE earlier.aggregate_id = candidates.aggregate_id
+                AND earlier.status IN ('UNPUBLISHED', 'FAILED')
+                AND earlier.created_at < candidates.created_at
+        )
+    ORDER BY candidates.created_at ASC
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, aggregate_id, event_name, payload, status::TEXT AS status, trace_context, attempts, last_error, next_attempt_at, created_at;
+
+-- name: GetOutboxBacklog :one
+SELECT
+    COUNT(*)::BIGINT AS size,
+    MIN(created_at)::TIMESTAMPTZ AS oldest_created_at
+FROM outbox_events
+WHERE status = 'UNPUBLISHED';
+
+-- name: RecordOutboxEventFailure :exec
+UPDATE outbox_events
+SET
+    status = CASE WHEN sqlc.arg(failed)::BOOLEAN THEN 'FAILED'::outbox_event_status ELSE status END,
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE
+    id = sqlc.arg(id);
+
+-- name: ListOutboxEventsByStatus :many
+SELECT id, aggregate_id, event_name, payload, status::TEXT AS status, trace_context, attempts, last_error, next_attempt_at, created_at
 FROM outbox_events
-WHERE status = 'UNPUBLISHED'
+WHERE status::TEXT = ANY(sqlc.arg(statuses)::TEXT[])
 ORDER BY created_at ASC
-LIMIT $1;
+LIMIT sqlc.arg(max_events)::INTEGER;
+
+-- name: RequeueOutboxEvent :one
+-- A pending event is only requeued while no relayer holds its lease, so a
+-- publish in flight is not reset underneath the relayer.
+UPDATE outbox_events
+SET
+    status = 'UNPUBLISHED',
+    attempts = 0,
+    last_error = NULL,
+    next_attempt_at = NOW()
+WHERE
+    id = $1
+    AND (
+        status = 'FAILED'
+        OR (status = 'UNPUBLISHED' AND next_attempt_at <= NOW())
+    )
+RETURNING id, aggregate_id, event_name, payload, status::TEXT AS status, trace_context, attempts, last_error, next_attempt_at, created_at;
+
+-- name: ArchiveOutboxEvents :execrows
+WITH archived AS (
+    DELETE FROM outbox_events
+    WHERE id IN (
+        SELECT candidates.id
+        FROM outbox_events AS candidates
+        WHERE candidates.status IN ('PUBLISHED', 'CANCELLED')
+            AND candidates.created_at < sqlc.arg(created_before)::TIMESTAMPTZ
+        ORDER BY candidates.created_at ASC
+        LIMIT sqlc.arg(batch_size)::INTEGER
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, aggregate_id, event_name, payload, status, trace_context, attempts, last_error, created_at, published_at
+)
+INSERT INTO outbox_events_archive (id, aggregate_id, event_name, payload, status, trace_context, attempts, last_error, created_at, published_at)
+SELECT id, aggregate_id, event_name, payload, status, trace_context, attempts, last_error, created_at, published_at
+FROM archived;
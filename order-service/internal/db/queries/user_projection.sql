@@ -0,0 +1,15 @@
+-- name: GetUserProjection :one
+SELECT *
+FROM user_projections
+WHERE id = $1;
+
+-- name: UpsertUserProjection :exec
+INSERT INTO user_projections (id, username, email, deleted, version)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (id) DO UPDATE
+SET
+    username = EXCLUDED.username,
+    email = EXCLUDED.email,
+    deleted = EXCLUDED.deleted,
+    version = EXCLUDED.version
+WHERE user_projections.version < EXCLUDED.version;
@@ -0,0 +1,93 @@
+package consumers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	orderv1 "github.com/sonuudigital/microservices/gen/order/v1"
+	"github.com/sonuudigital/microservices/order-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	paymentEventsConsumerName = "order_payment_events_consumer"
+
+	paymentRejectedReason = "payment rejected"
+	paymentRefundedReason = "payment refunded"
+)
+
+type PaymentEventsConsumerRepository interface {
+	MarkOrderPaid(ctx context.Context, orderID, paymentID string) (*orderv1.Order, error)
+	CancelOrder(ctx context.Context, orderID, reason string) error
+}
+
+// PaymentEventsConsumer brings orders in line with their payments. Checkout
+// already moves the order when ProcessPayment returns, so the events mostly
+// find the order in its new status; they matter when checkout failed after
+// the payment was recorded, and for refunds, which cancel orders that have
+// not been shipped yet.
+type PaymentEventsConsumer struct {
+	logger     logs.Logger
+	repo       PaymentEventsConsumerRepository
+	subscriber broker.Subscriber
+}
+
+func NewPaymentEventsConsumer(logger logs.Logger, repo PaymentEventsConsumerRepository, subscriber broker.Subscriber) *PaymentEventsConsumer {
+	return &PaymentEventsConsumer{
+		logger:     logger,
+		repo:       repo,
+		subscriber: subscriber,
+	}
+}
+
+func (pec *PaymentEventsConsumer) Start(ctx context.Context) error {
+	sub, err := events.Registry.Subscription(events.OrderPaymentEventsQueueName)
+	if err != nil {
+		return err
+	}
+	sub.Consumer = paymentEventsConsumerName
+	sub.Handler = pec.handlePaymentEvent
+	return pec.subscriber.Subscribe(ctx, sub)
+}
+
+func (pec *PaymentEventsConsumer) handlePaymentEvent(ctx context.Context, msg broker.Message) error {
+	envelope, event, err := events.Decode[events.PaymentEvent](msg.Body)
+	if err != nil {
+		pec.logger.Error("failed to unmarshal PaymentEvent", "error", err)
+		return fmt.Errorf("failed to unmarshal PaymentEvent: %w", err)
+	}
+
+	switch envelope.Type {
+	case events.PaymentSucceededEventType:
+		_, err = pec.repo.MarkOrderPaid(ctx, event.OrderID, event.PaymentID)
+	case events.PaymentRejectedEventType:
+		reason := paymentRejectedReason
+		if event.Reason != "" {
+			reason += ": " + event.Reason
+		}
+		err = pec.repo.CancelOrder(ctx, event.OrderID, reason)
+	case events.PaymentRefundedEventType:
+		err = pec.repo.CancelOrder(ctx, event.OrderID, paymentRefundedReason)
+	default:
+		pec.logger.Debug("ignoring payment event", "type", envelope.Type, "eventId", envelope.ID)
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrInvalidOrderTransition):
+		pec.logger.Debug("order already moved past payment event, skipping", "type", envelope.Type, "orderId", event.OrderID)
+		return nil
+	case errors.Is(err, repository.ErrOrderNotFound):
+		pec.logger.Error("order not found for payment event", "type", envelope.Type, "orderId", event.OrderID, "paymentId", event.PaymentID)
+		return nil
+	case err != nil:
+		pec.logger.Error("failed to apply payment event", "error", err, "type", envelope.Type, "orderId", event.OrderID)
+		return broker.Retryable(fmt.Errorf("failed to apply %s: %w", envelope.Type, err))
+	}
+
+	pec.logger.Info("applied payment event to order", "type", envelope.Type, "orderId", event.OrderID, "paymentId", event.PaymentID)
+	return nil
+}
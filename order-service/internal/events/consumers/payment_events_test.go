@@ -0,0 +1,112 @@
+package consumers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	orderv1 "github.com/sonuudigital/microservices/gen/order/v1"
+	"github.com/sonuudigital/microservices/order-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/broker/memory"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePaymentOrderRepository struct {
+	mu    sync.Mutex
+	calls []string
+	err   error
+}
+
+func (r *fakePaymentOrderRepository) MarkOrderPaid(_ context.Context, orderID, paymentID string) (*orderv1.Order, error) {
+	r.record(fmt.Sprintf("paid %s %s", orderID, paymentID))
+	return &orderv1.Order{Id: orderID}, r.err
+}
+
+func (r *fakePaymentOrderRepository) CancelOrder(_ context.Context, orderID, reason string) error {
+	r.record(fmt.Sprintf("cancel %s %s", orderID, reason))
+	return r.err
+}
+
+func (r *fakePaymentOrderRepository) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *fakePaymentOrderRepository) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func publishPaymentEvent(t *testing.T, b *memory.Broker, eventType string, event events.PaymentEvent) {
+	t.Helper()
+
+	body, err := events.Wrap("/payment-service", eventType, event.PaymentID, event)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return b.Publish(context.Background(), broker.Publishing{Exchange: events.PaymentExchangeName, Kind: broker.ExchangeTopic, RoutingKey: eventType, Body: body}) == nil
+	}, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, b.Wait(ctx))
+}
+
+func TestPaymentEventsConsumer(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		event     events.PaymentEvent
+		repoErr   error
+		wantCall  string
+	}{
+		{
+			name:      "Succeeded",
+			eventType: events.PaymentSucceededEventType,
+			event:     events.PaymentEvent{PaymentID: "payment-1", OrderID: "order-1"},
+			wantCall:  "paid order-1 payment-1",
+		},
+		{
+			name:      "Rejected",
+			eventType: events.PaymentRejectedEventType,
+			event:     events.PaymentEvent{PaymentID: "payment-1", OrderID: "order-1", Reason: "amount exceeds the limit"},
+			wantCall:  "cancel order-1 payment rejected: amount exceeds the limit",
+		},
+		{
+			name:      "Refunded",
+			eventType: events.PaymentRefundedEventType,
+			event:     events.PaymentEvent{PaymentID: "payment-1", OrderID: "order-1"},
+			wantCall:  "cancel order-1 payment refunded",
+		},
+		{
+			name:      "OrderAlreadyMoved",
+			eventType: events.PaymentRefundedEventType,
+			event:     events.PaymentEvent{PaymentID: "payment-1", OrderID: "order-1"},
+			repoErr:   repository.ErrInvalidOrderTransition,
+			wantCall:  "cancel order-1 payment refunded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			repo := &fakePaymentOrderRepository{err: tt.repoErr}
+			b := memory.New()
+			go func() { _ = NewPaymentEventsConsumer(logs.NewSlogLogger(), repo, b).Start(ctx) }()
+
+			publishPaymentEvent(t, b, tt.eventType, tt.event)
+
+			assert.Equal(t, []string{tt.wantCall}, repo.Calls())
+			assert.Empty(t, b.DeadLetters(events.OrderPaymentEventsQueueName))
+		})
+	}
+}
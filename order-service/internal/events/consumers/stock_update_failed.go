@@ -2,44 +2,37 @@ package consumers
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/rabbitmq/amqp091-go"
 	"github.com/sonuudigital/microservices/order-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/broker"
 	"github.com/sonuudigital/microservices/shared/events"
 	"github.com/sonuudigital/microservices/shared/logs"
 )
 
 const (
-	exchangeName string = "stock_update_failed_exchange"
-	queueName    string = "order_stock_update_failed_queue"
 	consumerName string = "order_stock_update_failed_consumer"
 
-	orderStatusCancelled string = "CANCELLED"
+	stockUpdateFailedReason = "stock update failed"
 )
 
 type StockUpdateFailedConsumerRepository interface {
 	GetOrderById(ctx context.Context, id pgtype.UUID) (repository.GetOrderByIdRow, error)
 	GetOrderStatusByName(ctx context.Context, name string) (repository.GetOrderStatusByNameRow, error)
-	UpdateOrderStatus(ctx context.Context, arg repository.UpdateOrderStatusParams) (repository.Order, error)
-}
-
-type MessageSubscriber interface {
-	Subscribe(ctx context.Context, exchangeName, queueName, consumerName string, handler func(ctx context.Context, d amqp091.Delivery)) error
+	CancelOrder(ctx context.Context, orderID, reason string) error
 }
 
 type StockUpdateFailedConsumer struct {
 	logger                 logs.Logger
 	repo                   StockUpdateFailedConsumerRepository
-	subscriber             MessageSubscriber
+	subscriber             broker.Subscriber
 	cancelledOrderStatusID pgtype.UUID
 }
 
-func NewStockUpdateFailedConsumer(logger logs.Logger, repo StockUpdateFailedConsumerRepository, subscriber MessageSubscriber) *StockUpdateFailedConsumer {
+func NewStockUpdateFailedConsumer(logger logs.Logger, repo StockUpdateFailedConsumerRepository, subscriber broker.Subscriber) *StockUpdateFailedConsumer {
 	return &StockUpdateFailedConsumer{
 		logger:     logger,
 		repo:       repo,
@@ -51,7 +44,7 @@ func (sufc *StockUpdateFailedConsumer) initStatus(ctx context.Context) error {
 	if sufc.cancelledOrderStatusID.Valid {
 		return nil
 	}
-	status, err := sufc.repo.GetOrderStatusByName(ctx, orderStatusCancelled)
+	status, err := sufc.repo.GetOrderStatusByName(ctx, repository.OrderStatusCancelled)
 	if err != nil {
 		return fmt.Errorf("could not fetch CANCELLED order status ID: %w", err)
 	}
@@ -64,15 +57,20 @@ func (sufc *StockUpdateFailedConsumer) Start(ctx context.Context) error {
 	if err := sufc.initStatus(ctx); err != nil {
 		return err
 	}
-	return sufc.subscriber.Subscribe(ctx, exchangeName, queueName, consumerName, sufc.handleStockUpdateFailedEvent)
+	sub, err := events.Registry.Subscription(events.OrderStockUpdateFailedQueueName)
+	if err != nil {
+		return err
+	}
+	sub.Consumer = consumerName
+	sub.Handler = sufc.handleStockUpdateFailedEvent
+	return sufc.subscriber.Subscribe(ctx, sub)
 }
 
-func (sufc *StockUpdateFailedConsumer) handleStockUpdateFailedEvent(ctx context.Context, d amqp091.Delivery) {
-	event, err := sufc.unmarshalEvent(d.Body)
+func (sufc *StockUpdateFailedConsumer) handleStockUpdateFailedEvent(ctx context.Context, msg broker.Message) error {
+	event, err := sufc.unmarshalEvent(msg.Body)
 	if err != nil {
 		sufc.logger.Error("failed to unmarshal StockUpdateFailedEvent", "error", err)
-		d.Nack(false, false)
-		return
+		return fmt.Errorf("failed to unmarshal StockUpdateFailedEvent: %w", err)
 	}
 
 	sufc.logger.Debug("received StockUpdateFailedEvent", "orderId", event.OrderID)
@@ -80,53 +78,46 @@ func (sufc *StockUpdateFailedConsumer) handleStockUpdateFailedEvent(ctx context.
 	orderUUID, err := parseOrderIDToUUID(event.OrderID)
 	if err != nil {
 		sufc.logger.Error("failed to parse order ID", "error", err, "orderId", event.OrderID)
-		d.Nack(false, false)
-		return
+		return err
 	}
 
 	order, err := sufc.repo.GetOrderById(ctx, orderUUID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			sufc.logger.Error("order not found for cancellation", "orderId", event.OrderID)
-			d.Ack(false)
-			return
+			return nil
 		}
 		sufc.logger.Error("failed to get order for status check", "error", err, "orderId", event.OrderID)
-		d.Nack(false, true)
-		return
+		return broker.Retryable(fmt.Errorf("failed to get order: %w", err))
 	}
 
 	if order.Status == sufc.cancelledOrderStatusID {
 		sufc.logger.Info("order is already cancelled, skipping", "orderId", event.OrderID)
-		d.Ack(false)
-		return
+		return nil
 	}
 
-	if err := sufc.cancelOrder(ctx, orderUUID); err != nil {
+	err = sufc.repo.CancelOrder(ctx, event.OrderID, stockUpdateFailedReason)
+	if errors.Is(err, repository.ErrInvalidOrderTransition) {
+		sufc.logger.Warn("order can no longer be cancelled, skipping", "orderId", event.OrderID)
+		return nil
+	}
+	if err != nil {
 		sufc.logger.Error("failed to cancel order", "error", err, "orderId", event.OrderID)
-		d.Nack(false, true)
-	} else {
-		sufc.logger.Info("order cancelled due to stock update failure", "orderId", event.OrderID)
-		d.Ack(false)
+		return broker.Retryable(fmt.Errorf("failed to cancel order: %w", err))
 	}
+
+	sufc.logger.Info("order cancelled due to stock update failure", "orderId", event.OrderID)
+	return nil
 }
 
 func (sufc *StockUpdateFailedConsumer) unmarshalEvent(body []byte) (*events.StockUpdateFailedEvent, error) {
-	var event events.StockUpdateFailedEvent
-	if err := json.Unmarshal(body, &event); err != nil {
+	_, event, err := events.Decode[events.StockUpdateFailedEvent](body)
+	if err != nil {
 		return nil, err
 	}
 	return &event, nil
 }
 
-func (sufc *StockUpdateFailedConsumer) cancelOrder(ctx context.Context, orderUUID pgtype.UUID) error {
-	_, err := sufc.repo.UpdateOrderStatus(ctx, repository.UpdateOrderStatusParams{
-		ID:     orderUUID,
-		Status: sufc.cancelledOrderStatusID,
-	})
-	return err
-}
-
 func parseOrderIDToUUID(orderID string) (pgtype.UUID, error) {
 	var orderUUID pgtype.UUID
 	if err := orderUUID.Scan(orderID); err != nil {
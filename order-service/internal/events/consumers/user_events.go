@@ -0,0 +1,75 @@
+package consumers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sonuudigital/microservices/order-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const userEventsConsumerName = "order_user_events_consumer"
+
+type UserEventsConsumerRepository interface {
+	UpsertUserProjection(ctx context.Context, arg repository.UpsertUserProjectionParams) error
+}
+
+// UserEventsConsumer keeps the user projection up to date from user events.
+// The event time is the version of a row, so redelivered and out of order
+// events never overwrite a newer state, and deleted users stay as
+// tombstones.
+type UserEventsConsumer struct {
+	logger     logs.Logger
+	repo       UserEventsConsumerRepository
+	subscriber broker.Subscriber
+}
+
+func NewUserEventsConsumer(logger logs.Logger, repo UserEventsConsumerRepository, subscriber broker.Subscriber) *UserEventsConsumer {
+	return &UserEventsConsumer{
+		logger:     logger,
+		repo:       repo,
+		subscriber: subscriber,
+	}
+}
+
+func (uec *UserEventsConsumer) Start(ctx context.Context) error {
+	sub, err := events.Registry.Subscription(events.OrderUserEventsQueueName)
+	if err != nil {
+		return err
+	}
+	sub.Consumer = userEventsConsumerName
+	sub.Handler = uec.handleUserEvent
+	return uec.subscriber.Subscribe(ctx, sub)
+}
+
+func (uec *UserEventsConsumer) handleUserEvent(ctx context.Context, msg broker.Message) error {
+	envelope, event, err := events.Decode[events.UserEvent](msg.Body)
+	if err != nil {
+		uec.logger.Error("failed to unmarshal UserEvent", "error", err)
+		return fmt.Errorf("failed to unmarshal UserEvent: %w", err)
+	}
+
+	var userUUID pgtype.UUID
+	if err := userUUID.Scan(event.ID); err != nil {
+		uec.logger.Error("failed to parse user ID", "error", err, "userId", event.ID)
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	err = uec.repo.UpsertUserProjection(ctx, repository.UpsertUserProjectionParams{
+		ID:       userUUID,
+		Username: event.Username,
+		Email:    event.Email,
+		Deleted:  envelope.Type == events.UserDeletedEventType,
+		Version:  pgtype.Timestamptz{Time: envelope.Time, Valid: true},
+	})
+	if err != nil {
+		uec.logger.Error("failed to update user projection", "error", err, "userId", event.ID)
+		return broker.Retryable(fmt.Errorf("failed to update user projection: %w", err))
+	}
+
+	uec.logger.Debug("user projection updated", "userId", event.ID, "type", envelope.Type)
+	return nil
+}
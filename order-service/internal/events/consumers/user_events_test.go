@@ -0,0 +1,75 @@
+package consumers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/order-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/broker/memory"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUserProjectionRepository struct {
+	mu      sync.Mutex
+	upserts []repository.UpsertUserProjectionParams
+}
+
+func (r *fakeUserProjectionRepository) UpsertUserProjection(_ context.Context, arg repository.UpsertUserProjectionParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.upserts = append(r.upserts, arg)
+	return nil
+}
+
+func (r *fakeUserProjectionRepository) Upserts() []repository.UpsertUserProjectionParams {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]repository.UpsertUserProjectionParams(nil), r.upserts...)
+}
+
+func publishUserEvent(t *testing.T, b *memory.Broker, routingKey string, body []byte) {
+	t.Helper()
+	// The consumer binds its queue asynchronously, so publishing fails as
+	// unroutable until it has subscribed.
+	require.Eventually(t, func() bool {
+		return b.Publish(context.Background(), broker.Publishing{Exchange: events.UserExchangeName, Kind: broker.ExchangeTopic, RoutingKey: routingKey, Body: body}) == nil
+	}, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, b.Wait(ctx))
+}
+
+func TestUserEventsConsumer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := &fakeUserProjectionRepository{}
+	b := memory.New()
+	go func() { _ = NewUserEventsConsumer(logs.NewSlogLogger(), repo, b).Start(ctx) }()
+
+	user := events.UserEvent{ID: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", Username: "user", Email: "user@example.com"}
+	registered, err := events.Wrap("/user-service", events.UserRegisteredEventType, user.ID, user)
+	require.NoError(t, err)
+	deleted, err := events.Wrap("/user-service", events.UserDeletedEventType, user.ID, user)
+	require.NoError(t, err)
+
+	publishUserEvent(t, b, events.UserRegisteredRoutingKey, registered)
+	publishUserEvent(t, b, events.UserDeletedRoutingKey, deleted)
+	publishUserEvent(t, b, events.UserUpdatedRoutingKey, []byte(`{invalid`))
+
+	upserts := repo.Upserts()
+	require.Len(t, upserts, 2)
+	assert.Equal(t, user.ID, upserts[0].ID.String())
+	assert.Equal(t, user.Email, upserts[0].Email)
+	assert.False(t, upserts[0].Deleted)
+	assert.True(t, upserts[1].Deleted, "user.deleted must leave a tombstone")
+	assert.True(t, upserts[1].Version.Valid)
+	assert.Len(t, b.DeadLetters(events.OrderUserEventsQueueName), 1)
+}
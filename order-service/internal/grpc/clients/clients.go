@@ -6,8 +6,8 @@ import (
 	cartv1 "github.com/sonuudigital/microservices/gen/cart/v1"
 	paymentv1 "github.com/sonuudigital/microservices/gen/payment/v1"
 	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
+	"github.com/sonuudigital/microservices/shared/web/health"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type clientsURL struct {
@@ -28,22 +28,26 @@ type Clients struct {
 	cartv1.CartServiceClient
 	paymentv1.PaymentServiceClient
 	userv1.UserServiceClient
+
+	cartConn    *grpc.ClientConn
+	paymentConn *grpc.ClientConn
+	userConn    *grpc.ClientConn
 }
 
-func NewClients(urls clientsURL) (*Clients, error) {
-	cartServiceClient, err := grpc.NewClient(urls.cartServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewClients(urls clientsURL, opts ...grpc.DialOption) (*Clients, error) {
+	cartServiceClient, err := grpc.NewClient(urls.cartServiceURL, opts...)
 	if err != nil {
 		err := fmt.Errorf("failed to connect to cart gRPC client: %w", err)
 		return nil, err
 	}
 
-	paymentServiceClient, err := grpc.NewClient(urls.paymentServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	paymentServiceClient, err := grpc.NewClient(urls.paymentServiceURL, opts...)
 	if err != nil {
 		err := fmt.Errorf("failed to connect to payment gRPC client: %w", err)
 		return nil, err
 	}
 
-	userServiceClient, err := grpc.NewClient(urls.userServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	userServiceClient, err := grpc.NewClient(urls.userServiceURL, opts...)
 	if err != nil {
 		err := fmt.Errorf("failed to connect to user gRPC client: %w", err)
 		return nil, err
@@ -53,5 +57,16 @@ func NewClients(urls clientsURL) (*Clients, error) {
 		CartServiceClient:    cartv1.NewCartServiceClient(cartServiceClient),
 		PaymentServiceClient: paymentv1.NewPaymentServiceClient(paymentServiceClient),
 		UserServiceClient:    userv1.NewUserServiceClient(userServiceClient),
+		cartConn:             cartServiceClient,
+		paymentConn:          paymentServiceClient,
+		userConn:             userServiceClient,
 	}, nil
 }
+
+func (c *Clients) HealthChecks() []health.Check {
+	return []health.Check{
+		health.GRPCCheck("cart-service", c.cartConn, "cart-service"),
+		health.GRPCCheck("payment-service", c.paymentConn, "payment-service"),
+		health.GRPCCheck("user-service", c.userConn, "user-service"),
+	}
+}
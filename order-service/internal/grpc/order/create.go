@@ -2,71 +2,82 @@ package order
 
 import (
 	"context"
+	"errors"
 
 	cartv1 "github.com/sonuudigital/microservices/gen/cart/v1"
 	orderv1 "github.com/sonuudigital/microservices/gen/order/v1"
 	paymentv1 "github.com/sonuudigital/microservices/gen/payment/v1"
 	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
+	"github.com/sonuudigital/microservices/order-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+const paymentFailedReason = "payment failed"
+
 func (s *Server) CreateOrder(ctx context.Context, req *orderv1.CreateOrderRequest) (*orderv1.Order, error) {
-	s.logger.Debug("CreateOrder called", "userId", req.UserId)
 	if err := ctx.Err(); err != nil {
 		return nil, status.FromContextError(err).Err()
 	}
 
-	if req.UserId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "user ID is required")
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
 	}
+	s.logger.DebugContext(ctx, "CreateOrder called", "userId", principal.UserID)
 
-	cart, err := s.getCart(ctx, req.UserId)
+	cart, err := s.getCart(ctx, principal.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	userEmail, err := s.getUserEmail(ctx, req.UserId)
+	userEmail, err := s.getUserEmail(ctx, principal.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	gRPCOrder, err := s.repository.CreateOrder(ctx, req.UserId, userEmail, cart.TotalPrice, cart.Products)
+	gRPCOrder, err := s.repository.CreateOrder(ctx, principal.UserID, userEmail, cart.TotalPrice, cart.Products)
 	if err != nil {
-		s.logger.Error(
+		s.logger.ErrorContext(
+			ctx,
 			"failed to create order and outbox event",
 			"error", err,
-			"userId", req.UserId,
+			"userId", principal.UserID,
 			"cartId", cart.Id,
 		)
 		return nil, status.Errorf(codes.Internal, "failed to create order: %v", err)
 	}
 
-	s.logger.Debug(
+	s.logger.DebugContext(
+		ctx,
 		"order created and outbox event recorded",
 		"orderId", gRPCOrder.Id,
 		"userId", gRPCOrder.UserId,
 		"totalAmount", gRPCOrder.TotalAmount,
 	)
 
-	payment, err := s.processPayment(ctx, gRPCOrder.Id, req.UserId, cart.TotalPrice)
+	payment, err := s.processPayment(ctx, gRPCOrder.Id, cart.TotalPrice)
 	if err != nil {
-		s.logger.Error(
+		s.logger.ErrorContext(
+			ctx,
 			"order created but payment processing failed",
 			"error", err,
 			"orderId", gRPCOrder.Id,
-			"userId", req.UserId,
+			"userId", principal.UserID,
 			"amount", cart.TotalPrice,
 		)
 
-		if err := s.repository.CancelOrder(ctx, gRPCOrder.Id); err != nil {
-			s.logger.Error(
+		if err := s.repository.CancelOrder(ctx, gRPCOrder.Id, paymentFailedReason); err != nil {
+			s.logger.ErrorContext(
+				ctx,
 				"failed to cancel order after payment failure",
 				"error", err,
 				"orderId", gRPCOrder.Id,
 			)
 		} else {
-			s.logger.Info(
+			s.logger.InfoContext(
+				ctx,
 				"order cancelled successfully after payment failure",
 				"orderId", gRPCOrder.Id,
 			)
@@ -75,7 +86,23 @@ func (s *Server) CreateOrder(ctx context.Context, req *orderv1.CreateOrderReques
 		return nil, err
 	}
 
-	s.logger.Info(
+	paidOrder, err := s.repository.MarkOrderPaid(ctx, gRPCOrder.Id, payment.Id)
+	if err != nil {
+		// The payment went through, so the order is returned as created
+		// rather than failing a checkout the client would retry.
+		s.logger.ErrorContext(
+			ctx,
+			"payment processed but failed to mark order as paid",
+			"error", err,
+			"orderId", gRPCOrder.Id,
+			"paymentId", payment.Id,
+		)
+	} else {
+		gRPCOrder = paidOrder
+	}
+
+	s.logger.InfoContext(
+		ctx,
 		"order created successfully with payment processed",
 		"orderId", gRPCOrder.Id,
 		"userId", gRPCOrder.UserId,
@@ -88,11 +115,9 @@ func (s *Server) CreateOrder(ctx context.Context, req *orderv1.CreateOrderReques
 }
 
 func (s *Server) getCart(ctx context.Context, userID string) (*cartv1.GetCartResponse, error) {
-	s.logger.Debug("fetching cart for user", "userId", userID)
+	s.logger.DebugContext(ctx, "fetching cart for user", "userId", userID)
 
-	cart, err := s.clients.CartServiceClient.GetCart(ctx, &cartv1.GetCartRequest{
-		UserId: userID,
-	})
+	cart, err := s.clients.CartServiceClient.GetCart(ctx, &cartv1.GetCartRequest{})
 	if err != nil {
 		st := status.Convert(err)
 		switch st.Code() {
@@ -113,7 +138,8 @@ func (s *Server) getCart(ctx context.Context, userID string) (*cartv1.GetCartRes
 		return nil, status.Errorf(codes.FailedPrecondition, "cannot create order: invalid cart total price %.2f", cart.TotalPrice)
 	}
 
-	s.logger.Debug(
+	s.logger.DebugContext(
+		ctx,
 		"Fetched cart for user",
 		"userId", userID,
 		"cartId", cart.Id,
@@ -124,8 +150,21 @@ func (s *Server) getCart(ctx context.Context, userID string) (*cartv1.GetCartRes
 	return cart, nil
 }
 
+// getUserEmail reads the email from the local user projection and only calls
+// user-service for users the projection has not seen yet, so checkout keeps
+// working while user-service is down.
 func (s *Server) getUserEmail(ctx context.Context, userID string) (string, error) {
-	s.logger.Debug("fetching user email", "userId", userID)
+	email, err := s.repository.GetUserEmail(ctx, userID)
+	switch {
+	case err == nil:
+		return email, nil
+	case errors.Is(err, repository.ErrUserDeleted):
+		return "", status.Errorf(codes.FailedPrecondition, "cannot create order: user not found %s", userID)
+	case !errors.Is(err, repository.ErrUserNotProjected):
+		s.logger.WarnContext(ctx, "failed to read user projection, falling back to user service", "error", err, "userId", userID)
+	}
+
+	s.logger.DebugContext(ctx, "fetching user email", "userId", userID)
 
 	user, err := s.clients.UserServiceClient.GetUserByID(ctx, &userv1.GetUserByIDRequest{
 		Id: userID,
@@ -142,7 +181,12 @@ func (s *Server) getUserEmail(ctx context.Context, userID string) (string, error
 		}
 	}
 
-	s.logger.Debug(
+	if err := s.repository.SaveUserProjection(ctx, user); err != nil {
+		s.logger.WarnContext(ctx, "failed to save user projection", "error", err, "userId", userID)
+	}
+
+	s.logger.DebugContext(
+		ctx,
 		"fetched user email",
 		"userId", userID,
 		"email", user.Email,
@@ -151,10 +195,9 @@ func (s *Server) getUserEmail(ctx context.Context, userID string) (string, error
 	return user.Email, nil
 }
 
-func (s *Server) processPayment(ctx context.Context, orderID, userID string, amount float64) (*paymentv1.Payment, error) {
+func (s *Server) processPayment(ctx context.Context, orderID string, amount float64) (*paymentv1.Payment, error) {
 	payment, err := s.clients.PaymentServiceClient.ProcessPayment(ctx, &paymentv1.ProcessPaymentRequest{
 		OrderId: orderID,
-		UserId:  userID,
 		Amount:  amount,
 	})
 	if err != nil {
@@ -171,7 +214,8 @@ func (s *Server) processPayment(ctx context.Context, orderID, userID string, amo
 		}
 	}
 
-	s.logger.Debug(
+	s.logger.DebugContext(
+		ctx,
 		"Payment processed",
 		"paymentId", payment.Id,
 		"orderId", payment.OrderId,
@@ -11,6 +11,8 @@ import (
 	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
 	"github.com/sonuudigital/microservices/order-service/internal/grpc/clients"
 	"github.com/sonuudigital/microservices/order-service/internal/grpc/order"
+	"github.com/sonuudigital/microservices/order-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"github.com/sonuudigital/microservices/shared/logs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -43,8 +45,42 @@ func (m *MockOrderRepository) CreateOrder(ctx context.Context, userID, userEmail
 	return args.Get(0).(*orderv1.Order), args.Error(1)
 }
 
-func (m *MockOrderRepository) CancelOrder(ctx context.Context, orderID string) error {
+func (m *MockOrderRepository) MarkOrderPaid(ctx context.Context, orderID, paymentID string) (*orderv1.Order, error) {
+	args := m.Called(ctx, orderID, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*orderv1.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) CancelOrder(ctx context.Context, orderID, reason string) error {
+	args := m.Called(ctx, orderID, reason)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) ShipOrder(ctx context.Context, orderID string) (*orderv1.Order, error) {
 	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*orderv1.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) DeliverOrder(ctx context.Context, orderID string) (*orderv1.Order, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*orderv1.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetUserEmail(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOrderRepository) SaveUserProjection(ctx context.Context, user *userv1.User) error {
+	args := m.Called(ctx, user)
 	return args.Error(0)
 }
 
@@ -89,6 +125,10 @@ func (m *MockPaymentClient) ProcessPayment(ctx context.Context, in *paymentv1.Pr
 	return args.Get(0).(*paymentv1.Payment), args.Error(1)
 }
 
+func (m *MockPaymentClient) RefundPayment(ctx context.Context, in *paymentv1.RefundPaymentRequest, opts ...grpc.CallOption) (*paymentv1.Payment, error) {
+	panic(notImplementedError)
+}
+
 type MockUserClient struct {
 	mock.Mock
 }
@@ -109,10 +149,17 @@ func (m *MockUserClient) AuthorizeUser(ctx context.Context, in *userv1.Authorize
 	panic(notImplementedError)
 }
 
+func (m *MockUserClient) UpdateUser(ctx context.Context, in *userv1.UpdateUserRequest, opts ...grpc.CallOption) (*userv1.User, error) {
+	panic(notImplementedError)
+}
+
+func (m *MockUserClient) DeleteUser(ctx context.Context, in *userv1.DeleteUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	panic(notImplementedError)
+}
+
 func TestCreateOrder(t *testing.T) {
-	req := &orderv1.CreateOrderRequest{
-		UserId: testUserID,
-	}
+	req := &orderv1.CreateOrderRequest{}
+	ctx := auth.ContextWithPrincipal(context.Background(), auth.Principal{UserID: testUserID, Email: testUserEmail})
 	cartProducts := []*cartv1.CartProduct{
 		{ProductId: testProductID, Quantity: 2},
 	}
@@ -129,53 +176,148 @@ func TestCreateOrder(t *testing.T) {
 		mockPaymentClient := new(MockPaymentClient)
 		mockUserClient := new(MockUserClient)
 
-		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{UserId: testUserID}).Return(cartResponse, nil).Once()
+		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{}).Return(cartResponse, nil).Once()
 
-		mockUserClient.On("GetUserByID", mock.Anything, &userv1.GetUserByIDRequest{Id: testUserID}).Return(&userv1.User{Id: testUserID, Email: testUserEmail}, nil).Once()
+		mockRepo.On("GetUserEmail", mock.Anything, testUserID).Return(testUserEmail, nil).Once()
 
 		expectedOrder := &orderv1.Order{Id: testOrderID, UserId: testUserID, TotalAmount: 100.50, Status: "CREATED"}
 		mockRepo.On("CreateOrder", mock.Anything, testUserID, testUserEmail, 100.50, cartProducts).Return(expectedOrder, nil).Once()
 
 		mockPaymentClient.On("ProcessPayment", mock.Anything, mock.MatchedBy(func(req *paymentv1.ProcessPaymentRequest) bool {
-			return req.UserId == testUserID && req.Amount == 100.50
+			return req.Amount == 100.50
 		})).Return(&paymentv1.Payment{Id: testPaymentID, Status: "COMPLETED"}, nil).Once()
 
+		paidOrder := &orderv1.Order{Id: testOrderID, UserId: testUserID, TotalAmount: 100.50, Status: "PAID"}
+		mockRepo.On("MarkOrderPaid", mock.Anything, testOrderID, testPaymentID).Return(paidOrder, nil).Once()
+
 		server := order.New(
 			logs.NewSlogLogger(),
 			mockRepo,
 			&clients.Clients{CartServiceClient: mockCartClient, PaymentServiceClient: mockPaymentClient, UserServiceClient: mockUserClient},
 		)
 
-		res, err := server.CreateOrder(context.Background(), req)
+		res, err := server.CreateOrder(ctx, req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
-		assert.Equal(t, expectedOrder, res)
+		assert.Equal(t, paidOrder, res)
 		mockRepo.AssertExpectations(t)
 		mockCartClient.AssertExpectations(t)
-		mockUserClient.AssertExpectations(t)
+		mockUserClient.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
 		mockPaymentClient.AssertExpectations(t)
 	})
 
+	t.Run("User Not Projected Is Fetched And Projected", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		mockCartClient := new(MockCartClient)
+		mockPaymentClient := new(MockPaymentClient)
+		mockUserClient := new(MockUserClient)
+
+		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{}).Return(cartResponse, nil).Once()
+
+		user := &userv1.User{Id: testUserID, Username: "testuser", Email: testUserEmail}
+		mockRepo.On("GetUserEmail", mock.Anything, testUserID).Return("", repository.ErrUserNotProjected).Once()
+		mockUserClient.On("GetUserByID", mock.Anything, &userv1.GetUserByIDRequest{Id: testUserID}).Return(user, nil).Once()
+		// Checkout does not depend on the projection being saved.
+		mockRepo.On("SaveUserProjection", mock.Anything, user).Return(errors.New("database unavailable")).Once()
+
+		expectedOrder := &orderv1.Order{Id: testOrderID, UserId: testUserID, TotalAmount: 100.50, Status: "CREATED"}
+		mockRepo.On("CreateOrder", mock.Anything, testUserID, testUserEmail, 100.50, cartProducts).Return(expectedOrder, nil).Once()
+
+		mockPaymentClient.On("ProcessPayment", mock.Anything, mock.Anything).Return(&paymentv1.Payment{Id: testPaymentID, Status: "COMPLETED"}, nil).Once()
+
+		paidOrder := &orderv1.Order{Id: testOrderID, UserId: testUserID, TotalAmount: 100.50, Status: "PAID"}
+		mockRepo.On("MarkOrderPaid", mock.Anything, testOrderID, testPaymentID).Return(paidOrder, nil).Once()
+
+		server := order.New(
+			logs.NewSlogLogger(),
+			mockRepo,
+			&clients.Clients{CartServiceClient: mockCartClient, PaymentServiceClient: mockPaymentClient, UserServiceClient: mockUserClient},
+		)
+
+		res, err := server.CreateOrder(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, paidOrder, res)
+		mockRepo.AssertExpectations(t)
+		mockUserClient.AssertExpectations(t)
+	})
+
+	t.Run("Deleted User", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		mockCartClient := new(MockCartClient)
+		mockUserClient := new(MockUserClient)
+
+		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{}).Return(cartResponse, nil).Once()
+		mockRepo.On("GetUserEmail", mock.Anything, testUserID).Return("", repository.ErrUserDeleted).Once()
+
+		server := order.New(
+			logs.NewSlogLogger(),
+			mockRepo,
+			&clients.Clients{CartServiceClient: mockCartClient, UserServiceClient: mockUserClient},
+		)
+		res, err := server.CreateOrder(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		mockUserClient.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Payment Succeeded and MarkOrderPaid Fails", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		mockCartClient := new(MockCartClient)
+		mockPaymentClient := new(MockPaymentClient)
+		mockUserClient := new(MockUserClient)
+
+		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{}).Return(cartResponse, nil).Once()
+
+		mockRepo.On("GetUserEmail", mock.Anything, testUserID).Return("", repository.ErrUserNotProjected).Once()
+		mockUserClient.On("GetUserByID", mock.Anything, &userv1.GetUserByIDRequest{Id: testUserID}).Return(&userv1.User{Id: testUserID, Email: testUserEmail}, nil).Once()
+		mockRepo.On("SaveUserProjection", mock.Anything, mock.Anything).Return(nil).Once()
+
+		expectedOrder := &orderv1.Order{Id: testOrderID, UserId: testUserID, TotalAmount: 100.50, Status: "CREATED"}
+		mockRepo.On("CreateOrder", mock.Anything, testUserID, testUserEmail, 100.50, cartProducts).Return(expectedOrder, nil).Once()
+
+		mockPaymentClient.On("ProcessPayment", mock.Anything, mock.Anything).Return(&paymentv1.Payment{Id: testPaymentID, Status: "COMPLETED"}, nil).Once()
+
+		mockRepo.On("MarkOrderPaid", mock.Anything, testOrderID, testPaymentID).Return(nil, errors.New("database unavailable")).Once()
+
+		server := order.New(
+			logs.NewSlogLogger(),
+			mockRepo,
+			&clients.Clients{CartServiceClient: mockCartClient, PaymentServiceClient: mockPaymentClient, UserServiceClient: mockUserClient},
+		)
+
+		res, err := server.CreateOrder(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedOrder, res)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CancelOrder", mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	t.Run("Payment Failed", func(t *testing.T) {
 		mockRepo := new(MockOrderRepository)
 		mockCartClient := new(MockCartClient)
 		mockPaymentClient := new(MockPaymentClient)
 		mockUserClient := new(MockUserClient)
 
-		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{UserId: testUserID}).Return(cartResponse, nil).Once()
+		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{}).Return(cartResponse, nil).Once()
 
+		mockRepo.On("GetUserEmail", mock.Anything, testUserID).Return("", repository.ErrUserNotProjected).Once()
 		mockUserClient.On("GetUserByID", mock.Anything, &userv1.GetUserByIDRequest{Id: testUserID}).Return(&userv1.User{Id: testUserID, Email: testUserEmail}, nil).Once()
+		mockRepo.On("SaveUserProjection", mock.Anything, mock.Anything).Return(nil).Once()
 
 		expectedOrder := &orderv1.Order{Id: testOrderID, UserId: testUserID, TotalAmount: 100.50, Status: "CREATED"}
 		mockRepo.On("CreateOrder", mock.Anything, testUserID, testUserEmail, 100.50, cartProducts).Return(expectedOrder, nil).Once()
 
 		paymentErr := status.Error(codes.FailedPrecondition, "insufficient funds")
 		mockPaymentClient.On("ProcessPayment", mock.Anything, mock.MatchedBy(func(req *paymentv1.ProcessPaymentRequest) bool {
-			return req.UserId == testUserID && req.OrderId == testOrderID && req.Amount == 100.50
+			return req.OrderId == testOrderID && req.Amount == 100.50
 		})).Return(nil, paymentErr).Once()
 
-		mockRepo.On("CancelOrder", mock.Anything, testOrderID).Return(nil).Once()
+		mockRepo.On("CancelOrder", mock.Anything, testOrderID, "payment failed").Return(nil).Once()
 
 		server := order.New(
 			logs.NewSlogLogger(),
@@ -183,7 +325,7 @@ func TestCreateOrder(t *testing.T) {
 			&clients.Clients{CartServiceClient: mockCartClient, PaymentServiceClient: mockPaymentClient, UserServiceClient: mockUserClient},
 		)
 
-		res, err := server.CreateOrder(context.Background(), req)
+		res, err := server.CreateOrder(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -202,20 +344,22 @@ func TestCreateOrder(t *testing.T) {
 		mockPaymentClient := new(MockPaymentClient)
 		mockUserClient := new(MockUserClient)
 
-		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{UserId: testUserID}).Return(cartResponse, nil).Once()
+		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{}).Return(cartResponse, nil).Once()
 
+		mockRepo.On("GetUserEmail", mock.Anything, testUserID).Return("", repository.ErrUserNotProjected).Once()
 		mockUserClient.On("GetUserByID", mock.Anything, &userv1.GetUserByIDRequest{Id: testUserID}).Return(&userv1.User{Id: testUserID, Email: testUserEmail}, nil).Once()
+		mockRepo.On("SaveUserProjection", mock.Anything, mock.Anything).Return(nil).Once()
 
 		expectedOrder := &orderv1.Order{Id: testOrderID, UserId: testUserID, TotalAmount: 100.50, Status: "CREATED"}
 		mockRepo.On("CreateOrder", mock.Anything, testUserID, testUserEmail, 100.50, cartProducts).Return(expectedOrder, nil).Once()
 
 		paymentErr := status.Error(codes.FailedPrecondition, "insufficient funds")
 		mockPaymentClient.On("ProcessPayment", mock.Anything, mock.MatchedBy(func(req *paymentv1.ProcessPaymentRequest) bool {
-			return req.UserId == testUserID && req.OrderId == testOrderID && req.Amount == 100.50
+			return req.OrderId == testOrderID && req.Amount == 100.50
 		})).Return(nil, paymentErr).Once()
 
 		cancelErr := errors.New("failed to cancel order")
-		mockRepo.On("CancelOrder", mock.Anything, testOrderID).Return(cancelErr).Once()
+		mockRepo.On("CancelOrder", mock.Anything, testOrderID, "payment failed").Return(cancelErr).Once()
 
 		server := order.New(
 			logs.NewSlogLogger(),
@@ -223,7 +367,7 @@ func TestCreateOrder(t *testing.T) {
 			&clients.Clients{CartServiceClient: mockCartClient, PaymentServiceClient: mockPaymentClient, UserServiceClient: mockUserClient},
 		)
 
-		res, err := server.CreateOrder(context.Background(), req)
+		res, err := server.CreateOrder(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -242,9 +386,11 @@ func TestCreateOrder(t *testing.T) {
 		mockPaymentClient := new(MockPaymentClient)
 		mockUserClient := new(MockUserClient)
 
-		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{UserId: testUserID}).Return(cartResponse, nil).Once()
+		mockCartClient.On("GetCart", mock.Anything, &cartv1.GetCartRequest{}).Return(cartResponse, nil).Once()
 
+		mockRepo.On("GetUserEmail", mock.Anything, testUserID).Return("", repository.ErrUserNotProjected).Once()
 		mockUserClient.On("GetUserByID", mock.Anything, &userv1.GetUserByIDRequest{Id: testUserID}).Return(&userv1.User{Id: testUserID, Email: testUserEmail}, nil).Once()
+		mockRepo.On("SaveUserProjection", mock.Anything, mock.Anything).Return(nil).Once()
 
 		repoErr := errors.New("database transaction failed")
 		mockRepo.On("CreateOrder", mock.Anything, testUserID, testUserEmail, 100.50, cartProducts).Return(nil, repoErr).Once()
@@ -255,7 +401,7 @@ func TestCreateOrder(t *testing.T) {
 			&clients.Clients{CartServiceClient: mockCartClient, PaymentServiceClient: mockPaymentClient, UserServiceClient: mockUserClient},
 		)
 
-		res, err := server.CreateOrder(context.Background(), req)
+		res, err := server.CreateOrder(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -268,15 +414,15 @@ func TestCreateOrder(t *testing.T) {
 		mockPaymentClient.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything)
 	})
 
-	t.Run("Empty User ID", func(t *testing.T) {
+	t.Run("Missing Principal", func(t *testing.T) {
 		mockRepo := new(MockOrderRepository)
 		server := order.New(logs.NewSlogLogger(), mockRepo, nil)
-		res, err := server.CreateOrder(context.Background(), &orderv1.CreateOrderRequest{UserId: ""})
+		res, err := server.CreateOrder(context.Background(), &orderv1.CreateOrderRequest{UserId: testUserID})
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
 		st, _ := status.FromError(err)
-		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Equal(t, codes.Unauthenticated, st.Code())
 		mockRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
 
@@ -290,7 +436,7 @@ func TestCreateOrder(t *testing.T) {
 			mockRepo,
 			&clients.Clients{CartServiceClient: mockCartClient},
 		)
-		res, err := server.CreateOrder(context.Background(), req)
+		res, err := server.CreateOrder(ctx, req)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
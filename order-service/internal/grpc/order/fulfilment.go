@@ -0,0 +1,58 @@
+package order
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	orderv1 "github.com/sonuudigital/microservices/gen/order/v1"
+	"github.com/sonuudigital/microservices/order-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ShipOrder and DeliverOrder are fulfilment calls made by operators with an
+// admin token; users cannot move their own orders.
+func (s *Server) ShipOrder(ctx context.Context, req *orderv1.ShipOrderRequest) (*orderv1.Order, error) {
+	return s.transitionOrder(ctx, "ship", req.GetOrderId(), s.repository.ShipOrder)
+}
+
+func (s *Server) DeliverOrder(ctx context.Context, req *orderv1.DeliverOrderRequest) (*orderv1.Order, error) {
+	return s.transitionOrder(ctx, "deliver", req.GetOrderId(), s.repository.DeliverOrder)
+}
+
+func (s *Server) transitionOrder(ctx context.Context, action, orderID string, transition func(ctx context.Context, orderID string) (*orderv1.Order, error)) (*orderv1.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	operator, err := auth.RequireOperator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if orderID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "order ID is required")
+	}
+	var orderUUID pgtype.UUID
+	if err := orderUUID.Scan(orderID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid order ID: %v", err)
+	}
+
+	gRPCOrder, err := transition(ctx, orderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrOrderNotFound):
+			return nil, status.Errorf(codes.NotFound, "order not found: %s", orderID)
+		case errors.Is(err, repository.ErrInvalidOrderTransition):
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot %s order: %v", action, err)
+		default:
+			s.logger.ErrorContext(ctx, "failed to "+action+" order", "error", err, "orderId", orderID)
+			return nil, status.Errorf(codes.Internal, "failed to %s order: %v", action, err)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "order status updated", "orderId", gRPCOrder.Id, "status", gRPCOrder.Status, "operator", operator)
+	return gRPCOrder, nil
+}
@@ -0,0 +1,132 @@
+package order_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	orderv1 "github.com/sonuudigital/microservices/gen/order/v1"
+	"github.com/sonuudigital/microservices/order-service/internal/grpc/order"
+	"github.com/sonuudigital/microservices/order-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestShipOrder(t *testing.T) {
+	ctx := auth.ContextWithOperator(context.Background(), "alice")
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		shippedOrder := &orderv1.Order{Id: testOrderID, UserId: testUserID, Status: "SHIPPED"}
+		mockRepo.On("ShipOrder", mock.Anything, testOrderID).Return(shippedOrder, nil).Once()
+
+		server := order.New(logs.NewSlogLogger(), mockRepo, nil)
+		res, err := server.ShipOrder(ctx, &orderv1.ShipOrderRequest{OrderId: testOrderID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, shippedOrder, res)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Requires Operator", func(t *testing.T) {
+		tests := []struct {
+			name string
+			ctx  context.Context
+			code codes.Code
+		}{
+			{"No Identity", context.Background(), codes.Unauthenticated},
+			{"Order Owner", auth.ContextWithPrincipal(context.Background(), auth.Principal{UserID: testUserID}), codes.PermissionDenied},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := new(MockOrderRepository)
+				server := order.New(logs.NewSlogLogger(), mockRepo, nil)
+				res, err := server.ShipOrder(tt.ctx, &orderv1.ShipOrderRequest{OrderId: testOrderID})
+
+				assert.Nil(t, res)
+				assert.Equal(t, tt.code, status.Code(err))
+				mockRepo.AssertNotCalled(t, "ShipOrder", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("Invalid Order ID", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		server := order.New(logs.NewSlogLogger(), mockRepo, nil)
+		res, err := server.ShipOrder(ctx, &orderv1.ShipOrderRequest{OrderId: "not-a-uuid"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		mockRepo.AssertNotCalled(t, "ShipOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			err  error
+			code codes.Code
+		}{
+			{"Not Found", repository.ErrOrderNotFound, codes.NotFound},
+			{"Not Paid", fmt.Errorf("%w: cannot move order to SHIPPED", repository.ErrInvalidOrderTransition), codes.FailedPrecondition},
+			{"Repository Fails", errors.New("database unavailable"), codes.Internal},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := new(MockOrderRepository)
+				mockRepo.On("ShipOrder", mock.Anything, testOrderID).Return(nil, tt.err).Once()
+
+				server := order.New(logs.NewSlogLogger(), mockRepo, nil)
+				res, err := server.ShipOrder(ctx, &orderv1.ShipOrderRequest{OrderId: testOrderID})
+
+				assert.Nil(t, res)
+				assert.Equal(t, tt.code, status.Code(err))
+				mockRepo.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestDeliverOrder(t *testing.T) {
+	ctx := auth.ContextWithOperator(context.Background(), "alice")
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		deliveredOrder := &orderv1.Order{Id: testOrderID, UserId: testUserID, Status: "DELIVERED"}
+		mockRepo.On("DeliverOrder", mock.Anything, testOrderID).Return(deliveredOrder, nil).Once()
+
+		server := order.New(logs.NewSlogLogger(), mockRepo, nil)
+		res, err := server.DeliverOrder(ctx, &orderv1.DeliverOrderRequest{OrderId: testOrderID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, deliveredOrder, res)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Order Owner", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		server := order.New(logs.NewSlogLogger(), mockRepo, nil)
+		ownerCtx := auth.ContextWithPrincipal(context.Background(), auth.Principal{UserID: testUserID})
+		res, err := server.DeliverOrder(ownerCtx, &orderv1.DeliverOrderRequest{OrderId: testOrderID})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		mockRepo.AssertNotCalled(t, "DeliverOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Not Shipped", func(t *testing.T) {
+		mockRepo := new(MockOrderRepository)
+		mockRepo.On("DeliverOrder", mock.Anything, testOrderID).Return(nil, repository.ErrInvalidOrderTransition).Once()
+
+		server := order.New(logs.NewSlogLogger(), mockRepo, nil)
+		res, err := server.DeliverOrder(ctx, &orderv1.DeliverOrderRequest{OrderId: testOrderID})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		mockRepo.AssertExpectations(t)
+	})
+}
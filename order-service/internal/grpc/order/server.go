@@ -5,13 +5,19 @@ import (
 
 	cartv1 "github.com/sonuudigital/microservices/gen/cart/v1"
 	orderv1 "github.com/sonuudigital/microservices/gen/order/v1"
+	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
 	"github.com/sonuudigital/microservices/order-service/internal/grpc/clients"
 	"github.com/sonuudigital/microservices/shared/logs"
 )
 
 type OrderRepository interface {
 	CreateOrder(ctx context.Context, userID, userEmail string, totalAmount float64, products []*cartv1.CartProduct) (*orderv1.Order, error)
-	CancelOrder(ctx context.Context, orderID string) error
+	MarkOrderPaid(ctx context.Context, orderID, paymentID string) (*orderv1.Order, error)
+	CancelOrder(ctx context.Context, orderID, reason string) error
+	ShipOrder(ctx context.Context, orderID string) (*orderv1.Order, error)
+	DeliverOrder(ctx context.Context, orderID string) (*orderv1.Order, error)
+	GetUserEmail(ctx context.Context, userID string) (string, error)
+	SaveUserProjection(ctx context.Context, user *userv1.User) error
 }
 
 type Server struct {
@@ -17,6 +17,7 @@ const (
 	OutboxEventStatusUNPUBLISHED OutboxEventStatus = "UNPUBLISHED"
 	OutboxEventStatusPUBLISHED   OutboxEventStatus = "PUBLISHED"
 	OutboxEventStatusCANCELLED   OutboxEventStatus = "CANCELLED"
+	OutboxEventStatusFAILED      OutboxEventStatus = "FAILED"
 )
 
 func (e *OutboxEventStatus) Scan(src interface{}) error {
@@ -60,6 +61,7 @@ type Order struct {
 	TotalAmount pgtype.Numeric     `json:"totalAmount"`
 	Status      pgtype.UUID        `json:"status"`
 	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
+	UserEmail   pgtype.Text        `json:"userEmail"`
 }
 
 type OrderStatus struct {
@@ -69,11 +71,37 @@ type OrderStatus struct {
 }
 
 type OutboxEvent struct {
-	ID          pgtype.UUID        `json:"id"`
-	AggregateID pgtype.UUID        `json:"aggregateId"`
-	EventName   string             `json:"eventName"`
-	Payload     []byte             `json:"payload"`
-	Status      OutboxEventStatus  `json:"status"`
-	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
-	PublishedAt pgtype.Timestamptz `json:"publishedAt"`
+	ID            pgtype.UUID        `json:"id"`
+	AggregateID   pgtype.UUID        `json:"aggregateId"`
+	EventName     string             `json:"eventName"`
+	Payload       []byte             `json:"payload"`
+	Status        OutboxEventStatus  `json:"status"`
+	CreatedAt     pgtype.Timestamptz `json:"createdAt"`
+	PublishedAt   pgtype.Timestamptz `json:"publishedAt"`
+	TraceContext  []byte             `json:"traceContext"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"lastError"`
+	NextAttemptAt pgtype.Timestamptz `json:"nextAttemptAt"`
+}
+
+type OutboxEventsArchive struct {
+	ID           pgtype.UUID        `json:"id"`
+	AggregateID  pgtype.UUID        `json:"aggregateId"`
+	EventName    string             `json:"eventName"`
+	Payload      []byte             `json:"payload"`
+	Status       OutboxEventStatus  `json:"status"`
+	TraceContext []byte             `json:"traceContext"`
+	Attempts     int32              `json:"attempts"`
+	LastError    pgtype.Text        `json:"lastError"`
+	CreatedAt    pgtype.Timestamptz `json:"createdAt"`
+	PublishedAt  pgtype.Timestamptz `json:"publishedAt"`
+	ArchivedAt   pgtype.Timestamptz `json:"archivedAt"`
+}
+
+type UserProjection struct {
+	ID       pgtype.UUID        `json:"id"`
+	Username string             `json:"username"`
+	Email    string             `json:"email"`
+	Deleted  bool               `json:"deleted"`
+	Version  pgtype.Timestamptz `json:"version"`
 }
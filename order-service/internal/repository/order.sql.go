@@ -12,18 +12,19 @@ import (
 )
 
 const createOrder = `-- name: CreateOrder :one
-INSERT INTO orders (user_id, total_amount)
-VALUES ($1, $2)
-RETURNING id, user_id, total_amount, status, created_at
+INSERT INTO orders (user_id, user_email, total_amount)
+VALUES ($1, $2, $3)
+RETURNING id, user_id, total_amount, status, created_at, user_email
 `
 
 type CreateOrderParams struct {
 	UserID      pgtype.UUID    `json:"userId"`
+	UserEmail   pgtype.Text    `json:"userEmail"`
 	TotalAmount pgtype.Numeric `json:"totalAmount"`
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
-	row := q.db.QueryRow(ctx, createOrder, arg.UserID, arg.TotalAmount)
+	row := q.db.QueryRow(ctx, createOrder, arg.UserID, arg.UserEmail, arg.TotalAmount)
 	var i Order
 	err := row.Scan(
 		&i.ID,
@@ -31,6 +32,7 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.TotalAmount,
 		&i.Status,
 		&i.CreatedAt,
+		&i.UserEmail,
 	)
 	return i, err
 }
@@ -78,11 +80,39 @@ func (q *Queries) GetOrderStatusByName(ctx context.Context, name string) (GetOrd
 	return i, err
 }
 
+const transitionOrderStatus = `-- name: TransitionOrderStatus :one
+UPDATE orders
+SET status = (SELECT s.id FROM order_statuses s WHERE s.name = $1::VARCHAR)
+WHERE orders.id = $2
+    AND orders.status IN (SELECT s.id FROM order_statuses s WHERE s.name = ANY($3::VARCHAR[]))
+RETURNING id, user_id, total_amount, status, created_at, user_email
+`
+
+type TransitionOrderStatusParams struct {
+	ToStatus     string      `json:"toStatus"`
+	ID           pgtype.UUID `json:"id"`
+	FromStatuses []string    `json:"fromStatuses"`
+}
+
+func (q *Queries) TransitionOrderStatus(ctx context.Context, arg TransitionOrderStatusParams) (Order, error) {
+	row := q.db.QueryRow(ctx, transitionOrderStatus, arg.ToStatus, arg.ID, arg.FromStatuses)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TotalAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UserEmail,
+	)
+	return i, err
+}
+
 const updateOrderStatus = `-- name: UpdateOrderStatus :one
 UPDATE orders
 SET status = $2
 WHERE id = $1
-RETURNING id, user_id, total_amount, status, created_at
+RETURNING id, user_id, total_amount, status, created_at, user_email
 `
 
 type UpdateOrderStatusParams struct {
@@ -99,6 +129,7 @@ func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusPa
 		&i.TotalAmount,
 		&i.Status,
 		&i.CreatedAt,
+		&i.UserEmail,
 	)
 	return i, err
 }
@@ -11,60 +11,238 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const cancelOutboxEventStatusByAggregateID = `-- name: CancelOutboxEventStatusByAggregateID :exec
+const archiveOutboxEvents = `-- name: ArchiveOutboxEvents :execrows
+WITH archived AS (
+    DELETE FROM outbox_events
+    WHERE id IN (
+        SELECT candidates.id
+        FROM outbox_events AS candidates
+        WHERE candidates.status IN ('PUBLISHED', 'CANCELLED')
+            AND candidates.created_at < $1::TIMESTAMPTZ
+        ORDER BY candidates.created_at ASC
+        LIMIT $2::INTEGER
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, aggregate_id, event_name, payload, status, trace_context, attempts, last_error, created_at, published_at
+)
+INSERT INTO outbox_events_archive (id, aggregate_id, event_name, payload, status, trace_context, attempts, last_error, created_at, published_at)
+SELECT id, aggregate_id, event_name, payload, status, trace_context, attempts, last_error, created_at, published_at
+FROM archived
+`
+
+type ArchiveOutboxEventsParams struct {
+	CreatedBefore pgtype.Timestamptz `json:"createdBefore"`
+	BatchSize     int32              `json:"batchSize"`
+}
+
+func (q *Queries) ArchiveOutboxEvents(ctx context.Context, arg ArchiveOutboxEventsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, archiveOutboxEvents, arg.CreatedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const cancelOutboxEventStatusByAggregateID = `-- name: CancelOutboxEventStatusByAggregateID :many
 UPDATE outbox_events
 SET
     status = 'CANCELLED'
 WHERE
     aggregate_id = $1
+    AND status = 'UNPUBLISHED'
+RETURNING event_name, attempts
 `
 
-func (q *Queries) CancelOutboxEventStatusByAggregateID(ctx context.Context, aggregateID pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, cancelOutboxEventStatusByAggregateID, aggregateID)
-	return err
+type CancelOutboxEventStatusByAggregateIDRow struct {
+	EventName string `json:"eventName"`
+	Attempts  int32  `json:"attempts"`
+}
+
+func (q *Queries) CancelOutboxEventStatusByAggregateID(ctx context.Context, aggregateID pgtype.UUID) ([]CancelOutboxEventStatusByAggregateIDRow, error) {
+	rows, err := q.db.Query(ctx, cancelOutboxEventStatusByAggregateID, aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CancelOutboxEventStatusByAggregateIDRow
+	for rows.Next() {
+		var i CancelOutboxEventStatusByAggregateIDRow
+		if err := rows.Scan(&i.EventName, &i.Attempts); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimUnpublishedOutboxEvents = `-- name: ClaimUnpublishedOutboxEvents :many
+UPDATE outbox_events
+SET
+    attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => $1::FLOAT8)
+WHERE id IN (
+    SELECT candidates.id
+    FROM outbox_events AS candidates
+    WHERE candidates.status = 'UNPUBLISHED'
+        AND candidates.next_attempt_at <= NOW()
+        -- Keep the events of an aggregate in order: only claim an event once
+        -- every earlier event of its aggregate is published or cancelled, so
+        -- one that is claimed by another relayer, waiting for a retry or
+        -- FAILED holds back the rest.
+        AND NOT EXISTS (
+            SELECT 1
+            FROM outbox_events AS earlier
+            WHERE earlier.aggregate_id = candidates.aggregate_id
+                AND earlier.status IN ('UNPUBLISHED', 'FAILED')
+                AND earlier.created_at < candidates.created_at
+        )
+    ORDER BY candidates.created_at ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, aggregate_id, event_name, payload, status::TEXT AS status, trace_context, attempts, last_error, next_attempt_at, created_at
+`
+
+type ClaimUnpublishedOutboxEventsParams struct {
+	LeaseSeconds float64 `json:"leaseSeconds"`
+	BatchSize    int32   `json:"batchSize"`
+}
+
+type ClaimUnpublishedOutboxEventsRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	AggregateID   pgtype.UUID        `json:"aggregateId"`
+	EventName     string             `json:"eventName"`
+	Payload       []byte             `json:"payload"`
+	Status        string             `json:"status"`
+	TraceContext  []byte             `json:"traceContext"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"lastError"`
+	NextAttemptAt pgtype.Timestamptz `json:"nextAttemptAt"`
+	CreatedAt     pgtype.Timestamptz `json:"createdAt"`
+}
+
+func (q *Queries) ClaimUnpublishedOutboxEvents(ctx context.Context, arg ClaimUnpublishedOutboxEventsParams) ([]ClaimUnpublishedOutboxEventsRow, error) {
+	rows, err := q.db.Query(ctx, claimUnpublishedOutboxEvents, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimUnpublishedOutboxEventsRow
+	for rows.Next() {
+		var i ClaimUnpublishedOutboxEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateID,
+			&i.EventName,
+			&i.Payload,
+			&i.Status,
+			&i.TraceContext,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const createOutboxEvent = `-- name: CreateOutboxEvent :exec
-INSERT INTO outbox_events (aggregate_id, event_name, payload)
-VALUES ($1, $2, $3)
+INSERT INTO outbox_events (aggregate_id, event_name, payload, trace_context)
+VALUES ($1, $2, $3, $4)
 `
 
 type CreateOutboxEventParams struct {
-	AggregateID pgtype.UUID `json:"aggregateId"`
-	EventName   string      `json:"eventName"`
-	Payload     []byte      `json:"payload"`
+	AggregateID  pgtype.UUID `json:"aggregateId"`
+	EventName    string      `json:"eventName"`
+	Payload      []byte      `json:"payload"`
+	TraceContext []byte      `json:"traceContext"`
 }
 
 func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error {
-	_, err := q.db.Exec(ctx, createOutboxEvent, arg.AggregateID, arg.EventName, arg.Payload)
+	_, err := q.db.Exec(ctx, createOutboxEvent,
+		arg.AggregateID,
+		arg.EventName,
+		arg.Payload,
+		arg.TraceContext,
+	)
 	return err
 }
 
-const getUnpublishedOutboxEvents = `-- name: GetUnpublishedOutboxEvents :many
-SELECT id, aggregate_id, event_name, payload, status, created_at, published_at
+const getOutboxBacklog = `-- name: GetOutboxBacklog :one
+SELECT
+    COUNT(*)::BIGINT AS size,
+    MIN(created_at)::TIMESTAMPTZ AS oldest_created_at
 FROM outbox_events
 WHERE status = 'UNPUBLISHED'
+`
+
+type GetOutboxBacklogRow struct {
+	Size            int64              `json:"size"`
+	OldestCreatedAt pgtype.Timestamptz `json:"oldestCreatedAt"`
+}
+
+func (q *Queries) GetOutboxBacklog(ctx context.Context) (GetOutboxBacklogRow, error) {
+	row := q.db.QueryRow(ctx, getOutboxBacklog)
+	var i GetOutboxBacklogRow
+	err := row.Scan(&i.Size, &i.OldestCreatedAt)
+	return i, err
+}
+
+const listOutboxEventsByStatus = `-- name: ListOutboxEventsByStatus :many
+SELECT id, aggregate_id, event_name, payload, status::TEXT AS status, trace_context, attempts, last_error, next_attempt_at, created_at
+FROM outbox_events
+WHERE status::TEXT = ANY($1::TEXT[])
 ORDER BY created_at ASC
-LIMIT $1
+LIMIT $2::INTEGER
 `
 
-func (q *Queries) GetUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error) {
-	rows, err := q.db.Query(ctx, getUnpublishedOutboxEvents, limit)
+type ListOutboxEventsByStatusParams struct {
+	Statuses  []string `json:"statuses"`
+	MaxEvents int32    `json:"maxEvents"`
+}
+
+type ListOutboxEventsByStatusRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	AggregateID   pgtype.UUID        `json:"aggregateId"`
+	EventName     string             `json:"eventName"`
+	Payload       []byte             `json:"payload"`
+	Status        string             `json:"status"`
+	TraceContext  []byte             `json:"traceContext"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"lastError"`
+	NextAttemptAt pgtype.Timestamptz `json:"nextAttemptAt"`
+	CreatedAt     pgtype.Timestamptz `json:"createdAt"`
+}
+
+func (q *Queries) ListOutboxEventsByStatus(ctx context.Context, arg ListOutboxEventsByStatusParams) ([]ListOutboxEventsByStatusRow, error) {
+	rows, err := q.db.Query(ctx, listOutboxEventsByStatus, arg.Statuses, arg.MaxEvents)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []OutboxEvent
+	var items []ListOutboxEventsByStatusRow
 	for rows.Next() {
-		var i OutboxEvent
+		var i ListOutboxEventsByStatusRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.AggregateID,
 			&i.EventName,
 			&i.Payload,
 			&i.Status,
+			&i.TraceContext,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
 			&i.CreatedAt,
-			&i.PublishedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -76,6 +254,82 @@ func (q *Queries) GetUnpublishedOutboxEvents(ctx context.Context, limit int32) (
 	return items, nil
 }
 
+const recordOutboxEventFailure = `-- name: RecordOutboxEventFailure :exec
+UPDATE outbox_events
+SET
+    status = CASE WHEN $1::BOOLEAN THEN 'FAILED'::outbox_event_status ELSE status END,
+    last_error = $2,
+    next_attempt_at = $3
+WHERE
+    id = $4
+`
+
+type RecordOutboxEventFailureParams struct {
+	Failed        bool               `json:"failed"`
+	LastError     pgtype.Text        `json:"lastError"`
+	NextAttemptAt pgtype.Timestamptz `json:"nextAttemptAt"`
+	ID            pgtype.UUID        `json:"id"`
+}
+
+func (q *Queries) RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error {
+	_, err := q.db.Exec(ctx, recordOutboxEventFailure,
+		arg.Failed,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.ID,
+	)
+	return err
+}
+
+const requeueOutboxEvent = `-- name: RequeueOutboxEvent :one
+UPDATE outbox_events
+SET
+    status = 'UNPUBLISHED',
+    attempts = 0,
+    last_error = NULL,
+    next_attempt_at = NOW()
+WHERE
+    id = $1
+    AND (
+        status = 'FAILED'
+        OR (status = 'UNPUBLISHED' AND next_attempt_at <= NOW())
+    )
+RETURNING id, aggregate_id, event_name, payload, status::TEXT AS status, trace_context, attempts, last_error, next_attempt_at, created_at
+`
+
+type RequeueOutboxEventRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	AggregateID   pgtype.UUID        `json:"aggregateId"`
+	EventName     string             `json:"eventName"`
+	Payload       []byte             `json:"payload"`
+	Status        string             `json:"status"`
+	TraceContext  []byte             `json:"traceContext"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"lastError"`
+	NextAttemptAt pgtype.Timestamptz `json:"nextAttemptAt"`
+	CreatedAt     pgtype.Timestamptz `json:"createdAt"`
+}
+
+// A pending event is only requeued while no relayer holds its lease, so a
+// publish in flight is not reset underneath the relayer.
+func (q *Queries) RequeueOutboxEvent(ctx context.Context, id pgtype.UUID) (RequeueOutboxEventRow, error) {
+	row := q.db.QueryRow(ctx, requeueOutboxEvent, id)
+	var i RequeueOutboxEventRow
+	err := row.Scan(
+		&i.ID,
+		&i.AggregateID,
+		&i.EventName,
+		&i.Payload,
+		&i.Status,
+		&i.TraceContext,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const updateOutboxEventStatus = `-- name: UpdateOutboxEventStatus :exec
 UPDATE outbox_events
 SET
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const claimLease = 30 * time.Second
+
+// newTestOutboxDB migrates a fresh schema of the database in
+// TEST_DATABASE_URL. The test is skipped without it.
+func newTestOutboxDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	schema := fmt.Sprintf("outbox_claim_test_%d", time.Now().UnixNano())
+	admin, err := pgxpool.New(ctx, url)
+	require.NoError(t, err)
+	defer admin.Close()
+	_, err = admin.Exec(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		admin, err := pgxpool.New(context.Background(), url)
+		if err == nil {
+			_, _ = admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+			admin.Close()
+		}
+	})
+
+	config, err := pgxpool.ParseConfig(url)
+	require.NoError(t, err)
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	migrations, err := filepath.Glob("../../db/migrations/*.up.sql")
+	require.NoError(t, err)
+	sort.Strings(migrations)
+	for _, migration := range migrations {
+		sql, err := os.ReadFile(migration)
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, string(sql))
+		require.NoError(t, err, migration)
+	}
+	return pool
+}
+
+func insertOutboxEvent(t *testing.T, pool *pgxpool.Pool, aggregateID, eventName string, createdAt time.Time) string {
+	t.Helper()
+
+	var id string
+	err := pool.QueryRow(context.Background(),
+		`INSERT INTO outbox_events (aggregate_id, event_name, payload, created_at)
+		VALUES ($1, $2, '{}', $3)
+		RETURNING id::TEXT`,
+		aggregateID, eventName, createdAt,
+	).Scan(&id)
+	require.NoError(t, err)
+	return id
+}
+
+func claimedIDs(outboxEvents []events.OutboxEvent) []string {
+	ids := make([]string, 0, len(outboxEvents))
+	for _, event := range outboxEvents {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestClaimUnpublishedOutboxEventsKeepsAggregateOrderAcrossClaimers(t *testing.T) {
+	pool := newTestOutboxDB(t)
+	ctx := context.Background()
+
+	aggregateID := "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"
+	createdAt := time.Now().Add(-time.Minute)
+	first := insertOutboxEvent(t, pool, aggregateID, events.OrderCreatedEventName, createdAt)
+	second := insertOutboxEvent(t, pool, aggregateID, events.OrderPaidEventName, createdAt.Add(time.Second))
+
+	// Claimer A holds the lock on the first event while claimer B claims.
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	claimedByA, err := NewOutboxEventMessageRelayerRepository(tx).ClaimUnpublishedOutboxEvents(ctx, 10, claimLease)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first}, claimedIDs(claimedByA), "only the first event of an aggregate is claimable")
+
+	claimedByB := make(chan []events.OutboxEvent, 1)
+	go func() {
+		claimed, err := NewOutboxEventMessageRelayerRepository(pool).ClaimUnpublishedOutboxEvents(ctx, 10, claimLease)
+		assert.NoError(t, err)
+		claimedByB <- claimed
+	}()
+	select {
+	case claimed := <-claimedByB:
+		assert.Empty(t, claimed, "the second event must wait while the first is claimed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("claim blocked on the rows locked by the other claimer")
+	}
+	require.NoError(t, tx.Commit(ctx))
+
+	repo := NewOutboxEventMessageRelayerRepository(pool)
+	claimed, err := repo.ClaimUnpublishedOutboxEvents(ctx, 10, claimLease)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "the second event waits until the first is published")
+
+	_, err = pool.Exec(ctx, "UPDATE outbox_events SET status = 'FAILED', next_attempt_at = NOW() WHERE id = $1", first)
+	require.NoError(t, err)
+	claimed, err = repo.ClaimUnpublishedOutboxEvents(ctx, 10, claimLease)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "a FAILED event holds back the rest of its aggregate")
+
+	require.NoError(t, repo.UpdateOutboxEventStatus(ctx, first))
+	claimed, err = repo.ClaimUnpublishedOutboxEvents(ctx, 10, claimLease)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second}, claimedIDs(claimed))
+}
@@ -2,10 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/sonuudigital/microservices/order-service/internal/repository"
 	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/events/worker"
 )
 
 const (
@@ -22,28 +26,32 @@ func NewOutboxEventMessageRelayerRepository(db repository.DBTX) *OutboxEventMess
 	}
 }
 
-func (r *OutboxEventMessageRelayerRepository) GetUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]events.OutboxEvent, error) {
-	outboxEvents, err := r.Queries.GetUnpublishedOutboxEvents(ctx, limit)
+func (r *OutboxEventMessageRelayerRepository) ClaimUnpublishedOutboxEvents(ctx context.Context, limit int32, lease time.Duration) ([]events.OutboxEvent, error) {
+	outboxEvents, err := r.Queries.ClaimUnpublishedOutboxEvents(ctx, repository.ClaimUnpublishedOutboxEventsParams{
+		LeaseSeconds: lease.Seconds(),
+		BatchSize:    limit,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var result []events.OutboxEvent
+	result := make([]events.OutboxEvent, 0, len(outboxEvents))
 	for _, oe := range outboxEvents {
-		result = append(result, events.OutboxEvent{
-			ID:          oe.ID.String(),
-			AggregateID: oe.AggregateID.String(),
-			EventName:   oe.EventName,
-			Payload:     oe.Payload,
-			Status:      oe.Status,
-		})
+		result = append(result, worker.OutboxEventRow(oe).OutboxEvent())
 	}
-
 	return result, nil
 }
 
+func (r *OutboxEventMessageRelayerRepository) OutboxBacklog(ctx context.Context) (int64, time.Time, error) {
+	backlog, err := r.GetOutboxBacklog(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return backlog.Size, backlog.OldestCreatedAt.Time, nil
+}
+
 func (r *OutboxEventMessageRelayerRepository) UpdateOutboxEventStatus(ctx context.Context, eventID string) error {
-	eventUUID, err := parseIDStringToUUID(eventID)
+	eventUUID, err := worker.ParseOutboxEventID(eventID)
 	if err != nil {
 		return err
 	}
@@ -53,11 +61,54 @@ func (r *OutboxEventMessageRelayerRepository) UpdateOutboxEventStatus(ctx contex
 	})
 }
 
-func parseIDStringToUUID(id string) (pgtype.UUID, error) {
-	var uuid pgtype.UUID
-	if err := uuid.Scan(id); err != nil {
-		return pgtype.UUID{}, err
-	} else {
-		return uuid, nil
+func (r *OutboxEventMessageRelayerRepository) RecordOutboxEventFailure(ctx context.Context, eventID, lastError string, nextAttemptAt time.Time, failed bool) error {
+	eventUUID, err := worker.ParseOutboxEventID(eventID)
+	if err != nil {
+		return err
+	}
+	return r.Queries.RecordOutboxEventFailure(ctx, repository.RecordOutboxEventFailureParams{
+		Failed:        failed,
+		LastError:     pgtype.Text{String: lastError, Valid: true},
+		NextAttemptAt: pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+		ID:            eventUUID,
+	})
+}
+
+func (r *OutboxEventMessageRelayerRepository) ArchiveOutboxEvents(ctx context.Context, createdBefore time.Time, limit int32) (int64, error) {
+	return r.Queries.ArchiveOutboxEvents(ctx, repository.ArchiveOutboxEventsParams{
+		CreatedBefore: pgtype.Timestamptz{Time: createdBefore, Valid: true},
+		BatchSize:     limit,
+	})
+}
+
+func (r *OutboxEventMessageRelayerRepository) ListOutboxEvents(ctx context.Context, statuses []string, limit int32) ([]events.OutboxEvent, error) {
+	outboxEvents, err := r.ListOutboxEventsByStatus(ctx, repository.ListOutboxEventsByStatusParams{
+		Statuses:  statuses,
+		MaxEvents: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]events.OutboxEvent, 0, len(outboxEvents))
+	for _, oe := range outboxEvents {
+		result = append(result, worker.OutboxEventRow(oe).OutboxEvent())
+	}
+	return result, nil
+}
+
+func (r *OutboxEventMessageRelayerRepository) RequeueOutboxEvent(ctx context.Context, eventID string) (events.OutboxEvent, error) {
+	eventUUID, err := worker.ParseOutboxEventID(eventID)
+	if err != nil {
+		return events.OutboxEvent{}, worker.ErrOutboxEventNotRequeueable
+	}
+
+	oe, err := r.Queries.RequeueOutboxEvent(ctx, eventUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return events.OutboxEvent{}, worker.ErrOutboxEventNotRequeueable
+	}
+	if err != nil {
+		return events.OutboxEvent{}, err
 	}
+	return worker.OutboxEventRow(oe).OutboxEvent(), nil
 }
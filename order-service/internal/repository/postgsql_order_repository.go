@@ -2,19 +2,33 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	cartv1 "github.com/sonuudigital/microservices/gen/cart/v1"
 	orderv1 "github.com/sonuudigital/microservices/gen/order/v1"
 	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/tracing"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const eventSource = "/order-service"
+
 const (
-	orderCreatedEventName = "order_created_exchange"
+	OrderStatusCreated   = "CREATED"
+	OrderStatusPaid      = "PAID"
+	OrderStatusShipped   = "SHIPPED"
+	OrderStatusDelivered = "DELIVERED"
+	OrderStatusCancelled = "CANCELLED"
+)
+
+var (
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrInvalidOrderTransition = errors.New("order status does not allow this transition")
 )
 
 type PostgreSQLOrderRepository struct {
@@ -60,24 +74,19 @@ func (s *PostgreSQLOrderRepository) CreateOrder(ctx context.Context, userID, use
 
 		dbOrder, err := q.CreateOrder(ctx, CreateOrderParams{
 			UserID:      userUUID,
+			UserEmail:   pgtype.Text{String: userEmail, Valid: userEmail != ""},
 			TotalAmount: pgTotalAmount,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create order: %w", err)
 		}
 
-		encodedEvent, err := generateOrderCreatedEventPayload(dbOrder.ID.String(), dbOrder.UserID.String(), userEmail, products)
-
-		err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
-			AggregateID: dbOrder.ID,
-			EventName:   orderCreatedEventName,
-			Payload:     encodedEvent,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create outbox event: %w", err)
+		orderCreatedEvent := newOrderCreatedEvent(dbOrder.ID.String(), dbOrder.UserID.String(), userEmail, products)
+		if err := recordEvent(ctx, q, dbOrder.ID, events.OrderCreatedEventType, orderCreatedEvent, events.LegacyOrderCreatedEventName, events.OrderCreatedEventName); err != nil {
+			return err
 		}
 
-		orderStatus, err := q.GetOrderStatusByName(ctx, "CREATED")
+		orderStatus, err := q.GetOrderStatusByName(ctx, OrderStatusCreated)
 		if err != nil {
 			return fmt.Errorf("failed to get CREATED status: %w", err)
 		}
@@ -98,35 +107,132 @@ func (s *PostgreSQLOrderRepository) CreateOrder(ctx context.Context, userID, use
 	}
 }
 
-func (s *PostgreSQLOrderRepository) CancelOrder(ctx context.Context, orderID string) error {
-	return s.execTx(ctx, func(q *Queries) error {
-		orderUUID, err := mapStringToPgUUID(orderID)
+// MarkOrderPaid moves a created order to PAID and records order.paid.
+func (s *PostgreSQLOrderRepository) MarkOrderPaid(ctx context.Context, orderID, paymentID string) (*orderv1.Order, error) {
+	return s.transitionOrder(ctx, orderID, OrderStatusPaid, []string{OrderStatusCreated}, func(q *Queries, order Order) error {
+		amount, err := order.TotalAmount.Float64Value()
 		if err != nil {
 			return err
 		}
+		event := events.OrderPaidEvent{
+			OrderID:   orderID,
+			UserID:    order.UserID.String(),
+			UserEmail: order.UserEmail.String,
+			PaymentID: paymentID,
+			Amount:    fmt.Sprintf("%.2f", amount.Float64),
+		}
+		return recordEvent(ctx, q, order.ID, events.OrderPaidEventType, event, events.OrderPaidEventName)
+	})
+}
+
+// CancelOrder cancels an order that has not been shipped yet. Outbox events
+// of the order that were not published yet are cancelled, so e.g. stock is
+// not reserved for an order whose payment failed, and order.cancelled is
+// recorded unless order.created was among them and was never handed to the
+// broker, since consumers of orders.events never saw the order.
+func (s *PostgreSQLOrderRepository) CancelOrder(ctx context.Context, orderID, reason string) error {
+	_, err := s.transitionOrder(ctx, orderID, OrderStatusCancelled, []string{OrderStatusCreated, OrderStatusPaid}, func(q *Queries, order Order) error {
+		cancelled, err := q.CancelOutboxEventStatusByAggregateID(ctx, order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to cancel outbox event status: %w", err)
+		}
+		if slices.ContainsFunc(cancelled, func(e CancelOutboxEventStatusByAggregateIDRow) bool {
+			return e.EventName == events.OrderCreatedEventName && e.Attempts == 0
+		}) {
+			return nil
+		}
+		event := events.OrderCancelledEvent{
+			OrderID:   orderID,
+			UserID:    order.UserID.String(),
+			UserEmail: order.UserEmail.String,
+			Reason:    reason,
+		}
+		return recordEvent(ctx, q, order.ID, events.OrderCancelledEventType, event, events.OrderCancelledEventName)
+	})
+	return err
+}
+
+// ShipOrder moves a paid order to SHIPPED and records order.shipped.
+func (s *PostgreSQLOrderRepository) ShipOrder(ctx context.Context, orderID string) (*orderv1.Order, error) {
+	return s.transitionOrder(ctx, orderID, OrderStatusShipped, []string{OrderStatusPaid}, func(q *Queries, order Order) error {
+		event := events.OrderShippedEvent{OrderID: orderID, UserID: order.UserID.String(), UserEmail: order.UserEmail.String}
+		return recordEvent(ctx, q, order.ID, events.OrderShippedEventType, event, events.OrderShippedEventName)
+	})
+}
+
+// DeliverOrder moves a shipped order to DELIVERED and records
+// order.delivered.
+func (s *PostgreSQLOrderRepository) DeliverOrder(ctx context.Context, orderID string) (*orderv1.Order, error) {
+	return s.transitionOrder(ctx, orderID, OrderStatusDelivered, []string{OrderStatusShipped}, func(q *Queries, order Order) error {
+		event := events.OrderDeliveredEvent{OrderID: orderID, UserID: order.UserID.String(), UserEmail: order.UserEmail.String}
+		return recordEvent(ctx, q, order.ID, events.OrderDeliveredEventType, event, events.OrderDeliveredEventName)
+	})
+}
 
-		canceledStatus, err := q.GetOrderStatusByName(ctx, "CANCELED")
+// transitionOrder moves an order to status if it is in one of from and runs
+// record in the same transaction to write the outbox events of the change.
+func (s *PostgreSQLOrderRepository) transitionOrder(ctx context.Context, orderID, status string, from []string, record func(q *Queries, order Order) error) (*orderv1.Order, error) {
+	var updatedOrder *orderv1.Order
+	err := s.execTx(ctx, func(q *Queries) error {
+		orderUUID, err := mapStringToPgUUID(orderID)
 		if err != nil {
-			return fmt.Errorf("failed to get CANCELED status: %w", err)
+			return err
 		}
 
-		_, err = q.UpdateOrderStatus(ctx, UpdateOrderStatusParams{
-			ID:     orderUUID,
-			Status: canceledStatus.ID,
+		dbOrder, err := q.TransitionOrderStatus(ctx, TransitionOrderStatusParams{
+			ID:           orderUUID,
+			ToStatus:     status,
+			FromStatuses: from,
 		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := q.GetOrderById(ctx, orderUUID); errors.Is(err, pgx.ErrNoRows) {
+				return ErrOrderNotFound
+			}
+			return fmt.Errorf("%w: cannot move order %s to %s", ErrInvalidOrderTransition, orderID, status)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to update order status to CANCELED: %w", err)
+			return fmt.Errorf("failed to update order status to %s: %w", status, err)
 		}
 
-		if err = q.CancelOutboxEventStatusByAggregateID(ctx, orderUUID); err != nil {
-			return fmt.Errorf("failed to cancel outbox event status: %w", err)
+		if err := record(q, dbOrder); err != nil {
+			return err
 		}
 
+		updatedOrder, err = mapRepositoryToGRPC(&dbOrder, status)
+		if err != nil {
+			return fmt.Errorf("failed to map order to gRPC model: %w", err)
+		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedOrder, nil
+}
+
+// recordEvent wraps data in an envelope and records it once for each
+// event name, so one event can be published to several exchanges.
+func recordEvent(ctx context.Context, q *Queries, aggregateID pgtype.UUID, eventType string, data any, eventNames ...string) error {
+	payload, err := events.Wrap(eventSource, eventType, aggregateID.String(), data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	for _, eventName := range eventNames {
+		err := q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+			AggregateID:  aggregateID,
+			EventName:    eventName,
+			Payload:      payload,
+			TraceContext: tracing.MarshalTraceContext(ctx),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create outbox event: %w", err)
+		}
+	}
+	return nil
 }
 
-func generateOrderCreatedEventPayload(orderID, userID, userEmail string, products []*cartv1.CartProduct) ([]byte, error) {
+func newOrderCreatedEvent(orderID, userID, userEmail string, products []*cartv1.CartProduct) events.OrderCreatedEvent {
 	eventProducts := make([]events.OrderItem, len(products))
 	for i, p := range products {
 		eventProducts[i] = events.OrderItem{
@@ -135,19 +241,12 @@ func generateOrderCreatedEventPayload(orderID, userID, userEmail string, product
 		}
 	}
 
-	orderCreatedEvent := events.OrderCreatedEvent{
+	return events.OrderCreatedEvent{
 		OrderID:   orderID,
 		UserID:    userID,
 		UserEmail: userEmail,
 		Products:  eventProducts,
 	}
-
-	encodedEvent, err := json.Marshal(orderCreatedEvent)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal OrderCreatedEvent: %w", err)
-	}
-
-	return encodedEvent, nil
 }
 
 func mapStringToPgUUID(value string) (pgtype.UUID, error) {
@@ -11,14 +11,24 @@ import (
 )
 
 type Querier interface {
-	CancelOutboxEventStatusByAggregateID(ctx context.Context, aggregateID pgtype.UUID) error
+	ArchiveOutboxEvents(ctx context.Context, arg ArchiveOutboxEventsParams) (int64, error)
+	CancelOutboxEventStatusByAggregateID(ctx context.Context, aggregateID pgtype.UUID) ([]CancelOutboxEventStatusByAggregateIDRow, error)
+	ClaimUnpublishedOutboxEvents(ctx context.Context, arg ClaimUnpublishedOutboxEventsParams) ([]ClaimUnpublishedOutboxEventsRow, error)
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
 	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error
 	GetOrderById(ctx context.Context, id pgtype.UUID) (GetOrderByIdRow, error)
 	GetOrderStatusByName(ctx context.Context, name string) (GetOrderStatusByNameRow, error)
-	GetUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error)
+	GetOutboxBacklog(ctx context.Context) (GetOutboxBacklogRow, error)
+	GetUserProjection(ctx context.Context, id pgtype.UUID) (UserProjection, error)
+	ListOutboxEventsByStatus(ctx context.Context, arg ListOutboxEventsByStatusParams) ([]ListOutboxEventsByStatusRow, error)
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error
+	// A pending event is only requeued while no relayer holds its lease, so a
+	// publish in flight is not reset underneath the relayer.
+	RequeueOutboxEvent(ctx context.Context, id pgtype.UUID) (RequeueOutboxEventRow, error)
+	TransitionOrderStatus(ctx context.Context, arg TransitionOrderStatusParams) (Order, error)
 	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error)
 	UpdateOutboxEventStatus(ctx context.Context, arg UpdateOutboxEventStatusParams) error
+	UpsertUserProjection(ctx context.Context, arg UpsertUserProjectionParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
+)
+
+var (
+	ErrUserNotProjected = errors.New("user is not in the user projection")
+	ErrUserDeleted      = errors.New("user has been deleted")
+)
+
+// GetUserEmail returns the email of a user from the projection built from
+// user events. ErrUserNotProjected means no event about the user has been
+// consumed yet, so the caller should ask user-service instead.
+func (s *PostgreSQLOrderRepository) GetUserEmail(ctx context.Context, userID string) (string, error) {
+	userUUID, err := mapStringToPgUUID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.GetUserProjection(ctx, userUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrUserNotProjected
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user projection: %w", err)
+	}
+	if user.Deleted {
+		return "", ErrUserDeleted
+	}
+	return user.Email, nil
+}
+
+// SaveUserProjection records a user fetched from user-service, so the next
+// order of a user the projection had not seen is served locally. The user's
+// last change is the version, so an event about a later change still wins.
+func (s *PostgreSQLOrderRepository) SaveUserProjection(ctx context.Context, user *userv1.User) error {
+	userUUID, err := mapStringToPgUUID(user.Id)
+	if err != nil {
+		return err
+	}
+
+	version := user.GetUpdatedAt()
+	if version == nil {
+		version = user.GetCreatedAt()
+	}
+	err = s.UpsertUserProjection(ctx, UpsertUserProjectionParams{
+		ID:       userUUID,
+		Username: user.Username,
+		Email:    user.Email,
+		Version:  pgtype.Timestamptz{Time: version.AsTime(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save user projection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: user_projection.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getUserProjection = `-- name: GetUserProjection :one
+SELECT id, username, email, deleted, version
+FROM user_projections
+WHERE id = $1
+`
+
+func (q *Queries) GetUserProjection(ctx context.Context, id pgtype.UUID) (UserProjection, error) {
+	row := q.db.QueryRow(ctx, getUserProjection, id)
+	var i UserProjection
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Deleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const upsertUserProjection = `-- name: UpsertUserProjection :exec
+INSERT INTO user_projections (id, username, email, deleted, version)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (id) DO UPDATE
+SET
+    username = EXCLUDED.username,
+    email = EXCLUDED.email,
+    deleted = EXCLUDED.deleted,
+    version = EXCLUDED.version
+WHERE user_projections.version < EXCLUDED.version
+`
+
+type UpsertUserProjectionParams struct {
+	ID       pgtype.UUID        `json:"id"`
+	Username string             `json:"username"`
+	Email    string             `json:"email"`
+	Deleted  bool               `json:"deleted"`
+	Version  pgtype.Timestamptz `json:"version"`
+}
+
+func (q *Queries) UpsertUserProjection(ctx context.Context, arg UpsertUserProjectionParams) error {
+	_, err := q.db.Exec(ctx, upsertUserProjection,
+		arg.ID,
+		arg.Username,
+		arg.Email,
+		arg.Deleted,
+		arg.Version,
+	)
+	return err
+}
@@ -2,25 +2,36 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
-	"time"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	paymentv1 "github.com/sonuudigital/microservices/gen/payment/v1"
 	"github.com/sonuudigital/microservices/payment-service/internal/grpc/payment"
 	"github.com/sonuudigital/microservices/payment-service/internal/repository"
+	repo_postgres "github.com/sonuudigital/microservices/payment-service/internal/repository/postgres"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/events/worker"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"github.com/sonuudigital/microservices/shared/postgres"
+	"github.com/sonuudigital/microservices/shared/rabbitmq"
+	"github.com/sonuudigital/microservices/shared/tracing"
 	"github.com/sonuudigital/microservices/shared/web"
 	"github.com/sonuudigital/microservices/shared/web/health"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 )
 
 func main() {
-	logger := logs.NewSlogLogger()
+	logger := logs.NewSlogLogger(logs.WithServiceName("payment-service"))
 	err := godotenv.Load()
 	if err == nil {
 		logger.Info("loaded environment variables from .env file")
@@ -28,6 +39,19 @@ func main() {
 		logger.Info("no .env file found, using environment variables")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "payment-service", logger)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	adminAuth, err := auth.LoadAdminAuthFromEnv(logger)
+	if err != nil {
+		logger.Error("failed to load admin auth", "error", err)
+		os.Exit(1)
+	}
+
 	pgDb, err := postgres.InitializePostgresDB()
 	if err != nil {
 		logger.Error("error connecting to database", "error", err)
@@ -36,38 +60,117 @@ func main() {
 	logger.Info("database connected successfully")
 	defer pgDb.Close()
 
-	startGRPCServer(logger, pgDb)
+	metricsServer := metrics.StartServer(logger, metrics.WithAdminAuth(adminAuth), metrics.WithAdminHandler(
+		worker.OutboxAdminPath,
+		worker.NewOutboxAdminHandler(logger, repo_postgres.NewOutboxEventMessageRelayerRepository(pgDb)),
+	))
+	defer metricsServer.Close()
+
+	rabbitmqClient, err := rabbitmq.NewServiceClientFromEnv(logger, "payment-service")
+	if err != nil {
+		logger.Error("failed to initialize RabbitMQ", "error", err)
+		os.Exit(1)
+	}
+	defer rabbitmqClient.Close()
+
+	initializeServicesAndWaitForShutdown(logger, rabbitmqClient, pgDb)
 }
 
-func startGRPCServer(logger logs.Logger, pgDb *pgxpool.Pool) {
+func initializeServicesAndWaitForShutdown(logger logs.Logger, rabbitmqClient *rabbitmq.Client, pgDb *pgxpool.Pool) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return startGRPCServer(gCtx, logger, pgDb, rabbitmqClient)
+	})
+
+	relayer, err := worker.NewOutboxEventMessageRelayerFromEnv(
+		logger,
+		worker.NewEventPublisher(rabbitmqClient, events.Registry),
+		repo_postgres.NewOutboxEventMessageRelayerRepository(pgDb),
+	)
+	if err != nil {
+		logger.Error("failed to configure message relayer", "error", err)
+		os.Exit(1)
+	}
+	go relayer.Start(gCtx)
+
+	retentionPolicy, err := worker.RetentionPolicyFromEnv()
+	if err != nil {
+		logger.Error("failed to get outbox retention policy", "error", err)
+		os.Exit(1)
+	}
+	go worker.NewOutboxRetentionJob(logger, repo_postgres.NewOutboxEventMessageRelayerRepository(pgDb), retentionPolicy).Start(gCtx)
+
+	if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+		logger.Error("application exited with error", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("application shut down gracefully")
+}
+
+func startGRPCServer(ctx context.Context, logger logs.Logger, pgDb *pgxpool.Pool, rabbitmq *rabbitmq.Client) error {
 	grpcPort := os.Getenv("PAYMENT_SERVICE_GRPC_PORT")
 	if grpcPort == "" {
-		logger.Error("PAYMENT_SERVICE_GRPC_PORT is not set")
-		os.Exit(1)
+		return fmt.Errorf("PAYMENT_SERVICE_GRPC_PORT is not set")
 	}
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", grpcPort))
 	if err != nil {
-		logger.Error("failed to listen on gRPC port", "error", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to listen on gRPC port: %w", err)
+	}
+
+	tlsConfig, err := web.LoadTLSConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load TLS config: %w", err)
+	}
+
+	serverOpts, err := web.NewGRPCServerCredentials(tlsConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC server credentials: %w", err)
+	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logs.UnaryServerInterceptor()))
+
+	identityVerifier, err := auth.LoadIdentityTokenVerifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load identity token verifier: %w", err)
 	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(identityVerifier, logger)))
 
-	grpcServer := grpc.NewServer()
-	paymentGrpcServer := payment.New(logger, repository.New(pgDb))
+	serverOpts = append(serverOpts, tracing.GRPCServerOption())
+	grpcServer := grpc.NewServer(serverOpts...)
+	maxAmount, err := getPaymentMaxAmountFromEnv()
+	if err != nil {
+		return err
+	}
+	paymentGrpcServer := payment.New(logger, repository.NewPostgreSQLPaymentRepository(pgDb), payment.WithMaxAmount(maxAmount))
 	paymentv1.RegisterPaymentServiceServer(grpcServer, paymentGrpcServer)
 
-	health.StartGRPCHealthCheckService(grpcServer, "payment-service", func(ctx context.Context) error {
-		ctx, cancel := context.WithTimeout(ctx, time.Second*3)
-		defer cancel()
-
-		if err := pgDb.Ping(ctx); err == nil {
-			logger.Info("service is healthy and serving")
-			return nil
-		} else {
-			logger.Error("service is not healthy", "error", err)
-			return err
-		}
-	})
+	checker := health.NewChecker(logger, health.DefaultCheckInterval,
+		health.NewCheck("postgres", health.DefaultCheckTimeout, pgDb.Ping),
+		health.NewCheck("rabbitmq", health.DefaultCheckTimeout, func(context.Context) error {
+			return rabbitmq.Ping()
+		}),
+	)
+	health.StartGRPCHealthCheckService(ctx, grpcServer, "payment-service", checker)
+
+	return web.StartGRPCServerAndWaitForShutdown(ctx, grpcServer, lis, logger)
+}
 
-	web.StartGRPCServerAndWaitForShutdown(context.Background(), grpcServer, lis, logger)
+// getPaymentMaxAmountFromEnv reads PAYMENT_MAX_AMOUNT, the amount above which
+// payments are rejected. Unset means no limit.
+func getPaymentMaxAmountFromEnv() (float64, error) {
+	value := os.Getenv("PAYMENT_MAX_AMOUNT")
+	if value == "" {
+		return 0, nil
+	}
+
+	maxAmount, err := strconv.ParseFloat(value, 64)
+	if err != nil || maxAmount < 0 {
+		return 0, fmt.Errorf("invalid PAYMENT_MAX_AMOUNT: %q", value)
+	}
+	return maxAmount, nil
 }
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS outbox_events_notify ON outbox_events;
+DROP FUNCTION IF EXISTS notify_outbox_event();
+DROP TABLE IF EXISTS outbox_events;
+DROP TYPE IF EXISTS outbox_event_status;
+
+UPDATE payments
+SET status = (SELECT id FROM payment_statuses WHERE name = 'SUCCEEDED')
+WHERE status = (SELECT id FROM payment_statuses WHERE name = 'REFUNDED');
+DELETE FROM payment_statuses WHERE name = 'REFUNDED';
\ No newline at end of file
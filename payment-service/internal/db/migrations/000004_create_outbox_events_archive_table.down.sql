@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_events_archivable;
+DROP TABLE IF EXISTS outbox_events_archive;
\ No newline at end of file
@@ -16,3 +16,8 @@ RETURNING *;
 -- name: GetPaymentStatusByName :one
 SELECT id FROM payment_statuses
 WHERE name = $1;
+
+-- name: GetPaymentByIDForUpdate :one
+SELECT * FROM payments
+WHERE id = $1
+FOR UPDATE;
@@ -7,6 +7,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	paymentv1 "github.com/sonuudigital/microservices/gen/payment/v1"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -17,12 +18,17 @@ func (s *Server) GetPayment(ctx context.Context, req *paymentv1.GetPaymentReques
 		return nil, status.FromContextError(err).Err()
 	}
 
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var uuid pgtype.UUID
 	if err := uuid.Scan(req.Id); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid payment id format: %s", req.Id)
 	}
 
-	payment, err := s.querier.GetPaymentByID(ctx, uuid)
+	payment, err := s.repository.GetPaymentByID(ctx, uuid)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, status.Errorf(codes.NotFound, "payment with id %s not found", req.Id)
@@ -31,6 +37,10 @@ func (s *Server) GetPayment(ctx context.Context, req *paymentv1.GetPaymentReques
 		}
 	}
 
+	if payment.UserID.String() != principal.UserID {
+		return nil, status.Errorf(codes.NotFound, "payment with id %s not found", req.Id)
+	}
+
 	grpcPayment, err := mapRepositoryToGRPC(&payment)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to convert payment: %v", err)
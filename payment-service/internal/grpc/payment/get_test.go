@@ -34,8 +34,8 @@ func TestGetPayment(t *testing.T) {
 	req := &paymentv1.GetPaymentRequest{Id: testPaymentID}
 
 	t.Run("Success", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		var amount pgtype.Numeric
 		_ = amount.Scan("99.99")
@@ -49,10 +49,10 @@ func TestGetPayment(t *testing.T) {
 			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
 		}
 
-		mockQuerier.On("GetPaymentByID", mock.Anything, paymentUUID).
+		mockRepo.On("GetPaymentByID", mock.Anything, paymentUUID).
 			Return(mockPayment, nil).Once()
 
-		res, err := server.GetPayment(context.Background(), req)
+		res, err := server.GetPayment(principalContext(testUserID), req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
@@ -60,17 +60,34 @@ func TestGetPayment(t *testing.T) {
 		assert.Equal(t, testOrderID, res.OrderId)
 		assert.Equal(t, testUserID, res.UserId)
 		assert.Equal(t, 99.99, res.Amount)
-		mockQuerier.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Payment Owned By Another User", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
+
+		var amount pgtype.Numeric
+		_ = amount.Scan("99.99")
+
+		mockRepo.On("GetPaymentByID", mock.Anything, paymentUUID).
+			Return(repository.Payment{ID: paymentUUID, OrderID: orderUUID, UserID: userUUID, Amount: amount, Status: statusUUID}, nil).Once()
+
+		res, err := server.GetPayment(principalContext(testOrderID), req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Payment Not Found", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
-		mockQuerier.On("GetPaymentByID", mock.Anything, paymentUUID).
+		mockRepo.On("GetPaymentByID", mock.Anything, paymentUUID).
 			Return(repository.Payment{}, pgx.ErrNoRows).Once()
 
-		res, err := server.GetPayment(context.Background(), req)
+		res, err := server.GetPayment(principalContext(testUserID), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -78,16 +95,16 @@ func TestGetPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.NotFound, st.Code())
 		assert.Contains(t, st.Message(), "not found")
-		mockQuerier.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Invalid Payment ID", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		invalidReq := &paymentv1.GetPaymentRequest{Id: "invalid-uuid"}
 
-		res, err := server.GetPayment(context.Background(), invalidReq)
+		res, err := server.GetPayment(principalContext(testUserID), invalidReq)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -95,17 +112,17 @@ func TestGetPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.InvalidArgument, st.Code())
 		assert.Contains(t, st.Message(), "invalid payment id format")
-		mockQuerier.AssertNotCalled(t, "GetPaymentByID", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "GetPaymentByID", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Database Error", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
-		mockQuerier.On("GetPaymentByID", mock.Anything, paymentUUID).
+		mockRepo.On("GetPaymentByID", mock.Anything, paymentUUID).
 			Return(repository.Payment{}, errors.New("database connection error")).Once()
 
-		res, err := server.GetPayment(context.Background(), req)
+		res, err := server.GetPayment(principalContext(testUserID), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -113,12 +130,12 @@ func TestGetPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.Internal, st.Code())
 		assert.Contains(t, st.Message(), "failed to retrieve payment")
-		mockQuerier.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Context Canceled", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
@@ -130,12 +147,12 @@ func TestGetPayment(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.Canceled, st.Code())
-		mockQuerier.AssertNotCalled(t, "GetPaymentByID", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "GetPaymentByID", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Invalid Amount in Payment", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		mockPayment := repository.Payment{
 			ID:        paymentUUID,
@@ -146,10 +163,10 @@ func TestGetPayment(t *testing.T) {
 			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
 		}
 
-		mockQuerier.On("GetPaymentByID", mock.Anything, paymentUUID).
+		mockRepo.On("GetPaymentByID", mock.Anything, paymentUUID).
 			Return(mockPayment, nil).Once()
 
-		res, err := server.GetPayment(context.Background(), req)
+		res, err := server.GetPayment(principalContext(testUserID), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -157,6 +174,6 @@ func TestGetPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.Internal, st.Code())
 		assert.Contains(t, st.Message(), "failed to convert payment")
-		mockQuerier.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
 	})
 }
@@ -7,31 +7,39 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	paymentv1 "github.com/sonuudigital/microservices/gen/payment/v1"
 	"github.com/sonuudigital/microservices/payment-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 type processPaymentReqValidationResult struct {
-	orderUUID pgtype.UUID
-	userUUID  pgtype.UUID
-	amount    pgtype.Numeric
+	orderUUID   pgtype.UUID
+	userUUID    pgtype.UUID
+	amount      pgtype.Numeric
+	amountFloat float64
 }
 
 func (s *Server) ProcessPayment(ctx context.Context, req *paymentv1.ProcessPaymentRequest) (*paymentv1.Payment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	s.logger.Debug(
 		"ProccesPayment called",
 		"orderId",
 		req.OrderId,
 		"userId",
-		req.UserId,
+		principal.UserID,
 		"amount",
 		req.Amount,
 	)
-	if err := ctx.Err(); err != nil {
-		return nil, status.FromContextError(err).Err()
-	}
 
-	reqValidation, err := validateProcessPaymentRequest(req)
+	reqValidation, err := validateProcessPaymentRequest(req, principal.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -49,15 +57,15 @@ func (s *Server) ProcessPayment(ctx context.Context, req *paymentv1.ProcessPayme
 	return gRPCPayment, nil
 }
 
-func validateProcessPaymentRequest(req *paymentv1.ProcessPaymentRequest) (*processPaymentReqValidationResult, error) {
+func validateProcessPaymentRequest(req *paymentv1.ProcessPaymentRequest, userID string) (*processPaymentReqValidationResult, error) {
 	var orderUUID pgtype.UUID
 	if err := orderUUID.Scan(req.OrderId); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid order id format: %s", req.OrderId)
 	}
 
 	var userUUID pgtype.UUID
-	if err := userUUID.Scan(req.UserId); err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", req.UserId)
+	if err := userUUID.Scan(userID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", userID)
 	}
 
 	var amount pgtype.Numeric
@@ -70,9 +78,10 @@ func validateProcessPaymentRequest(req *paymentv1.ProcessPaymentRequest) (*proce
 	}
 
 	return &processPaymentReqValidationResult{
-		orderUUID: orderUUID,
-		userUUID:  userUUID,
-		amount:    amount,
+		orderUUID:   orderUUID,
+		userUUID:    userUUID,
+		amount:      amount,
+		amountFloat: req.Amount,
 	}, nil
 }
 
@@ -83,6 +92,9 @@ func (s *Server) processCreateAndUpdatePayment(ctx context.Context, reqValidatio
 	}
 
 	// Simulate payment processing logic here (e.g., interacting with a payment gateway)
+	if reason := s.rejectionReason(reqValidation); reason != "" {
+		return nil, s.rejectDBPayment(ctx, repoPayment, reason)
+	}
 
 	updatedRepoPayment, err := s.updateDBPaymentStatus(ctx, repoPayment)
 	if err != nil {
@@ -92,8 +104,26 @@ func (s *Server) processCreateAndUpdatePayment(ctx context.Context, reqValidatio
 	return updatedRepoPayment, nil
 }
 
+func (s *Server) rejectionReason(reqValidation *processPaymentReqValidationResult) string {
+	if s.maxAmount > 0 && reqValidation.amountFloat > s.maxAmount {
+		return fmt.Sprintf("amount %.2f exceeds the limit of %.2f", reqValidation.amountFloat, s.maxAmount)
+	}
+	return ""
+}
+
+// rejectDBPayment moves the payment to REJECTED, which records
+// payment.rejected, and returns the FailedPrecondition error callers see.
+func (s *Server) rejectDBPayment(ctx context.Context, repoPayment *repository.Payment, reason string) error {
+	if _, err := s.repository.SetPaymentStatus(ctx, repoPayment.ID, repository.PaymentStatusRejected, reason); err != nil {
+		return status.Errorf(codes.Internal, "failed to update payment status: %v", err)
+	}
+
+	s.logger.Info("payment rejected", "paymentId", repoPayment.ID.String(), "orderId", repoPayment.OrderID.String(), "reason", reason)
+	return status.Errorf(codes.FailedPrecondition, "payment rejected: %s", reason)
+}
+
 func (s *Server) createDBPayment(ctx context.Context, reqValidation *processPaymentReqValidationResult) (*repository.Payment, error) {
-	repositoryPayment, err := s.querier.CreatePayment(ctx, repository.CreatePaymentParams{
+	repositoryPayment, err := s.repository.CreatePayment(ctx, repository.CreatePaymentParams{
 		OrderID: reqValidation.orderUUID,
 		UserID:  reqValidation.userUUID,
 		Amount:  reqValidation.amount,
@@ -105,15 +135,7 @@ func (s *Server) createDBPayment(ctx context.Context, reqValidation *processPaym
 }
 
 func (s *Server) updateDBPaymentStatus(ctx context.Context, repoPayment *repository.Payment) (*repository.Payment, error) {
-	statusUUID, err := s.querier.GetPaymentStatusByName(ctx, "SUCCEEDED")
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get 'SUCCEEDED' status ID: %v", err)
-	}
-
-	updatedRepoPayment, err := s.querier.UpdatePaymentStatus(ctx, repository.UpdatePaymentStatusParams{
-		ID:     repoPayment.ID,
-		Status: statusUUID,
-	})
+	updatedRepoPayment, err := s.repository.SetPaymentStatus(ctx, repoPayment.ID, repository.PaymentStatusSucceeded, "")
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to update payment status: %v", err)
 	}
@@ -18,8 +18,7 @@ import (
 )
 
 const (
-	repositoryCreatePaymentParamsType       = "repository.CreatePaymentParams"
-	repositoryUpdatePaymentStatusParamsType = "repository.UpdatePaymentStatusParams"
+	repositoryCreatePaymentParamsType = "repository.CreatePaymentParams"
 )
 
 func TestProcessPayment(t *testing.T) {
@@ -37,13 +36,12 @@ func TestProcessPayment(t *testing.T) {
 
 	req := &paymentv1.ProcessPaymentRequest{
 		OrderId: testOrderID,
-		UserId:  testUserID,
 		Amount:  99.99,
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		var amount pgtype.Numeric
 		_ = amount.Scan("99.99")
@@ -66,14 +64,12 @@ func TestProcessPayment(t *testing.T) {
 			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
 		}
 
-		mockQuerier.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
+		mockRepo.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
 			Return(createdPayment, nil).Once()
-		mockQuerier.On("GetPaymentStatusByName", mock.Anything, "SUCCEEDED").
-			Return(statusUUID, nil).Once()
-		mockQuerier.On("UpdatePaymentStatus", mock.Anything, mock.AnythingOfType(repositoryUpdatePaymentStatusParamsType)).
+		mockRepo.On("SetPaymentStatus", mock.Anything, paymentUUID, repository.PaymentStatusSucceeded, "").
 			Return(updatedPayment, nil).Once()
 
-		res, err := server.ProcessPayment(context.Background(), req)
+		res, err := server.ProcessPayment(principalContext(testUserID), req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
@@ -81,20 +77,19 @@ func TestProcessPayment(t *testing.T) {
 		assert.Equal(t, testOrderID, res.OrderId)
 		assert.Equal(t, testUserID, res.UserId)
 		assert.Equal(t, 99.99, res.Amount)
-		mockQuerier.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Invalid Order ID", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		invalidReq := &paymentv1.ProcessPaymentRequest{
 			OrderId: "invalid-uuid",
-			UserId:  testUserID,
 			Amount:  99.99,
 		}
 
-		res, err := server.ProcessPayment(context.Background(), invalidReq)
+		res, err := server.ProcessPayment(principalContext(testUserID), invalidReq)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -102,20 +97,30 @@ func TestProcessPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.InvalidArgument, st.Code())
 		assert.Contains(t, st.Message(), "invalid order id format")
-		mockQuerier.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Missing Principal", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
+
+		res, err := server.ProcessPayment(context.Background(), req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		mockRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Invalid User ID", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		invalidReq := &paymentv1.ProcessPaymentRequest{
 			OrderId: testOrderID,
-			UserId:  "invalid-uuid",
 			Amount:  99.99,
 		}
 
-		res, err := server.ProcessPayment(context.Background(), invalidReq)
+		res, err := server.ProcessPayment(principalContext("invalid-uuid"), invalidReq)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -123,20 +128,19 @@ func TestProcessPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.InvalidArgument, st.Code())
 		assert.Contains(t, st.Message(), "invalid user id format")
-		mockQuerier.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Invalid Amount", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		invalidReq := &paymentv1.ProcessPaymentRequest{
 			OrderId: testOrderID,
-			UserId:  testUserID,
 			Amount:  -10.00,
 		}
 
-		res, err := server.ProcessPayment(context.Background(), invalidReq)
+		res, err := server.ProcessPayment(principalContext(testUserID), invalidReq)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -144,17 +148,17 @@ func TestProcessPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.InvalidArgument, st.Code())
 		assert.Contains(t, st.Message(), "amount must be positive")
-		mockQuerier.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Create Payment Error", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
-		mockQuerier.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
+		mockRepo.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
 			Return(repository.Payment{}, errors.New("database error")).Once()
 
-		res, err := server.ProcessPayment(context.Background(), req)
+		res, err := server.ProcessPayment(principalContext(testUserID), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -162,44 +166,12 @@ func TestProcessPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.Internal, st.Code())
 		assert.Contains(t, st.Message(), "failed to create payment")
-		mockQuerier.AssertExpectations(t)
-	})
-
-	t.Run("Get Payment Status Error", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
-
-		var amount pgtype.Numeric
-		_ = amount.Scan("99.99")
-
-		createdPayment := repository.Payment{
-			ID:        paymentUUID,
-			OrderID:   orderUUID,
-			UserID:    userUUID,
-			Amount:    amount,
-			Status:    pgtype.UUID{},
-			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
-		}
-
-		mockQuerier.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
-			Return(createdPayment, nil).Once()
-		mockQuerier.On("GetPaymentStatusByName", mock.Anything, "SUCCEEDED").
-			Return(pgtype.UUID{}, errors.New("status not found")).Once()
-
-		res, err := server.ProcessPayment(context.Background(), req)
-
-		assert.Error(t, err)
-		assert.Nil(t, res)
-		st, ok := status.FromError(err)
-		assert.True(t, ok)
-		assert.Equal(t, codes.Internal, st.Code())
-		assert.Contains(t, st.Message(), "failed to get 'SUCCEEDED' status ID")
-		mockQuerier.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Update Payment Status Error", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		var amount pgtype.Numeric
 		_ = amount.Scan("99.99")
@@ -213,14 +185,12 @@ func TestProcessPayment(t *testing.T) {
 			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
 		}
 
-		mockQuerier.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
+		mockRepo.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
 			Return(createdPayment, nil).Once()
-		mockQuerier.On("GetPaymentStatusByName", mock.Anything, "SUCCEEDED").
-			Return(statusUUID, nil).Once()
-		mockQuerier.On("UpdatePaymentStatus", mock.Anything, mock.AnythingOfType(repositoryUpdatePaymentStatusParamsType)).
+		mockRepo.On("SetPaymentStatus", mock.Anything, paymentUUID, repository.PaymentStatusSucceeded, "").
 			Return(repository.Payment{}, errors.New("update failed")).Once()
 
-		res, err := server.ProcessPayment(context.Background(), req)
+		res, err := server.ProcessPayment(principalContext(testUserID), req)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -228,12 +198,12 @@ func TestProcessPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.Internal, st.Code())
 		assert.Contains(t, st.Message(), "failed to update payment status")
-		mockQuerier.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Context Canceled", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
@@ -245,20 +215,19 @@ func TestProcessPayment(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.Canceled, st.Code())
-		mockQuerier.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Zero Amount", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		zeroReq := &paymentv1.ProcessPaymentRequest{
 			OrderId: testOrderID,
-			UserId:  testUserID,
 			Amount:  0,
 		}
 
-		res, err := server.ProcessPayment(context.Background(), zeroReq)
+		res, err := server.ProcessPayment(principalContext(testUserID), zeroReq)
 
 		assert.Error(t, err)
 		assert.Nil(t, res)
@@ -266,16 +235,15 @@ func TestProcessPayment(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, codes.InvalidArgument, st.Code())
 		assert.Contains(t, st.Message(), "amount must be positive")
-		mockQuerier.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Large Amount", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
-		server := payment.New(logs.NewSlogLogger(), mockQuerier)
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
 
 		largeReq := &paymentv1.ProcessPaymentRequest{
 			OrderId: testOrderID,
-			UserId:  testUserID,
 			Amount:  999999.99,
 		}
 
@@ -300,18 +268,64 @@ func TestProcessPayment(t *testing.T) {
 			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
 		}
 
-		mockQuerier.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
+		mockRepo.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
 			Return(createdPayment, nil).Once()
-		mockQuerier.On("GetPaymentStatusByName", mock.Anything, "SUCCEEDED").
-			Return(statusUUID, nil).Once()
-		mockQuerier.On("UpdatePaymentStatus", mock.Anything, mock.AnythingOfType(repositoryUpdatePaymentStatusParamsType)).
+		mockRepo.On("SetPaymentStatus", mock.Anything, paymentUUID, repository.PaymentStatusSucceeded, "").
 			Return(updatedPayment, nil).Once()
 
-		res, err := server.ProcessPayment(context.Background(), largeReq)
+		res, err := server.ProcessPayment(principalContext(testUserID), largeReq)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, 999999.99, res.Amount)
-		mockQuerier.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Amount Above Limit Is Rejected", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo, payment.WithMaxAmount(50))
+
+		var amount pgtype.Numeric
+		_ = amount.Scan("99.99")
+
+		createdPayment := repository.Payment{
+			ID:        paymentUUID,
+			OrderID:   orderUUID,
+			UserID:    userUUID,
+			Amount:    amount,
+			Status:    pgtype.UUID{},
+			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		}
+
+		mockRepo.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
+			Return(createdPayment, nil).Once()
+		mockRepo.On("SetPaymentStatus", mock.Anything, paymentUUID, repository.PaymentStatusRejected, "amount 99.99 exceeds the limit of 50.00").
+			Return(createdPayment, nil).Once()
+
+		res, err := server.ProcessPayment(principalContext(testUserID), req)
+
+		assert.Nil(t, res)
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.FailedPrecondition, st.Code())
+		assert.Contains(t, st.Message(), "payment rejected")
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "SetPaymentStatus", mock.Anything, paymentUUID, repository.PaymentStatusSucceeded, mock.Anything)
+	})
+
+	t.Run("Reject Status Error", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo, payment.WithMaxAmount(50))
+
+		mockRepo.On("CreatePayment", mock.Anything, mock.AnythingOfType(repositoryCreatePaymentParamsType)).
+			Return(repository.Payment{ID: paymentUUID}, nil).Once()
+		mockRepo.On("SetPaymentStatus", mock.Anything, paymentUUID, repository.PaymentStatusRejected, mock.Anything).
+			Return(repository.Payment{}, errors.New("db error")).Once()
+
+		res, err := server.ProcessPayment(principalContext(testUserID), req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		mockRepo.AssertExpectations(t)
 	})
 }
@@ -0,0 +1,64 @@
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	paymentv1 "github.com/sonuudigital/microservices/gen/payment/v1"
+	"github.com/sonuudigital/microservices/payment-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RefundPayment refunds a payment of the calling user. Operators calling with
+// an admin token may refund any payment.
+func (s *Server) RefundPayment(ctx context.Context, req *paymentv1.RefundPaymentRequest) (*paymentv1.Payment, error) {
+	s.logger.Debug("RefundPayment called", "paymentId", req.Id)
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	var userUUID pgtype.UUID
+	operator, isOperator := auth.OperatorFromContext(ctx)
+	if !isOperator {
+		principal, err := auth.RequirePrincipal(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := userUUID.Scan(principal.UserID); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", principal.UserID)
+		}
+	}
+
+	var uuid pgtype.UUID
+	if err := uuid.Scan(req.Id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payment id format: %s", req.Id)
+	}
+
+	payment, err := s.repository.RefundPayment(ctx, uuid, userUUID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrPaymentNotFound):
+			return nil, status.Errorf(codes.NotFound, "payment with id %s not found", req.Id)
+		case errors.Is(err, repository.ErrPaymentNotRefundable):
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot refund payment %s: %v", req.Id, err)
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to refund payment: %v", err)
+		}
+	}
+
+	if isOperator {
+		s.logger.Info("payment refunded", "paymentId", req.Id, "orderId", payment.OrderID.String(), "operator", operator)
+	} else {
+		s.logger.Info("payment refunded", "paymentId", req.Id, "orderId", payment.OrderID.String())
+	}
+
+	grpcPayment, err := mapRepositoryToGRPC(&payment)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert payment: %v", err)
+	}
+
+	return grpcPayment, nil
+}
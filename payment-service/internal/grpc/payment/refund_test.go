@@ -0,0 +1,125 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	paymentv1 "github.com/sonuudigital/microservices/gen/payment/v1"
+	"github.com/sonuudigital/microservices/payment-service/internal/grpc/payment"
+	"github.com/sonuudigital/microservices/payment-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRefundPayment(t *testing.T) {
+	var paymentUUID pgtype.UUID
+	_ = paymentUUID.Scan(testPaymentID)
+
+	var orderUUID pgtype.UUID
+	_ = orderUUID.Scan(testOrderID)
+
+	var userUUID pgtype.UUID
+	_ = userUUID.Scan(testUserID)
+
+	req := &paymentv1.RefundPaymentRequest{Id: testPaymentID, Reason: "order cancelled"}
+
+	var amount pgtype.Numeric
+	_ = amount.Scan("99.99")
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
+
+		refundedPayment := repository.Payment{
+			ID:        paymentUUID,
+			OrderID:   orderUUID,
+			UserID:    userUUID,
+			Amount:    amount,
+			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		}
+		mockRepo.On("RefundPayment", mock.Anything, paymentUUID, userUUID, "order cancelled").
+			Return(refundedPayment, nil).Once()
+
+		res, err := server.RefundPayment(principalContext(testUserID), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, testPaymentID, res.Id)
+		assert.Equal(t, testOrderID, res.OrderId)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Operator Refunds Any Payment", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
+
+		mockRepo.On("RefundPayment", mock.Anything, paymentUUID, pgtype.UUID{}, "order cancelled").
+			Return(repository.Payment{
+				ID:        paymentUUID,
+				OrderID:   orderUUID,
+				UserID:    userUUID,
+				Amount:    amount,
+				CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+			}, nil).Once()
+
+		res, err := server.RefundPayment(auth.ContextWithOperator(context.Background(), "alice"), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, testPaymentID, res.Id)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
+
+		res, err := server.RefundPayment(context.Background(), req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		mockRepo.AssertNotCalled(t, "RefundPayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid Payment ID", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		server := payment.New(logs.NewSlogLogger(), mockRepo)
+
+		res, err := server.RefundPayment(principalContext(testUserID), &paymentv1.RefundPaymentRequest{Id: "invalid-uuid"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		mockRepo.AssertNotCalled(t, "RefundPayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			err  error
+			code codes.Code
+		}{
+			{"Payment Not Found Or Of Another User", repository.ErrPaymentNotFound, codes.NotFound},
+			{"Payment Not Refundable", repository.ErrPaymentNotRefundable, codes.FailedPrecondition},
+			{"Database Error", errors.New("database connection error"), codes.Internal},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := new(MockPaymentRepository)
+				server := payment.New(logs.NewSlogLogger(), mockRepo)
+				mockRepo.On("RefundPayment", mock.Anything, paymentUUID, userUUID, "order cancelled").
+					Return(repository.Payment{}, tt.err).Once()
+
+				res, err := server.RefundPayment(principalContext(testUserID), req)
+
+				assert.Nil(t, res)
+				assert.Equal(t, tt.code, status.Code(err))
+				mockRepo.AssertExpectations(t)
+			})
+		}
+	})
+}
@@ -1,20 +1,45 @@
 package payment
 
 import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 	paymentv1 "github.com/sonuudigital/microservices/gen/payment/v1"
 	"github.com/sonuudigital/microservices/payment-service/internal/repository"
 	"github.com/sonuudigital/microservices/shared/logs"
 )
 
+type PaymentRepository interface {
+	CreatePayment(ctx context.Context, arg repository.CreatePaymentParams) (repository.Payment, error)
+	GetPaymentByID(ctx context.Context, id pgtype.UUID) (repository.Payment, error)
+	SetPaymentStatus(ctx context.Context, id pgtype.UUID, status, reason string) (repository.Payment, error)
+	RefundPayment(ctx context.Context, id, userID pgtype.UUID, reason string) (repository.Payment, error)
+}
+
 type Server struct {
 	paymentv1.UnimplementedPaymentServiceServer
-	logger  logs.Logger
-	querier repository.Querier
+	logger     logs.Logger
+	repository PaymentRepository
+	maxAmount  float64
+}
+
+type Option func(*Server)
+
+// WithMaxAmount rejects payments above maxAmount. Zero, the default, accepts
+// any amount.
+func WithMaxAmount(maxAmount float64) Option {
+	return func(s *Server) {
+		s.maxAmount = maxAmount
+	}
 }
 
-func New(logger logs.Logger, querier repository.Querier) *Server {
-	return &Server{
-		logger:  logger,
-		querier: querier,
+func New(logger logs.Logger, repository PaymentRepository, opts ...Option) *Server {
+	s := &Server{
+		logger:     logger,
+		repository: repository,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
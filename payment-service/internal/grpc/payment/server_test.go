@@ -5,6 +5,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/sonuudigital/microservices/payment-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -15,26 +16,30 @@ const (
 	testsPaymentStatusID = "d0eebc99-9c0b-4ef8-bb6d-6bb9bd380a14"
 )
 
-type MockQuerier struct {
+func principalContext(userID string) context.Context {
+	return auth.ContextWithPrincipal(context.Background(), auth.Principal{UserID: userID})
+}
+
+type MockPaymentRepository struct {
 	mock.Mock
 }
 
-func (m *MockQuerier) CreatePayment(ctx context.Context, arg repository.CreatePaymentParams) (repository.Payment, error) {
+func (m *MockPaymentRepository) CreatePayment(ctx context.Context, arg repository.CreatePaymentParams) (repository.Payment, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(repository.Payment), args.Error(1)
 }
 
-func (m *MockQuerier) GetPaymentByID(ctx context.Context, id pgtype.UUID) (repository.Payment, error) {
+func (m *MockPaymentRepository) GetPaymentByID(ctx context.Context, id pgtype.UUID) (repository.Payment, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(repository.Payment), args.Error(1)
 }
 
-func (m *MockQuerier) GetPaymentStatusByName(ctx context.Context, name string) (pgtype.UUID, error) {
-	args := m.Called(ctx, name)
-	return args.Get(0).(pgtype.UUID), args.Error(1)
+func (m *MockPaymentRepository) SetPaymentStatus(ctx context.Context, id pgtype.UUID, status, reason string) (repository.Payment, error) {
+	args := m.Called(ctx, id, status, reason)
+	return args.Get(0).(repository.Payment), args.Error(1)
 }
 
-func (m *MockQuerier) UpdatePaymentStatus(ctx context.Context, arg repository.UpdatePaymentStatusParams) (repository.Payment, error) {
-	args := m.Called(ctx, arg)
+func (m *MockPaymentRepository) RefundPayment(ctx context.Context, id, userID pgtype.UUID, reason string) (repository.Payment, error) {
+	args := m.Called(ctx, id, userID, reason)
 	return args.Get(0).(repository.Payment), args.Error(1)
 }
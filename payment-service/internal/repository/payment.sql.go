@@ -56,6 +56,26 @@ func (q *Queries) GetPaymentByID(ctx context.Context, id pgtype.UUID) (Payment,
 	return i, err
 }
 
+const getPaymentByIDForUpdate = `-- name: GetPaymentByIDForUpdate :one
+SELECT id, order_id, user_id, amount, status, created_at FROM payments
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetPaymentByIDForUpdate(ctx context.Context, id pgtype.UUID) (Payment, error) {
+	row := q.db.QueryRow(ctx, getPaymentByIDForUpdate, id)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.UserID,
+		&i.Amount,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getPaymentStatusByName = `-- name: GetPaymentStatusByName :one
 SELECT id FROM payment_statuses
 WHERE name = $1
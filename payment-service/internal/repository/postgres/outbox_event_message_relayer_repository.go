@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sonuudigital/microservices/payment-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/events/worker"
+)
+
+const (
+	outboxEventStatusPublished = "PUBLISHED"
+)
+
+type OutboxEventMessageRelayerRepository struct {
+	*repository.Queries
+}
+
+func NewOutboxEventMessageRelayerRepository(db repository.DBTX) *OutboxEventMessageRelayerRepository {
+	return &OutboxEventMessageRelayerRepository{
+		Queries: repository.New(db),
+	}
+}
+
+func (r *OutboxEventMessageRelayerRepository) ClaimUnpublishedOutboxEvents(ctx context.Context, limit int32, lease time.Duration) ([]events.OutboxEvent, error) {
+	outboxEvents, err := r.Queries.ClaimUnpublishedOutboxEvents(ctx, repository.ClaimUnpublishedOutboxEventsParams{
+		LeaseSeconds: lease.Seconds(),
+		BatchSize:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]events.OutboxEvent, 0, len(outboxEvents))
+	for _, oe := range outboxEvents {
+		result = append(result, worker.OutboxEventRow(oe).OutboxEvent())
+	}
+	return result, nil
+}
+
+func (r *OutboxEventMessageRelayerRepository) OutboxBacklog(ctx context.Context) (int64, time.Time, error) {
+	backlog, err := r.GetOutboxBacklog(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return backlog.Size, backlog.OldestCreatedAt.Time, nil
+}
+
+func (r *OutboxEventMessageRelayerRepository) UpdateOutboxEventStatus(ctx context.Context, eventID string) error {
+	eventUUID, err := worker.ParseOutboxEventID(eventID)
+	if err != nil {
+		return err
+	}
+	return r.Queries.UpdateOutboxEventStatus(ctx, repository.UpdateOutboxEventStatusParams{
+		ID:     eventUUID,
+		Status: outboxEventStatusPublished,
+	})
+}
+
+func (r *OutboxEventMessageRelayerRepository) RecordOutboxEventFailure(ctx context.Context, eventID, lastError string, nextAttemptAt time.Time, failed bool) error {
+	eventUUID, err := worker.ParseOutboxEventID(eventID)
+	if err != nil {
+		return err
+	}
+	return r.Queries.RecordOutboxEventFailure(ctx, repository.RecordOutboxEventFailureParams{
+		Failed:        failed,
+		LastError:     pgtype.Text{String: lastError, Valid: true},
+		NextAttemptAt: pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+		ID:            eventUUID,
+	})
+}
+
+func (r *OutboxEventMessageRelayerRepository) ArchiveOutboxEvents(ctx context.Context, createdBefore time.Time, limit int32) (int64, error) {
+	return r.Queries.ArchiveOutboxEvents(ctx, repository.ArchiveOutboxEventsParams{
+		CreatedBefore: pgtype.Timestamptz{Time: createdBefore, Valid: true},
+		BatchSize:     limit,
+	})
+}
+
+func (r *OutboxEventMessageRelayerRepository) ListOutboxEvents(ctx context.Context, statuses []string, limit int32) ([]events.OutboxEvent, error) {
+	outboxEvents, err := r.ListOutboxEventsByStatus(ctx, repository.ListOutboxEventsByStatusParams{
+		Statuses:  statuses,
+		MaxEvents: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]events.OutboxEvent, 0, len(outboxEvents))
+	for _, oe := range outboxEvents {
+		result = append(result, worker.OutboxEventRow(oe).OutboxEvent())
+	}
+	return result, nil
+}
+
+func (r *OutboxEventMessageRelayerRepository) RequeueOutboxEvent(ctx context.Context, eventID string) (events.OutboxEvent, error) {
+	eventUUID, err := worker.ParseOutboxEventID(eventID)
+	if err != nil {
+		return events.OutboxEvent{}, worker.ErrOutboxEventNotRequeueable
+	}
+
+	oe, err := r.Queries.RequeueOutboxEvent(ctx, eventUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return events.OutboxEvent{}, worker.ErrOutboxEventNotRequeueable
+	}
+	if err != nil {
+		return events.OutboxEvent{}, err
+	}
+	return worker.OutboxEventRow(oe).OutboxEvent(), nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/tracing"
+)
+
+const eventSource = "/payment-service"
+
+const (
+	PaymentStatusProcessing = "PROCESSING"
+	PaymentStatusSucceeded  = "SUCCEEDED"
+	PaymentStatusRejected   = "REJECTED"
+	PaymentStatusRefunded   = "REFUNDED"
+)
+
+var (
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrPaymentNotRefundable = errors.New("only succeeded payments can be refunded")
+)
+
+type paymentStatusEvent struct {
+	name      string
+	eventType string
+}
+
+// paymentStatusEvents maps the statuses that end a payment to the event
+// recorded when a payment moves to them.
+var paymentStatusEvents = map[string]paymentStatusEvent{
+	PaymentStatusSucceeded: {name: events.PaymentSucceededEventName, eventType: events.PaymentSucceededEventType},
+	PaymentStatusRejected:  {name: events.PaymentRejectedEventName, eventType: events.PaymentRejectedEventType},
+	PaymentStatusRefunded:  {name: events.PaymentRefundedEventName, eventType: events.PaymentRefundedEventType},
+}
+
+type PostgreSQLPaymentRepository struct {
+	*Queries
+	db *pgxpool.Pool
+}
+
+func NewPostgreSQLPaymentRepository(db *pgxpool.Pool) *PostgreSQLPaymentRepository {
+	return &PostgreSQLPaymentRepository{
+		db:      db,
+		Queries: New(db),
+	}
+}
+
+func (r *PostgreSQLPaymentRepository) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetPaymentStatus updates the status of a payment and records the payment
+// event of the new status in the outbox in the same transaction.
+func (r *PostgreSQLPaymentRepository) SetPaymentStatus(ctx context.Context, id pgtype.UUID, status, reason string) (Payment, error) {
+	var payment Payment
+	err := r.execTx(ctx, func(q *Queries) error {
+		var err error
+		payment, err = changePaymentStatus(ctx, q, id, status, reason)
+		return err
+	})
+	return payment, err
+}
+
+// RefundPayment moves a succeeded payment to REFUNDED and records
+// payment.refunded. When userID is valid, payments of other users are
+// reported as not found.
+func (r *PostgreSQLPaymentRepository) RefundPayment(ctx context.Context, id, userID pgtype.UUID, reason string) (Payment, error) {
+	var payment Payment
+	err := r.execTx(ctx, func(q *Queries) error {
+		current, err := q.GetPaymentByIDForUpdate(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPaymentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get payment: %w", err)
+		}
+		if userID.Valid && current.UserID != userID {
+			return ErrPaymentNotFound
+		}
+
+		succeeded, err := q.GetPaymentStatusByName(ctx, PaymentStatusSucceeded)
+		if err != nil {
+			return fmt.Errorf("failed to get %s status: %w", PaymentStatusSucceeded, err)
+		}
+		if current.Status != succeeded {
+			return ErrPaymentNotRefundable
+		}
+
+		payment, err = changePaymentStatus(ctx, q, id, PaymentStatusRefunded, reason)
+		return err
+	})
+	return payment, err
+}
+
+func changePaymentStatus(ctx context.Context, q *Queries, id pgtype.UUID, status, reason string) (Payment, error) {
+	statusID, err := q.GetPaymentStatusByName(ctx, status)
+	if err != nil {
+		return Payment{}, fmt.Errorf("failed to get %s status: %w", status, err)
+	}
+
+	payment, err := q.UpdatePaymentStatus(ctx, UpdatePaymentStatusParams{
+		ID:     id,
+		Status: statusID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Payment{}, ErrPaymentNotFound
+	}
+	if err != nil {
+		return Payment{}, fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	if event, ok := paymentStatusEvents[status]; ok {
+		if err := recordEvent(ctx, q, payment, status, reason, event); err != nil {
+			return Payment{}, err
+		}
+	}
+	return payment, nil
+}
+
+func recordEvent(ctx context.Context, q *Queries, payment Payment, status, reason string, event paymentStatusEvent) error {
+	amount, err := payment.Amount.Float64Value()
+	if err != nil {
+		return fmt.Errorf("failed to convert amount: %w", err)
+	}
+
+	payload, err := events.Wrap(eventSource, event.eventType, payment.ID.String(), events.PaymentEvent{
+		PaymentID: payment.ID.String(),
+		OrderID:   payment.OrderID.String(),
+		UserID:    payment.UserID.String(),
+		Amount:    fmt.Sprintf("%.2f", amount.Float64),
+		Status:    status,
+		Reason:    reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", event.eventType, err)
+	}
+
+	err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateID:  payment.ID,
+		EventName:    event.name,
+		Payload:      payload,
+		TraceContext: tracing.MarshalTraceContext(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+	return nil
+}
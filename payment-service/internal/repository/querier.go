@@ -11,9 +11,20 @@ import (
 )
 
 type Querier interface {
+	ArchiveOutboxEvents(ctx context.Context, arg ArchiveOutboxEventsParams) (int64, error)
+	ClaimUnpublishedOutboxEvents(ctx context.Context, arg ClaimUnpublishedOutboxEventsParams) ([]ClaimUnpublishedOutboxEventsRow, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error
 	CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error)
+	GetOutboxBacklog(ctx context.Context) (GetOutboxBacklogRow, error)
 	GetPaymentByID(ctx context.Context, id pgtype.UUID) (Payment, error)
+	GetPaymentByIDForUpdate(ctx context.Context, id pgtype.UUID) (Payment, error)
 	GetPaymentStatusByName(ctx context.Context, name string) (pgtype.UUID, error)
+	ListOutboxEventsByStatus(ctx context.Context, arg ListOutboxEventsByStatusParams) ([]ListOutboxEventsByStatusRow, error)
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error
+	// A pending event is only requeued while no relayer holds its lease, so a
+	// publish in flight is not reset underneath the relayer.
+	RequeueOutboxEvent(ctx context.Context, id pgtype.UUID) (RequeueOutboxEventRow, error)
+	UpdateOutboxEventStatus(ctx context.Context, arg UpdateOutboxEventStatusParams) error
 	UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) (Payment, error)
 }
 
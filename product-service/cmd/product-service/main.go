@@ -7,7 +7,6 @@ import (
 	"net"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
@@ -15,16 +14,20 @@ import (
 	"github.com/redis/go-redis/v9"
 	product_categoriesv1 "github.com/sonuudigital/microservices/gen/product-categories/v1"
 	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
-	"github.com/sonuudigital/microservices/product-service/internal/events"
 	"github.com/sonuudigital/microservices/product-service/internal/events/consumers"
 	grpc_server "github.com/sonuudigital/microservices/product-service/internal/grpc"
 	"github.com/sonuudigital/microservices/product-service/internal/grpc/category"
 	"github.com/sonuudigital/microservices/product-service/internal/repository"
 	repo_postgres "github.com/sonuudigital/microservices/product-service/internal/repository/postgres"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/events"
 	"github.com/sonuudigital/microservices/shared/events/worker"
+	"github.com/sonuudigital/microservices/shared/inbox"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"github.com/sonuudigital/microservices/shared/postgres"
 	"github.com/sonuudigital/microservices/shared/rabbitmq"
+	"github.com/sonuudigital/microservices/shared/tracing"
 	"github.com/sonuudigital/microservices/shared/web"
 	"github.com/sonuudigital/microservices/shared/web/health"
 	"golang.org/x/sync/errgroup"
@@ -34,7 +37,7 @@ import (
 )
 
 func main() {
-	logger := logs.NewSlogLogger()
+	logger := logs.NewSlogLogger(logs.WithServiceName("product-service"))
 	err := godotenv.Load()
 	if err == nil {
 		logger.Info("loaded environment variables from .env file")
@@ -42,6 +45,20 @@ func main() {
 		logger.Info("no .env file found, using environment variables")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "product-service", logger)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	// The inbox table must exist before the service migrations, which
+	// backfill it from processed_events.
+	if err := inbox.Migrate(os.Getenv("DATABASE_URL")); err != nil {
+		logger.Error("failed to migrate inbox", "error", err)
+		os.Exit(1)
+	}
+
 	pgDb, err := postgres.InitializePostgresDB()
 	if err != nil {
 		logger.Error("error connecting to database", "error", err)
@@ -50,6 +67,18 @@ func main() {
 	logger.Info("database connected successfully")
 	defer pgDb.Close()
 
+	adminAuth, err := auth.LoadAdminAuthFromEnv(logger)
+	if err != nil {
+		logger.Error("failed to load admin auth", "error", err)
+		os.Exit(1)
+	}
+
+	metricsServer := metrics.StartServer(logger, metrics.WithAdminAuth(adminAuth), metrics.WithAdminHandler(
+		worker.OutboxAdminPath,
+		worker.NewOutboxAdminHandler(logger, repo_postgres.NewOutboxEventMessageRelayerRepository(pgDb)),
+	))
+	defer metricsServer.Close()
+
 	redisClient, err := initializeRedisClient()
 	if err != nil {
 		logger.Error("error connecting to redis", "error", err)
@@ -58,23 +87,19 @@ func main() {
 	logger.Info("redis connected successfully")
 	defer redisClient.Close()
 
-	fanoutPublisher, rabbitmqClient, err := initializeRabbitMQClients(logger)
+	rabbitmqClient, err := rabbitmq.NewServiceClientFromEnv(logger, "product-service")
 	if err != nil {
-		logger.Error("failed to initialize RabbitMQ clients", "error", err)
+		logger.Error("failed to initialize RabbitMQ client", "error", err)
 		os.Exit(1)
 	}
-	defer fanoutPublisher.Close()
 	defer rabbitmqClient.Close()
 
-	delegatingPublisher := events.NewDelegatingPublisher(fanoutPublisher, rabbitmqClient)
-
-	initializeServicesAndWaitForShutdown(logger, fanoutPublisher, delegatingPublisher, pgDb, redisClient)
+	initializeServicesAndWaitForShutdown(logger, rabbitmqClient, pgDb, redisClient)
 }
 
 func initializeServicesAndWaitForShutdown(
 	logger logs.Logger,
-	fanoutPublisher *rabbitmq.RabbitMQ,
-	delegatingPublisher *events.DelegatingPublisher,
+	rabbitmqClient *rabbitmq.Client,
 	pgDb *pgxpool.Pool,
 	redisClient *redis.Client,
 ) {
@@ -84,14 +109,25 @@ func initializeServicesAndWaitForShutdown(
 	g, gCtx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
-		return startRabbitMQConsumer(gCtx, logger, fanoutPublisher, redisClient, repository.NewPostgreSQLOrderCreatedConsumerRepository(pgDb))
+		return startRabbitMQConsumer(gCtx, logger, rabbitmqClient, redisClient, pgDb, repository.NewPostgreSQLOrderCreatedConsumerRepository())
 	})
 
 	g.Go(func() error {
-		return startGRPCServer(gCtx, pgDb, redisClient, logger)
+		return startGRPCServer(gCtx, pgDb, redisClient, rabbitmqClient, logger)
 	})
 
-	go startMessageRelayerWorker(gCtx, logger, delegatingPublisher, pgDb)
+	relayer, err := worker.NewOutboxEventMessageRelayerFromEnv(
+		logger,
+		worker.NewEventPublisher(rabbitmqClient, events.Registry),
+		repo_postgres.NewOutboxEventMessageRelayerRepository(pgDb),
+	)
+	if err != nil {
+		logger.Error("failed to configure message relayer", "error", err)
+		os.Exit(1)
+	}
+	go relayer.Start(gCtx)
+	go startOutboxRetentionJob(gCtx, logger, pgDb)
+	go startInboxRetentionJob(gCtx, logger, pgDb)
 
 	if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
 		logger.Error("application exited with error", "error", err)
@@ -101,7 +137,7 @@ func initializeServicesAndWaitForShutdown(
 	logger.Info("application shut down gracefully")
 }
 
-func startGRPCServer(ctx context.Context, pgDb *pgxpool.Pool, redisClient *redis.Client, logger logs.Logger) error {
+func startGRPCServer(ctx context.Context, pgDb *pgxpool.Pool, redisClient *redis.Client, rabbitmq *rabbitmq.Client, logger logs.Logger) error {
 	grpcPort := os.Getenv("PRODUCT_SERVICE_GRPC_PORT")
 	if grpcPort == "" {
 		return fmt.Errorf("PRODUCT_SERVICE_GRPC_PORT is not set")
@@ -112,8 +148,28 @@ func startGRPCServer(ctx context.Context, pgDb *pgxpool.Pool, redisClient *redis
 		return fmt.Errorf("failed to listen for gRPC: %w", err)
 	}
 
+	tlsConfig, err := web.LoadTLSConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load TLS config: %w", err)
+	}
+
+	serverOpts, err := web.NewGRPCServerCredentials(tlsConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC server credentials: %w", err)
+	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logs.UnaryServerInterceptor()))
+	serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor(), logs.StreamServerInterceptor()))
+
+	identityVerifier, err := auth.LoadIdentityTokenVerifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load identity token verifier: %w", err)
+	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(identityVerifier, logger)))
+	serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(auth.StreamServerInterceptor(identityVerifier, logger)))
+
 	productRepo := repo_postgres.NewProductRepository(pgDb)
-	grpcServer := grpc.NewServer()
+	serverOpts = append(serverOpts, tracing.GRPCServerOption())
+	grpcServer := grpc.NewServer(serverOpts...)
 
 	categoryServer := category.New(logger, productRepo, redisClient)
 	productServer := grpc_server.NewServer(logger, productRepo, redisClient)
@@ -121,40 +177,40 @@ func startGRPCServer(ctx context.Context, pgDb *pgxpool.Pool, redisClient *redis
 	product_categoriesv1.RegisterProductCategoriesServiceServer(grpcServer, categoryServer)
 	productv1.RegisterProductServiceServer(grpcServer, productServer)
 
-	health.StartGRPCHealthCheckService(grpcServer, "product-service", func(ctx context.Context) error {
-		dbErr := pgDb.Ping(ctx)
-		redisErr := redisClient.Ping(ctx).Err()
-
-		if dbErr == nil && redisErr == nil {
-			logger.Info("service is healthy and serving")
-			return nil
-		} else {
-			errors := errors.Join(dbErr, redisErr)
-			logger.Error("service is not healthy", "errors", errors)
-			return errors
-		}
-	})
+	checker := health.NewChecker(logger, health.DefaultCheckInterval,
+		health.NewCheck("postgres", health.DefaultCheckTimeout, pgDb.Ping),
+		health.NewCheck("redis", health.DefaultCheckTimeout, func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}),
+		health.NewCheck("rabbitmq", health.DefaultCheckTimeout, func(context.Context) error {
+			return rabbitmq.Ping()
+		}),
+	)
+	health.StartGRPCHealthCheckService(ctx, grpcServer, "product-service", checker)
 
 	return web.StartGRPCServerAndWaitForShutdown(ctx, grpcServer, lis, logger)
 }
 
-func startMessageRelayerWorker(ctx context.Context, logger logs.Logger, publisher worker.Publisher, repo *pgxpool.Pool) {
-	pollInterval, batchSize, err := getMessageRelayerConfigFromEnv()
+func startOutboxRetentionJob(ctx context.Context, logger logs.Logger, db *pgxpool.Pool) {
+	policy, err := worker.RetentionPolicyFromEnv()
 	if err != nil {
-		logger.Error("failed to get message relayer config from env", "error", err)
+		logger.Error("failed to get outbox retention policy from env", "error", err)
 		os.Exit(1)
 	}
-	worker.NewOutboxEventMessageRelayer(
-		logger,
-		publisher,
-		repo_postgres.NewOutboxEventMessageRelayerRepository(repo),
-		pollInterval,
-		batchSize,
-	).Start(ctx)
+	worker.NewOutboxRetentionJob(logger, repo_postgres.NewOutboxEventMessageRelayerRepository(db), policy).Start(ctx)
 }
 
-func startRabbitMQConsumer(ctx context.Context, logger logs.Logger, rabbitmq *rabbitmq.RabbitMQ, redisClient *redis.Client, repo consumers.OrderCreatedConsumerRepository) error {
-	orderCreatedConsumer := consumers.NewOrderCreatedConsumer(logger, rabbitmq, redisClient, repo)
+func startInboxRetentionJob(ctx context.Context, logger logs.Logger, db *pgxpool.Pool) {
+	policy, err := inbox.RetentionPolicyFromEnv()
+	if err != nil {
+		logger.Error("failed to get inbox retention policy from env", "error", err)
+		os.Exit(1)
+	}
+	inbox.NewRetentionJob(logger, db, policy).Start(ctx)
+}
+
+func startRabbitMQConsumer(ctx context.Context, logger logs.Logger, rabbitmq *rabbitmq.Client, redisClient *redis.Client, db inbox.TxBeginner, repo consumers.OrderCreatedConsumerRepository) error {
+	orderCreatedConsumer := consumers.NewOrderCreatedConsumer(logger, rabbitmq, redisClient, db, repo)
 	logger.Info("starting OrderCreatedConsumer")
 
 	if err := orderCreatedConsumer.Start(ctx); err != nil {
@@ -185,46 +241,3 @@ func initializeRedisClient() (*redis.Client, error) {
 
 	return client, nil
 }
-
-func initializeRabbitMQClients(logger logs.Logger) (*rabbitmq.RabbitMQ, *rabbitmq.Client, error) {
-	rabbitmqURL := os.Getenv("RABBITMQ_URL")
-	if rabbitmqURL == "" {
-		return nil, nil, fmt.Errorf("RABBITMQ_URL is not set")
-	}
-
-	fanoutPublisher, err := rabbitmq.NewConnection(logger, rabbitmqURL)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create fanout rabbitmq connection: %w", err)
-	}
-
-	topicClient, err := rabbitmq.NewClient(logger, rabbitmqURL)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create topic rabbitmq client: %w", err)
-	}
-
-	return fanoutPublisher, topicClient, nil
-}
-
-func getMessageRelayerConfigFromEnv() (time.Duration, int32, error) {
-	pollIntervalStr := os.Getenv("MESSAGE_RELAYER_POLL_INTERVAL")
-	if pollIntervalStr == "" {
-		pollIntervalStr = "5s"
-	}
-
-	pollInterval, err := time.ParseDuration(pollIntervalStr)
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid MESSAGE_RELAYER_POLL_INTERVAL: %w", err)
-	}
-
-	batchSizeStr := os.Getenv("MESSAGE_RELAYER_BATCH_SIZE")
-	if batchSizeStr == "" {
-		batchSizeStr = "25"
-	}
-
-	batchSize, err := strconv.Atoi(batchSizeStr)
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid MESSAGE_RELAYER_BATCH_SIZE: %w", err)
-	}
-
-	return pollInterval, int32(batchSize), nil
-}
@@ -0,0 +1 @@
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS trace_context;
\ No newline at end of file
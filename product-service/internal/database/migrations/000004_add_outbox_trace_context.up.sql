@@ -0,0 +1 @@
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS trace_context JSONB NULL;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TYPE outbox_event_status ADD VALUE IF NOT EXISTS 'FAILED';
+ALTER TABLE outbox_events
+    ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_error TEXT NULL,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+CREATE INDEX IF NOT EXISTS idx_outbox_events_claim ON outbox_events (next_attempt_at, created_at) WHERE status = 'UNPUBLISHED';
\ No newline at end of file
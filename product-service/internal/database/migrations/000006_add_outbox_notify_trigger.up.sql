@@ -0,0 +1,12 @@
+CREATE OR REPLACE FUNCTION notify_outbox_event() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('outbox_events', NEW.event_name);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_events_notify ON outbox_events;
+CREATE TRIGGER outbox_events_notify
+    AFTER INSERT ON outbox_events
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_outbox_event();
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- Carries the orders deduplicated through processed_events over to the inbox,
+-- keyed by order ID like the consumer's inbox records. processed_events is
+-- kept so the previous release can still be rolled back to; drop it in a
+-- later release.
+INSERT INTO inbox_events (consumer, event_id, event_type, processed_at)
+SELECT 'product_order_created_consumer', aggregate_id::TEXT, 'order.created', processed_at
+FROM processed_events
+WHERE event_name = 'order_created_exchange'
+ON CONFLICT (consumer, event_id) DO NOTHING;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished_aggregate ON outbox_events (aggregate_id, created_at) WHERE status = 'UNPUBLISHED';
\ No newline at end of file
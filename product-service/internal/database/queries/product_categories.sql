@@ -22,3 +22,7 @@ WHERE id = $1;
 -- name: DeleteProductCategory :exec
 DELETE FROM product_categories
 WHERE id = $1;
+
+-- name: GetProductCategoryName :one
+SELECT name FROM product_categories
+WHERE id = $1;
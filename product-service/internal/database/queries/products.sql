@@ -55,3 +55,10 @@ WHERE id = ANY(@product_ids::uuid[]);
 SELECT * FROM products
 WHERE category_id = $1
 ORDER BY id;
+
+-- name: ListProductsAfterID :many
+SELECT * FROM products
+WHERE (sqlc.narg(after_id)::uuid IS NULL OR id > sqlc.narg(after_id)::uuid)
+  AND (sqlc.narg(updated_since)::timestamptz IS NULL OR COALESCE(updated_at, created_at) >= sqlc.narg(updated_since)::timestamptz)
+ORDER BY id
+LIMIT sqlc.arg(batch_size)::INTEGER;
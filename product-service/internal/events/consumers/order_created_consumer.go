@@ -3,66 +3,63 @@ package consumers
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/rabbitmq/amqp091-go"
 	"github.com/redis/go-redis/v9"
-	"github.com/sonuudigital/microservices/product-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/broker"
 	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/inbox"
 	"github.com/sonuudigital/microservices/shared/logs"
-	"github.com/sonuudigital/microservices/shared/rabbitmq"
 )
 
 const (
-	exchangeName               string = "order_created_exchange"
-	queueName                  string = "product_queue"
-	consumerName               string = "product_order_created_consumer"
-	stockUpdateFailedEventName string = "stock_update_failed_exchange"
+	consumerName string = "product_order_created_consumer"
 )
 
 type OrderCreatedConsumerRepository interface {
-	GetProcessedEventByAggregateIDAndEventName(ctx context.Context, arg repository.GetProcessedEventByAggregateIDAndEventNameParams) (repository.ProcessedEvent, error)
-	UpdateStockBatch(ctx context.Context, event *events.OrderCreatedEvent, createOutboxEventOnFailure bool, outboxEventName string, outboxEventPayload []byte) (int64, error)
+	UpdateStockBatch(ctx context.Context, tx pgx.Tx, event *events.OrderCreatedEvent, outboxEventName string, outboxEventPayload []byte) (int64, error)
 }
 
 type OrderCreatedConsumer struct {
 	logger      logs.Logger
-	rabbitmq    *rabbitmq.RabbitMQ
+	subscriber  broker.Subscriber
 	redisClient *redis.Client
+	inbox       *inbox.Inbox
 	repo        OrderCreatedConsumerRepository
 }
 
-func NewOrderCreatedConsumer(logger logs.Logger, rabbitmq *rabbitmq.RabbitMQ, redisClient *redis.Client, repo OrderCreatedConsumerRepository) *OrderCreatedConsumer {
+func NewOrderCreatedConsumer(logger logs.Logger, subscriber broker.Subscriber, redisClient *redis.Client, db inbox.TxBeginner, repo OrderCreatedConsumerRepository) *OrderCreatedConsumer {
 	return &OrderCreatedConsumer{
 		logger:      logger,
-		rabbitmq:    rabbitmq,
+		subscriber:  subscriber,
 		redisClient: redisClient,
+		inbox:       inbox.New(logger, db, consumerName),
 		repo:        repo,
 	}
 }
 
 func (occ *OrderCreatedConsumer) Start(ctx context.Context) error {
-	return occ.rabbitmq.Subscribe(ctx, exchangeName, queueName, consumerName, occ.handleOrderCreatedEvent)
-}
-
-func (occ *OrderCreatedConsumer) handleOrderCreatedEvent(ctx context.Context, d amqp091.Delivery) {
-	orderCreatedEvent, err := occ.unmarshalEvent(d.Body)
+	sub, err := events.Registry.Subscription(events.ProductOrderCreatedQueueName)
 	if err != nil {
-		occ.logger.Error("failed to unmarshal OrderCreatedEvent", "error", err)
-		d.Nack(false, false)
-		return
+		return err
 	}
+	sub.Consumer = consumerName
+	sub.Handler = inbox.HandleKeyed(occ.inbox, orderCreatedKey, occ.handleOrderCreatedEvent)
+	return occ.subscriber.Subscribe(ctx, sub)
+}
 
-	if occ.shouldSkipProcessing(ctx, orderCreatedEvent.OrderID, d) {
-		return
-	}
+// orderCreatedKey deduplicates by order, so a republished payload without an
+// envelope is still recognised.
+func orderCreatedKey(event events.OrderCreatedEvent) string {
+	return event.OrderID
+}
 
+func (occ *OrderCreatedConsumer) handleOrderCreatedEvent(ctx context.Context, tx pgx.Tx, envelope events.Envelope, orderCreatedEvent events.OrderCreatedEvent) error {
 	occ.logger.Debug(
 		"received OrderCreatedEvent",
+		"eventId", envelope.ID,
 		"orderId", orderCreatedEvent.OrderID,
 		"userId", orderCreatedEvent.UserID,
 		"productsCount", len(orderCreatedEvent.Products),
@@ -74,19 +71,25 @@ func (occ *OrderCreatedConsumer) handleOrderCreatedEvent(ctx context.Context, d
 	})
 	if err != nil {
 		occ.logger.Error("failed to marshal StockUpdateFailedEvent", "error", err, "orderId", orderCreatedEvent.OrderID)
-		d.Nack(false, false)
-		return
+		return fmt.Errorf("failed to marshal StockUpdateFailedEvent: %w", err)
 	}
 
-	rowsAffected, err := occ.repo.UpdateStockBatch(ctx, orderCreatedEvent, true, stockUpdateFailedEventName, stockUpdateFailedEventData)
+	rowsAffected, err := occ.repo.UpdateStockBatch(ctx, tx, &orderCreatedEvent, events.StockUpdateFailedEventName, stockUpdateFailedEventData)
 	if err != nil {
 		occ.logger.Error("failed to update stock batch transactionally", "error", err, "orderId", orderCreatedEvent.OrderID)
-		d.Nack(false, true)
-		return
+		return broker.Retryable(fmt.Errorf("failed to update stock batch: %w", err))
 	}
 
-	if !occ.validateStockUpdate(orderCreatedEvent, rowsAffected, d) {
-		return
+	expectedRows := int64(len(orderCreatedEvent.Products))
+	if rowsAffected != expectedRows {
+		occ.logger.Error(
+			"stock update affected unexpected number of rows - some products might not exist or have insufficient stock, publishing stock update failed event",
+			"expected", expectedRows,
+			"actual", rowsAffected,
+			"orderId", orderCreatedEvent.OrderID,
+			"products", orderCreatedEvent.Products,
+		)
+		return nil
 	}
 
 	go occ.invalidateCacheForUpdatedProducts(orderCreatedEvent.Products)
@@ -97,68 +100,7 @@ func (occ *OrderCreatedConsumer) handleOrderCreatedEvent(ctx context.Context, d
 		"productsCount", len(orderCreatedEvent.Products),
 	)
 
-	d.Ack(false)
-}
-
-func (occ *OrderCreatedConsumer) unmarshalEvent(body []byte) (*events.OrderCreatedEvent, error) {
-	var orderCreatedEvent events.OrderCreatedEvent
-	if err := json.Unmarshal(body, &orderCreatedEvent); err != nil {
-		return nil, err
-	}
-	return &orderCreatedEvent, nil
-}
-
-func (occ *OrderCreatedConsumer) shouldSkipProcessing(ctx context.Context, orderID string, d amqp091.Delivery) bool {
-	processed, err := occ.isEventProcessed(ctx, orderID, exchangeName)
-	if err != nil {
-		occ.logger.Error("failed to check if event is already processed", "error", err, "orderId", orderID)
-		d.Nack(false, true)
-		return true
-	}
-	if processed {
-		occ.logger.Info("event already processed, acknowledging without reprocessing", "orderId", orderID)
-		d.Ack(false)
-		return true
-	}
-	return false
-}
-
-func (occ *OrderCreatedConsumer) isEventProcessed(ctx context.Context, aggregateID string, eventName string) (bool, error) {
-	var agreggateUUID pgtype.UUID
-	if err := agreggateUUID.Scan(aggregateID); err != nil {
-		return false, err
-	}
-
-	processedEvent, err := occ.repo.GetProcessedEventByAggregateIDAndEventName(ctx, repository.GetProcessedEventByAggregateIDAndEventNameParams{
-		AggregateID: agreggateUUID,
-		EventName:   eventName,
-	})
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return false, nil
-		} else {
-			return false, err
-		}
-	}
-
-	return processedEvent.ID.Valid, nil
-}
-
-func (occ *OrderCreatedConsumer) validateStockUpdate(event *events.OrderCreatedEvent, rowsAffected int64, d amqp091.Delivery) bool {
-	expectedRows := int64(len(event.Products))
-	if rowsAffected != expectedRows {
-		occ.logger.Error(
-			"stock update affected unexpected number of rows - some products might not exist or have insufficient stock",
-			"expected", expectedRows,
-			"actual", rowsAffected,
-			"orderId", event.OrderID,
-			"products", event.Products,
-		)
-		d.Nack(false, false)
-		return false
-	}
-
-	return true
+	return nil
 }
 
 func (occ *OrderCreatedConsumer) invalidateCacheForUpdatedProducts(products []events.OrderItem) {
@@ -3,8 +3,11 @@ package category
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
+	"github.com/redis/go-redis/v9"
 	product_categoriesv1 "github.com/sonuudigital/microservices/gen/product-categories/v1"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -32,16 +35,24 @@ func (s *GRPCServer) GetProductCategories(ctx context.Context, emptypb *emptypb.
 func (s *GRPCServer) getProductCategoriesFromCache(ctx context.Context) ([]*product_categoriesv1.ProductCategory, error) {
 	cachedData, err := s.redisClient.Get(ctx, allProductCategoriesCacheKey).Result()
 	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			metrics.CacheMiss(productCategoriesCacheName)
+		} else {
+			metrics.CacheError(productCategoriesCacheName)
+		}
 		s.logger.Error("failed to get product categories from cache", "error", err)
 		return nil, err
 	}
 
 	productsCategoriesBd := make([]*product_categoriesv1.ProductCategory, 0)
 	if err := json.Unmarshal([]byte(cachedData), &productsCategoriesBd); err != nil {
+		metrics.CacheError(productCategoriesCacheName)
 		s.logger.Error("failed to unmarshal cached product categories", "error", err)
 		return nil, err
 	}
 
+	metrics.CacheHit(productCategoriesCacheName)
+
 	return productsCategoriesBd, nil
 }
 
@@ -13,6 +13,7 @@ import (
 
 const (
 	allProductCategoriesCacheKey = "product_categories:all"
+	productCategoriesCacheName   = "product_categories"
 	cacheExpirationTime          = 1 * time.Hour
 	cacheContextTimeout          = 2 * time.Second
 )
@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -20,9 +21,15 @@ func (s *GRPCServer) GetProduct(ctx context.Context, req *productv1.GetProductRe
 	if err == nil && len(cachedData) > 0 {
 		product, err := mapToProduct(cachedData)
 		if err == nil {
+			metrics.CacheHit(productCacheName)
 			return product, nil
 		}
 	}
+	if err != nil {
+		metrics.CacheError(productCacheName)
+	} else {
+		metrics.CacheMiss(productCacheName)
+	}
 
 	var uid pgtype.UUID
 	if err := uid.Scan(req.Id); err != nil {
@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/redis/go-redis/v9"
 	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -41,6 +42,7 @@ func (s *GRPCServer) getProductsFromCache(ctx context.Context, ids []string) ([]
 
 	cmds, err := pipe.Exec(ctx)
 	if err != nil {
+		metrics.CacheError(productCacheName)
 		return products, ids
 	}
 
@@ -49,9 +51,11 @@ func (s *GRPCServer) getProductsFromCache(ctx context.Context, ids []string) ([]
 		if err == nil && len(data) > 0 {
 			product, err := mapToProduct(data)
 			if err == nil {
+				metrics.CacheHit(productCacheName)
 				products = append(products, product)
 			}
 		} else {
+			metrics.CacheMiss(productCacheName)
 			missedIDs = append(missedIDs, ids[i])
 		}
 	}
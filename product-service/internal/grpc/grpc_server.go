@@ -14,6 +14,7 @@ import (
 
 const (
 	productCachePrefix  = "product:"
+	productCacheName    = "product"
 	cacheExpirationTime = 10 * time.Minute
 	cacheContextTimeout = 2 * time.Second
 )
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
+	"github.com/sonuudigital/microservices/product-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultStreamBatchSize = 500
+	maxStreamBatchSize     = 5000
+)
+
+// StreamProducts sends every product ordered by ID, reading them from the
+// database in batches of batch_size. With updated_since only products created
+// or updated at or after that time are sent. Only operators may stream the
+// catalogue, with an admin token.
+func (s *GRPCServer) StreamProducts(req *productv1.StreamProductsRequest, stream productv1.ProductService_StreamProductsServer) error {
+	ctx := stream.Context()
+
+	operator, err := auth.RequireOperator(ctx)
+	if err != nil {
+		return err
+	}
+
+	batchSize := req.BatchSize
+	if batchSize < 0 || batchSize > maxStreamBatchSize {
+		return status.Errorf(codes.InvalidArgument, "batch size must be between 1 and %d", maxStreamBatchSize)
+	}
+	if batchSize == 0 {
+		batchSize = defaultStreamBatchSize
+	}
+
+	params := repository.ListProductsAfterIDParams{BatchSize: batchSize}
+	if req.UpdatedSince != nil {
+		params.UpdatedSince = pgtype.Timestamptz{Time: req.UpdatedSince.AsTime(), Valid: true}
+	}
+
+	var sent int
+	for {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		products, err := s.queries.ListProductsAfterID(ctx, params)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to list products: %v", err)
+		}
+
+		for _, p := range products {
+			if err := stream.Send(toGRPCProduct(p)); err != nil {
+				return err
+			}
+		}
+		sent += len(products)
+
+		if len(products) < int(batchSize) {
+			break
+		}
+		params.AfterID = products[len(products)-1].ID
+	}
+
+	s.logger.Info("products streamed", "count", sent, "incremental", req.UpdatedSince != nil, "operator", operator)
+	return nil
+}
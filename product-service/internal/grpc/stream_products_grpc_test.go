@@ -0,0 +1,133 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/jackc/pgx/v5/pgtype"
+	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
+	grpc_server "github.com/sonuudigital/microservices/product-service/internal/grpc"
+	product_service_mock "github.com/sonuudigital/microservices/product-service/internal/mock"
+	"github.com/sonuudigital/microservices/product-service/internal/repository"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeProductStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*productv1.Product
+}
+
+func (f *fakeProductStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeProductStream) Send(p *productv1.Product) error {
+	f.sent = append(f.sent, p)
+	return nil
+}
+
+func newTestProduct(t *testing.T, id string) repository.Product {
+	t.Helper()
+	var uuid pgtype.UUID
+	assert.NoError(t, uuid.Scan(id))
+	return repository.Product{ID: uuid, Name: "Product " + id[len(id)-1:]}
+}
+
+func TestStreamProducts(t *testing.T) {
+	operatorCtx := auth.ContextWithOperator(context.Background(), "alice")
+
+	t.Run("Streams All Batches", func(t *testing.T) {
+		mockQuerier := new(product_service_mock.MockQuerier)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewServer(logs.NewSlogLogger(), mockQuerier, redisClient)
+
+		first := []repository.Product{
+			newTestProduct(t, "00000000-0000-0000-0000-000000000001"),
+			newTestProduct(t, "00000000-0000-0000-0000-000000000002"),
+		}
+		second := []repository.Product{
+			newTestProduct(t, "00000000-0000-0000-0000-000000000003"),
+		}
+		mockQuerier.On("ListProductsAfterID", mock.Anything, repository.ListProductsAfterIDParams{BatchSize: 2}).
+			Return(first, nil).Once()
+		mockQuerier.On("ListProductsAfterID", mock.Anything, repository.ListProductsAfterIDParams{AfterID: first[1].ID, BatchSize: 2}).
+			Return(second, nil).Once()
+
+		stream := &fakeProductStream{ctx: operatorCtx}
+		err := server.StreamProducts(&productv1.StreamProductsRequest{BatchSize: 2}, stream)
+
+		assert.NoError(t, err)
+		assert.Len(t, stream.sent, 3)
+		assert.Equal(t, "00000000-0000-0000-0000-000000000003", stream.sent[2].Id)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("Updated Since", func(t *testing.T) {
+		mockQuerier := new(product_service_mock.MockQuerier)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewServer(logs.NewSlogLogger(), mockQuerier, redisClient)
+
+		since := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+		mockQuerier.On("ListProductsAfterID", mock.Anything, repository.ListProductsAfterIDParams{
+			UpdatedSince: pgtype.Timestamptz{Time: since, Valid: true},
+			BatchSize:    500,
+		}).Return([]repository.Product{}, nil).Once()
+
+		stream := &fakeProductStream{ctx: operatorCtx}
+		err := server.StreamProducts(&productv1.StreamProductsRequest{UpdatedSince: timestamppb.New(since)}, stream)
+
+		assert.NoError(t, err)
+		assert.Empty(t, stream.sent)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("Not An Operator", func(t *testing.T) {
+		mockQuerier := new(product_service_mock.MockQuerier)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewServer(logs.NewSlogLogger(), mockQuerier, redisClient)
+		userCtx := auth.ContextWithPrincipal(context.Background(), auth.Principal{UserID: "user-1"})
+
+		err := server.StreamProducts(&productv1.StreamProductsRequest{}, &fakeProductStream{ctx: userCtx})
+
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		mockQuerier.AssertNotCalled(t, "ListProductsAfterID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid Batch Size", func(t *testing.T) {
+		mockQuerier := new(product_service_mock.MockQuerier)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewServer(logs.NewSlogLogger(), mockQuerier, redisClient)
+
+		err := server.StreamProducts(&productv1.StreamProductsRequest{BatchSize: -1}, &fakeProductStream{ctx: operatorCtx})
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		mockQuerier.AssertNotCalled(t, "ListProductsAfterID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mockQuerier := new(product_service_mock.MockQuerier)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewServer(logs.NewSlogLogger(), mockQuerier, redisClient)
+		mockQuerier.On("ListProductsAfterID", mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		err := server.StreamProducts(&productv1.StreamProductsRequest{}, &fakeProductStream{ctx: operatorCtx})
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.Internal, st.Code())
+		mockQuerier.AssertExpectations(t)
+	})
+}
@@ -73,6 +73,11 @@ func (m *MockQuerier) GetProductCategories(ctx context.Context) ([]repository.Pr
 	return nil, args.Error(1)
 }
 
+func (m *MockQuerier) GetProductCategoryName(ctx context.Context, id pgtype.UUID) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockQuerier) CreateProductCategory(ctx context.Context, arg repository.CreateProductCategoryParams) (repository.ProductCategory, error) {
 	args := m.Called(ctx, arg)
 	if p, ok := args.Get(0).(repository.ProductCategory); ok {
@@ -96,33 +101,59 @@ func (m *MockQuerier) UpdateStockBatch(ctx context.Context, updatesJSON []byte)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockQuerier) CreateProcessedEvent(ctx context.Context, arg repository.CreateProcessedEventParams) error {
+func (m *MockQuerier) CreateOutboxEvent(ctx context.Context, arg repository.CreateOutboxEventParams) error {
 	args := m.Called(ctx, arg)
 	return args.Error(0)
 }
 
-func (m *MockQuerier) CreateOutboxEvent(ctx context.Context, arg repository.CreateOutboxEventParams) error {
+func (m *MockQuerier) ClaimUnpublishedOutboxEvents(ctx context.Context, arg repository.ClaimUnpublishedOutboxEventsParams) ([]repository.ClaimUnpublishedOutboxEventsRow, error) {
 	args := m.Called(ctx, arg)
-	return args.Error(0)
+	if p, ok := args.Get(0).([]repository.ClaimUnpublishedOutboxEventsRow); ok {
+		return p, args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
-func (m *MockQuerier) GetUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]repository.OutboxEvent, error) {
-	args := m.Called(ctx, limit)
-	if p, ok := args.Get(0).([]repository.OutboxEvent); ok {
+func (m *MockQuerier) ListProductsAfterID(ctx context.Context, arg repository.ListProductsAfterIDParams) ([]repository.Product, error) {
+	args := m.Called(ctx, arg)
+	if p, ok := args.Get(0).([]repository.Product); ok {
 		return p, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockQuerier) UpdateOutboxEventStatus(ctx context.Context, id pgtype.UUID) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
+func (m *MockQuerier) ListOutboxEventsByStatus(ctx context.Context, arg repository.ListOutboxEventsByStatusParams) ([]repository.ListOutboxEventsByStatusRow, error) {
+	args := m.Called(ctx, arg)
+	if p, ok := args.Get(0).([]repository.ListOutboxEventsByStatusRow); ok {
+		return p, args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
-func (m *MockQuerier) GetProcessedEventByAggregateIDAndEventName(ctx context.Context, arg repository.GetProcessedEventByAggregateIDAndEventNameParams) (repository.ProcessedEvent, error) {
-	args := m.Called(ctx, arg)
-	if p, ok := args.Get(0).(repository.ProcessedEvent); ok {
+func (m *MockQuerier) RequeueOutboxEvent(ctx context.Context, id pgtype.UUID) (repository.RequeueOutboxEventRow, error) {
+	args := m.Called(ctx, id)
+	if p, ok := args.Get(0).(repository.RequeueOutboxEventRow); ok {
 		return p, args.Error(1)
 	}
-	return repository.ProcessedEvent{}, args.Error(1)
+	return repository.RequeueOutboxEventRow{}, args.Error(1)
+}
+
+func (m *MockQuerier) ArchiveOutboxEvents(ctx context.Context, arg repository.ArchiveOutboxEventsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) GetOutboxBacklog(ctx context.Context) (repository.GetOutboxBacklogRow, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.GetOutboxBacklogRow), args.Error(1)
+}
+
+func (m *MockQuerier) RecordOutboxEventFailure(ctx context.Context, arg repository.RecordOutboxEventFailureParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) UpdateOutboxEventStatus(ctx context.Context, id pgtype.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
 }
@@ -16,6 +16,7 @@ type OutboxEventStatus string
 const (
 	OutboxEventStatusUNPUBLISHED OutboxEventStatus = "UNPUBLISHED"
 	OutboxEventStatusPUBLISHED   OutboxEventStatus = "PUBLISHED"
+	OutboxEventStatusFAILED      OutboxEventStatus = "FAILED"
 )
 
 func (e *OutboxEventStatus) Scan(src interface{}) error {
@@ -54,13 +55,31 @@ func (ns NullOutboxEventStatus) Value() (driver.Value, error) {
 }
 
 type OutboxEvent struct {
-	ID          pgtype.UUID        `json:"id"`
-	AggregateID pgtype.UUID        `json:"aggregateId"`
-	EventName   string             `json:"eventName"`
-	Payload     []byte             `json:"payload"`
-	Status      OutboxEventStatus  `json:"status"`
-	CreatedAt   pgtype.Timestamptz `json:"createdAt"`
-	PublishedAt pgtype.Timestamptz `json:"publishedAt"`
+	ID            pgtype.UUID        `json:"id"`
+	AggregateID   pgtype.UUID        `json:"aggregateId"`
+	EventName     string             `json:"eventName"`
+	Payload       []byte             `json:"payload"`
+	Status        OutboxEventStatus  `json:"status"`
+	CreatedAt     pgtype.Timestamptz `json:"createdAt"`
+	PublishedAt   pgtype.Timestamptz `json:"publishedAt"`
+	TraceContext  []byte             `json:"traceContext"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"lastError"`
+	NextAttemptAt pgtype.Timestamptz `json:"nextAttemptAt"`
+}
+
+type OutboxEventsArchive struct {
+	ID           pgtype.UUID        `json:"id"`
+	AggregateID  pgtype.UUID        `json:"aggregateId"`
+	EventName    string             `json:"eventName"`
+	Payload      []byte             `json:"payload"`
+	Status       OutboxEventStatus  `json:"status"`
+	TraceContext []byte             `json:"traceContext"`
+	Attempts     int32              `json:"attempts"`
+	LastError    pgtype.Text        `json:"lastError"`
+	CreatedAt    pgtype.Timestamptz `json:"createdAt"`
+	PublishedAt  pgtype.Timestamptz `json:"publishedAt"`
+	ArchivedAt   pgtype.Timestamptz `json:"archivedAt"`
 }
 
 type ProcessedEvent struct {
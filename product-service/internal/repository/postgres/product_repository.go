@@ -2,13 +2,15 @@ package postgres
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sonuudigital/microservices/product-service/internal/repository"
 	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/tracing"
 )
 
 type ProductRepository struct {
@@ -30,12 +32,12 @@ func (r *ProductRepository) CreateProduct(ctx context.Context, arg repository.Cr
 			return repository.Product{}, err
 		}
 
-		payload, err := r.productToPayload(product)
+		event, err := r.productToEvent(ctx, q, product)
 		if err != nil {
 			return repository.Product{}, err
 		}
 
-		if err := r.addOutboxEvent(ctx, q, product.ID, events.ProductCreatedEventName, payload); err != nil {
+		if err := r.addOutboxEvent(ctx, q, product.ID, events.ProductCreatedEventName, events.ProductCreatedEventType, event); err != nil {
 			return repository.Product{}, err
 		}
 
@@ -50,12 +52,12 @@ func (r *ProductRepository) UpdateProduct(ctx context.Context, arg repository.Up
 			return repository.Product{}, err
 		}
 
-		payload, err := r.productToPayload(product)
+		event, err := r.productToEvent(ctx, q, product)
 		if err != nil {
 			return repository.Product{}, err
 		}
 
-		if err := r.addOutboxEvent(ctx, q, product.ID, events.ProductUpdatedEventName, payload); err != nil {
+		if err := r.addOutboxEvent(ctx, q, product.ID, events.ProductUpdatedEventName, events.ProductUpdatedEventType, event); err != nil {
 			return repository.Product{}, err
 		}
 
@@ -69,14 +71,9 @@ func (r *ProductRepository) DeleteProduct(ctx context.Context, id pgtype.UUID) e
 			return err
 		}
 
-		payload, err := json.Marshal(events.Product{
+		return r.addOutboxEvent(ctx, q, id, events.ProductDeletedEventName, events.ProductDeletedEventType, events.Product{
 			ID: id.String(),
 		})
-		if err != nil {
-			return fmt.Errorf("failed to marshal delete product payload: %w", err)
-		}
-
-		return r.addOutboxEvent(ctx, q, id, events.ProductDeletedEventName, payload)
 	})
 }
 
@@ -117,32 +114,48 @@ func (r *ProductRepository) withTx(ctx context.Context, fn func(q *repository.Qu
 	return tx.Commit(ctx)
 }
 
-func (r *ProductRepository) addOutboxEvent(ctx context.Context, q *repository.Queries, aggregateID pgtype.UUID, eventName string, payload []byte) error {
+func (r *ProductRepository) addOutboxEvent(ctx context.Context, q *repository.Queries, aggregateID pgtype.UUID, eventName, eventType string, event events.Product) error {
+	payload, err := events.Wrap(repository.EventSource, eventType, aggregateID.String(), event)
+	if err != nil {
+		return err
+	}
+
 	return q.CreateOutboxEvent(ctx, repository.CreateOutboxEventParams{
-		AggregateID: aggregateID,
-		EventName:   eventName,
-		Payload:     payload,
+		AggregateID:  aggregateID,
+		EventName:    eventName,
+		Payload:      payload,
+		TraceContext: tracing.MarshalTraceContext(ctx),
 	})
 }
 
-func (r *ProductRepository) productToPayload(p repository.Product) ([]byte, error) {
+// productToEvent builds the product event, with the category name so the
+// search index can match categories by name without calling back.
+func (r *ProductRepository) productToEvent(ctx context.Context, q *repository.Queries, p repository.Product) (events.Product, error) {
 	priceJSON, err := p.Price.MarshalJSON()
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal price: %w", err)
+		return events.Product{}, fmt.Errorf("failed to marshal price: %w", err)
 	}
 	priceStr := string(priceJSON)
 	if len(priceStr) >= 2 && priceStr[0] == '"' && priceStr[len(priceStr)-1] == '"' {
 		priceStr = priceStr[1 : len(priceStr)-1]
 	}
 
-	event := events.Product{
+	var categoryName string
+	if p.CategoryID.Valid {
+		categoryName, err = q.GetProductCategoryName(ctx, p.CategoryID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return events.Product{}, fmt.Errorf("failed to get category name: %w", err)
+		}
+	}
+
+	return events.Product{
 		ID:            p.ID.String(),
 		CategoryID:    p.CategoryID.String(),
+		CategoryName:  categoryName,
 		Name:          p.Name,
 		Description:   p.Description.String,
 		Price:         priceStr,
 		StockQuantity: p.StockQuantity,
-	}
-
-	return json.Marshal(event)
+		CreatedAt:     p.CreatedAt.Time,
+	}, nil
 }
@@ -4,73 +4,74 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/tracing"
 )
 
 const (
-	eventName = "order_created_exchange"
+	// EventSource is the CloudEvents source of the events product-service
+	// writes to its outbox.
+	EventSource = "/product-service"
 )
 
-type PostgreSQLOrderCreatedConsumerRepository struct {
-	*Queries
-	db *pgxpool.Pool
-}
+type PostgreSQLOrderCreatedConsumerRepository struct{}
 
-func NewPostgreSQLOrderCreatedConsumerRepository(db *pgxpool.Pool) *PostgreSQLOrderCreatedConsumerRepository {
-	return &PostgreSQLOrderCreatedConsumerRepository{
-		db:      db,
-		Queries: New(db),
-	}
+func NewPostgreSQLOrderCreatedConsumerRepository() *PostgreSQLOrderCreatedConsumerRepository {
+	return &PostgreSQLOrderCreatedConsumerRepository{}
 }
 
-func (r *PostgreSQLOrderCreatedConsumerRepository) GetProcessedEventByAggregateIDAndEventName(ctx context.Context, arg GetProcessedEventByAggregateIDAndEventNameParams) (ProcessedEvent, error) {
-	return r.Queries.GetProcessedEventByAggregateIDAndEventName(ctx, arg)
-}
-
-func (r *PostgreSQLOrderCreatedConsumerRepository) UpdateStockBatch(ctx context.Context, event *events.OrderCreatedEvent, createOutboxEventOnFailure bool, outboxEventName string, outboxEventPayload []byte) (int64, error) {
-	tx, err := r.db.Begin(ctx)
+// UpdateStockBatch reserves the stock of every product in the order within
+// tx. If any product is missing or short of stock, the stock changes are
+// rolled back to a savepoint and a stock update failed event is written to
+// the outbox instead. It returns the number of products that were updated.
+func (r *PostgreSQLOrderCreatedConsumerRepository) UpdateStockBatch(ctx context.Context, tx pgx.Tx, event *events.OrderCreatedEvent, outboxEventName string, outboxEventPayload []byte) (int64, error) {
+	encodedOrderItems, err := marshalOrderItems(event)
 	if err != nil {
 		return 0, err
 	}
-	defer tx.Rollback(ctx)
 
-	q := r.WithTx(tx)
-
-	encodedOrderItems, err := marshalOrderItems(event)
+	savepoint, err := tx.Begin(ctx)
 	if err != nil {
 		return 0, err
 	}
+	defer savepoint.Rollback(ctx)
 
-	rowsAffected, err := q.UpdateStockBatch(ctx, encodedOrderItems)
+	rowsAffected, err := New(savepoint).UpdateStockBatch(ctx, encodedOrderItems)
 	if err != nil {
 		return 0, err
 	}
 
+	expectedRows := int64(len(event.Products))
+	if rowsAffected == expectedRows {
+		return rowsAffected, savepoint.Commit(ctx)
+	}
+
+	if err := savepoint.Rollback(ctx); err != nil {
+		return 0, err
+	}
+
 	orderUUID, err := parseOrderID(event.OrderID)
 	if err != nil {
 		return 0, err
 	}
-	if err = q.CreateProcessedEvent(ctx, CreateProcessedEventParams{
-		AggregateID: orderUUID,
-		EventName:   eventName,
-	}); err != nil {
+
+	payload, err := events.Wrap(EventSource, events.StockUpdateFailedEventType, event.OrderID, json.RawMessage(outboxEventPayload))
+	if err != nil {
 		return 0, err
 	}
 
-	expectedRows := int64(len(event.Products))
-	if rowsAffected != expectedRows && createOutboxEventOnFailure {
-		if err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
-			AggregateID: orderUUID,
-			EventName:   outboxEventName,
-			Payload:     outboxEventPayload,
-		}); err != nil {
-			return 0, err
-		}
+	if err = New(tx).CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateID:  orderUUID,
+		EventName:    outboxEventName,
+		Payload:      payload,
+		TraceContext: tracing.MarshalTraceContext(ctx),
+	}); err != nil {
+		return 0, err
 	}
 
-	return rowsAffected, tx.Commit(ctx)
+	return rowsAffected, nil
 }
 
 func marshalOrderItems(event *events.OrderCreatedEvent) ([]byte, error) {
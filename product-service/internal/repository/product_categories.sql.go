@@ -80,6 +80,18 @@ func (q *Queries) GetProductCategories(ctx context.Context) ([]ProductCategory,
 	return items, nil
 }
 
+const getProductCategoryName = `-- name: GetProductCategoryName :one
+SELECT name FROM product_categories
+WHERE id = $1
+`
+
+func (q *Queries) GetProductCategoryName(ctx context.Context, id pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getProductCategoryName, id)
+	var name string
+	err := row.Scan(&name)
+	return name, err
+}
+
 const updateProductCategory = `-- name: UpdateProductCategory :exec
 UPDATE product_categories
 SET
@@ -154,6 +154,49 @@ func (q *Queries) GetProductsByIDs(ctx context.Context, productIds []pgtype.UUID
 	return items, nil
 }
 
+const listProductsAfterID = `-- name: ListProductsAfterID :many
+SELECT id, category_id, name, description, price, stock_quantity, created_at, updated_at FROM products
+WHERE ($1::uuid IS NULL OR id > $1::uuid)
+  AND ($2::timestamptz IS NULL OR COALESCE(updated_at, created_at) >= $2::timestamptz)
+ORDER BY id
+LIMIT $3::INTEGER
+`
+
+type ListProductsAfterIDParams struct {
+	AfterID      pgtype.UUID        `json:"afterId"`
+	UpdatedSince pgtype.Timestamptz `json:"updatedSince"`
+	BatchSize    int32              `json:"batchSize"`
+}
+
+func (q *Queries) ListProductsAfterID(ctx context.Context, arg ListProductsAfterIDParams) ([]Product, error) {
+	rows, err := q.db.Query(ctx, listProductsAfterID, arg.AfterID, arg.UpdatedSince, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Name,
+			&i.Description,
+			&i.Price,
+			&i.StockQuantity,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProductsPaginated = `-- name: ListProductsPaginated :many
 SELECT id, category_id, name, description, price, stock_quantity, created_at, updated_at FROM products
 ORDER BY name
@@ -11,19 +11,26 @@ import (
 )
 
 type Querier interface {
+	ArchiveOutboxEvents(ctx context.Context, arg ArchiveOutboxEventsParams) (int64, error)
+	ClaimUnpublishedOutboxEvents(ctx context.Context, arg ClaimUnpublishedOutboxEventsParams) ([]ClaimUnpublishedOutboxEventsRow, error)
 	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error
-	CreateProcessedEvent(ctx context.Context, arg CreateProcessedEventParams) error
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
 	CreateProductCategory(ctx context.Context, arg CreateProductCategoryParams) (ProductCategory, error)
 	DeleteProduct(ctx context.Context, id pgtype.UUID) error
 	DeleteProductCategory(ctx context.Context, id pgtype.UUID) error
-	GetProcessedEventByAggregateIDAndEventName(ctx context.Context, arg GetProcessedEventByAggregateIDAndEventNameParams) (ProcessedEvent, error)
+	GetOutboxBacklog(ctx context.Context) (GetOutboxBacklogRow, error)
 	GetProduct(ctx context.Context, id pgtype.UUID) (Product, error)
 	GetProductCategories(ctx context.Context) ([]ProductCategory, error)
+	GetProductCategoryName(ctx context.Context, id pgtype.UUID) (string, error)
 	GetProductsByCategoryID(ctx context.Context, categoryID pgtype.UUID) ([]Product, error)
 	GetProductsByIDs(ctx context.Context, productIds []pgtype.UUID) ([]Product, error)
-	GetUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error)
+	ListOutboxEventsByStatus(ctx context.Context, arg ListOutboxEventsByStatusParams) ([]ListOutboxEventsByStatusRow, error)
+	ListProductsAfterID(ctx context.Context, arg ListProductsAfterIDParams) ([]Product, error)
 	ListProductsPaginated(ctx context.Context, arg ListProductsPaginatedParams) ([]Product, error)
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error
+	// A pending event is only requeued while no relayer holds its lease, so a
+	// publish in flight is not reset underneath the relayer.
+	RequeueOutboxEvent(ctx context.Context, id pgtype.UUID) (RequeueOutboxEventRow, error)
 	UpdateOutboxEventStatus(ctx context.Context, id pgtype.UUID) error
 	UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error)
 	UpdateProductCategory(ctx context.Context, arg UpdateProductCategoryParams) error
@@ -15,14 +15,18 @@ import (
 	productHandler "github.com/sonuudigital/microservices/search-service/internal/handlers/product"
 	"github.com/sonuudigital/microservices/search-service/internal/opensearch"
 	"github.com/sonuudigital/microservices/search-service/internal/router"
+	"github.com/sonuudigital/microservices/shared/auth"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"github.com/sonuudigital/microservices/shared/rabbitmq"
+	"github.com/sonuudigital/microservices/shared/tracing"
 	"github.com/sonuudigital/microservices/shared/web"
+	"github.com/sonuudigital/microservices/shared/web/health"
 	"golang.org/x/sync/errgroup"
 )
 
 func main() {
-	logger := logs.NewSlogLogger()
+	logger := logs.NewSlogLogger(logs.WithServiceName("search-service"))
 	err := godotenv.Load()
 	if err == nil {
 		logger.Info("loaded environment variables from .env file")
@@ -30,7 +34,31 @@ func main() {
 		logger.Info("no .env file found, using environment variables")
 	}
 
-	rabbitmqClient, err := initializeRabbitMQClient(logger)
+	if len(os.Args) > 1 && os.Args[1] == reindexCommand {
+		if err := runReindex(logger, os.Args[2:]); err != nil {
+			logger.Error("failed to reindex products", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "search-service", logger)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	adminAuth, err := auth.LoadAdminAuthFromEnv(logger)
+	if err != nil {
+		logger.Error("failed to load admin auth", "error", err)
+		os.Exit(1)
+	}
+
+	metricsServer := metrics.StartServer(logger, metrics.WithAdminAuth(adminAuth))
+	defer metricsServer.Close()
+
+	rabbitmqClient, err := rabbitmq.NewServiceClientFromEnv(logger, "search-service")
 	if err != nil {
 		logger.Error("failed to initialize RabbitMQ client", "error", err)
 		os.Exit(1)
@@ -49,19 +77,31 @@ func main() {
 		os.Exit(1)
 	}
 
+	if _, err := opensearch.MigrateProductIndex(context.Background(), logger, opensearchClient, opensearchProductIndex); err != nil {
+		logger.Error("failed to migrate product index", "error", err)
+		os.Exit(1)
+	}
+
 	productHandler, err := productHandler.NewProductHandler(logger, opensearchClient, opensearchProductIndex)
 	if err != nil {
 		logger.Error("failed to create product handler", "error", err)
 		os.Exit(1)
 	}
 
-	router, err := router.New(logger, productHandler)
+	readinessChecker := health.NewChecker(logger, health.DefaultCheckInterval,
+		health.NewCheck("opensearch", health.DefaultCheckTimeout, opensearchClient.Ping),
+		health.NewCheck("rabbitmq", health.DefaultCheckTimeout, func(context.Context) error {
+			return rabbitmqClient.Ping()
+		}),
+	)
+
+	router, err := router.New(logger, productHandler, health.ReadinessHandler(readinessChecker))
 	if err != nil {
 		logger.Error("failed to create router", "error", err)
 		os.Exit(1)
 	}
 
-	if err := startServices(logger, rabbitmqClient, opensearchClient, opensearchProductIndex, router); err != nil && !errors.Is(err, context.Canceled) {
+	if err := startServices(logger, rabbitmqClient, opensearchClient, opensearchProductIndex, router, readinessChecker); err != nil && !errors.Is(err, context.Canceled) {
 		logger.Error("application error", "error", err)
 		os.Exit(1)
 	}
@@ -69,7 +109,7 @@ func main() {
 	logger.Info("application shut down gracefully")
 }
 
-func startServices(logger *logs.SlogLogger, rabbitmqClient *rabbitmq.Client, opensearchClient *opensearch.Client, opensearchProductIndex string, router *router.Router) error {
+func startServices(logger *logs.SlogLogger, rabbitmqClient *rabbitmq.Client, opensearchClient *opensearch.Client, opensearchProductIndex string, router *router.Router, readinessChecker *health.Checker) error {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
@@ -83,6 +123,11 @@ func startServices(logger *logs.SlogLogger, rabbitmqClient *rabbitmq.Client, ope
 		return startHTTPServer(gCtx, logger, router)
 	})
 
+	g.Go(func() error {
+		readinessChecker.Start(gCtx)
+		return nil
+	})
+
 	return g.Wait()
 }
 
@@ -93,7 +138,7 @@ func startProductEventConsumer(ctx context.Context, logger logs.Logger, rabbitmq
 }
 
 func startHTTPServer(ctx context.Context, logger logs.Logger, handler http.Handler) error {
-	srv, err := web.InitializeServer(os.Getenv("PORT"), handler, logger)
+	srv, err := web.InitializeServer(os.Getenv("PORT"), tracing.HTTPHandler(handler, "search-service"), logger)
 	if err != nil {
 		return err
 	}
@@ -116,20 +161,6 @@ func startHTTPServer(ctx context.Context, logger logs.Logger, handler http.Handl
 	return nil
 }
 
-func initializeRabbitMQClient(logger logs.Logger) (*rabbitmq.Client, error) {
-	rabbitmqURL := os.Getenv("RABBITMQ_URL")
-	if rabbitmqURL == "" {
-		return nil, fmt.Errorf("RABBITMQ_URL is not set")
-	}
-
-	client, err := rabbitmq.NewClient(logger, rabbitmqURL)
-	if err != nil {
-		return nil, err
-	}
-
-	return client, nil
-}
-
 func initializeOpenSearchClient(logger logs.Logger) (*opensearch.Client, error) {
 	opensearchAddresses := []string{os.Getenv("OPENSEARCH_ADDRESS")}
 	opensearchUsername := os.Getenv("OPENSEARCH_USERNAME")
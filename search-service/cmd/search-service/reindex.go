@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	product_categoriesv1 "github.com/sonuudigital/microservices/gen/product-categories/v1"
+	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
+	"github.com/sonuudigital/microservices/search-service/internal/opensearch"
+	"github.com/sonuudigital/microservices/search-service/internal/reindex"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/tracing"
+	"github.com/sonuudigital/microservices/shared/web"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const reindexCommand = "reindex"
+
+// runReindex rebuilds the product index from a product-service snapshot and
+// swaps the OPENSEARCH_PRODUCT_INDEX alias to it. The snapshot is read with
+// the operator's admin token from ADMIN_TOKEN:
+//
+//	ADMIN_TOKEN=... search-service reindex [-batch-size 500] [-keep-old]
+func runReindex(logger logs.Logger, args []string) error {
+	fs := flag.NewFlagSet(reindexCommand, flag.ExitOnError)
+	batchSize := fs.Int("batch-size", reindex.DefaultBatchSize, "number of products per bulk request and per database read")
+	keepOld := fs.Bool("keep-old", false, "keep the previous index instead of deleting it after the alias swap")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize < 1 {
+		return fmt.Errorf("invalid -batch-size: %d", *batchSize)
+	}
+
+	alias := os.Getenv("OPENSEARCH_PRODUCT_INDEX")
+	if alias == "" {
+		return fmt.Errorf("OPENSEARCH_PRODUCT_INDEX is not set")
+	}
+
+	productServiceURL := os.Getenv("PRODUCT_SERVICE_GRPC_URL")
+	if productServiceURL == "" {
+		return fmt.Errorf("PRODUCT_SERVICE_GRPC_URL is not set")
+	}
+
+	// StreamProducts is an operator call.
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		return fmt.Errorf("ADMIN_TOKEN is not set")
+	}
+
+	opensearchClient, err := initializeOpenSearchClient(logger)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig, err := web.LoadTLSConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load TLS config: %w", err)
+	}
+
+	creds, err := web.NewGRPCClientCredentials(tlsConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC client credentials: %w", err)
+	}
+
+	conn, err := grpc.NewClient(productServiceURL, creds, tracing.GRPCDialOption())
+	if err != nil {
+		return fmt.Errorf("failed to connect to product-service: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx = metadata.AppendToOutgoingContext(ctx, auth.AdminMetadataKey, adminToken)
+
+	if err := opensearch.PutProductIndexTemplate(ctx, opensearchClient, alias); err != nil {
+		return fmt.Errorf("failed to put product index template: %w", err)
+	}
+
+	source := reindex.NewGRPCProductSource(
+		productv1.NewProductServiceClient(conn),
+		product_categoriesv1.NewProductCategoriesServiceClient(conn),
+		int32(*batchSize),
+	)
+	result, err := reindex.NewReindexer(logger, source, opensearchClient, alias, *batchSize, *keepOld).Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Info(
+		"reindex completed",
+		"alias", alias,
+		"index", result.Index,
+		"indexed", result.Indexed,
+		"caughtUp", result.CaughtUp,
+		"pruned", result.Pruned,
+		"removedIndices", result.RemovedIndices,
+	)
+	return nil
+}
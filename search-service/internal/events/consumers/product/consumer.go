@@ -3,19 +3,22 @@ package product
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
-	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/search-service/internal/opensearch"
+	"github.com/sonuudigital/microservices/shared/broker"
 	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/inbox"
 	"github.com/sonuudigital/microservices/shared/logs"
-	"github.com/sonuudigital/microservices/shared/rabbitmq"
 )
 
-type Subscriber interface {
-	Subscribe(ctx context.Context, opts rabbitmq.SubscribeOptions) error
-}
+const (
+	consumerWorkers  = 8
+	consumerPrefetch = 32
+)
 
 type DocumentStore interface {
 	Index(ctx context.Context, indexName string, documentID string, body []byte) (*opensearchapi.Response, error)
@@ -24,17 +27,19 @@ type DocumentStore interface {
 
 type ProductEventsConsumer struct {
 	logger          logs.Logger
-	subscriber      Subscriber
+	subscriber      broker.Subscriber
 	indexer         DocumentStore
 	opensearchIndex string
+	dedup           *inbox.Deduplicator
 }
 
-func NewProductEventsConsumer(logger logs.Logger, subscriber Subscriber, indexer DocumentStore, index string) *ProductEventsConsumer {
+func NewProductEventsConsumer(logger logs.Logger, subscriber broker.Subscriber, indexer DocumentStore, index string) *ProductEventsConsumer {
 	return &ProductEventsConsumer{
 		logger:          logger,
 		subscriber:      subscriber,
 		indexer:         indexer,
 		opensearchIndex: index,
+		dedup:           inbox.NewDeduplicator(logger, events.SearchProductEventsQueueName, inbox.DefaultDedupWindow),
 	}
 }
 
@@ -42,42 +47,52 @@ func (p *ProductEventsConsumer) Start(ctx context.Context) error {
 	unixTime := time.Now().Unix()
 	unixTimeStr := strconv.Itoa(int(unixTime))
 
-	return p.subscriber.Subscribe(ctx, rabbitmq.SubscribeOptions{
-		Exchange:     events.ProductExchangeName,
-		ExchangeType: rabbitmq.ExchangeTopic,
-		QueueName:    "search_product_events_queue",
-		ConsumerTag:  "search_product_events_indexer_" + unixTimeStr,
-		BindingKey:   events.ProductWildcardRoutingKey,
-		Handler:      p.handleProductCreatedEvent,
-	})
+	sub, err := events.Registry.Subscription(events.SearchProductEventsQueueName)
+	if err != nil {
+		return err
+	}
+	sub.Consumer = "search_product_events_indexer_" + unixTimeStr
+	sub.Handler = p.dedup.Wrap(p.handleProductCreatedEvent)
+	sub.Workers = consumerWorkers
+	sub.Prefetch = consumerPrefetch
+	sub.PartitionKey = productPartitionKey
+	return p.subscriber.Subscribe(ctx, sub)
 }
 
-func (p *ProductEventsConsumer) handleProductCreatedEvent(ctx context.Context, d amqp091.Delivery) {
-	var productEvent events.Product
-	if err := json.Unmarshal(d.Body, &productEvent); err != nil {
+// productPartitionKey keeps the events of one product in order, so that e.g.
+// a product.deleted is not indexed before the preceding product.updated. A
+// retried event is redelivered behind the product's later events, so an
+// indexing failure can leave a stale document until the next update.
+func productPartitionKey(msg broker.Message) string {
+	envelope, product, _ := events.Decode[events.Product](msg.Body)
+	if envelope.Subject != "" {
+		return envelope.Subject
+	}
+	return product.ID
+}
+
+func (p *ProductEventsConsumer) handleProductCreatedEvent(ctx context.Context, msg broker.Message) error {
+	envelope, productEvent, err := events.Decode[events.Product](msg.Body)
+	if err != nil {
 		p.logger.Error("failed to unmarshal product event", "error", err)
-		d.Nack(false, false)
-		return
+		return fmt.Errorf("failed to unmarshal product event: %w", err)
 	}
 
-	p.logger.Info("product event received", "routingKey", d.RoutingKey, "productId", productEvent.ID)
+	p.logger.Info("product event received", "routingKey", msg.RoutingKey, "eventId", envelope.ID, "productId", productEvent.ID)
 
-	body, err := json.Marshal(productEvent)
+	body, err := json.Marshal(opensearch.NewProductDocument(productEvent))
 	if err != nil {
 		p.logger.Error("failed to marshal product event for opensearch", "error", err, "productId", productEvent.ID)
-		d.Nack(false, true)
-		return
+		return fmt.Errorf("failed to marshal product event: %w", err)
 	}
 
-	if d.RoutingKey != events.ProductDeletedRoutingKey {
-		p.indexProduct(ctx, productEvent, body, d)
-	} else {
-		p.deleteProduct(ctx, productEvent, d)
+	if msg.RoutingKey != events.ProductDeletedRoutingKey {
+		return p.indexProduct(ctx, productEvent, body)
 	}
-
+	return p.deleteProduct(ctx, productEvent)
 }
 
-func (p *ProductEventsConsumer) indexProduct(ctx context.Context, productEvent events.Product, body []byte, d amqp091.Delivery) {
+func (p *ProductEventsConsumer) indexProduct(ctx context.Context, productEvent events.Product, body []byte) error {
 	res, err := p.indexer.Index(
 		ctx,
 		p.opensearchIndex,
@@ -86,21 +101,19 @@ func (p *ProductEventsConsumer) indexProduct(ctx context.Context, productEvent e
 	)
 	if err != nil {
 		p.logger.Error("failed to index document in opensearch", "error", err, "productId", productEvent.ID)
-		d.Nack(false, true)
-		return
+		return broker.Retryable(fmt.Errorf("failed to index document: %w", err))
 	}
 
 	if res.IsError() {
 		p.logger.Error("opensearch returned an error during indexing", "status", res.Status(), "productId", productEvent.ID)
-		d.Nack(false, true)
-		return
+		return broker.Retryable(fmt.Errorf("opensearch returned %s during indexing", res.Status()))
 	}
 
 	p.logger.Info("product indexed successfully", "index", p.opensearchIndex, "productId", productEvent.ID, "opensearchStatus", res.Status())
-	d.Ack(false)
+	return nil
 }
 
-func (p *ProductEventsConsumer) deleteProduct(ctx context.Context, productEvent events.Product, d amqp091.Delivery) {
+func (p *ProductEventsConsumer) deleteProduct(ctx context.Context, productEvent events.Product) error {
 	res, err := p.indexer.Delete(
 		ctx,
 		p.opensearchIndex,
@@ -108,16 +121,14 @@ func (p *ProductEventsConsumer) deleteProduct(ctx context.Context, productEvent
 	)
 	if err != nil {
 		p.logger.Error("failed to delete document in opensearch", "error", err, "productId", productEvent.ID)
-		d.Nack(false, true)
-		return
+		return broker.Retryable(fmt.Errorf("failed to delete document: %w", err))
 	}
 
 	if res.IsError() {
 		p.logger.Error("opensearch returned an error during deletion", "status", res.Status(), "productId", productEvent.ID)
-		d.Nack(false, true)
-		return
+		return broker.Retryable(fmt.Errorf("opensearch returned %s during deletion", res.Status()))
 	}
 
 	p.logger.Info("product deleted successfully", "index", p.opensearchIndex, "productId", productEvent.ID, "opensearchStatus", res.Status())
-	d.Ack(false)
+	return nil
 }
@@ -9,10 +9,9 @@ import (
 	"testing"
 
 	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
-	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
 	"github.com/sonuudigital/microservices/shared/events"
 	"github.com/sonuudigital/microservices/shared/logs"
-	"github.com/sonuudigital/microservices/shared/rabbitmq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -25,8 +24,8 @@ type MockSubscriber struct {
 	mock.Mock
 }
 
-func (m *MockSubscriber) Subscribe(ctx context.Context, opts rabbitmq.SubscribeOptions) error {
-	args := m.Called(ctx, opts)
+func (m *MockSubscriber) Subscribe(ctx context.Context, sub broker.Subscription) error {
+	args := m.Called(ctx, sub)
 	return args.Error(0)
 }
 
@@ -104,21 +103,23 @@ func TestProductEventsConsumerStart(t *testing.T) {
 
 	consumer := NewProductEventsConsumer(logger, mockSubscriber, mockIndexer, index)
 
-	expectedOpts := rabbitmq.SubscribeOptions{
-		Exchange:     events.ProductExchangeName,
-		ExchangeType: rabbitmq.ExchangeTopic,
-		QueueName:    "search_product_events_queue",
-		BindingKey:   events.ProductWildcardRoutingKey,
-		Handler:      consumer.handleProductCreatedEvent,
+	expectedSub := broker.Subscription{
+		Exchange:   events.ProductExchangeName,
+		Kind:       broker.ExchangeTopic,
+		Queue:      "search_product_events_queue",
+		BindingKey: events.ProductWildcardRoutingKey,
+		Handler:    consumer.handleProductCreatedEvent,
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mockSubscriber.On("Subscribe", mock.Anything, mock.MatchedBy(func(opts rabbitmq.SubscribeOptions) bool {
-			return opts.Exchange == expectedOpts.Exchange &&
-				opts.ExchangeType == expectedOpts.ExchangeType &&
-				opts.QueueName == expectedOpts.QueueName &&
-				opts.BindingKey == expectedOpts.BindingKey &&
-				opts.Handler != nil
+		mockSubscriber.On("Subscribe", mock.Anything, mock.MatchedBy(func(sub broker.Subscription) bool {
+			return sub.Exchange == expectedSub.Exchange &&
+				sub.Kind == expectedSub.Kind &&
+				sub.Queue == expectedSub.Queue &&
+				sub.BindingKey == expectedSub.BindingKey &&
+				sub.Handler != nil &&
+				sub.PartitionKey != nil &&
+				sub.PartitionKey(broker.Message{Body: []byte(`{"id":"product-123"}`)}) == "product-123"
 		})).Return(nil).Once()
 
 		err := consumer.Start(context.Background())
@@ -167,8 +168,22 @@ func TestProductEventsConsumerHandleProductEvent(t *testing.T) {
 
 		mockIndexer.On("Index", mock.Anything, index, testProduct.ID, mock.AnythingOfType(uint8ArrayType)).Return("success", nil).Once()
 
-		delivery := amqp091.Delivery{Body: []byte(productJSON)}
-		consumer.handleProductCreatedEvent(context.Background(), delivery)
+		msg := broker.Message{Body: []byte(productJSON)}
+		err := consumer.handleProductCreatedEvent(context.Background(), msg)
+		assert.NoError(t, err)
+
+		mockIndexer.AssertExpectations(t)
+	})
+
+	t.Run("IndexEnvelope", func(t *testing.T) {
+		body, err := events.Wrap("/product-service", events.ProductUpdatedEventType, testProduct.ID, testProduct)
+		assert.NoError(t, err)
+
+		mockIndexer.On("Index", mock.Anything, index, testProduct.ID, mock.AnythingOfType(uint8ArrayType)).Return("success", nil).Once()
+
+		msg := broker.Message{Body: body, RoutingKey: events.ProductUpdatedRoutingKey}
+		err = consumer.handleProductCreatedEvent(context.Background(), msg)
+		assert.NoError(t, err)
 
 		mockIndexer.AssertExpectations(t)
 	})
@@ -176,8 +191,10 @@ func TestProductEventsConsumerHandleProductEvent(t *testing.T) {
 	t.Run("InvalidJSON", func(t *testing.T) {
 		invalidJSON := `{invalid json}`
 
-		delivery := amqp091.Delivery{Body: []byte(invalidJSON)}
-		consumer.handleProductCreatedEvent(context.Background(), delivery)
+		msg := broker.Message{Body: []byte(invalidJSON)}
+		err := consumer.handleProductCreatedEvent(context.Background(), msg)
+		assert.Error(t, err)
+		assert.False(t, broker.IsRetryable(err))
 
 		mockIndexer.AssertNotCalled(t, "Index")
 	})
@@ -196,8 +213,9 @@ func TestProductEventsConsumerHandleProductEvent(t *testing.T) {
 
 		mockIndexer.On("Index", mock.Anything, index, testProduct.ID, mock.AnythingOfType(uint8ArrayType)).Return(nil, indexErr).Once()
 
-		delivery := amqp091.Delivery{Body: []byte(productJSON)}
-		consumer.handleProductCreatedEvent(context.Background(), delivery)
+		msg := broker.Message{Body: []byte(productJSON)}
+		err := consumer.handleProductCreatedEvent(context.Background(), msg)
+		assert.True(t, broker.IsRetryable(err))
 
 		mockIndexer.AssertExpectations(t)
 	})
@@ -214,8 +232,9 @@ func TestProductEventsConsumerHandleProductEvent(t *testing.T) {
 
 		mockIndexer.On("Index", mock.Anything, index, testProduct.ID, mock.AnythingOfType(uint8ArrayType)).Return("error_response", nil).Once()
 
-		delivery := amqp091.Delivery{Body: []byte(productJSON)}
-		consumer.handleProductCreatedEvent(context.Background(), delivery)
+		msg := broker.Message{Body: []byte(productJSON)}
+		err := consumer.handleProductCreatedEvent(context.Background(), msg)
+		assert.True(t, broker.IsRetryable(err))
 
 		mockIndexer.AssertExpectations(t)
 	})
@@ -232,8 +251,9 @@ func TestProductEventsConsumerHandleProductEvent(t *testing.T) {
 
 		mockIndexer.On("Index", mock.Anything, index, "product-123", mock.AnythingOfType(uint8ArrayType)).Return("success", nil).Once()
 
-		delivery := amqp091.Delivery{Body: []byte(productJSON)}
-		consumer.handleProductCreatedEvent(context.Background(), delivery)
+		msg := broker.Message{Body: []byte(productJSON)}
+		err := consumer.handleProductCreatedEvent(context.Background(), msg)
+		assert.NoError(t, err)
 
 		mockIndexer.AssertExpectations(t)
 	})
@@ -248,8 +268,9 @@ func TestProductEventsConsumerHandleProductEvent(t *testing.T) {
 			"stockQuantity": 10
 		}`
 		mockIndexer.On("Delete", mock.Anything, index, testProduct.ID).Return("success", nil).Once()
-		delivery := amqp091.Delivery{Body: []byte(productJSON), RoutingKey: events.ProductDeletedRoutingKey}
-		consumer.handleProductCreatedEvent(context.Background(), delivery)
+		msg := broker.Message{Body: []byte(productJSON), RoutingKey: events.ProductDeletedRoutingKey}
+		err := consumer.handleProductCreatedEvent(context.Background(), msg)
+		assert.NoError(t, err)
 		mockIndexer.AssertExpectations(t)
 		mockIndexer.AssertNotCalled(t, "Index")
 	})
@@ -265,8 +286,9 @@ func TestProductEventsConsumerHandleProductEvent(t *testing.T) {
 		}`
 		deleteErr := errors.New("delete failed")
 		mockIndexer.On("Delete", mock.Anything, index, testProduct.ID).Return(nil, deleteErr).Once()
-		delivery := amqp091.Delivery{Body: []byte(productJSON), RoutingKey: events.ProductDeletedRoutingKey}
-		consumer.handleProductCreatedEvent(context.Background(), delivery)
+		msg := broker.Message{Body: []byte(productJSON), RoutingKey: events.ProductDeletedRoutingKey}
+		err := consumer.handleProductCreatedEvent(context.Background(), msg)
+		assert.True(t, broker.IsRetryable(err))
 		mockIndexer.AssertExpectations(t)
 		mockIndexer.AssertNotCalled(t, "Index")
 	})
@@ -281,8 +303,9 @@ func TestProductEventsConsumerHandleProductEvent(t *testing.T) {
 			"stockQuantity": 10
 		}`
 		mockIndexer.On("Delete", mock.Anything, index, testProduct.ID).Return("error_response", nil).Once()
-		delivery := amqp091.Delivery{Body: []byte(productJSON), RoutingKey: events.ProductDeletedRoutingKey}
-		consumer.handleProductCreatedEvent(context.Background(), delivery)
+		msg := broker.Message{Body: []byte(productJSON), RoutingKey: events.ProductDeletedRoutingKey}
+		err := consumer.handleProductCreatedEvent(context.Background(), msg)
+		assert.True(t, broker.IsRetryable(err))
 		mockIndexer.AssertExpectations(t)
 		mockIndexer.AssertNotCalled(t, "Index")
 	})
@@ -3,15 +3,62 @@ package product
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/sonuudigital/microservices/shared/events"
 	"github.com/sonuudigital/microservices/shared/web"
 )
 
+const (
+	defaultSearchSize    = 10
+	maxSearchSize        = 100
+	defaultPriceInterval = 50
+	maxCategoryFacets    = 20
+
+	// maxResultWindow is OpenSearch's default index.max_result_window; pages
+	// ending beyond it are rejected by the cluster.
+	maxResultWindow = 10000
+	// minPriceInterval keeps the price histogram to a cent, below which
+	// OpenSearch fails to build the buckets.
+	minPriceInterval = 0.01
+
+	SortRelevance = "relevance"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortNewest    = "newest"
+)
+
 type Query map[string]any
 
+type SearchResult struct {
+	Total  int              `json:"total"`
+	From   int              `json:"from"`
+	Size   int              `json:"size"`
+	Facets Facets           `json:"facets"`
+	Items  []events.Product `json:"items"`
+}
+
+type Facets struct {
+	Categories []CategoryFacet `json:"categories"`
+	Price      []PriceFacet    `json:"price"`
+}
+
+type CategoryFacet struct {
+	CategoryID string `json:"categoryId"`
+	Count      int    `json:"count"`
+}
+
+type PriceFacet struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
 type SearchResponse struct {
 	Hits struct {
 		Total struct {
@@ -21,38 +68,47 @@ type SearchResponse struct {
 			Source events.Product `json:"_source"`
 		} `json:"hits"`
 	} `json:"hits"`
+	Aggregations struct {
+		Categories struct {
+			Values struct {
+				Buckets []struct {
+					Key      string `json:"key"`
+					DocCount int    `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"values"`
+		} `json:"categories"`
+		Price struct {
+			Values struct {
+				Buckets []struct {
+					Key      float64 `json:"key"`
+					DocCount int     `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"values"`
+		} `json:"price"`
+	} `json:"aggregations"`
+}
+
+type searchParams struct {
+	query         string
+	categories    []string
+	minPrice      *float64
+	maxPrice      *float64
+	inStock       bool
+	sort          string
+	priceInterval float64
+	from          int
+	size          int
 }
 
 func (h *ProductHandler) SearchProduct(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
-	if query == "" {
-		web.RespondWithError(w, h.logger, r, http.StatusBadRequest, "Query parameter 'q' is required", "The 'q' parameter cannot be empty")
+	params, err := parseSearchParams(r.URL.Query())
+	if err != nil {
+		web.RespondWithError(w, h.logger, r, http.StatusBadRequest, "Invalid search parameters", err.Error())
 		return
 	}
 
-	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
-	if size <= 0 {
-		size = 10
-	}
-
-	from, _ := strconv.Atoi(r.URL.Query().Get("from"))
-	if from < 0 {
-		from = 0
-	}
-
-	searchQuery := Query{
-		"from": from,
-		"size": size,
-		"query": Query{
-			"multi_match": Query{
-				"query":  query,
-				"fields": []string{"name", "description"},
-			},
-		},
-	}
-
 	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(searchQuery); err != nil {
+	if err := json.NewEncoder(&buf).Encode(buildSearchQuery(params)); err != nil {
 		web.RespondWithError(w, h.logger, r, http.StatusInternalServerError, "Failed to encode search query", err.Error())
 		return
 	}
@@ -75,15 +131,178 @@ func (h *ProductHandler) SearchProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if searchRes.Hits.Total.Value == 0 {
-		web.RespondWithError(w, h.logger, r, http.StatusNotFound, "No products found", "No results match the search query")
-		return
+	web.RespondWithJSON(w, h.logger, http.StatusOK, toSearchResult(params, searchRes))
+}
+
+func parseSearchParams(values url.Values) (searchParams, error) {
+	params := searchParams{
+		query:         strings.TrimSpace(values.Get("q")),
+		sort:          values.Get("sort"),
+		priceInterval: defaultPriceInterval,
+		size:          defaultSearchSize,
+	}
+	if params.query == "" {
+		return params, fmt.Errorf("the 'q' parameter cannot be empty")
 	}
 
-	products := make([]events.Product, len(searchRes.Hits.Hits))
-	for i, hit := range searchRes.Hits.Hits {
-		products[i] = hit.Source
+	if size, _ := strconv.Atoi(values.Get("size")); size > 0 {
+		params.size = min(size, maxSearchSize)
+	}
+	if from, _ := strconv.Atoi(values.Get("from")); from > 0 {
+		params.from = from
+	}
+	if params.from > maxResultWindow-params.size {
+		return params, fmt.Errorf("'from' plus 'size' must not be greater than %d", maxResultWindow)
+	}
+
+	for _, value := range values["category"] {
+		for category := range strings.SplitSeq(value, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				params.categories = append(params.categories, category)
+			}
+		}
+	}
+
+	var err error
+	if params.minPrice, err = parsePrice(values, "minPrice"); err != nil {
+		return params, err
+	}
+	if params.maxPrice, err = parsePrice(values, "maxPrice"); err != nil {
+		return params, err
+	}
+	if params.minPrice != nil && params.maxPrice != nil && *params.minPrice > *params.maxPrice {
+		return params, fmt.Errorf("'minPrice' must not be greater than 'maxPrice'")
+	}
+
+	if value := values.Get("inStock"); value != "" {
+		if params.inStock, err = strconv.ParseBool(value); err != nil {
+			return params, fmt.Errorf("'inStock' must be true or false")
+		}
+	}
+
+	switch params.sort {
+	case "":
+		params.sort = SortRelevance
+	case SortRelevance, SortPriceAsc, SortPriceDesc, SortNewest:
+	default:
+		return params, fmt.Errorf("'sort' must be one of %s, %s, %s or %s", SortRelevance, SortPriceAsc, SortPriceDesc, SortNewest)
+	}
+
+	if value := values.Get("priceInterval"); value != "" {
+		interval, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(interval) || interval < minPriceInterval || math.IsInf(interval, 0) {
+			return params, fmt.Errorf("'priceInterval' must be a number of at least %g", minPriceInterval)
+		}
+		params.priceInterval = interval
+	}
+
+	return params, nil
+}
+
+func parsePrice(values url.Values, name string) (*float64, error) {
+	value := values.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(price) || price < 0 || math.IsInf(price, 0) {
+		return nil, fmt.Errorf("'%s' must be a non-negative number", name)
+	}
+	return &price, nil
+}
+
+// buildSearchQuery filters by stock in the query, so it also narrows the
+// facets, while the category and price filters go to the post_filter. Each
+// facet applies only the other facet's filter, so selecting a category still
+// shows the counts of the other categories.
+func buildSearchQuery(params searchParams) Query {
+	filters := []Query{}
+	if params.inStock {
+		filters = append(filters, Query{"range": Query{"stockQuantity": Query{"gt": 0}}})
+	}
+
+	var categoryFilters, priceFilters []Query
+	if len(params.categories) > 0 {
+		categoryFilters = append(categoryFilters, Query{"terms": Query{"categoryId": params.categories}})
+	}
+	if params.minPrice != nil || params.maxPrice != nil {
+		priceRange := Query{}
+		if params.minPrice != nil {
+			priceRange["gte"] = *params.minPrice
+		}
+		if params.maxPrice != nil {
+			priceRange["lte"] = *params.maxPrice
+		}
+		priceFilters = append(priceFilters, Query{"range": Query{"price": priceRange}})
+	}
+
+	searchQuery := Query{
+		"from":             params.from,
+		"size":             params.size,
+		"track_total_hits": true,
+		"query": Query{
+			"bool": Query{
+				"must": Query{
+					"multi_match": Query{
+						"query":  params.query,
+						"fields": []string{"name^2", "description"},
+					},
+				},
+				"filter": filters,
+			},
+		},
+		"post_filter": Query{
+			"bool": Query{"filter": append(append([]Query{}, categoryFilters...), priceFilters...)},
+		},
+		"aggs": Query{
+			"categories": Query{
+				"filter": Query{"bool": Query{"filter": append([]Query{}, priceFilters...)}},
+				"aggs": Query{
+					"values": Query{"terms": Query{"field": "categoryId", "size": maxCategoryFacets}},
+				},
+			},
+			"price": Query{
+				"filter": Query{"bool": Query{"filter": append([]Query{}, categoryFilters...)}},
+				"aggs": Query{
+					"values": Query{"histogram": Query{"field": "price", "interval": params.priceInterval, "min_doc_count": 1}},
+				},
+			},
+		},
+	}
+
+	switch params.sort {
+	case SortPriceAsc:
+		searchQuery["sort"] = []Query{{"price": "asc"}, {"_score": "desc"}}
+	case SortPriceDesc:
+		searchQuery["sort"] = []Query{{"price": "desc"}, {"_score": "desc"}}
+	case SortNewest:
+		searchQuery["sort"] = []Query{{"createdAt": Query{"order": "desc", "missing": "_last"}}, {"_score": "desc"}}
+	}
+
+	return searchQuery
+}
+
+func toSearchResult(params searchParams, searchRes SearchResponse) SearchResult {
+	result := SearchResult{
+		Total: searchRes.Hits.Total.Value,
+		From:  params.from,
+		Size:  params.size,
+		Facets: Facets{
+			Categories: make([]CategoryFacet, 0, len(searchRes.Aggregations.Categories.Values.Buckets)),
+			Price:      make([]PriceFacet, 0, len(searchRes.Aggregations.Price.Values.Buckets)),
+		},
+		Items: make([]events.Product, 0, len(searchRes.Hits.Hits)),
+	}
+
+	for _, hit := range searchRes.Hits.Hits {
+		result.Items = append(result.Items, hit.Source)
+	}
+	for _, bucket := range searchRes.Aggregations.Categories.Values.Buckets {
+		result.Facets.Categories = append(result.Facets.Categories, CategoryFacet{CategoryID: bucket.Key, Count: bucket.DocCount})
+	}
+	for _, bucket := range searchRes.Aggregations.Price.Values.Buckets {
+		result.Facets.Price = append(result.Facets.Price, PriceFacet{From: bucket.Key, To: bucket.Key + params.priceInterval, Count: bucket.DocCount})
 	}
 
-	web.RespondWithJSON(w, h.logger, http.StatusOK, products)
+	return result
 }
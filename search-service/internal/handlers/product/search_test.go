@@ -108,3 +108,107 @@ func TestSearchProductHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchProductFacets(t *testing.T) {
+	logger := logs.NewSlogLogger()
+
+	searchRes := map[string]any{
+		"hits": map[string]any{
+			"total": map[string]int{"value": 1},
+			"hits": []map[string]any{
+				{"_source": map[string]any{"id": "p1", "categoryId": "c1", "name": "Laptop", "price": "120.00", "stockQuantity": 3}},
+			},
+		},
+		"aggregations": map[string]any{
+			"categories": map[string]any{
+				"doc_count": 3,
+				"values": map[string]any{"buckets": []map[string]any{
+					{"key": "c1", "doc_count": 1},
+					{"key": "c2", "doc_count": 2},
+				}},
+			},
+			"price": map[string]any{
+				"doc_count": 1,
+				"values": map[string]any{"buckets": []map[string]any{
+					{"key": 100.0, "doc_count": 1},
+				}},
+			},
+		},
+	}
+	bodyBytes, _ := json.Marshal(searchRes)
+
+	var sentQuery map[string]any
+	ms := new(MockSearcher)
+	ms.On("Search", mock.Anything, "products", mock.MatchedBy(func(body io.Reader) bool {
+		return json.NewDecoder(body).Decode(&sentQuery) == nil
+	})).Return(&opensearchapi.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader(bodyBytes))}, nil).Once()
+
+	h, err := product.NewProductHandler(logger, ms, "products")
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=laptop&category=c1&minPrice=100&maxPrice=200&inStock=true&sort=price_asc&priceInterval=100&size=500", nil)
+	rr := httptest.NewRecorder()
+	h.SearchProduct(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var result product.SearchResult
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+	assert.Equal(t, 1, result.Total)
+	assert.Equal(t, 100, result.Size)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, []product.CategoryFacet{{CategoryID: "c1", Count: 1}, {CategoryID: "c2", Count: 2}}, result.Facets.Categories)
+	assert.Equal(t, []product.PriceFacet{{From: 100, To: 200, Count: 1}}, result.Facets.Price)
+
+	assert.Equal(t, []any{map[string]any{"price": "asc"}, map[string]any{"_score": "desc"}}, sentQuery["sort"])
+	postFilter, _ := json.Marshal(sentQuery["post_filter"])
+	assert.JSONEq(t, `{"bool":{"filter":[{"terms":{"categoryId":["c1"]}},{"range":{"price":{"gte":100,"lte":200}}}]}}`, string(postFilter))
+	queryFilter, _ := json.Marshal(sentQuery["query"].(map[string]any)["bool"].(map[string]any)["filter"])
+	assert.JSONEq(t, `[{"range":{"stockQuantity":{"gt":0}}}]`, string(queryFilter))
+	ms.AssertExpectations(t)
+}
+
+func TestSearchProductNoResults(t *testing.T) {
+	ms := new(MockSearcher)
+	resp := &opensearchapi.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader([]byte(`{"hits":{"total":{"value":0},"hits":[]}}`)))}
+	ms.On("Search", mock.Anything, "products", mock.Anything).Return(resp, nil).Once()
+
+	h, err := product.NewProductHandler(logs.NewSlogLogger(), ms, "products")
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	h.SearchProduct(rr, httptest.NewRequest(http.MethodGet, "/search?q=nothing", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"total":0,"from":0,"size":10,"facets":{"categories":[],"price":[]},"items":[]}`, rr.Body.String())
+	ms.AssertExpectations(t)
+}
+
+func TestSearchProductInvalidParams(t *testing.T) {
+	paths := []string{
+		"/search?q=laptop&sort=cheapest",
+		"/search?q=laptop&minPrice=abc",
+		"/search?q=laptop&minPrice=-1",
+		"/search?q=laptop&minPrice=200&maxPrice=100",
+		"/search?q=laptop&inStock=maybe",
+		"/search?q=laptop&minPrice=NaN",
+		"/search?q=laptop&priceInterval=0",
+		"/search?q=laptop&priceInterval=0.0000001",
+		"/search?q=laptop&priceInterval=NaN",
+		"/search?q=laptop&from=9995&size=10",
+		"/search?q=laptop&from=20000",
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			ms := new(MockSearcher)
+			h, err := product.NewProductHandler(logs.NewSlogLogger(), ms, "products")
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			h.SearchProduct(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			ms.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package product
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sonuudigital/microservices/shared/web"
+)
+
+const (
+	defaultSuggestSize   = 5
+	maxSuggestSize       = 10
+	suggestCandidates    = 20
+	maxSuggestCategories = 5
+	productSuggesterName = "products"
+	productSuggestField  = "suggest"
+)
+
+type SuggestResult struct {
+	Suggestions []Suggestion        `json:"suggestions"`
+	Categories  []SuggestedCategory `json:"categories"`
+}
+
+// SuggestedCategory is a category whose name matches the typed text, with the
+// number of its products.
+type SuggestedCategory struct {
+	CategoryID string `json:"categoryId"`
+	Name       string `json:"name"`
+	Count      int    `json:"count"`
+}
+
+type Suggestion struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	CategoryID string `json:"categoryId"`
+}
+
+type SuggestResponse struct {
+	Suggest map[string][]struct {
+		Options []struct {
+			Source Suggestion `json:"_source"`
+		} `json:"options"`
+	} `json:"suggest"`
+	Aggregations struct {
+		Categories struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int    `json:"doc_count"`
+				Name     struct {
+					Buckets []struct {
+						Key string `json:"key"`
+					} `json:"buckets"`
+				} `json:"name"`
+			} `json:"buckets"`
+		} `json:"categories"`
+	} `json:"aggregations"`
+}
+
+// SuggestProducts completes the product names starting with a word prefix of
+// q and returns the categories whose name has a word starting with it (every
+// word of q must match), ranked by their number of products. Products in
+// stock rank first.
+func (h *ProductHandler) SuggestProducts(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		web.RespondWithError(w, h.logger, r, http.StatusBadRequest, "Invalid suggest parameters", "the 'q' parameter cannot be empty")
+		return
+	}
+
+	size := defaultSuggestSize
+	if value, _ := strconv.Atoi(r.URL.Query().Get("size")); value > 0 {
+		size = min(value, maxSuggestSize)
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(buildSuggestQuery(query)); err != nil {
+		web.RespondWithError(w, h.logger, r, http.StatusInternalServerError, "Failed to encode suggest query", err.Error())
+		return
+	}
+
+	res, err := h.searcher.Search(r.Context(), h.index, &buf)
+	if err != nil {
+		web.RespondWithError(w, h.logger, r, http.StatusInternalServerError, "Failed to execute suggest query", err.Error())
+		return
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		web.RespondWithError(w, h.logger, r, http.StatusInternalServerError, "Opensearch returned an error", "Status code: "+strconv.Itoa(res.StatusCode))
+		return
+	}
+
+	var suggestRes SuggestResponse
+	if err := json.NewDecoder(res.Body).Decode(&suggestRes); err != nil {
+		web.RespondWithError(w, h.logger, r, http.StatusInternalServerError, "Failed to decode suggest response", err.Error())
+		return
+	}
+
+	web.RespondWithJSON(w, h.logger, http.StatusOK, toSuggestResult(size, suggestRes))
+}
+
+func buildSuggestQuery(query string) Query {
+	return Query{
+		"size":    0,
+		"_source": []string{"id", "name", "categoryId"},
+		"query": Query{
+			"match_bool_prefix": Query{
+				"categoryName": Query{"query": query, "operator": "and"},
+			},
+		},
+		"aggs": Query{
+			"categories": Query{
+				"terms": Query{"field": "categoryId", "size": maxSuggestCategories},
+				"aggs": Query{
+					"name": Query{"terms": Query{"field": "categoryName.keyword", "size": 1}},
+				},
+			},
+		},
+		"suggest": Query{
+			productSuggesterName: Query{
+				"prefix": query,
+				"completion": Query{
+					"field":           productSuggestField,
+					"size":            suggestCandidates,
+					"skip_duplicates": true,
+				},
+			},
+		},
+	}
+}
+
+// toSuggestResult keeps the first option of every product, since a product
+// matches once per word suffix of its name.
+func toSuggestResult(size int, suggestRes SuggestResponse) SuggestResult {
+	buckets := suggestRes.Aggregations.Categories.Buckets
+	result := SuggestResult{
+		Suggestions: make([]Suggestion, 0, size),
+		Categories:  make([]SuggestedCategory, 0, len(buckets)),
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range suggestRes.Suggest[productSuggesterName] {
+		for _, option := range entry.Options {
+			suggestion := option.Source
+			if seen[suggestion.ID] || len(result.Suggestions) == size {
+				continue
+			}
+			seen[suggestion.ID] = true
+			result.Suggestions = append(result.Suggestions, suggestion)
+		}
+	}
+
+	for _, bucket := range buckets {
+		category := SuggestedCategory{CategoryID: bucket.Key, Count: bucket.DocCount}
+		if len(bucket.Name.Buckets) > 0 {
+			category.Name = bucket.Name.Buckets[0].Key
+		}
+		result.Categories = append(result.Categories, category)
+	}
+
+	return result
+}
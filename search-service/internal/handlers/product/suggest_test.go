@@ -0,0 +1,125 @@
+package product_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
+	"github.com/sonuudigital/microservices/search-service/internal/handlers/product"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuggestProducts(t *testing.T) {
+	suggestRes := `{"suggest":{"products":[{"text":"pro","options":[
+		{"text":"Pro 14","_id":"p1","_source":{"id":"p1","name":"MacBook Pro 14","categoryId":"c1"}},
+		{"text":"Pro Stand","_id":"p2","_source":{"id":"p2","name":"Pro Stand","categoryId":"c2"}},
+		{"text":"Pro Pro","_id":"p3","_source":{"id":"p3","name":"Pro Pro","categoryId":"c1"}},
+		{"text":"Pro","_id":"p3","_source":{"id":"p3","name":"Pro Pro","categoryId":"c1"}}
+	]}]},"aggregations":{"categories":{"buckets":[
+		{"key":"c3","doc_count":12,"name":{"buckets":[{"key":"Pro Audio","doc_count":12}]}},
+		{"key":"c4","doc_count":3,"name":{"buckets":[{"key":"Projectors","doc_count":3}]}}
+	]}}}`
+
+	var sentQuery map[string]any
+	ms := new(MockSearcher)
+	ms.On("Search", mock.Anything, "products", mock.MatchedBy(func(body io.Reader) bool {
+		return json.NewDecoder(body).Decode(&sentQuery) == nil
+	})).Return(&opensearchapi.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader([]byte(suggestRes)))}, nil).Once()
+
+	h, err := product.NewProductHandler(logs.NewSlogLogger(), ms, "products")
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	h.SuggestProducts(rr, httptest.NewRequest(http.MethodGet, "/search/suggest?q=+pro&size=2", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{
+		"suggestions":[
+			{"id":"p1","name":"MacBook Pro 14","categoryId":"c1"},
+			{"id":"p2","name":"Pro Stand","categoryId":"c2"}
+		],
+		"categories":[
+			{"categoryId":"c3","name":"Pro Audio","count":12},
+			{"categoryId":"c4","name":"Projectors","count":3}
+		]
+	}`, rr.Body.String())
+
+	suggester, _ := json.Marshal(sentQuery["suggest"])
+	assert.JSONEq(t, `{"products":{"prefix":"pro","completion":{"field":"suggest","size":20,"skip_duplicates":true}}}`, string(suggester))
+	categoryQuery, _ := json.Marshal(sentQuery["query"])
+	assert.JSONEq(t, `{"match_bool_prefix":{"categoryName":{"query":"pro","operator":"and"}}}`, string(categoryQuery))
+	categoryAggs, _ := json.Marshal(sentQuery["aggs"])
+	assert.JSONEq(t, `{"categories":{"terms":{"field":"categoryId","size":5},"aggs":{"name":{"terms":{"field":"categoryName.keyword","size":1}}}}}`, string(categoryAggs))
+	ms.AssertExpectations(t)
+}
+
+func TestSuggestProductsNoResults(t *testing.T) {
+	ms := new(MockSearcher)
+	resp := &opensearchapi.Response{StatusCode: 200, Body: io.NopCloser(bytes.NewReader([]byte(`{"suggest":{"products":[{"text":"zz","options":[]}]}}`)))}
+	ms.On("Search", mock.Anything, "products", mock.Anything).Return(resp, nil).Once()
+
+	h, err := product.NewProductHandler(logs.NewSlogLogger(), ms, "products")
+	assert.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	h.SuggestProducts(rr, httptest.NewRequest(http.MethodGet, "/search/suggest?q=zz", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"suggestions":[],"categories":[]}`, rr.Body.String())
+	ms.AssertExpectations(t)
+}
+
+func TestSuggestProductsErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		setupMock func(*MockSearcher)
+		status    int
+	}{
+		{
+			name:      "MissingQueryParam",
+			path:      "/search/suggest?q=%20",
+			setupMock: func(ms *MockSearcher) {},
+			status:    http.StatusBadRequest,
+		},
+		{
+			name: "SearcherError",
+			path: "/search/suggest?q=pro",
+			setupMock: func(ms *MockSearcher) {
+				ms.On("Search", mock.Anything, "products", mock.Anything).Return(nil, assert.AnError).Once()
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name: "SearchResponseErrorStatus",
+			path: "/search/suggest?q=pro",
+			setupMock: func(ms *MockSearcher) {
+				resp := &opensearchapi.Response{StatusCode: 400, Body: io.NopCloser(bytes.NewReader([]byte("{}")))}
+				ms.On("Search", mock.Anything, "products", mock.Anything).Return(resp, nil).Once()
+			},
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := new(MockSearcher)
+			tt.setupMock(ms)
+			h, err := product.NewProductHandler(logs.NewSlogLogger(), ms, "products")
+			assert.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			h.SuggestProducts(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Contains(t, rr.Header().Get("Content-Type"), "application/problem+json")
+			ms.AssertExpectations(t)
+		})
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/opensearch-project/opensearch-go/v2"
 	"github.com/opensearch-project/opensearch-go/v2/opensearchapi"
@@ -69,6 +72,43 @@ func (c *Client) Delete(ctx context.Context, indexName string, documentID string
 	return res, nil
 }
 
+// CreateDocument indexes the document only when no document with the same ID
+// exists in the index, and reports whether it was created.
+func (c *Client) CreateDocument(ctx context.Context, indexName string, documentID string, body []byte) (bool, error) {
+	req := opensearchapi.CreateRequest{
+		Index:      indexName,
+		DocumentID: documentID,
+		Body:       bytes.NewReader(body),
+		Refresh:    "true",
+	}
+
+	res, err := req.Do(ctx, c.Client)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute create request: %w", err)
+	}
+	if res.StatusCode == http.StatusConflict {
+		res.Body.Close()
+		return false, nil
+	}
+	if err := checkResponse(res, "create document "+documentID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteDocument deletes the document, if it exists.
+func (c *Client) DeleteDocument(ctx context.Context, indexName string, documentID string) error {
+	res, err := c.Delete(ctx, indexName, documentID)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil
+	}
+	return checkResponse(res, "delete document "+documentID)
+}
+
 func (c *Client) Search(ctx context.Context, indexName string, body io.Reader) (*opensearchapi.Response, error) {
 	req := opensearchapi.SearchRequest{
 		Index: []string{indexName},
@@ -82,3 +122,329 @@ func (c *Client) Search(ctx context.Context, indexName string, body io.Reader) (
 
 	return res, nil
 }
+
+func (c *Client) Ping(ctx context.Context) error {
+	res, err := opensearchapi.PingRequest{}.Do(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to execute ping request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("opensearch ping failed: %s", res.Status())
+	}
+	return nil
+}
+
+type Document struct {
+	ID   string
+	Body []byte
+}
+
+// AliasAction is one action of an _aliases request. All actions of a request
+// are applied atomically.
+type AliasAction struct {
+	Add         *AliasTarget `json:"add,omitempty"`
+	Remove      *AliasTarget `json:"remove,omitempty"`
+	RemoveIndex *IndexTarget `json:"remove_index,omitempty"`
+}
+
+type AliasTarget struct {
+	Index        string `json:"index"`
+	Alias        string `json:"alias"`
+	IsWriteIndex bool   `json:"is_write_index,omitempty"`
+}
+
+type IndexTarget struct {
+	Index string `json:"index"`
+}
+
+func (c *Client) CreateIndex(ctx context.Context, indexName string, body []byte) error {
+	req := opensearchapi.IndicesCreateRequest{Index: indexName}
+	if body != nil {
+		req.Body = bytes.NewReader(body)
+	}
+
+	res, err := req.Do(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to execute create index request: %w", err)
+	}
+	return checkResponse(res, "create index "+indexName)
+}
+
+func (c *Client) DeleteIndex(ctx context.Context, indexNames ...string) error {
+	res, err := opensearchapi.IndicesDeleteRequest{Index: indexNames}.Do(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to execute delete index request: %w", err)
+	}
+	return checkResponse(res, "delete index")
+}
+
+func (c *Client) IndexExists(ctx context.Context, indexName string) (bool, error) {
+	res, err := opensearchapi.IndicesExistsRequest{Index: []string{indexName}}.Do(ctx, c.Client)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute index exists request: %w", err)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("opensearch returned %s checking index %s", res.Status(), indexName)
+	}
+}
+
+func (c *Client) Refresh(ctx context.Context, indexName string) error {
+	res, err := opensearchapi.IndicesRefreshRequest{Index: []string{indexName}}.Do(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to execute refresh request: %w", err)
+	}
+	return checkResponse(res, "refresh "+indexName)
+}
+
+// AliasIndices returns the indices the alias points to, or none when the
+// alias does not exist.
+func (c *Client) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	res, err := opensearchapi.IndicesGetAliasRequest{Name: []string{alias}}.Do(ctx, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get alias request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("opensearch returned %s getting alias %s", res.Status(), alias)
+	}
+
+	var indices map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return nil, fmt.Errorf("failed to decode get alias response: %w", err)
+	}
+
+	names := make([]string, 0, len(indices))
+	for name := range indices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *Client) UpdateAliases(ctx context.Context, actions []AliasAction) error {
+	body, err := json.Marshal(map[string][]AliasAction{"actions": actions})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alias actions: %w", err)
+	}
+
+	res, err := opensearchapi.IndicesUpdateAliasesRequest{Body: bytes.NewReader(body)}.Do(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to execute update aliases request: %w", err)
+	}
+	return checkResponse(res, "update aliases")
+}
+
+// BulkIndex indexes the documents with a single _bulk request and fails if
+// any of them was rejected.
+func (c *Client) BulkIndex(ctx context.Context, indexName string, documents []Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	for _, doc := range documents {
+		meta, err := json.Marshal(map[string]map[string]string{"index": {"_index": indexName, "_id": doc.ID}})
+		if err != nil {
+			return fmt.Errorf("failed to marshal bulk action: %w", err)
+		}
+		body.Write(meta)
+		body.WriteByte('\n')
+		body.Write(doc.Body)
+		body.WriteByte('\n')
+	}
+
+	res, err := opensearchapi.BulkRequest{Body: &body}.Do(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to execute bulk request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("opensearch returned %s during bulk indexing", res.Status())
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	for _, item := range result.Items {
+		for _, op := range item {
+			if len(op.Error) > 0 && string(op.Error) != "null" {
+				return fmt.Errorf("failed to bulk index document %s: %s", op.ID, op.Error)
+			}
+		}
+	}
+	return fmt.Errorf("bulk indexing into %s reported errors", indexName)
+}
+
+// BulkDelete deletes the documents with a single _bulk request. Documents
+// that no longer exist are not an error.
+func (c *Client) BulkDelete(ctx context.Context, indexName string, documentIDs []string) error {
+	if len(documentIDs) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	for _, id := range documentIDs {
+		meta, err := json.Marshal(map[string]map[string]string{"delete": {"_index": indexName, "_id": id}})
+		if err != nil {
+			return fmt.Errorf("failed to marshal bulk action: %w", err)
+		}
+		body.Write(meta)
+		body.WriteByte('\n')
+	}
+
+	res, err := opensearchapi.BulkRequest{Body: &body}.Do(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to execute bulk request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("opensearch returned %s during bulk delete", res.Status())
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	for _, item := range result.Items {
+		for _, op := range item {
+			if len(op.Error) > 0 && string(op.Error) != "null" {
+				return fmt.Errorf("failed to bulk delete document %s: %s", op.ID, op.Error)
+			}
+		}
+	}
+	return fmt.Errorf("bulk delete from %s reported errors", indexName)
+}
+
+func (c *Client) PutIndexTemplate(ctx context.Context, name string, body []byte) error {
+	res, err := opensearchapi.IndicesPutIndexTemplateRequest{Name: name, Body: bytes.NewReader(body)}.Do(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to execute put index template request: %w", err)
+	}
+	return checkResponse(res, "put index template "+name)
+}
+
+const scrollKeepAlive = time.Minute
+
+// ScrollDocuments reads every document of the indices in batches of
+// batchSize with a scroll and passes each batch to fn, stopping at the first
+// error fn returns.
+func (c *Client) ScrollDocuments(ctx context.Context, indices []string, batchSize int, fn func([]Document) error) error {
+	res, err := opensearchapi.SearchRequest{
+		Index:  indices,
+		Size:   &batchSize,
+		Sort:   []string{"_doc"},
+		Scroll: scrollKeepAlive,
+	}.Do(ctx, c.Client)
+	if err != nil {
+		return fmt.Errorf("failed to execute scroll search request: %w", err)
+	}
+
+	var scrollID string
+	defer func() {
+		if scrollID != "" {
+			c.clearScroll(scrollID)
+		}
+	}()
+
+	for {
+		page, err := decodeScrollPage(res)
+		if err != nil {
+			return err
+		}
+		scrollID = page.ScrollID
+		if len(page.Hits.Hits) == 0 {
+			return nil
+		}
+
+		documents := make([]Document, 0, len(page.Hits.Hits))
+		for _, hit := range page.Hits.Hits {
+			documents = append(documents, Document{ID: hit.ID, Body: hit.Source})
+		}
+		if err := fn(documents); err != nil {
+			return err
+		}
+
+		res, err = opensearchapi.ScrollRequest{ScrollID: scrollID, Scroll: scrollKeepAlive}.Do(ctx, c.Client)
+		if err != nil {
+			return fmt.Errorf("failed to execute scroll request: %w", err)
+		}
+	}
+}
+
+type scrollPage struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []struct {
+			ID     string          `json:"_id"`
+			Source json.RawMessage `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+func decodeScrollPage(res *opensearchapi.Response) (scrollPage, error) {
+	defer res.Body.Close()
+
+	var page scrollPage
+	if res.IsError() {
+		return page, fmt.Errorf("opensearch returned %s during scroll", res.Status())
+	}
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		return page, fmt.Errorf("failed to decode scroll response: %w", err)
+	}
+	return page, nil
+}
+
+// clearScroll releases the scroll context early; it expires on its own
+// after scrollKeepAlive, so failures are ignored.
+func (c *Client) clearScroll(scrollID string) {
+	res, err := opensearchapi.ClearScrollRequest{ScrollID: []string{scrollID}}.Do(context.Background(), c.Client)
+	if err == nil {
+		res.Body.Close()
+	}
+}
+
+func checkResponse(res *opensearchapi.Response, operation string) error {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("opensearch returned %s on %s: %s", res.Status(), operation, body)
+	}
+	return nil
+}
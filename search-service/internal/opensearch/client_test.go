@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -98,3 +99,170 @@ func TestClientIndexNetworkError(t *testing.T) {
 		t.Fatalf("expected network error")
 	}
 }
+
+func TestClientBulkIndex(t *testing.T) {
+	var receivedBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		receivedBody = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(receivedBody, `"_id":"bad"`) {
+			w.Write([]byte(`{"errors":true,"items":[{"index":{"_id":"bad","status":400,"error":{"type":"mapper_parsing_exception"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":false,"items":[{"index":{"_id":"id1","status":201}}]}`))
+	}))
+	defer srv.Close()
+	c, err := opensearch.NewClient([]string{srv.URL}, "u", "p")
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+
+	err = c.BulkIndex(context.Background(), "products_1", []opensearch.Document{{ID: "id1", Body: []byte(`{"name":"test"}`)}})
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+	expected := "{\"index\":{\"_id\":\"id1\",\"_index\":\"products_1\"}}\n{\"name\":\"test\"}\n"
+	if receivedBody != expected {
+		t.Fatalf("unexpected bulk body: %q", receivedBody)
+	}
+
+	err = c.BulkIndex(context.Background(), "products_1", []opensearch.Document{{ID: "bad", Body: []byte(`{}`)}})
+	if err == nil || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Fatalf("expected item error, got %v", err)
+	}
+}
+
+func TestClientBulkDelete(t *testing.T) {
+	var receivedBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		receivedBody = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(receivedBody, `"_id":"locked"`) {
+			w.Write([]byte(`{"errors":true,"items":[{"delete":{"_id":"locked","status":403,"error":{"type":"cluster_block_exception"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":false,"items":[{"delete":{"_id":"id1","status":200,"result":"deleted"}},{"delete":{"_id":"gone","status":404,"result":"not_found"}}]}`))
+	}))
+	defer srv.Close()
+	c, err := opensearch.NewClient([]string{srv.URL}, "u", "p")
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+
+	err = c.BulkDelete(context.Background(), "products_1", []string{"id1", "gone"})
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+	expected := "{\"delete\":{\"_id\":\"id1\",\"_index\":\"products_1\"}}\n{\"delete\":{\"_id\":\"gone\",\"_index\":\"products_1\"}}\n"
+	if receivedBody != expected {
+		t.Fatalf("unexpected bulk body: %q", receivedBody)
+	}
+
+	err = c.BulkDelete(context.Background(), "products_1", []string{"locked"})
+	if err == nil || !strings.Contains(err.Error(), "cluster_block_exception") {
+		t.Fatalf("expected item error, got %v", err)
+	}
+}
+
+func TestClientCreateDocument(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/taken") {
+			w.WriteHeader(409)
+			w.Write([]byte(`{"error":{"type":"version_conflict_engine_exception"},"status":409}`))
+			return
+		}
+		w.WriteHeader(201)
+		w.Write([]byte(`{"result":"created"}`))
+	}))
+	defer srv.Close()
+	c, err := opensearch.NewClient([]string{srv.URL}, "u", "p")
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+
+	created, err := c.CreateDocument(context.Background(), "locks", "free", []byte(`{}`))
+	if err != nil || !created {
+		t.Fatalf("expected document to be created, got %v, %v", created, err)
+	}
+	created, err = c.CreateDocument(context.Background(), "locks", "taken", []byte(`{}`))
+	if err != nil || created {
+		t.Fatalf("expected conflict without error, got %v, %v", created, err)
+	}
+	if requests[0] != "PUT /locks/_create/free" {
+		t.Fatalf("unexpected request: %s", requests[0])
+	}
+}
+
+func TestClientAliasIndices(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_alias/missing" {
+			w.WriteHeader(404)
+			w.Write([]byte(`{"error":"alias [missing] missing","status":404}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"products_2":{"aliases":{"products":{}}},"products_1":{"aliases":{"products":{}}}}`))
+	}))
+	defer srv.Close()
+	c, err := opensearch.NewClient([]string{srv.URL}, "u", "p")
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+
+	indices, err := c.AliasIndices(context.Background(), "products")
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+	if len(indices) != 2 || indices[0] != "products_1" || indices[1] != "products_2" {
+		t.Fatalf("unexpected indices: %v", indices)
+	}
+
+	indices, err = c.AliasIndices(context.Background(), "missing")
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+	if len(indices) != 0 {
+		t.Fatalf("expected no indices, got %v", indices)
+	}
+}
+
+func TestClientScrollDocuments(t *testing.T) {
+	var cleared bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodDelete:
+			cleared = true
+			w.Write([]byte(`{"succeeded":true}`))
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			w.Write([]byte(`{"_scroll_id":"s1","hits":{"hits":[{"_id":"id1","_source":{"name":"a"}},{"_id":"id2","_source":{"name":"b"}}]}}`))
+		default:
+			w.Write([]byte(`{"_scroll_id":"s1","hits":{"hits":[]}}`))
+		}
+	}))
+	defer srv.Close()
+	c, err := opensearch.NewClient([]string{srv.URL}, "u", "p")
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+
+	var documents []opensearch.Document
+	err = c.ScrollDocuments(context.Background(), []string{"products_v1"}, 2, func(batch []opensearch.Document) error {
+		documents = append(documents, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf(unexpectedErrFmt, err)
+	}
+	if len(documents) != 2 || documents[0].ID != "id1" || string(documents[1].Body) != `{"name":"b"}` {
+		t.Fatalf("unexpected documents: %v", documents)
+	}
+	if !cleared {
+		t.Fatalf("expected scroll to be cleared")
+	}
+}
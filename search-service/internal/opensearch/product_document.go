@@ -0,0 +1,48 @@
+package opensearch
+
+import (
+	"strings"
+
+	"github.com/sonuudigital/microservices/shared/events"
+)
+
+const (
+	suggestWeightInStock    = 2
+	suggestWeightOutOfStock = 1
+)
+
+// ProductDocument is a product as stored in the product index: the product
+// event plus the completion field the suggest endpoint reads.
+type ProductDocument struct {
+	events.Product
+	Suggest *ProductSuggest `json:"suggest,omitempty"`
+}
+
+type ProductSuggest struct {
+	Input  []string `json:"input"`
+	Weight int      `json:"weight"`
+}
+
+// NewProductDocument builds the document of a product. The suggest inputs
+// are the name and every word suffix of it, so "pro" completes "MacBook Pro"
+// as well as "Pro Stand"; products in stock are suggested first.
+func NewProductDocument(product events.Product) ProductDocument {
+	document := ProductDocument{Product: product}
+
+	words := strings.Fields(product.Name)
+	if len(words) == 0 {
+		return document
+	}
+
+	inputs := make([]string, 0, len(words))
+	for i := range words {
+		inputs = append(inputs, strings.Join(words[i:], " "))
+	}
+
+	weight := suggestWeightOutOfStock
+	if product.StockQuantity > 0 {
+		weight = suggestWeightInStock
+	}
+	document.Suggest = &ProductSuggest{Input: inputs, Weight: weight}
+	return document
+}
@@ -0,0 +1,300 @@
+package opensearch
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+// ProductIndexVersion is the version of the product index settings and
+// mappings in product_index.json. Bump it with every change to them; the
+// next startup creates a new index, copies the documents into it and moves
+// the alias.
+const ProductIndexVersion = 4
+
+//go:embed product_index.json
+var productIndexDefinition []byte
+
+// IndexAdmin is the part of the client the product index migration uses.
+type IndexAdmin interface {
+	PutIndexTemplate(ctx context.Context, name string, body []byte) error
+	CreateIndex(ctx context.Context, indexName string, body []byte) error
+	DeleteIndex(ctx context.Context, indexNames ...string) error
+	IndexExists(ctx context.Context, indexName string) (bool, error)
+	AliasIndices(ctx context.Context, alias string) ([]string, error)
+	UpdateAliases(ctx context.Context, actions []AliasAction) error
+	ScrollDocuments(ctx context.Context, indices []string, batchSize int, fn func([]Document) error) error
+	BulkIndex(ctx context.Context, indexName string, documents []Document) error
+	Refresh(ctx context.Context, indexName string) error
+	CreateDocument(ctx context.Context, indexName string, documentID string, body []byte) (bool, error)
+	DeleteDocument(ctx context.Context, indexName string, documentID string) error
+}
+
+const (
+	migrationBatchSize = 500
+
+	// migrationLockPollInterval is how often an instance waiting for another
+	// one to migrate the index checks the alias again.
+	migrationLockPollInterval = 2 * time.Second
+	// migrationLockTimeout is how long an instance waits for a migration lock
+	// before it assumes the holder died and takes the lock over.
+	migrationLockTimeout = 10 * time.Minute
+)
+
+// ProductIndexName returns the name of the index of a version behind the
+// alias, e.g. products_v1. Rebuilt indices append a timestamp to it.
+func ProductIndexName(alias string, version int) string {
+	return fmt.Sprintf("%s_v%d", alias, version)
+}
+
+// ProductIndexVersionOf parses the version from an index created by
+// ProductIndexName, with or without a rebuild timestamp.
+func ProductIndexVersionOf(alias, indexName string) (int, bool) {
+	rest, ok := strings.CutPrefix(indexName, alias+"_v")
+	if !ok {
+		return 0, false
+	}
+	rest, _, _ = strings.Cut(rest, "_")
+	version, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// ProductIndexTemplate returns the index template that applies the product
+// settings and mappings to every index created behind the alias.
+func ProductIndexTemplate(alias string) ([]byte, error) {
+	var definition map[string]json.RawMessage
+	if err := json.Unmarshal(productIndexDefinition, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse product index definition: %w", err)
+	}
+
+	return json.Marshal(map[string]any{
+		"index_patterns": []string{alias + "_v*"},
+		"priority":       100,
+		"version":        ProductIndexVersion,
+		"template":       definition,
+	})
+}
+
+func productIndexTemplateName(alias string) string {
+	return alias + "_template"
+}
+
+// PutProductIndexTemplate creates or updates the product index template.
+func PutProductIndexTemplate(ctx context.Context, admin IndexAdmin, alias string) error {
+	template, err := ProductIndexTemplate(alias)
+	if err != nil {
+		return err
+	}
+	return admin.PutIndexTemplate(ctx, productIndexTemplateName(alias), template)
+}
+
+// MigrateProductIndex makes the alias point at an index of
+// ProductIndexVersion. When the alias is missing or points at an older
+// version, or when a concrete index carries the alias name (as created by
+// dynamic mapping before the alias existed), a new versioned index is
+// created, the existing documents are copied into it and the alias is moved
+// in a single request; the previous indices are deleted afterwards. It runs
+// before the consumer starts, so no events are indexed during the copy.
+// Documents are rebuilt with NewProductDocument while copying, so fields
+// derived from the product, like the suggest input, follow the new version.
+//
+// Replicas starting together serialize on a lock document in the
+// <alias>_migrations index, created with op_type=create; the others wait
+// until the alias has moved instead of deleting the index being filled.
+func MigrateProductIndex(ctx context.Context, logger logs.Logger, admin IndexAdmin, alias string) (string, error) {
+	if err := PutProductIndexTemplate(ctx, admin, alias); err != nil {
+		return "", err
+	}
+
+	target := ProductIndexName(alias, ProductIndexVersion)
+	unlock, index, err := lockProductIndexMigration(ctx, logger, admin, alias, target)
+	if err != nil || unlock == nil {
+		return index, err
+	}
+	defer unlock()
+
+	current, err := admin.AliasIndices(ctx, alias)
+	if err != nil {
+		return "", err
+	}
+
+	var legacyIndex bool
+	if len(current) == 0 {
+		if legacyIndex, err = admin.IndexExists(ctx, alias); err != nil {
+			return "", err
+		}
+	}
+
+	exists, err := admin.IndexExists(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		logger.Warn("deleting product index left by an interrupted migration", "index", target)
+		if err := admin.DeleteIndex(ctx, target); err != nil {
+			return "", err
+		}
+	}
+
+	if err := admin.CreateIndex(ctx, target, nil); err != nil {
+		return "", err
+	}
+
+	sources := current
+	if legacyIndex {
+		sources = []string{alias}
+	}
+	if len(sources) > 0 {
+		copied, incomplete, err := copyProductDocuments(ctx, admin, sources, target)
+		if err != nil {
+			return "", fmt.Errorf("failed to copy products from %v to %s: %w", sources, target, err)
+		}
+		logger.Info("copied products to new index", "from", sources, "to", target, "count", copied)
+		if incomplete > 0 {
+			logger.Warn("copied products lack createdAt or categoryName until the index is rebuilt with search-service reindex", "index", target, "count", incomplete)
+		}
+	}
+
+	actions := make([]AliasAction, 0, len(current)+1)
+	for _, index := range current {
+		actions = append(actions, AliasAction{Remove: &AliasTarget{Index: index, Alias: alias}})
+	}
+	if legacyIndex {
+		actions = append(actions, AliasAction{RemoveIndex: &IndexTarget{Index: alias}})
+	}
+	actions = append(actions, AliasAction{Add: &AliasTarget{Index: target, Alias: alias, IsWriteIndex: true}})
+	if err := admin.UpdateAliases(ctx, actions); err != nil {
+		return "", fmt.Errorf("failed to move alias %s to %s: %w", alias, target, err)
+	}
+	logger.Info("product index migrated", "alias", alias, "index", target, "version", ProductIndexVersion)
+
+	if len(current) > 0 {
+		if err := admin.DeleteIndex(ctx, current...); err != nil {
+			logger.Error("failed to delete previous product indices", "indices", current, "error", err)
+		}
+	}
+	return target, nil
+}
+
+// ProductIndexMigrationLockIndex returns the index holding the migration lock
+// documents of the alias.
+func ProductIndexMigrationLockIndex(alias string) string {
+	return alias + "_migrations"
+}
+
+// lockProductIndexMigration returns the up-to-date index behind the alias, or
+// takes the migration lock and returns the function releasing it. The alias is
+// checked again after every attempt, so an instance that was waiting returns
+// the index another one migrated to.
+func lockProductIndexMigration(ctx context.Context, logger logs.Logger, admin IndexAdmin, alias, target string) (func(), string, error) {
+	lockIndex := ProductIndexMigrationLockIndex(alias)
+	owner, _ := os.Hostname()
+	lock, err := json.Marshal(map[string]any{"owner": owner, "startedAt": time.Now().UTC()})
+	if err != nil {
+		return nil, "", err
+	}
+	unlock := func() {
+		if err := admin.DeleteDocument(context.WithoutCancel(ctx), lockIndex, target); err != nil {
+			logger.Error("failed to release product index migration lock", "index", lockIndex, "lock", target, "error", err)
+		}
+	}
+
+	deadline := time.Now().Add(migrationLockTimeout)
+	for {
+		locked, err := admin.CreateDocument(ctx, lockIndex, target, lock)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to take product index migration lock: %w", err)
+		}
+
+		index, upToDate, err := upToDateProductIndex(ctx, logger, admin, alias)
+		if err != nil || upToDate {
+			if locked {
+				unlock()
+			}
+			return nil, index, err
+		}
+		if locked {
+			return unlock, "", nil
+		}
+
+		if time.Now().After(deadline) {
+			logger.Warn("taking over product index migration lock that was not released", "index", lockIndex, "lock", target)
+			if err := admin.DeleteDocument(ctx, lockIndex, target); err != nil {
+				return nil, "", fmt.Errorf("failed to take over product index migration lock: %w", err)
+			}
+			deadline = time.Now().Add(migrationLockTimeout)
+			continue
+		}
+
+		logger.Info("waiting for another instance to migrate the product index", "alias", alias, "index", target)
+		select {
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		case <-time.After(migrationLockPollInterval):
+		}
+	}
+}
+
+func upToDateProductIndex(ctx context.Context, logger logs.Logger, admin IndexAdmin, alias string) (string, bool, error) {
+	current, err := admin.AliasIndices(ctx, alias)
+	if err != nil {
+		return "", false, err
+	}
+	for _, index := range current {
+		version, ok := ProductIndexVersionOf(alias, index)
+		if !ok || version < ProductIndexVersion {
+			continue
+		}
+		if version > ProductIndexVersion {
+			logger.Warn("product index is newer than this service", "index", index, "version", version, "expectedVersion", ProductIndexVersion)
+		}
+		logger.Info("product index is up to date", "alias", alias, "index", index)
+		return index, true, nil
+	}
+	return "", false, nil
+}
+
+// copyProductDocuments copies the products of the sources into the target
+// and returns how many were copied and how many of them lack createdAt or
+// categoryName. Documents indexed before those were added to the product
+// events do not carry them, and only product-service can fill them in.
+func copyProductDocuments(ctx context.Context, admin IndexAdmin, sources []string, target string) (int, int, error) {
+	var copied, incomplete int
+	err := admin.ScrollDocuments(ctx, sources, migrationBatchSize, func(documents []Document) error {
+		batch := make([]Document, 0, len(documents))
+		for _, document := range documents {
+			var product events.Product
+			if err := json.Unmarshal(document.Body, &product); err != nil {
+				return fmt.Errorf("failed to unmarshal product %s: %w", document.ID, err)
+			}
+			if product.CreatedAt.IsZero() || (product.CategoryID != "" && product.CategoryName == "") {
+				incomplete++
+			}
+			body, err := json.Marshal(NewProductDocument(product))
+			if err != nil {
+				return fmt.Errorf("failed to marshal product %s: %w", document.ID, err)
+			}
+			batch = append(batch, Document{ID: document.ID, Body: body})
+		}
+		if err := admin.BulkIndex(ctx, target, batch); err != nil {
+			return err
+		}
+		copied += len(batch)
+		return nil
+	})
+	if err != nil {
+		return copied, incomplete, err
+	}
+	return copied, incomplete, admin.Refresh(ctx, target)
+}
@@ -0,0 +1,266 @@
+package opensearch_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sonuudigital/microservices/search-service/internal/opensearch"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIndexAdmin struct {
+	templates    map[string][]byte
+	aliasIndices []string
+	existing     map[string]bool
+	created      []string
+	deleted      [][]string
+	documents    map[string][]opensearch.Document
+	scrolled     [][]string
+	indexed      map[string][]opensearch.Document
+	aliasActions []opensearch.AliasAction
+	locks        map[string]bool
+	lockedBy     func(f *fakeIndexAdmin)
+}
+
+func newFakeIndexAdmin() *fakeIndexAdmin {
+	return &fakeIndexAdmin{
+		templates: map[string][]byte{},
+		existing:  map[string]bool{},
+		documents: map[string][]opensearch.Document{},
+		indexed:   map[string][]opensearch.Document{},
+		locks:     map[string]bool{},
+	}
+}
+
+func (f *fakeIndexAdmin) PutIndexTemplate(ctx context.Context, name string, body []byte) error {
+	f.templates[name] = body
+	return nil
+}
+
+func (f *fakeIndexAdmin) CreateIndex(ctx context.Context, indexName string, body []byte) error {
+	f.created = append(f.created, indexName)
+	return nil
+}
+
+func (f *fakeIndexAdmin) DeleteIndex(ctx context.Context, indexNames ...string) error {
+	f.deleted = append(f.deleted, indexNames)
+	return nil
+}
+
+func (f *fakeIndexAdmin) IndexExists(ctx context.Context, indexName string) (bool, error) {
+	return f.existing[indexName], nil
+}
+
+func (f *fakeIndexAdmin) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	return f.aliasIndices, nil
+}
+
+func (f *fakeIndexAdmin) UpdateAliases(ctx context.Context, actions []opensearch.AliasAction) error {
+	f.aliasActions = actions
+	return nil
+}
+
+func (f *fakeIndexAdmin) ScrollDocuments(ctx context.Context, indices []string, batchSize int, fn func([]opensearch.Document) error) error {
+	f.scrolled = append(f.scrolled, indices)
+	for _, index := range indices {
+		if err := fn(f.documents[index]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakeIndexAdmin) BulkIndex(ctx context.Context, indexName string, documents []opensearch.Document) error {
+	f.indexed[indexName] = append(f.indexed[indexName], documents...)
+	return nil
+}
+
+func (f *fakeIndexAdmin) Refresh(ctx context.Context, indexName string) error {
+	return nil
+}
+
+func (f *fakeIndexAdmin) CreateDocument(ctx context.Context, indexName string, documentID string, body []byte) (bool, error) {
+	key := indexName + "/" + documentID
+	if f.lockedBy != nil {
+		f.lockedBy(f)
+		f.lockedBy = nil
+		return false, nil
+	}
+	if f.locks[key] {
+		return false, nil
+	}
+	f.locks[key] = true
+	return true, nil
+}
+
+func (f *fakeIndexAdmin) DeleteDocument(ctx context.Context, indexName string, documentID string) error {
+	delete(f.locks, indexName+"/"+documentID)
+	return nil
+}
+
+func TestProductIndexTemplate(t *testing.T) {
+	body, err := opensearch.ProductIndexTemplate("products")
+	require.NoError(t, err)
+
+	var template struct {
+		IndexPatterns []string `json:"index_patterns"`
+		Version       int      `json:"version"`
+		Template      struct {
+			Mappings struct {
+				Properties map[string]struct {
+					Type          string         `json:"type"`
+					ScalingFactor int            `json:"scaling_factor"`
+					Fields        map[string]any `json:"fields"`
+				} `json:"properties"`
+			} `json:"mappings"`
+		} `json:"template"`
+	}
+	require.NoError(t, json.Unmarshal(body, &template))
+
+	assert.Equal(t, []string{"products_v*"}, template.IndexPatterns)
+	assert.Equal(t, opensearch.ProductIndexVersion, template.Version)
+	properties := template.Template.Mappings.Properties
+	assert.Equal(t, "scaled_float", properties["price"].Type)
+	assert.Equal(t, 100, properties["price"].ScalingFactor)
+	assert.Equal(t, "integer", properties["stockQuantity"].Type)
+	assert.Equal(t, "keyword", properties["categoryId"].Type)
+	assert.Equal(t, "text", properties["categoryName"].Type)
+	assert.Contains(t, properties["categoryName"].Fields, "keyword")
+	assert.Equal(t, "text", properties["name"].Type)
+	assert.Contains(t, properties["name"].Fields, "keyword")
+	assert.Equal(t, "completion", properties["suggest"].Type)
+}
+
+func TestProductIndexVersionOf(t *testing.T) {
+	tests := []struct {
+		index   string
+		version int
+		ok      bool
+	}{
+		{index: "products_v1", version: 1, ok: true},
+		{index: "products_v12_20251018120000", version: 12, ok: true},
+		{index: "products", ok: false},
+		{index: "products_20251018120000", ok: false},
+		{index: "orders_v1", ok: false},
+	}
+
+	for _, tt := range tests {
+		version, ok := opensearch.ProductIndexVersionOf("products", tt.index)
+		assert.Equal(t, tt.ok, ok, tt.index)
+		assert.Equal(t, tt.version, version, tt.index)
+	}
+}
+
+func TestMigrateProductIndex(t *testing.T) {
+	logger := logs.NewSlogLogger()
+	target := opensearch.ProductIndexName("products", opensearch.ProductIndexVersion)
+
+	t.Run("Creates Index And Alias", func(t *testing.T) {
+		admin := newFakeIndexAdmin()
+
+		index, err := opensearch.MigrateProductIndex(context.Background(), logger, admin, "products")
+
+		require.NoError(t, err)
+		assert.Equal(t, target, index)
+		assert.Contains(t, admin.templates, "products_template")
+		assert.Equal(t, []string{target}, admin.created)
+		assert.Empty(t, admin.scrolled)
+		assert.Equal(t, []opensearch.AliasAction{
+			{Add: &opensearch.AliasTarget{Index: target, Alias: "products", IsWriteIndex: true}},
+		}, admin.aliasActions)
+	})
+
+	t.Run("Replaces Legacy Concrete Index", func(t *testing.T) {
+		admin := newFakeIndexAdmin()
+		admin.existing["products"] = true
+		admin.documents["products"] = []opensearch.Document{
+			{ID: "p1", Body: []byte(`{"id":"p1","name":"MacBook Pro","price":"10.00","stockQuantity":3}`)},
+		}
+
+		_, err := opensearch.MigrateProductIndex(context.Background(), logger, admin, "products")
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"products"}}, admin.scrolled)
+		require.Len(t, admin.indexed[target], 1)
+		assert.Equal(t, "p1", admin.indexed[target][0].ID)
+		assert.JSONEq(t, `{"id":"p1","categoryId":"","name":"MacBook Pro","description":"","price":"10.00","stockQuantity":3,"suggest":{"input":["MacBook Pro","Pro"],"weight":2}}`, string(admin.indexed[target][0].Body))
+		assert.Equal(t, []opensearch.AliasAction{
+			{RemoveIndex: &opensearch.IndexTarget{Index: "products"}},
+			{Add: &opensearch.AliasTarget{Index: target, Alias: "products", IsWriteIndex: true}},
+		}, admin.aliasActions)
+		assert.Empty(t, admin.deleted)
+	})
+
+	t.Run("Upgrades Older Version", func(t *testing.T) {
+		admin := newFakeIndexAdmin()
+		admin.aliasIndices = []string{"products_v1_20251018120000"}
+		admin.existing[target] = true
+
+		_, err := opensearch.MigrateProductIndex(context.Background(), logger, admin, "products")
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{target}, {"products_v1_20251018120000"}}, admin.deleted)
+		assert.Equal(t, [][]string{{"products_v1_20251018120000"}}, admin.scrolled)
+		assert.Equal(t, []opensearch.AliasAction{
+			{Remove: &opensearch.AliasTarget{Index: "products_v1_20251018120000", Alias: "products"}},
+			{Add: &opensearch.AliasTarget{Index: target, Alias: "products", IsWriteIndex: true}},
+		}, admin.aliasActions)
+	})
+
+	t.Run("Up To Date", func(t *testing.T) {
+		admin := newFakeIndexAdmin()
+		admin.aliasIndices = []string{target + "_20251018120000"}
+
+		index, err := opensearch.MigrateProductIndex(context.Background(), logger, admin, "products")
+
+		require.NoError(t, err)
+		assert.Equal(t, target+"_20251018120000", index)
+		assert.Empty(t, admin.created)
+		assert.Nil(t, admin.aliasActions)
+	})
+	t.Run("Releases Migration Lock", func(t *testing.T) {
+		admin := newFakeIndexAdmin()
+
+		_, err := opensearch.MigrateProductIndex(context.Background(), logger, admin, "products")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{target}, admin.created)
+		assert.Empty(t, admin.locks)
+	})
+
+	t.Run("Waits For Another Instance", func(t *testing.T) {
+		admin := newFakeIndexAdmin()
+		admin.aliasIndices = []string{"products_v1"}
+		admin.existing[target] = true
+		// Another instance holds the lock and moves the alias while this one
+		// tries to take it.
+		admin.lockedBy = func(f *fakeIndexAdmin) {
+			f.aliasIndices = []string{target}
+		}
+
+		index, err := opensearch.MigrateProductIndex(context.Background(), logger, admin, "products")
+
+		require.NoError(t, err)
+		assert.Equal(t, target, index)
+		assert.Empty(t, admin.created)
+		assert.Empty(t, admin.deleted)
+		assert.Nil(t, admin.aliasActions)
+	})
+
+	t.Run("Stops Waiting When Canceled", func(t *testing.T) {
+		admin := newFakeIndexAdmin()
+		admin.aliasIndices = []string{"products_v1"}
+		admin.locks[opensearch.ProductIndexMigrationLockIndex("products")+"/"+target] = true
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := opensearch.MigrateProductIndex(ctx, logger, admin, "products")
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, admin.created)
+		assert.Empty(t, admin.deleted)
+	})
+}
@@ -0,0 +1,87 @@
+package reindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	product_categoriesv1 "github.com/sonuudigital/microservices/gen/product-categories/v1"
+	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
+	"github.com/sonuudigital/microservices/shared/events"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCProductSource reads products with the ProductService.StreamProducts
+// server stream and adds the category names, which the stream does not
+// carry, from ProductCategoriesService.GetProductCategories.
+type GRPCProductSource struct {
+	client           productv1.ProductServiceClient
+	categoriesClient product_categoriesv1.ProductCategoriesServiceClient
+	batchSize        int32
+}
+
+func NewGRPCProductSource(client productv1.ProductServiceClient, categoriesClient product_categoriesv1.ProductCategoriesServiceClient, batchSize int32) *GRPCProductSource {
+	return &GRPCProductSource{
+		client:           client,
+		categoriesClient: categoriesClient,
+		batchSize:        batchSize,
+	}
+}
+
+func (s *GRPCProductSource) StreamProducts(ctx context.Context, updatedSince time.Time, fn func(events.Product) error) error {
+	req := &productv1.StreamProductsRequest{BatchSize: s.batchSize}
+	if !updatedSince.IsZero() {
+		req.UpdatedSince = timestamppb.New(updatedSince)
+	}
+
+	categories, err := s.categoriesClient.GetProductCategories(ctx, &emptypb.Empty{})
+	if err != nil {
+		return fmt.Errorf("failed to get product categories: %w", err)
+	}
+	categoryNames := make(map[string]string, len(categories.Categories))
+	for _, category := range categories.Categories {
+		categoryNames[category.Id] = category.Name
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := s.client.StreamProducts(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to open product stream: %w", err)
+	}
+
+	for {
+		product, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to receive product: %w", err)
+		}
+
+		event := toProductEvent(product)
+		event.CategoryName = categoryNames[event.CategoryID]
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+}
+
+// toProductEvent converts a product to the document the product events
+// consumer indexes, so a rebuilt index matches one built from events.
+func toProductEvent(p *productv1.Product) events.Product {
+	return events.Product{
+		ID:            p.Id,
+		CategoryID:    p.CategoryId,
+		Name:          p.Name,
+		Description:   p.Description,
+		Price:         strconv.FormatFloat(p.Price, 'f', 2, 64),
+		StockQuantity: p.StockQuantity,
+		CreatedAt:     p.CreatedAt.AsTime(),
+	}
+}
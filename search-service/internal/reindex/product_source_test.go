@@ -0,0 +1,72 @@
+package reindex_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	product_categoriesv1 "github.com/sonuudigital/microservices/gen/product-categories/v1"
+	productv1 "github.com/sonuudigital/microservices/gen/product/v1"
+	"github.com/sonuudigital/microservices/search-service/internal/reindex"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeProductClient struct {
+	productv1.ProductServiceClient
+	products []*productv1.Product
+}
+
+func (f *fakeProductClient) StreamProducts(ctx context.Context, in *productv1.StreamProductsRequest, opts ...grpc.CallOption) (productv1.ProductService_StreamProductsClient, error) {
+	return &fakeProductStream{products: f.products}, nil
+}
+
+type fakeProductStream struct {
+	grpc.ClientStream
+	products []*productv1.Product
+}
+
+func (f *fakeProductStream) Recv() (*productv1.Product, error) {
+	if len(f.products) == 0 {
+		return nil, io.EOF
+	}
+	product := f.products[0]
+	f.products = f.products[1:]
+	return product, nil
+}
+
+type fakeCategoriesClient struct {
+	product_categoriesv1.ProductCategoriesServiceClient
+	categories []*product_categoriesv1.ProductCategory
+}
+
+func (f *fakeCategoriesClient) GetProductCategories(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*product_categoriesv1.GetProductCategoriesResponse, error) {
+	return &product_categoriesv1.GetProductCategoriesResponse{Categories: f.categories}, nil
+}
+
+func TestGRPCProductSourceAddsCategoryNames(t *testing.T) {
+	products := &fakeProductClient{products: []*productv1.Product{
+		{Id: "p1", CategoryId: "c1", Name: "Studio Monitor", Price: 199.9, StockQuantity: 2, CreatedAt: timestamppb.Now()},
+		{Id: "p2", Name: "Gift Card", Price: 25, CreatedAt: timestamppb.Now()},
+	}}
+	categories := &fakeCategoriesClient{categories: []*product_categoriesv1.ProductCategory{
+		{Id: "c1", Name: "Pro Audio"},
+	}}
+
+	var streamed []events.Product
+	err := reindex.NewGRPCProductSource(products, categories, 100).StreamProducts(context.Background(), time.Time{}, func(p events.Product) error {
+		streamed = append(streamed, p)
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, streamed, 2)
+	assert.Equal(t, "Pro Audio", streamed[0].CategoryName)
+	assert.Equal(t, "199.90", streamed[0].Price)
+	assert.Empty(t, streamed[1].CategoryName)
+}
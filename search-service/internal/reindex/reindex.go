@@ -0,0 +1,250 @@
+package reindex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sonuudigital/microservices/search-service/internal/opensearch"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	DefaultBatchSize = 500
+
+	// catchUpMargin is subtracted from the start of the rebuild for the
+	// catch-up pass, so clock skew between the services cannot hide a change.
+	catchUpMargin = time.Minute
+)
+
+// ProductSource streams products from product-service. A zero updatedSince
+// streams every product.
+type ProductSource interface {
+	StreamProducts(ctx context.Context, updatedSince time.Time, fn func(events.Product) error) error
+}
+
+type IndexStore interface {
+	CreateIndex(ctx context.Context, indexName string, body []byte) error
+	DeleteIndex(ctx context.Context, indexNames ...string) error
+	IndexExists(ctx context.Context, indexName string) (bool, error)
+	Refresh(ctx context.Context, indexName string) error
+	AliasIndices(ctx context.Context, alias string) ([]string, error)
+	UpdateAliases(ctx context.Context, actions []opensearch.AliasAction) error
+	BulkIndex(ctx context.Context, indexName string, documents []opensearch.Document) error
+	BulkDelete(ctx context.Context, indexName string, documentIDs []string) error
+	ScrollDocuments(ctx context.Context, indices []string, batchSize int, fn func([]opensearch.Document) error) error
+}
+
+type Result struct {
+	Index          string
+	Indexed        int
+	CaughtUp       int
+	Pruned         int
+	RemovedIndices []string
+}
+
+// Reindexer rebuilds the product index behind an alias: it fills a new index
+// of the current version (created with the settings and mappings of the
+// product index template) from a product-service snapshot and then points
+// the alias at it in a single _aliases request, so searches and the product
+// events consumer, which both use the alias, never see a missing or
+// half-filled index.
+type Reindexer struct {
+	logger    logs.Logger
+	source    ProductSource
+	store     IndexStore
+	alias     string
+	batchSize int
+	keepOld   bool
+	now       func() time.Time
+}
+
+func NewReindexer(logger logs.Logger, source ProductSource, store IndexStore, alias string, batchSize int, keepOld bool) *Reindexer {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Reindexer{
+		logger:    logger,
+		source:    source,
+		store:     store,
+		alias:     alias,
+		batchSize: batchSize,
+		keepOld:   keepOld,
+		now:       time.Now,
+	}
+}
+
+// Run builds the new index, swaps the alias, replays the products that
+// changed while the snapshot was read, removes the products deleted since
+// then and, unless the old indices are kept, deletes the old indices. A
+// failure before the swap deletes the new index and leaves the alias
+// untouched.
+func (r *Reindexer) Run(ctx context.Context) (Result, error) {
+	startedAt := r.now().UTC()
+	versionedIndex := opensearch.ProductIndexName(r.alias, opensearch.ProductIndexVersion)
+	result := Result{Index: fmt.Sprintf("%s_%s", versionedIndex, startedAt.Format("20060102150405"))}
+
+	if err := r.store.CreateIndex(ctx, result.Index, nil); err != nil {
+		return result, err
+	}
+	r.logger.Info("created index", "index", result.Index)
+
+	indexed, err := r.fill(ctx, result.Index, time.Time{})
+	if err == nil {
+		err = r.store.Refresh(ctx, result.Index)
+	}
+	if err != nil {
+		r.dropIndex(result.Index)
+		return result, fmt.Errorf("failed to fill index %s: %w", result.Index, err)
+	}
+	result.Indexed = indexed
+	r.logger.Info("indexed product snapshot", "index", result.Index, "count", indexed)
+
+	oldIndices, err := r.swapAlias(ctx, result.Index)
+	if err != nil {
+		r.dropIndex(result.Index)
+		return result, err
+	}
+	r.logger.Info("alias swapped", "alias", r.alias, "index", result.Index, "previous", oldIndices)
+
+	caughtUp, err := r.fill(ctx, result.Index, startedAt.Add(-catchUpMargin))
+	if err != nil {
+		return result, fmt.Errorf("failed to replay products changed during the rebuild: %w", err)
+	}
+	result.CaughtUp = caughtUp
+
+	pruned, err := r.prune(ctx, result.Index)
+	if err != nil {
+		return result, fmt.Errorf("failed to remove products deleted during the rebuild: %w", err)
+	}
+	result.Pruned = pruned
+
+	if r.keepOld || len(oldIndices) == 0 {
+		return result, nil
+	}
+	if err := r.store.DeleteIndex(ctx, oldIndices...); err != nil {
+		return result, fmt.Errorf("failed to delete previous indices: %w", err)
+	}
+	result.RemovedIndices = oldIndices
+	return result, nil
+}
+
+func (r *Reindexer) fill(ctx context.Context, indexName string, updatedSince time.Time) (int, error) {
+	var (
+		batch   []opensearch.Document
+		indexed int
+	)
+	flush := func() error {
+		if err := r.store.BulkIndex(ctx, indexName, batch); err != nil {
+			return err
+		}
+		indexed += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := r.source.StreamProducts(ctx, updatedSince, func(product events.Product) error {
+		body, err := json.Marshal(opensearch.NewProductDocument(product))
+		if err != nil {
+			return fmt.Errorf("failed to marshal product %s: %w", product.ID, err)
+		}
+		batch = append(batch, opensearch.Document{ID: product.ID, Body: body})
+		if len(batch) < r.batchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return indexed, err
+	}
+	return indexed, flush()
+}
+
+// prune deletes the documents of products that were deleted after the
+// snapshot read them. Their delete events went to the previous index, and the
+// catch-up pass only streams products that still exist, so the index is
+// compared with the current product IDs instead. The index is read before
+// the products, so a product created in between is never removed; deletes
+// after the swap are applied by the product events consumer as well.
+func (r *Reindexer) prune(ctx context.Context, indexName string) (int, error) {
+	stale := make(map[string]struct{})
+	err := r.store.ScrollDocuments(ctx, []string{indexName}, r.batchSize, func(documents []opensearch.Document) error {
+		for _, doc := range documents {
+			stale[doc.ID] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.source.StreamProducts(ctx, time.Time{}, func(product events.Product) error {
+		delete(stale, product.ID)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(stale))
+	for id := range stale {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for start := 0; start < len(ids); start += r.batchSize {
+		end := min(start+r.batchSize, len(ids))
+		if err := r.store.BulkDelete(ctx, indexName, ids[start:end]); err != nil {
+			return start, err
+		}
+	}
+	r.logger.Info("removed deleted products", "index", indexName, "count", len(ids))
+	return len(ids), nil
+}
+
+// swapAlias points the alias at newIndex and returns the indices it pointed
+// to before. An existing concrete index with the alias name, created before
+// the alias was introduced, is removed in the same request.
+func (r *Reindexer) swapAlias(ctx context.Context, newIndex string) ([]string, error) {
+	oldIndices, err := r.store.AliasIndices(ctx, r.alias)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]opensearch.AliasAction, 0, len(oldIndices)+1)
+	for _, index := range oldIndices {
+		actions = append(actions, opensearch.AliasAction{Remove: &opensearch.AliasTarget{Index: index, Alias: r.alias}})
+	}
+
+	if len(oldIndices) == 0 {
+		exists, err := r.store.IndexExists(ctx, r.alias)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			r.logger.Warn("replacing concrete index with alias", "index", r.alias)
+			actions = append(actions, opensearch.AliasAction{RemoveIndex: &opensearch.IndexTarget{Index: r.alias}})
+		}
+	}
+
+	actions = append(actions, opensearch.AliasAction{Add: &opensearch.AliasTarget{Index: newIndex, Alias: r.alias, IsWriteIndex: true}})
+	if err := r.store.UpdateAliases(ctx, actions); err != nil {
+		return nil, fmt.Errorf("failed to swap alias %s: %w", r.alias, err)
+	}
+	return oldIndices, nil
+}
+
+func (r *Reindexer) dropIndex(indexName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := r.store.DeleteIndex(ctx, indexName); err != nil {
+		r.logger.Error("failed to delete unused index", "index", indexName, "error", err)
+	}
+}
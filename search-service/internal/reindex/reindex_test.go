@@ -0,0 +1,237 @@
+package reindex_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/search-service/internal/opensearch"
+	"github.com/sonuudigital/microservices/search-service/internal/reindex"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource struct {
+	snapshot     []events.Product
+	changed      []events.Product
+	updatedSince []time.Time
+	err          error
+}
+
+func (f *fakeSource) StreamProducts(ctx context.Context, updatedSince time.Time, fn func(events.Product) error) error {
+	f.updatedSince = append(f.updatedSince, updatedSince)
+	if f.err != nil {
+		return f.err
+	}
+
+	products := f.snapshot
+	if !updatedSince.IsZero() {
+		products = f.changed
+	}
+	for _, p := range products {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletingSource deletes products from the snapshot once it has been read.
+type deletingSource struct {
+	*fakeSource
+	deleted []string
+}
+
+func (d *deletingSource) StreamProducts(ctx context.Context, updatedSince time.Time, fn func(events.Product) error) error {
+	if err := d.fakeSource.StreamProducts(ctx, updatedSince, fn); err != nil {
+		return err
+	}
+	if len(d.deleted) == 0 {
+		return nil
+	}
+
+	remaining := d.snapshot[:0:0]
+	for _, p := range d.snapshot {
+		if !slices.Contains(d.deleted, p.ID) {
+			remaining = append(remaining, p)
+		}
+	}
+	d.snapshot = remaining
+	d.deleted = nil
+	return nil
+}
+
+type fakeStore struct {
+	created       []string
+	deleted       [][]string
+	bulks         map[string][]int
+	aliasIndices  []string
+	concreteIndex bool
+	aliasActions  []opensearch.AliasAction
+	swapErr       error
+	documents     map[string][]string
+	bulkDeletes   [][]string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{bulks: map[string][]int{}, documents: map[string][]string{}}
+}
+
+func (f *fakeStore) CreateIndex(ctx context.Context, indexName string, body []byte) error {
+	f.created = append(f.created, indexName)
+	return nil
+}
+
+func (f *fakeStore) DeleteIndex(ctx context.Context, indexNames ...string) error {
+	f.deleted = append(f.deleted, indexNames)
+	return nil
+}
+
+func (f *fakeStore) IndexExists(ctx context.Context, indexName string) (bool, error) {
+	return f.concreteIndex, nil
+}
+
+func (f *fakeStore) Refresh(ctx context.Context, indexName string) error {
+	return nil
+}
+
+func (f *fakeStore) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	return f.aliasIndices, nil
+}
+
+func (f *fakeStore) UpdateAliases(ctx context.Context, actions []opensearch.AliasAction) error {
+	if f.swapErr != nil {
+		return f.swapErr
+	}
+	f.aliasActions = actions
+	return nil
+}
+
+func (f *fakeStore) BulkIndex(ctx context.Context, indexName string, documents []opensearch.Document) error {
+	if len(documents) > 0 {
+		f.bulks[indexName] = append(f.bulks[indexName], len(documents))
+	}
+	for _, doc := range documents {
+		f.documents[indexName] = append(f.documents[indexName], doc.ID)
+	}
+	return nil
+}
+
+func (f *fakeStore) BulkDelete(ctx context.Context, indexName string, documentIDs []string) error {
+	f.bulkDeletes = append(f.bulkDeletes, append([]string(nil), documentIDs...))
+	return nil
+}
+
+func (f *fakeStore) ScrollDocuments(ctx context.Context, indices []string, batchSize int, fn func([]opensearch.Document) error) error {
+	var documents []opensearch.Document
+	for _, index := range indices {
+		for _, id := range f.documents[index] {
+			documents = append(documents, opensearch.Document{ID: id})
+		}
+	}
+	return fn(documents)
+}
+
+func products(n int) []events.Product {
+	result := make([]events.Product, n)
+	for i := range result {
+		result[i] = events.Product{ID: strings.Repeat("p", i+1), Name: "Product"}
+	}
+	return result
+}
+
+func TestReindexerRun(t *testing.T) {
+	t.Run("Swaps Alias From Previous Index", func(t *testing.T) {
+		source := &fakeSource{snapshot: products(5), changed: products(1)}
+		store := newFakeStore()
+		store.aliasIndices = []string{"products_20250101000000"}
+
+		result, err := reindex.NewReindexer(logs.NewSlogLogger(), source, store, "products", 2, false).Run(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, store.created, 1)
+		assert.Equal(t, store.created[0], result.Index)
+		assert.True(t, strings.HasPrefix(result.Index, opensearch.ProductIndexName("products", opensearch.ProductIndexVersion)+"_"))
+		assert.Equal(t, 5, result.Indexed)
+		assert.Equal(t, 1, result.CaughtUp)
+		assert.Equal(t, []int{2, 2, 1, 1}, store.bulks[result.Index])
+
+		assert.Equal(t, []opensearch.AliasAction{
+			{Remove: &opensearch.AliasTarget{Index: "products_20250101000000", Alias: "products"}},
+			{Add: &opensearch.AliasTarget{Index: result.Index, Alias: "products", IsWriteIndex: true}},
+		}, store.aliasActions)
+		assert.Equal(t, [][]string{{"products_20250101000000"}}, store.deleted)
+		assert.Equal(t, []string{"products_20250101000000"}, result.RemovedIndices)
+
+		require.Len(t, source.updatedSince, 3)
+		assert.True(t, source.updatedSince[0].IsZero())
+		assert.False(t, source.updatedSince[1].IsZero())
+		assert.True(t, source.updatedSince[2].IsZero())
+		assert.Zero(t, result.Pruned)
+		assert.Empty(t, store.bulkDeletes)
+	})
+
+	t.Run("Removes Products Deleted During Rebuild", func(t *testing.T) {
+		source := &fakeSource{snapshot: products(5)}
+		store := newFakeStore()
+		deleteAfterSnapshot := &deletingSource{fakeSource: source, deleted: []string{"pp", "pppp"}}
+
+		result, err := reindex.NewReindexer(logs.NewSlogLogger(), deleteAfterSnapshot, store, "products", 10, false).Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, result.Indexed)
+		assert.Equal(t, 2, result.Pruned)
+		assert.Equal(t, [][]string{{"pp", "pppp"}}, store.bulkDeletes)
+	})
+
+	t.Run("Replaces Concrete Index", func(t *testing.T) {
+		store := newFakeStore()
+		store.concreteIndex = true
+
+		result, err := reindex.NewReindexer(logs.NewSlogLogger(), &fakeSource{snapshot: products(1)}, store, "products", 10, false).Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []opensearch.AliasAction{
+			{RemoveIndex: &opensearch.IndexTarget{Index: "products"}},
+			{Add: &opensearch.AliasTarget{Index: result.Index, Alias: "products", IsWriteIndex: true}},
+		}, store.aliasActions)
+		assert.Empty(t, store.deleted)
+	})
+
+	t.Run("Keeps Previous Index", func(t *testing.T) {
+		store := newFakeStore()
+		store.aliasIndices = []string{"products_old"}
+
+		result, err := reindex.NewReindexer(logs.NewSlogLogger(), &fakeSource{}, store, "products", 10, true).Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Empty(t, store.deleted)
+		assert.Empty(t, result.RemovedIndices)
+	})
+
+	t.Run("Snapshot Failure Drops New Index", func(t *testing.T) {
+		store := newFakeStore()
+		source := &fakeSource{err: errors.New("product-service unavailable")}
+
+		result, err := reindex.NewReindexer(logs.NewSlogLogger(), source, store, "products", 10, false).Run(context.Background())
+
+		require.Error(t, err)
+		assert.Nil(t, store.aliasActions)
+		assert.Equal(t, [][]string{{result.Index}}, store.deleted)
+	})
+
+	t.Run("Swap Failure Drops New Index", func(t *testing.T) {
+		store := newFakeStore()
+		store.swapErr = errors.New("alias conflict")
+
+		result, err := reindex.NewReindexer(logs.NewSlogLogger(), &fakeSource{snapshot: products(1)}, store, "products", 10, false).Run(context.Background())
+
+		require.ErrorContains(t, err, "alias conflict")
+		assert.Equal(t, [][]string{{result.Index}}, store.deleted)
+	})
+}
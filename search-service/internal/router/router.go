@@ -5,42 +5,52 @@ import (
 	"net/http"
 
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/tracing"
 )
 
 var (
 	ErrLoggerIsNil         = errors.New("logger is nil")
 	ErrProductHandlerIsNil = errors.New("product handler is nil")
+	ErrReadinessHandlerNil = errors.New("readiness handler is nil")
 )
 
 type ProductHandler interface {
 	SearchProduct(w http.ResponseWriter, r *http.Request)
+	SuggestProducts(w http.ResponseWriter, r *http.Request)
 }
 
 type Router struct {
-	logger         logs.Logger
-	productHandler ProductHandler
-	mux            *http.ServeMux
+	logger           logs.Logger
+	productHandler   ProductHandler
+	readinessHandler http.Handler
+	mux              *http.ServeMux
+	handler          http.Handler
 }
 
-func New(logger logs.Logger, productHandler ProductHandler) (*Router, error) {
+func New(logger logs.Logger, productHandler ProductHandler, readinessHandler http.Handler) (*Router, error) {
 	if logger == nil {
 		return nil, ErrLoggerIsNil
 	}
 	if productHandler == nil {
 		return nil, ErrProductHandlerIsNil
 	}
+	if readinessHandler == nil {
+		return nil, ErrReadinessHandlerNil
+	}
 
 	r := &Router{
-		logger:         logger,
-		productHandler: productHandler,
-		mux:            http.NewServeMux(),
+		logger:           logger,
+		productHandler:   productHandler,
+		readinessHandler: readinessHandler,
+		mux:              http.NewServeMux(),
 	}
 	r.setupRoutes()
+	r.handler = tracing.RouteSpanName(r.mux)
 	return r, nil
 }
 
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	r.mux.ServeHTTP(w, req)
+	r.handler.ServeHTTP(w, req)
 }
 
 func (r *Router) setupRoutes() {
@@ -48,5 +58,7 @@ func (r *Router) setupRoutes() {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("search service is healthy"))
 	})
+	r.mux.Handle("/api/readyz", r.readinessHandler)
 	r.mux.HandleFunc("/api/search/products", r.productHandler.SearchProduct)
+	r.mux.HandleFunc("/api/search/suggest", r.productHandler.SuggestProducts)
 }
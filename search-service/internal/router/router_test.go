@@ -19,39 +19,58 @@ func (m *MockProductHandler) SearchProduct(w http.ResponseWriter, r *http.Reques
 	m.Called(w, r)
 }
 
+func (m *MockProductHandler) SuggestProducts(w http.ResponseWriter, r *http.Request) {
+	m.Called(w, r)
+}
+
+var readinessHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
 func TestNew(t *testing.T) {
 	logger := logs.NewSlogLogger()
 	mockHandler := new(MockProductHandler)
 
 	tests := []struct {
-		name           string
-		logger         logs.Logger
-		productHandler router.ProductHandler
-		expectedError  error
+		name             string
+		logger           logs.Logger
+		productHandler   router.ProductHandler
+		readinessHandler http.Handler
+		expectedError    error
 	}{
 		{
-			name:           "Success",
-			logger:         logger,
-			productHandler: mockHandler,
-			expectedError:  nil,
+			name:             "Success",
+			logger:           logger,
+			productHandler:   mockHandler,
+			readinessHandler: readinessHandler,
+			expectedError:    nil,
 		},
 		{
-			name:           "NilLogger",
-			logger:         nil,
-			productHandler: mockHandler,
-			expectedError:  router.ErrLoggerIsNil,
+			name:             "NilLogger",
+			logger:           nil,
+			productHandler:   mockHandler,
+			readinessHandler: readinessHandler,
+			expectedError:    router.ErrLoggerIsNil,
 		},
 		{
-			name:           "NilProductHandler",
-			logger:         logger,
-			productHandler: nil,
-			expectedError:  router.ErrProductHandlerIsNil,
+			name:             "NilProductHandler",
+			logger:           logger,
+			productHandler:   nil,
+			readinessHandler: readinessHandler,
+			expectedError:    router.ErrProductHandlerIsNil,
+		},
+		{
+			name:             "NilReadinessHandler",
+			logger:           logger,
+			productHandler:   mockHandler,
+			readinessHandler: nil,
+			expectedError:    router.ErrReadinessHandlerNil,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r, err := router.New(tt.logger, tt.productHandler)
+			r, err := router.New(tt.logger, tt.productHandler, tt.readinessHandler)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -69,7 +88,7 @@ func TestRouterServeHTTPHealthzEndpoint(t *testing.T) {
 	logger := logs.NewSlogLogger()
 	mockHandler := new(MockProductHandler)
 
-	r, err := router.New(logger, mockHandler)
+	r, err := router.New(logger, mockHandler, readinessHandler)
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 
@@ -82,6 +101,21 @@ func TestRouterServeHTTPHealthzEndpoint(t *testing.T) {
 	assert.Equal(t, "search service is healthy", rr.Body.String())
 }
 
+func TestRouterServeHTTPReadyzEndpoint(t *testing.T) {
+	logger := logs.NewSlogLogger()
+	mockHandler := new(MockProductHandler)
+
+	r, err := router.New(logger, mockHandler, readinessHandler)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/readyz", nil)
+	rr := httptest.NewRecorder()
+
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
 func TestRouterServeHTTPSearchProductEndpoint(t *testing.T) {
 	logger := logs.NewSlogLogger()
 
@@ -118,6 +152,20 @@ func TestRouterServeHTTPSearchProductEndpoint(t *testing.T) {
 			},
 			expectedStatusCode: http.StatusOK,
 		},
+		{
+			name:          "SuggestProductsCalled",
+			requestPath:   "/api/search/suggest?q=lap",
+			requestMethod: http.MethodGet,
+			setupMock: func(m *MockProductHandler) {
+				m.On("SuggestProducts", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					w := args.Get(0).(http.ResponseWriter)
+					r := args.Get(1).(*http.Request)
+					assert.Equal(t, "lap", r.URL.Query().Get("q"))
+					w.WriteHeader(http.StatusOK)
+				}).Once()
+			},
+			expectedStatusCode: http.StatusOK,
+		},
 	}
 
 	for _, tt := range tests {
@@ -125,7 +173,7 @@ func TestRouterServeHTTPSearchProductEndpoint(t *testing.T) {
 			mockHandler := new(MockProductHandler)
 			tt.setupMock(mockHandler)
 
-			r, err := router.New(logger, mockHandler)
+			r, err := router.New(logger, mockHandler, readinessHandler)
 			assert.NoError(t, err)
 
 			req := httptest.NewRequest(tt.requestMethod, tt.requestPath, nil)
@@ -143,7 +191,7 @@ func TestRouterServeHTTPNotFoundEndpoint(t *testing.T) {
 	logger := logs.NewSlogLogger()
 	mockHandler := new(MockProductHandler)
 
-	r, err := router.New(logger, mockHandler)
+	r, err := router.New(logger, mockHandler, readinessHandler)
 	assert.NoError(t, err)
 	assert.NotNil(t, r)
 
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// identityAdminAudience keeps the identity tokens the gateway signs for
+	// users from being accepted by the admin endpoints.
+	identityAdminAudience = "internal-admin"
+	// AdminMetadataKey carries an admin token on gRPC calls made by an
+	// operator.
+	AdminMetadataKey     = "x-internal-admin"
+	DefaultAdminTokenTTL = 15 * time.Minute
+	bearerPrefix         = "Bearer "
+)
+
+var ErrMissingOperator = errors.New("admin token has no operator")
+
+type operatorContextKey struct{}
+
+func ContextWithOperator(ctx context.Context, operator string) context.Context {
+	return context.WithValue(ctx, operatorContextKey{}, operator)
+}
+
+func OperatorFromContext(ctx context.Context) (string, bool) {
+	operator, ok := ctx.Value(operatorContextKey{}).(string)
+	return operator, ok && operator != ""
+}
+
+// RequireOperator returns the operator of the admin token the call was made
+// with. Calls made for a user are denied.
+func RequireOperator(ctx context.Context) (string, error) {
+	operator, ok := OperatorFromContext(ctx)
+	if ok {
+		return operator, nil
+	}
+	if _, ok := PrincipalFromContext(ctx); ok {
+		return "", status.Error(codes.PermissionDenied, "operation requires an admin token")
+	}
+	return "", status.Error(codes.Unauthenticated, "admin token not found in context")
+}
+
+// SignAdmin signs a token for the admin endpoints of the services. The
+// operator is logged with every admin request made with it.
+func (s *IdentityTokenSigner) SignAdmin(operator string, ttl time.Duration) (string, error) {
+	if operator == "" {
+		return "", ErrMissingOperator
+	}
+	return s.sign(operator, "", identityAdminAudience, ttl)
+}
+
+// VerifyAdmin verifies a token signed with SignAdmin and returns its
+// operator.
+func (v *IdentityTokenVerifier) VerifyAdmin(tokenString string) (string, error) {
+	claims, err := v.parse(tokenString, identityAdminAudience)
+	if err != nil {
+		return "", err
+	}
+	if claims.Subject == "" {
+		return "", ErrMissingOperator
+	}
+	return claims.Subject, nil
+}
+
+// RequireAdmin only lets requests with an admin token in the Authorization
+// header through to next.
+func RequireAdmin(verifier *IdentityTokenVerifier, logger logs.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix)
+			if !ok || token == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "missing admin token", http.StatusUnauthorized)
+				return
+			}
+
+			operator, err := verifier.VerifyAdmin(token)
+			if err != nil {
+				logger.Warn("rejected admin request", "method", r.Method, "path", r.URL.Path, "error", err)
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "invalid admin token", http.StatusUnauthorized)
+				return
+			}
+
+			logger.Info("admin request", "operator", operator, "method", r.Method, "path", r.URL.Path)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LoadAdminAuthFromEnv returns RequireAdmin with the identity token public key
+// of INTERNAL_TOKEN_PUBLIC_KEY_PATH, or nil when it is not set.
+func LoadAdminAuthFromEnv(logger logs.Logger) (func(http.Handler) http.Handler, error) {
+	if os.Getenv("INTERNAL_TOKEN_PUBLIC_KEY_PATH") == "" {
+		return nil, nil
+	}
+
+	verifier, err := LoadIdentityTokenVerifierFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin token verifier: %w", err)
+	}
+	return RequireAdmin(verifier, logger), nil
+}
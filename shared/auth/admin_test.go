@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	signer, verifier := newTestIdentityKeys(t)
+	otherSigner, _ := newTestIdentityKeys(t)
+
+	adminToken, err := signer.SignAdmin("alice", DefaultAdminTokenTTL)
+	require.NoError(t, err)
+	userToken, err := signer.Sign(Principal{UserID: "user-1", Email: "user@example.com"})
+	require.NoError(t, err)
+	expiredToken, err := signer.SignAdmin("alice", -time.Minute)
+	require.NoError(t, err)
+	foreignToken, err := otherSigner.SignAdmin("mallory", DefaultAdminTokenTTL)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      int
+	}{
+		{"AdminToken", "Bearer " + adminToken, http.StatusNoContent},
+		{"MissingHeader", "", http.StatusUnauthorized},
+		{"NotBearer", "Basic " + adminToken, http.StatusUnauthorized},
+		{"UserIdentityToken", "Bearer " + userToken, http.StatusUnauthorized},
+		{"ExpiredToken", "Bearer " + expiredToken, http.StatusUnauthorized},
+		{"SignedWithOtherKey", "Bearer " + foreignToken, http.StatusUnauthorized},
+	}
+
+	handler := RequireAdmin(verifier, logs.NewSlogLogger())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/admin/log-level", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
+
+func TestSignAdminRequiresOperator(t *testing.T) {
+	signer, _ := newTestIdentityKeys(t)
+
+	_, err := signer.SignAdmin("", DefaultAdminTokenTTL)
+
+	assert.ErrorIs(t, err, ErrMissingOperator)
+}
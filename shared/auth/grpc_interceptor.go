@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor verifies the identity token forwarded by the gateway
+// and stores the principal in the request context. An admin token sent by an
+// operator is verified the same way and stores the operator. Calls without a
+// token are passed through so handlers decide whether a principal is required.
+func UnaryServerInterceptor(verifier *IdentityTokenVerifier, logger logs.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, verifier, logger, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs.
+func StreamServerInterceptor(verifier *IdentityTokenVerifier, logger logs.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), verifier, logger, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextServerStream replaces the context of a server stream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticate(ctx context.Context, verifier *IdentityTokenVerifier, logger logs.Logger, method string) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	if values := md.Get(AdminMetadataKey); len(values) > 0 {
+		operator, err := verifier.VerifyAdmin(values[0])
+		if err != nil {
+			logger.Warn("invalid admin token", "method", method, "error", err)
+			return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+		}
+		logger.Info("admin call", "operator", operator, "method", method)
+		ctx = ContextWithOperator(ctx, operator)
+	}
+
+	values := md.Get(IdentityMetadataKey)
+	if len(values) == 0 {
+		return ctx, nil
+	}
+
+	principal, err := verifier.Verify(values[0])
+	if err != nil {
+		logger.Warn("invalid identity token", "method", method, "error", err)
+		return nil, status.Error(codes.Unauthenticated, "invalid identity token")
+	}
+
+	ctx = ContextWithPrincipal(ctx, principal)
+	ctx = contextWithIdentityToken(ctx, values[0])
+	return ctx, nil
+}
+
+// SigningUnaryClientInterceptor signs the principal found in the context and
+// attaches it to the outgoing metadata. It is used by the gateway, which is the
+// only component holding the signing key.
+func SigningUnaryClientInterceptor(signer *IdentityTokenSigner) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		principal, ok := PrincipalFromContext(ctx)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		token, err := signer.Sign(principal)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to sign identity token: %v", err)
+		}
+
+		ctx = metadata.AppendToOutgoingContext(ctx, IdentityMetadataKey, token)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// PropagatingUnaryClientInterceptor forwards the identity token received by
+// the current call to downstream services.
+func PropagatingUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if token, ok := identityTokenFromContext(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, IdentityMetadataKey, token)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func RequirePrincipal(ctx context.Context) (Principal, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return Principal{}, status.Error(codes.Unauthenticated, ErrMissingPrincipal.Error())
+	}
+	return principal, nil
+}
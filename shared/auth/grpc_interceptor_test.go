@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	signer, verifier := newTestIdentityKeys(t)
+	otherSigner, _ := newTestIdentityKeys(t)
+	principal := Principal{UserID: "user-1", Email: "user@example.com"}
+
+	validToken, err := signer.Sign(principal)
+	require.NoError(t, err)
+	foreignToken, err := otherSigner.Sign(principal)
+	require.NoError(t, err)
+	adminToken, err := signer.SignAdmin("alice", DefaultAdminTokenTTL)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		md            metadata.MD
+		wantCode      codes.Code
+		wantPrincipal bool
+	}{
+		{name: "ValidToken", md: metadata.Pairs(IdentityMetadataKey, validToken), wantCode: codes.OK, wantPrincipal: true},
+		{name: "NoMetadata", wantCode: codes.Unauthenticated},
+		{name: "MissingToken", md: metadata.Pairs("other", "value"), wantCode: codes.Unauthenticated},
+		{name: "SignedWithOtherKey", md: metadata.Pairs(IdentityMetadataKey, foreignToken), wantCode: codes.Unauthenticated},
+		{name: "AdminToken", md: metadata.Pairs(IdentityMetadataKey, adminToken), wantCode: codes.Unauthenticated},
+		{name: "Malformed", md: metadata.Pairs(IdentityMetadataKey, "not-a-token"), wantCode: codes.Unauthenticated},
+	}
+
+	interceptor := UnaryServerInterceptor(verifier, logs.NewSlogLogger())
+	info := &grpc.UnaryServerInfo{FullMethod: "/cart.CartService/GetCart"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			var got Principal
+			handler := func(ctx context.Context, req any) (any, error) {
+				p, err := RequirePrincipal(ctx)
+				if err != nil {
+					return nil, err
+				}
+				got = p
+				return "ok", nil
+			}
+
+			_, err := interceptor(ctx, nil, info, handler)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantPrincipal {
+				assert.Equal(t, principal, got)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptorPropagatesToken(t *testing.T) {
+	signer, verifier := newTestIdentityKeys(t)
+
+	token, err := signer.Sign(Principal{UserID: "user-1"})
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdentityMetadataKey, token))
+	interceptor := UnaryServerInterceptor(verifier, logs.NewSlogLogger())
+
+	var forwarded []string
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		forwarded = md.Get(IdentityMetadataKey)
+		return nil
+	}
+
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/order.OrderService/CreateOrder"}, func(ctx context.Context, req any) (any, error) {
+		return nil, PropagatingUnaryClientInterceptor()(ctx, "/payment.PaymentService/CreatePayment", nil, nil, nil, invoker)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{token}, forwarded)
+}
+
+func TestUnaryServerInterceptorAdminToken(t *testing.T) {
+	signer, verifier := newTestIdentityKeys(t)
+
+	adminToken, err := signer.SignAdmin("alice", DefaultAdminTokenTTL)
+	require.NoError(t, err)
+	userToken, err := signer.Sign(Principal{UserID: "user-1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		md           metadata.MD
+		wantCode     codes.Code
+		wantOperator string
+	}{
+		{name: "AdminToken", md: metadata.Pairs(AdminMetadataKey, adminToken), wantCode: codes.OK, wantOperator: "alice"},
+		{name: "UserTokenAsAdmin", md: metadata.Pairs(AdminMetadataKey, userToken), wantCode: codes.Unauthenticated},
+		{name: "UserToken", md: metadata.Pairs(IdentityMetadataKey, userToken), wantCode: codes.PermissionDenied},
+		{name: "NoToken", md: metadata.Pairs("other", "value"), wantCode: codes.Unauthenticated},
+	}
+
+	interceptor := UnaryServerInterceptor(verifier, logs.NewSlogLogger())
+	info := &grpc.UnaryServerInfo{FullMethod: "/order.OrderService/ShipOrder"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			var got string
+			handler := func(ctx context.Context, req any) (any, error) {
+				operator, err := RequireOperator(ctx)
+				if err != nil {
+					return nil, err
+				}
+				got = operator
+				return "ok", nil
+			}
+
+			_, err := interceptor(ctx, nil, info, handler)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantOperator, got)
+		})
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	signer, verifier := newTestIdentityKeys(t)
+
+	adminToken, err := signer.SignAdmin("alice", DefaultAdminTokenTTL)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		md           metadata.MD
+		wantCode     codes.Code
+		wantOperator string
+	}{
+		{name: "AdminToken", md: metadata.Pairs(AdminMetadataKey, adminToken), wantCode: codes.OK, wantOperator: "alice"},
+		{name: "InvalidAdminToken", md: metadata.Pairs(AdminMetadataKey, "invalid"), wantCode: codes.Unauthenticated},
+		{name: "NoToken", md: metadata.MD{}, wantCode: codes.Unauthenticated},
+	}
+
+	interceptor := StreamServerInterceptor(verifier, logs.NewSlogLogger())
+	info := &grpc.StreamServerInfo{FullMethod: "/product.ProductService/StreamProducts", IsServerStream: true}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &testServerStream{ctx: metadata.NewIncomingContext(context.Background(), tt.md)}
+
+			var got string
+			handler := func(srv any, stream grpc.ServerStream) error {
+				operator, err := RequireOperator(stream.Context())
+				if err != nil {
+					return err
+				}
+				got = operator
+				return nil
+			}
+
+			err := interceptor(nil, stream, info, handler)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantOperator, got)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	IdentityMetadataKey     = "x-internal-identity"
+	identityTokenIssuer     = "api-gateway"
+	identityTokenAudience   = "internal-services"
+	defaultIdentityTokenTTL = time.Minute
+)
+
+var (
+	ErrMissingPrincipal = errors.New("principal not found in context")
+	ErrInvalidPrincipal = errors.New("identity token has no subject")
+)
+
+type Principal struct {
+	UserID string
+	Email  string
+}
+
+type principalContextKey struct{}
+type identityTokenContextKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	ctx = logs.ContextWithUserID(ctx, principal.UserID)
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+	return principal, ok
+}
+
+func contextWithIdentityToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, identityTokenContextKey{}, token)
+}
+
+func identityTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(identityTokenContextKey{}).(string)
+	return token, ok && token != ""
+}
+
+type IdentityTokenSigner struct {
+	privateKey *ecdsa.PrivateKey
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+func NewIdentityTokenSigner(privateKeyPEM []byte) (*IdentityTokenSigner, error) {
+	privateKey, err := jwt.ParseECPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse identity token private key: %w", err)
+	}
+
+	return &IdentityTokenSigner{
+		privateKey: privateKey,
+		ttl:        defaultIdentityTokenTTL,
+		now:        time.Now,
+	}, nil
+}
+
+func LoadIdentityTokenSignerFromEnv() (*IdentityTokenSigner, error) {
+	path := os.Getenv("INTERNAL_TOKEN_PRIVATE_KEY_PATH")
+	if path == "" {
+		return nil, errors.New("INTERNAL_TOKEN_PRIVATE_KEY_PATH is not set")
+	}
+
+	privateKey, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity token private key: %w", err)
+	}
+
+	return NewIdentityTokenSigner(privateKey)
+}
+
+func (s *IdentityTokenSigner) Sign(principal Principal) (string, error) {
+	return s.sign(principal.UserID, principal.Email, identityTokenAudience, s.ttl)
+}
+
+func (s *IdentityTokenSigner) sign(subject, email, audience string, ttl time.Duration) (string, error) {
+	now := s.now()
+	claims := Claims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    identityTokenIssuer,
+			Audience:  []string{audience},
+			Subject:   subject,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	signedToken, err := token.SignedString(s.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign identity token: %w", err)
+	}
+
+	return signedToken, nil
+}
+
+type IdentityTokenVerifier struct {
+	publicKey *ecdsa.PublicKey
+	leeway    time.Duration
+	now       func() time.Time
+}
+
+func NewIdentityTokenVerifier(publicKeyPEM []byte) (*IdentityTokenVerifier, error) {
+	publicKey, err := jwt.ParseECPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse identity token public key: %w", err)
+	}
+
+	return &IdentityTokenVerifier{
+		publicKey: publicKey,
+		leeway:    5 * time.Second,
+		now:       time.Now,
+	}, nil
+}
+
+func LoadIdentityTokenVerifierFromEnv() (*IdentityTokenVerifier, error) {
+	path := os.Getenv("INTERNAL_TOKEN_PUBLIC_KEY_PATH")
+	if path == "" {
+		return nil, errors.New("INTERNAL_TOKEN_PUBLIC_KEY_PATH is not set")
+	}
+
+	publicKey, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity token public key: %w", err)
+	}
+
+	return NewIdentityTokenVerifier(publicKey)
+}
+
+func (v *IdentityTokenVerifier) Verify(tokenString string) (Principal, error) {
+	claims, err := v.parse(tokenString, identityTokenAudience)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	if claims.Subject == "" {
+		return Principal{}, ErrInvalidPrincipal
+	}
+
+	return Principal{UserID: claims.Subject, Email: claims.Email}, nil
+}
+
+func (v *IdentityTokenVerifier) parse(tokenString, audience string) (*Claims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}),
+		jwt.WithIssuer(identityTokenIssuer),
+		jwt.WithAudience(audience),
+		jwt.WithLeeway(v.leeway),
+		jwt.WithTimeFunc(v.now),
+		jwt.WithExpirationRequired(),
+	)
+
+	token, err := parser.ParseWithClaims(tokenString, &Claims{}, func(t *jwt.Token) (any, error) {
+		return v.publicKey, nil
+	})
+	if err != nil {
+		return nil, classifyJWTError(err)
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyPEM(t *testing.T) (privateKeyPEM, publicKeyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+}
+
+func newTestIdentityKeys(t *testing.T) (*IdentityTokenSigner, *IdentityTokenVerifier) {
+	t.Helper()
+
+	privateKeyPEM, publicKeyPEM := newTestKeyPEM(t)
+
+	signer, err := NewIdentityTokenSigner(privateKeyPEM)
+	require.NoError(t, err)
+	verifier, err := NewIdentityTokenVerifier(publicKeyPEM)
+	require.NoError(t, err)
+	return signer, verifier
+}
+
+func TestIdentityTokenVerifierVerify(t *testing.T) {
+	signer, verifier := newTestIdentityKeys(t)
+	otherSigner, _ := newTestIdentityKeys(t)
+	principal := Principal{UserID: "user-1", Email: "user@example.com"}
+
+	now := time.Now()
+	signAt := func(at time.Time) string {
+		signer.now = func() time.Time { return at }
+		defer func() { signer.now = time.Now }()
+
+		token, err := signer.Sign(principal)
+		require.NoError(t, err)
+		return token
+	}
+
+	validToken := signAt(now)
+	expiredToken := signAt(now.Add(-time.Hour))
+	notYetValidToken := signAt(now.Add(time.Hour))
+	adminToken, err := signer.SignAdmin("alice", DefaultAdminTokenTTL)
+	require.NoError(t, err)
+	noSubjectToken, err := signer.Sign(Principal{Email: "user@example.com"})
+	require.NoError(t, err)
+	foreignToken, err := otherSigner.Sign(principal)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		want    Principal
+		wantErr error
+	}{
+		{name: "ValidToken", token: validToken, want: principal},
+		{name: "ExpiredToken", token: expiredToken, wantErr: jwt.ErrTokenExpired},
+		{name: "NotYetValidToken", token: notYetValidToken, wantErr: jwt.ErrTokenNotValidYet},
+		{name: "AdminAudience", token: adminToken, wantErr: jwt.ErrTokenInvalidAudience},
+		{name: "NoSubject", token: noSubjectToken, wantErr: ErrInvalidPrincipal},
+		{name: "SignedWithOtherKey", token: foreignToken, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "Malformed", token: "not-a-token", wantErr: jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifier.Verify(tt.token)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewIdentityTokenSignerRejectsInvalidKey(t *testing.T) {
+	_, publicKeyPEM := newTestKeyPEM(t)
+
+	_, err := NewIdentityTokenSigner(publicKeyPEM)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "api-gateway"
+	testAudience = "microservices"
+)
+
+func newTestJWTManager(t *testing.T, now time.Time) *JWTManager {
+	t.Helper()
+
+	privateKeyPEM, publicKeyPEM := newTestKeyPEM(t)
+	manager, err := NewJWTManager(privateKeyPEM, publicKeyPEM, testIssuer, testAudience, time.Hour)
+	require.NoError(t, err)
+	return manager.WithNowFunc(func() time.Time { return now })
+}
+
+func TestJWTManagerValidateToken(t *testing.T) {
+	issuedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager := newTestJWTManager(t, issuedAt)
+
+	token, err := manager.GenerateToken("user-1", "user@example.com")
+	require.NoError(t, err)
+
+	foreignToken, err := newTestJWTManager(t, issuedAt).GenerateToken("user-1", "user@example.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "ValidToken", token: token, now: issuedAt},
+		{name: "BearerPrefix", token: "Bearer " + token, now: issuedAt},
+		{name: "WithinLeeway", token: token, now: issuedAt.Add(time.Hour + 10*time.Second)},
+		{name: "Expired", token: token, now: issuedAt.Add(2 * time.Hour), wantErr: jwt.ErrTokenExpired},
+		{name: "NotYetValid", token: token, now: issuedAt.Add(-time.Hour), wantErr: jwt.ErrTokenNotValidYet},
+		{name: "SignedWithOtherKey", token: foreignToken, now: issuedAt, wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "Malformed", token: "Bearer not-a-token", now: issuedAt, wantErr: jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager.WithNowFunc(func() time.Time { return tt.now })
+
+			claims, err := manager.ValidateToken(tt.token)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.Subject)
+			assert.Equal(t, "user@example.com", claims.Email)
+		})
+	}
+}
+
+func TestJWTManagerValidateTokenChecksIssuerAndAudience(t *testing.T) {
+	now := time.Now()
+	privateKeyPEM, publicKeyPEM := newTestKeyPEM(t)
+
+	newManager := func(issuer, audience string) *JWTManager {
+		manager, err := NewJWTManager(privateKeyPEM, publicKeyPEM, issuer, audience, time.Hour)
+		require.NoError(t, err)
+		return manager.WithNowFunc(func() time.Time { return now })
+	}
+
+	token, err := newManager(testIssuer, testAudience).GenerateToken("user-1", "user@example.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		issuer   string
+		audience string
+		wantErr  error
+	}{
+		{name: "Matching", issuer: testIssuer, audience: testAudience},
+		{name: "OtherIssuer", issuer: "someone-else", audience: testAudience, wantErr: jwt.ErrTokenInvalidIssuer},
+		{name: "OtherAudience", issuer: testIssuer, audience: "other-audience", wantErr: jwt.ErrTokenInvalidAudience},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newManager(tt.issuer, tt.audience).ValidateToken(token)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
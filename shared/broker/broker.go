@@ -0,0 +1,183 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+type ExchangeKind string
+
+const (
+	ExchangeFanout ExchangeKind = "fanout"
+	ExchangeTopic  ExchangeKind = "topic"
+	ExchangeDirect ExchangeKind = "direct"
+
+	DeadLetterExchangeSuffix = ".dlx"
+	DeadLetterQueueSuffix    = ".dlq"
+)
+
+// ErrUnroutable is returned by Publish when no queue is bound to receive the
+// message.
+var ErrUnroutable = errors.New("message is unroutable")
+
+// Message is a message as seen by a handler, independent of the broker that
+// delivered it.
+type Message struct {
+	ID          string
+	Exchange    string
+	RoutingKey  string
+	ContentType string
+	Type        string
+	Timestamp   time.Time
+	Headers     map[string]any
+	Body        []byte
+	// Retries is how many times the message has already been retried.
+	Retries int
+}
+
+// Handler processes a message. The result decides what happens to it:
+// returning nil acknowledges it, returning an error wrapped with Retryable
+// retries it after a delay, and any other error, or a retryable one once the
+// retries are exhausted, moves it to the dead letter queue.
+type Handler func(ctx context.Context, msg Message) error
+
+type Publishing struct {
+	Exchange   string
+	Kind       ExchangeKind
+	RoutingKey string
+	Headers    map[string]any
+	Body       []byte
+	// AllowUnroutable makes Publish succeed when no queue is bound to
+	// receive the message, which is then dropped. By default such a message
+	// fails with ErrUnroutable.
+	AllowUnroutable bool
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, publishing Publishing) error
+}
+
+type Subscription struct {
+	Exchange   string
+	Kind       ExchangeKind
+	Queue      string
+	Consumer   string
+	BindingKey string
+	Handler    Handler
+
+	// Workers is the number of handlers running concurrently. Defaults to 10.
+	Workers int
+	// Prefetch is the maximum number of unacknowledged messages. Defaults to
+	// 10.
+	Prefetch int
+	// PartitionKey, when set, routes messages with the same key to the same
+	// worker so they are handled in order, e.g. events of one aggregate. The
+	// order only holds until a retry: a retried message is redelivered after
+	// its delay, behind later messages with the same key, so handlers that
+	// retry must tolerate handling an older message after a newer one.
+	PartitionKey func(msg Message) string
+	// MaxRetries is how many times a retryable failure is retried before the
+	// message is dead-lettered. Defaults to 5.
+	MaxRetries int
+	// RetryDelays are the delay tiers; retry n waits RetryDelays[n-1], and
+	// the last tier is reused once exhausted. Defaults to 5s, 30s and 2m.
+	RetryDelays []time.Duration
+}
+
+// Subscriber consumes the queue of a subscription, declaring it and binding
+// it to the exchange first. Subscribe blocks until ctx is done.
+type Subscriber interface {
+	Subscribe(ctx context.Context, sub Subscription) error
+}
+
+const (
+	DefaultWorkers    = 10
+	DefaultPrefetch   = 10
+	DefaultMaxRetries = 5
+)
+
+var DefaultRetryDelays = []time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute}
+
+// WithDefaults fills in the unset tuning fields.
+func (s Subscription) WithDefaults() Subscription {
+	if s.Workers <= 0 {
+		s.Workers = DefaultWorkers
+	}
+	if s.Prefetch <= 0 {
+		s.Prefetch = DefaultPrefetch
+	}
+	if s.MaxRetries <= 0 {
+		s.MaxRetries = DefaultMaxRetries
+	}
+	if len(s.RetryDelays) == 0 {
+		s.RetryDelays = DefaultRetryDelays
+	}
+	return s
+}
+
+// RetryDelay returns the delay before retry number attempt, starting at 1.
+func (s Subscription) RetryDelay(attempt int) time.Duration {
+	return s.RetryDelays[min(max(attempt, 1), len(s.RetryDelays))-1]
+}
+
+// RetryableError marks a handler failure as transient. The message is
+// retried after a delay instead of being dead-lettered.
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+func IsRetryable(err error) bool {
+	var retryable *RetryableError
+	return errors.As(err, &retryable)
+}
+
+// BindingMatches reports whether a binding key routes routingKey on an
+// exchange of the given kind, following the AMQP rules: fanout matches
+// everything, direct needs equal keys and topic patterns use * for exactly
+// one word and # for zero or more words.
+func BindingMatches(kind ExchangeKind, bindingKey, routingKey string) bool {
+	switch kind {
+	case ExchangeFanout:
+		return true
+	case ExchangeDirect:
+		return bindingKey == routingKey
+	default:
+		return matchTopic(strings.Split(bindingKey, "."), strings.Split(routingKey, "."))
+	}
+}
+
+func matchTopic(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if matchTopic(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(words) > 0 && matchTopic(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && pattern[0] == words[0] && matchTopic(pattern[1:], words[1:])
+	}
+}
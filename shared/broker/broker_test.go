@@ -0,0 +1,57 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryable(t *testing.T) {
+	cause := errors.New("database down")
+	err := fmt.Errorf("handler: %w", Retryable(cause))
+
+	assert.True(t, IsRetryable(err))
+	assert.ErrorIs(t, err, cause)
+	assert.False(t, IsRetryable(cause))
+	assert.Nil(t, Retryable(nil))
+}
+
+func TestSubscriptionWithDefaults(t *testing.T) {
+	sub := Subscription{Workers: 3}.WithDefaults()
+
+	assert.Equal(t, 3, sub.Workers)
+	assert.Equal(t, DefaultPrefetch, sub.Prefetch)
+	assert.Equal(t, DefaultMaxRetries, sub.MaxRetries)
+	assert.Equal(t, DefaultRetryDelays, sub.RetryDelays)
+}
+
+func TestRetryDelay(t *testing.T) {
+	sub := Subscription{RetryDelays: []time.Duration{time.Second, time.Minute}}
+
+	assert.Equal(t, time.Second, sub.RetryDelay(0))
+	assert.Equal(t, time.Second, sub.RetryDelay(1))
+	assert.Equal(t, time.Minute, sub.RetryDelay(2))
+	assert.Equal(t, time.Minute, sub.RetryDelay(5))
+}
+
+func TestBindingMatches(t *testing.T) {
+	tests := []struct {
+		binding, key string
+		want         bool
+	}{
+		{"order.created", "order.created", true},
+		{"order.*", "order.created", true},
+		{"order.*", "order.created.v2", false},
+		{"order.#", "order", true},
+		{"order.#", "order.created.v2", true},
+		{"#", "anything.at.all", true},
+		{"*.created", "product.created", true},
+		{"*.created", "product.deleted", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, BindingMatches(ExchangeTopic, tt.binding, tt.key), "%s vs %s", tt.binding, tt.key)
+	}
+}
@@ -0,0 +1,271 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sonuudigital/microservices/shared/broker"
+)
+
+// Broker is an in-process implementation of broker.Publisher and
+// broker.Subscriber for tests and local runs. It routes like RabbitMQ
+// (fanout, direct and topic exchanges with * and # wildcards), retries
+// retryable failures after the subscription's delays and moves failed
+// messages to the queue's dead letter queue, which can be read with
+// DeadLetters. Nothing is persisted.
+type Broker struct {
+	mu        sync.Mutex
+	exchanges map[string]*exchange
+	queues    map[string]*queue
+
+	// pending counts messages that are queued, being handled or waiting
+	// for a retry, so Wait knows when the broker is idle.
+	pending int
+	idle    *sync.Cond
+}
+
+type exchange struct {
+	kind     broker.ExchangeKind
+	bindings []binding
+}
+
+type binding struct {
+	queue string
+	key   string
+}
+
+type queue struct {
+	messages    chan broker.Message
+	deadLetters []broker.Message
+}
+
+var (
+	_ broker.Publisher  = (*Broker)(nil)
+	_ broker.Subscriber = (*Broker)(nil)
+)
+
+func New() *Broker {
+	b := &Broker{
+		exchanges: make(map[string]*exchange),
+		queues:    make(map[string]*queue),
+	}
+	b.idle = sync.NewCond(&b.mu)
+	return b
+}
+
+// queueCapacity bounds each queue; Publish blocks while a queue is full.
+const queueCapacity = 1024
+
+func (b *Broker) Publish(ctx context.Context, p broker.Publishing) error {
+	b.mu.Lock()
+	ex, err := b.declareExchange(p.Exchange, p.Kind)
+	if err != nil {
+		b.mu.Unlock()
+		return err
+	}
+
+	var targets []*queue
+	for _, bind := range ex.bindings {
+		if broker.BindingMatches(ex.kind, bind.key, p.RoutingKey) {
+			targets = append(targets, b.queues[bind.queue])
+		}
+	}
+	if len(targets) == 0 {
+		b.mu.Unlock()
+		if p.AllowUnroutable {
+			return nil
+		}
+		return fmt.Errorf("%w: exchange %s, routing key %q", broker.ErrUnroutable, p.Exchange, p.RoutingKey)
+	}
+	b.pending += len(targets)
+	b.mu.Unlock()
+
+	msg := broker.Message{
+		ID:          uuid.NewString(),
+		Exchange:    p.Exchange,
+		RoutingKey:  p.RoutingKey,
+		ContentType: "application/json",
+		Timestamp:   time.Now(),
+		Headers:     p.Headers,
+		Body:        p.Body,
+	}
+	for i, q := range targets {
+		select {
+		case q.messages <- msg:
+		case <-ctx.Done():
+			b.done(len(targets) - i)
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe declares the queue and its binding and handles messages until
+// ctx is done. Messages published before the queue was bound are not
+// delivered, as with RabbitMQ.
+func (b *Broker) Subscribe(ctx context.Context, sub broker.Subscription) error {
+	sub = sub.WithDefaults()
+
+	b.mu.Lock()
+	ex, err := b.declareExchange(sub.Exchange, sub.Kind)
+	if err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	q := b.declareQueue(sub.Queue)
+	bound := false
+	for _, bind := range ex.bindings {
+		bound = bound || (bind.queue == sub.Queue && bind.key == sub.BindingKey)
+	}
+	if !bound {
+		ex.bindings = append(ex.bindings, binding{queue: sub.Queue, key: sub.BindingKey})
+	}
+	b.mu.Unlock()
+
+	workers := make([]chan broker.Message, 1)
+	if sub.PartitionKey != nil {
+		workers = make([]chan broker.Message, sub.Workers)
+	}
+	for i := range workers {
+		workers[i] = make(chan broker.Message)
+	}
+
+	var wg sync.WaitGroup
+	for i := range sub.Workers {
+		messages := workers[i%len(workers)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range messages {
+				b.handle(ctx, sub, q, msg)
+			}
+		}()
+	}
+	defer func() {
+		for _, messages := range workers {
+			close(messages)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg := <-q.messages:
+			target := workers[0]
+			if sub.PartitionKey != nil {
+				target = workers[partitionIndex(sub.PartitionKey(msg), len(workers))]
+			}
+			select {
+			case target <- msg:
+			case <-ctx.Done():
+				b.requeue(q, msg)
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func (b *Broker) handle(ctx context.Context, sub broker.Subscription, q *queue, msg broker.Message) {
+	err := sub.Handler(context.WithoutCancel(ctx), msg)
+	if err == nil {
+		b.done(1)
+		return
+	}
+
+	if broker.IsRetryable(err) && msg.Retries < sub.MaxRetries {
+		msg.Retries++
+		time.AfterFunc(sub.RetryDelay(msg.Retries), func() {
+			b.requeue(q, msg)
+		})
+		return
+	}
+
+	b.mu.Lock()
+	q.deadLetters = append(q.deadLetters, msg)
+	b.mu.Unlock()
+	b.done(1)
+}
+
+// requeue puts a message that is still counted as pending back on its queue.
+func (b *Broker) requeue(q *queue, msg broker.Message) {
+	go func() { q.messages <- msg }()
+}
+
+func (b *Broker) done(n int) {
+	b.mu.Lock()
+	b.pending -= n
+	if b.pending == 0 {
+		b.idle.Broadcast()
+	}
+	b.mu.Unlock()
+}
+
+// Wait blocks until every published message has been handled or
+// dead-lettered, including the ones waiting for a retry. It needs running
+// subscribers for the queues that hold messages.
+func (b *Broker) Wait(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		b.mu.Lock()
+		b.idle.Broadcast()
+		b.mu.Unlock()
+	})
+	defer stop()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.pending > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b.idle.Wait()
+	}
+	return nil
+}
+
+// DeadLetters returns the messages dead-lettered from queue.
+func (b *Broker) DeadLetters(queueName string) []broker.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return nil
+	}
+	return append([]broker.Message(nil), q.deadLetters...)
+}
+
+func (b *Broker) declareExchange(name string, kind broker.ExchangeKind) (*exchange, error) {
+	if kind == "" {
+		kind = broker.ExchangeTopic
+	}
+	if ex, ok := b.exchanges[name]; ok {
+		if ex.kind != kind {
+			return nil, fmt.Errorf("exchange %s already declared as %s, not %s", name, ex.kind, kind)
+		}
+		return ex, nil
+	}
+	ex := &exchange{kind: kind}
+	b.exchanges[name] = ex
+	return ex, nil
+}
+
+func (b *Broker) declareQueue(name string) *queue {
+	if q, ok := b.queues[name]; ok {
+		return q
+	}
+	q := &queue{messages: make(chan broker.Message, queueCapacity)}
+	b.queues[name] = q
+	return q
+}
+
+func partitionIndex(key string, partitions int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(partitions))
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// subscribe starts sub in the background and waits until its queue is bound.
+func subscribe(t *testing.T, ctx context.Context, b *Broker, sub broker.Subscription) {
+	t.Helper()
+	go func() { _ = b.Subscribe(ctx, sub) }()
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		_, ok := b.queues[sub.Queue]
+		return ok
+	}, time.Second, time.Millisecond)
+}
+
+func waitIdle(t *testing.T, b *Broker) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, b.Wait(ctx))
+}
+
+type recorder struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (r *recorder) handle(_ context.Context, msg broker.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = append(r.keys, msg.RoutingKey)
+	return nil
+}
+
+func (r *recorder) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.keys...)
+}
+
+func TestRouting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := New()
+
+	created, all, fanout := &recorder{}, &recorder{}, &recorder{}
+	subscribe(t, ctx, b, broker.Subscription{Exchange: "orders", Queue: "created", BindingKey: "order.created", Handler: created.handle})
+	subscribe(t, ctx, b, broker.Subscription{Exchange: "orders", Queue: "all", BindingKey: "order.#", Handler: all.handle})
+	subscribe(t, ctx, b, broker.Subscription{Exchange: "legacy", Kind: broker.ExchangeFanout, Queue: "legacy", Handler: fanout.handle})
+
+	require.NoError(t, b.Publish(ctx, broker.Publishing{Exchange: "orders", RoutingKey: "order.created"}))
+	require.NoError(t, b.Publish(ctx, broker.Publishing{Exchange: "orders", RoutingKey: "order.paid"}))
+	require.NoError(t, b.Publish(ctx, broker.Publishing{Exchange: "legacy", Kind: broker.ExchangeFanout}))
+	waitIdle(t, b)
+
+	assert.Equal(t, []string{"order.created"}, created.received())
+	assert.ElementsMatch(t, []string{"order.created", "order.paid"}, all.received())
+	assert.Len(t, fanout.received(), 1)
+
+	err := b.Publish(ctx, broker.Publishing{Exchange: "orders", RoutingKey: "payment.succeeded"})
+	assert.ErrorIs(t, err, broker.ErrUnroutable)
+	err = b.Publish(ctx, broker.Publishing{Exchange: "orders", RoutingKey: "payment.succeeded", AllowUnroutable: true})
+	assert.NoError(t, err)
+
+	err = b.Publish(ctx, broker.Publishing{Exchange: "legacy", Kind: broker.ExchangeTopic})
+	assert.Error(t, err, "redeclaring with another kind must fail")
+}
+
+func TestRetryAndDeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := New()
+
+	var mu sync.Mutex
+	attempts := map[string][]int{}
+	subscribe(t, ctx, b, broker.Subscription{
+		Exchange:    "orders",
+		Queue:       "orders_queue",
+		BindingKey:  "#",
+		MaxRetries:  2,
+		RetryDelays: []time.Duration{time.Millisecond},
+		Handler: func(_ context.Context, msg broker.Message) error {
+			mu.Lock()
+			attempts[msg.RoutingKey] = append(attempts[msg.RoutingKey], msg.Retries)
+			mu.Unlock()
+
+			switch msg.RoutingKey {
+			case "transient":
+				if msg.Retries == 0 {
+					return broker.Retryable(errors.New("database down"))
+				}
+				return nil
+			case "always":
+				return broker.Retryable(errors.New("database down"))
+			default:
+				return errors.New("invalid payload")
+			}
+		},
+	})
+
+	for _, key := range []string{"transient", "always", "invalid"} {
+		require.NoError(t, b.Publish(ctx, broker.Publishing{Exchange: "orders", RoutingKey: key}))
+	}
+	waitIdle(t, b)
+
+	mu.Lock()
+	assert.Equal(t, []int{0, 1}, attempts["transient"])
+	assert.Equal(t, []int{0, 1, 2}, attempts["always"])
+	assert.Equal(t, []int{0}, attempts["invalid"])
+	mu.Unlock()
+
+	deadLetters := b.DeadLetters("orders_queue")
+	require.Len(t, deadLetters, 2)
+	keys := []string{deadLetters[0].RoutingKey, deadLetters[1].RoutingKey}
+	assert.ElementsMatch(t, []string{"always", "invalid"}, keys)
+}
+
+func TestPartitionedOrdering(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := New()
+
+	var mu sync.Mutex
+	seen := map[string][]string{}
+	subscribe(t, ctx, b, broker.Subscription{
+		Exchange:     "products",
+		Queue:        "products_queue",
+		BindingKey:   "#",
+		Workers:      4,
+		PartitionKey: func(msg broker.Message) string { return string(msg.Body[:1]) },
+		Handler: func(_ context.Context, msg broker.Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			key := string(msg.Body[:1])
+			seen[key] = append(seen[key], string(msg.Body))
+			return nil
+		},
+	})
+
+	var want []string
+	for i := range 10 {
+		body := "a" + string(rune('0'+i))
+		want = append(want, body)
+		require.NoError(t, b.Publish(ctx, broker.Publishing{Exchange: "products", RoutingKey: "product.updated", Body: []byte(body)}))
+	}
+	waitIdle(t, b)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, want, seen["a"])
+}
@@ -0,0 +1,178 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	CloudEventsContentType = "application/cloudevents+json"
+
+	// DefaultDataVersion is the schema version of events that do not set
+	// one, including payloads published before the envelope was introduced.
+	DefaultDataVersion = "1"
+
+	OrderCreatedEventType      = OrderCreatedRoutingKey
+	OrderPaidEventType         = OrderPaidRoutingKey
+	OrderCancelledEventType    = OrderCancelledRoutingKey
+	OrderShippedEventType      = OrderShippedRoutingKey
+	OrderDeliveredEventType    = OrderDeliveredRoutingKey
+	PaymentSucceededEventType  = PaymentSucceededRoutingKey
+	PaymentRejectedEventType   = PaymentRejectedRoutingKey
+	PaymentRefundedEventType   = PaymentRefundedRoutingKey
+	UserRegisteredEventType    = UserRegisteredRoutingKey
+	UserUpdatedEventType       = UserUpdatedRoutingKey
+	UserDeletedEventType       = UserDeletedRoutingKey
+	StockUpdateFailedEventType = "stock.update_failed"
+	ProductCreatedEventType    = ProductCreatedRoutingKey
+	ProductUpdatedEventType    = ProductUpdatedRoutingKey
+	ProductDeletedEventType    = ProductDeletedRoutingKey
+)
+
+var (
+	ErrUnsupportedSpecVersion = errors.New("unsupported cloudevents spec version")
+	ErrUnsupportedDataVersion = errors.New("unsupported event data version")
+)
+
+// Envelope is a CloudEvents 1.0 event in structured mode. The outbox stores
+// it as the event payload and it is published as the message body; the
+// attributes are also copied to the message headers.
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	DataVersion     string          `json:"dataversion,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+type EnvelopeOption func(*Envelope)
+
+// WithDataVersion sets the schema version of the data, so a new version of
+// an event can be published while consumers still accept the old one.
+func WithDataVersion(version string) EnvelopeOption {
+	return func(e *Envelope) {
+		e.DataVersion = version
+	}
+}
+
+// NewEnvelope wraps data in an envelope with a new ID. source identifies the
+// producing service and subject the aggregate the event is about.
+func NewEnvelope(source, eventType, subject string, data any, opts ...EnvelopeOption) (Envelope, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to marshal %s data: %w", eventType, err)
+	}
+
+	envelope := Envelope{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              uuid.NewString(),
+		Source:          source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		DataVersion:     DefaultDataVersion,
+		Data:            encoded,
+	}
+	for _, opt := range opts {
+		opt(&envelope)
+	}
+	return envelope, nil
+}
+
+// Wrap is NewEnvelope followed by json.Marshal, for writing outbox payloads.
+func Wrap(source, eventType, subject string, data any, opts ...EnvelopeOption) ([]byte, error) {
+	envelope, err := NewEnvelope(source, eventType, subject, data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope)
+}
+
+// ParseEnvelope reads the envelope of a message body without decoding its
+// data. A body without a specversion is a bare payload published before the
+// envelope was introduced; it is returned as the data of an envelope with
+// only DataVersion set.
+func ParseEnvelope(body []byte) (Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return Envelope{}, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	if envelope.SpecVersion == "" {
+		return Envelope{DataVersion: DefaultDataVersion, Data: body}, nil
+	}
+	if envelope.SpecVersion != CloudEventsSpecVersion {
+		return Envelope{}, fmt.Errorf("%w: %s", ErrUnsupportedSpecVersion, envelope.SpecVersion)
+	}
+	if envelope.DataVersion == "" {
+		envelope.DataVersion = DefaultDataVersion
+	}
+	return envelope, nil
+}
+
+// Decoder decodes the data of one event type into T, with one decode
+// function per schema version. Older versions are usually upgraded to the
+// current struct, so handlers only deal with T.
+type Decoder[T any] struct {
+	versions map[string]func(data json.RawMessage) (T, error)
+}
+
+// NewDecoder returns a decoder that unmarshals DefaultDataVersion straight
+// into T.
+func NewDecoder[T any]() *Decoder[T] {
+	return &Decoder[T]{
+		versions: map[string]func(json.RawMessage) (T, error){
+			DefaultDataVersion: unmarshalData[T],
+		},
+	}
+}
+
+// Version registers the decode function of a schema version, replacing any
+// previous one.
+func (d *Decoder[T]) Version(version string, decode func(data json.RawMessage) (T, error)) *Decoder[T] {
+	d.versions[version] = decode
+	return d
+}
+
+func (d *Decoder[T]) Decode(body []byte) (Envelope, T, error) {
+	var zero T
+
+	envelope, err := ParseEnvelope(body)
+	if err != nil {
+		return Envelope{}, zero, err
+	}
+
+	decode, ok := d.versions[envelope.DataVersion]
+	if !ok {
+		return envelope, zero, fmt.Errorf("%w: %s version %s", ErrUnsupportedDataVersion, envelope.Type, envelope.DataVersion)
+	}
+
+	data, err := decode(envelope.Data)
+	if err != nil {
+		return envelope, zero, err
+	}
+	return envelope, data, nil
+}
+
+// Decode decodes a message body whose data has the default schema version.
+func Decode[T any](body []byte) (Envelope, T, error) {
+	return NewDecoder[T]().Decode(body)
+}
+
+func unmarshalData[T any](data json.RawMessage) (T, error) {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to unmarshal event data: %w", err)
+	}
+	return value, nil
+}
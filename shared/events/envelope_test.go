@@ -0,0 +1,104 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreatedV2 struct {
+	OrderID string      `json:"orderId"`
+	Items   []OrderItem `json:"items"`
+}
+
+func TestNewEnvelope(t *testing.T) {
+	event := OrderCreatedEvent{OrderID: "order-1", UserID: "user-1"}
+
+	envelope, err := NewEnvelope("/order-service", OrderCreatedEventType, "order-1", event)
+	require.NoError(t, err)
+
+	assert.Equal(t, CloudEventsSpecVersion, envelope.SpecVersion)
+	assert.NotEmpty(t, envelope.ID)
+	assert.Equal(t, "/order-service", envelope.Source)
+	assert.Equal(t, OrderCreatedEventType, envelope.Type)
+	assert.Equal(t, "order-1", envelope.Subject)
+	assert.False(t, envelope.Time.IsZero())
+	assert.Equal(t, DefaultDataVersion, envelope.DataVersion)
+	assert.JSONEq(t, `{"orderId":"order-1","userId":"user-1","userEmail":"","products":null}`, string(envelope.Data))
+
+	other, err := NewEnvelope("/order-service", OrderCreatedEventType, "order-1", event, WithDataVersion("2"))
+	require.NoError(t, err)
+	assert.NotEqual(t, envelope.ID, other.ID)
+	assert.Equal(t, "2", other.DataVersion)
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Envelope", func(t *testing.T) {
+		body, err := Wrap("/order-service", OrderCreatedEventType, "order-1", OrderCreatedEvent{OrderID: "order-1"})
+		require.NoError(t, err)
+
+		envelope, event, err := Decode[OrderCreatedEvent](body)
+		require.NoError(t, err)
+		assert.NotEmpty(t, envelope.ID)
+		assert.Equal(t, OrderCreatedEventType, envelope.Type)
+		assert.Equal(t, "order-1", event.OrderID)
+	})
+
+	t.Run("BarePayload", func(t *testing.T) {
+		envelope, event, err := Decode[OrderCreatedEvent]([]byte(`{"orderId":"order-1","userId":"user-1"}`))
+		require.NoError(t, err)
+		assert.Empty(t, envelope.ID)
+		assert.Equal(t, DefaultDataVersion, envelope.DataVersion)
+		assert.Equal(t, "order-1", event.OrderID)
+		assert.Equal(t, "user-1", event.UserID)
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, _, err := Decode[OrderCreatedEvent]([]byte(`{invalid`))
+		assert.Error(t, err)
+	})
+
+	t.Run("UnsupportedSpecVersion", func(t *testing.T) {
+		_, _, err := Decode[OrderCreatedEvent]([]byte(`{"specversion":"0.3","id":"1","data":{}}`))
+		assert.ErrorIs(t, err, ErrUnsupportedSpecVersion)
+	})
+
+	t.Run("UnsupportedDataVersion", func(t *testing.T) {
+		body, err := Wrap("/order-service", OrderCreatedEventType, "order-1", orderCreatedV2{OrderID: "order-1"}, WithDataVersion("2"))
+		require.NoError(t, err)
+
+		_, _, err = Decode[OrderCreatedEvent](body)
+		assert.ErrorIs(t, err, ErrUnsupportedDataVersion)
+	})
+}
+
+func TestDecoderVersions(t *testing.T) {
+	decoder := NewDecoder[OrderCreatedEvent]().Version("2", func(data json.RawMessage) (OrderCreatedEvent, error) {
+		var v2 orderCreatedV2
+		if err := json.Unmarshal(data, &v2); err != nil {
+			return OrderCreatedEvent{}, err
+		}
+		return OrderCreatedEvent{OrderID: v2.OrderID, Products: v2.Items}, nil
+	})
+
+	items := []OrderItem{{ProductID: "product-1", Quantity: 2}}
+
+	v1, err := Wrap("/order-service", OrderCreatedEventType, "order-1", OrderCreatedEvent{OrderID: "order-1", Products: items})
+	require.NoError(t, err)
+	v2, err := Wrap("/order-service", OrderCreatedEventType, "order-2", orderCreatedV2{OrderID: "order-2", Items: items}, WithDataVersion("2"))
+	require.NoError(t, err)
+
+	envelope, event, err := decoder.Decode(v1)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultDataVersion, envelope.DataVersion)
+	assert.Equal(t, "order-1", event.OrderID)
+	assert.Equal(t, items, event.Products)
+
+	envelope, event, err = decoder.Decode(v2)
+	require.NoError(t, err)
+	assert.Equal(t, "2", envelope.DataVersion)
+	assert.Equal(t, "order-2", event.OrderID)
+	assert.Equal(t, items, event.Products)
+}
@@ -0,0 +1,28 @@
+package events
+
+type OrderPaidEvent struct {
+	OrderID   string `json:"orderId"`
+	UserID    string `json:"userId"`
+	UserEmail string `json:"userEmail,omitempty"`
+	PaymentID string `json:"paymentId"`
+	Amount    string `json:"amount"`
+}
+
+type OrderCancelledEvent struct {
+	OrderID   string `json:"orderId"`
+	UserID    string `json:"userId"`
+	UserEmail string `json:"userEmail,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+type OrderShippedEvent struct {
+	OrderID   string `json:"orderId"`
+	UserID    string `json:"userId"`
+	UserEmail string `json:"userEmail,omitempty"`
+}
+
+type OrderDeliveredEvent struct {
+	OrderID   string `json:"orderId"`
+	UserID    string `json:"userId"`
+	UserEmail string `json:"userEmail,omitempty"`
+}
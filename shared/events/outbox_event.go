@@ -1,9 +1,16 @@
 package events
 
+import "time"
+
 type OutboxEvent struct {
-	ID          string `json:"id"`
-	AggregateID string `json:"aggregateId"`
-	EventName   string `json:"eventName"`
-	Payload     []byte `json:"payload"`
-	Status      any    `json:"status"`
+	ID            string    `json:"id"`
+	AggregateID   string    `json:"aggregateId"`
+	EventName     string    `json:"eventName"`
+	Payload       []byte    `json:"payload"`
+	Status        any       `json:"status"`
+	TraceContext  []byte    `json:"traceContext,omitempty"`
+	Attempts      int32     `json:"attempts"`
+	LastError     string    `json:"lastError,omitempty"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	CreatedAt     time.Time `json:"createdAt"`
 }
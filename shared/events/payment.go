@@ -0,0 +1,10 @@
+package events
+
+type PaymentEvent struct {
+	PaymentID string `json:"paymentId"`
+	OrderID   string `json:"orderId"`
+	UserID    string `json:"userId"`
+	Amount    string `json:"amount"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+}
@@ -0,0 +1,423 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+)
+
+const (
+	OrderCreatedExchangeName      = "order_created_exchange"
+	StockUpdateFailedExchangeName = "stock_update_failed_exchange"
+	ProductExchangeName           = "products.events"
+	OrderExchangeName             = "orders.events"
+	PaymentExchangeName           = "payments.events"
+	UserExchangeName              = "users.events"
+
+	OrderCreatedRoutingKey   = "order.created"
+	OrderPaidRoutingKey      = "order.paid"
+	OrderCancelledRoutingKey = "order.cancelled"
+	OrderShippedRoutingKey   = "order.shipped"
+	OrderDeliveredRoutingKey = "order.delivered"
+	OrderWildcardRoutingKey  = "order.#"
+
+	PaymentSucceededRoutingKey = "payment.succeeded"
+	PaymentRejectedRoutingKey  = "payment.rejected"
+	PaymentRefundedRoutingKey  = "payment.refunded"
+	PaymentWildcardRoutingKey  = "payment.#"
+
+	UserRegisteredRoutingKey = "user.registered"
+	UserUpdatedRoutingKey    = "user.updated"
+	UserDeletedRoutingKey    = "user.deleted"
+	UserWildcardRoutingKey   = "user.#"
+
+	ProductCreatedRoutingKey  = "product.created"
+	ProductUpdatedRoutingKey  = "product.updated"
+	ProductDeletedRoutingKey  = "product.deleted"
+	ProductWildcardRoutingKey = "product.#"
+
+	CartOrderCreatedQueueName           = "cart_queue"
+	ProductOrderCreatedQueueName        = "product_queue"
+	NotificationOrderCreatedQueueName   = "notification_queue"
+	NotificationUserRegisteredQueueName = "notification_user_registered_queue"
+	OrderStockUpdateFailedQueueName     = "order_stock_update_failed_queue"
+	OrderUserEventsQueueName            = "order_user_events_queue"
+	OrderPaymentEventsQueueName         = "order_payment_events_queue"
+	NotificationOrderEventsQueueName    = "notification_order_events_queue"
+	SearchProductEventsQueueName        = "search_product_events_queue"
+)
+
+// Outbox event names are "exchange:routingKey" for topic and direct
+// exchanges and the bare exchange name for fanout exchanges.
+//
+// LegacyOrderCreatedEventName publishes order.created to the fanout exchange
+// the cart, product and notification queues are still bound to. The order
+// outbox writes it next to OrderCreatedEventName until they move to
+// orders.events.
+const (
+	LegacyOrderCreatedEventName = OrderCreatedExchangeName
+	OrderCreatedEventName       = OrderExchangeName + ":" + OrderCreatedRoutingKey
+	OrderPaidEventName          = OrderExchangeName + ":" + OrderPaidRoutingKey
+	OrderCancelledEventName     = OrderExchangeName + ":" + OrderCancelledRoutingKey
+	OrderShippedEventName       = OrderExchangeName + ":" + OrderShippedRoutingKey
+	OrderDeliveredEventName     = OrderExchangeName + ":" + OrderDeliveredRoutingKey
+	PaymentSucceededEventName   = PaymentExchangeName + ":" + PaymentSucceededRoutingKey
+	PaymentRejectedEventName    = PaymentExchangeName + ":" + PaymentRejectedRoutingKey
+	PaymentRefundedEventName    = PaymentExchangeName + ":" + PaymentRefundedRoutingKey
+	UserRegisteredEventName     = UserExchangeName + ":" + UserRegisteredRoutingKey
+	UserUpdatedEventName        = UserExchangeName + ":" + UserUpdatedRoutingKey
+	UserDeletedEventName        = UserExchangeName + ":" + UserDeletedRoutingKey
+	StockUpdateFailedEventName  = StockUpdateFailedExchangeName
+	ProductCreatedEventName     = ProductExchangeName + ":" + ProductCreatedRoutingKey
+	ProductUpdatedEventName     = ProductExchangeName + ":" + ProductUpdatedRoutingKey
+	ProductDeletedEventName     = ProductExchangeName + ":" + ProductDeletedRoutingKey
+)
+
+var (
+	ErrUnknownExchange   = errors.New("exchange is not in the topology")
+	ErrUnknownRoutingKey = errors.New("routing key is not in the topology")
+	ErrUnknownQueue      = errors.New("queue is not in the topology")
+	ErrUnknownService    = errors.New("service is not in the topology")
+)
+
+type ExchangeSpec struct {
+	Name string
+	Kind broker.ExchangeKind
+	// RoutingKeys lists the keys published to a topic or direct exchange.
+	// Fanout exchanges have none.
+	RoutingKeys []string
+	// AllowUnroutable lets publishes succeed while no queue is bound to
+	// receive them, for exchanges consumers bind to selectively.
+	AllowUnroutable bool
+}
+
+// QueueSpec describes a consumer queue and its binding. Every queue gets a
+// dead letter exchange named after its exchange with broker's
+// DeadLetterExchangeSuffix, a dead letter queue named after itself with
+// DeadLetterQueueSuffix, and one delay queue per retry delay. Zero retry
+// settings fall back to the broker defaults.
+type QueueSpec struct {
+	Name        string
+	Exchange    string
+	BindingKey  string
+	MaxRetries  int
+	RetryDelays []time.Duration
+}
+
+// ServiceSpec lists the exchanges a service publishes to and the queues it
+// consumes.
+type ServiceSpec struct {
+	Name      string
+	Publishes []string
+	Consumes  []string
+}
+
+// Topology is a declarative description of the exchanges, queues and
+// bindings the services share. Producers resolve outbox event names with
+// Publishing and consumers build their subscriptions with Subscription, so
+// names and settings are defined once.
+type Topology struct {
+	Exchanges []ExchangeSpec
+	Queues    []QueueSpec
+	Services  []ServiceSpec
+}
+
+// Registry is the messaging topology of the platform.
+var Registry = Topology{
+	Exchanges: []ExchangeSpec{
+		{Name: OrderCreatedExchangeName, Kind: broker.ExchangeFanout},
+		{Name: StockUpdateFailedExchangeName, Kind: broker.ExchangeFanout},
+		{
+			Name: ProductExchangeName,
+			Kind: broker.ExchangeTopic,
+			RoutingKeys: []string{
+				ProductCreatedRoutingKey,
+				ProductUpdatedRoutingKey,
+				ProductDeletedRoutingKey,
+			},
+		},
+		{
+			Name: OrderExchangeName,
+			Kind: broker.ExchangeTopic,
+			RoutingKeys: []string{
+				OrderCreatedRoutingKey,
+				OrderPaidRoutingKey,
+				OrderCancelledRoutingKey,
+				OrderShippedRoutingKey,
+				OrderDeliveredRoutingKey,
+			},
+		},
+		{
+			Name: PaymentExchangeName,
+			Kind: broker.ExchangeTopic,
+			RoutingKeys: []string{
+				PaymentSucceededRoutingKey,
+				PaymentRejectedRoutingKey,
+				PaymentRefundedRoutingKey,
+			},
+		},
+		{
+			Name: UserExchangeName,
+			Kind: broker.ExchangeTopic,
+			RoutingKeys: []string{
+				UserRegisteredRoutingKey,
+				UserUpdatedRoutingKey,
+				UserDeletedRoutingKey,
+			},
+			AllowUnroutable: true,
+		},
+	},
+	Queues: []QueueSpec{
+		{Name: CartOrderCreatedQueueName, Exchange: OrderCreatedExchangeName},
+		{Name: ProductOrderCreatedQueueName, Exchange: OrderCreatedExchangeName},
+		{Name: NotificationOrderCreatedQueueName, Exchange: OrderCreatedExchangeName},
+		{Name: NotificationUserRegisteredQueueName, Exchange: UserExchangeName, BindingKey: UserRegisteredRoutingKey},
+		{Name: OrderStockUpdateFailedQueueName, Exchange: StockUpdateFailedExchangeName},
+		{Name: OrderUserEventsQueueName, Exchange: UserExchangeName, BindingKey: UserWildcardRoutingKey},
+		{Name: OrderPaymentEventsQueueName, Exchange: PaymentExchangeName, BindingKey: PaymentWildcardRoutingKey},
+		{Name: NotificationOrderEventsQueueName, Exchange: OrderExchangeName, BindingKey: OrderWildcardRoutingKey},
+		{Name: SearchProductEventsQueueName, Exchange: ProductExchangeName, BindingKey: ProductWildcardRoutingKey},
+	},
+	Services: []ServiceSpec{
+		{Name: "cart-service", Consumes: []string{CartOrderCreatedQueueName}},
+		{
+			Name:     "notification-service",
+			Consumes: []string{NotificationOrderCreatedQueueName, NotificationUserRegisteredQueueName, NotificationOrderEventsQueueName},
+		},
+		{
+			Name:      "order-service",
+			Publishes: []string{OrderCreatedExchangeName, OrderExchangeName},
+			Consumes:  []string{OrderStockUpdateFailedQueueName, OrderUserEventsQueueName, OrderPaymentEventsQueueName},
+		},
+		{Name: "payment-service", Publishes: []string{PaymentExchangeName}},
+		{
+			Name:      "product-service",
+			Publishes: []string{ProductExchangeName, StockUpdateFailedExchangeName},
+			Consumes:  []string{ProductOrderCreatedQueueName},
+		},
+		{Name: "search-service", Consumes: []string{SearchProductEventsQueueName}},
+		{Name: "user-service", Publishes: []string{UserExchangeName}},
+	},
+}
+
+func (t Topology) Exchange(name string) (ExchangeSpec, error) {
+	for _, exchange := range t.Exchanges {
+		if exchange.Name == name {
+			return exchange, nil
+		}
+	}
+	return ExchangeSpec{}, fmt.Errorf("%w: %s", ErrUnknownExchange, name)
+}
+
+func (t Topology) Queue(name string) (QueueSpec, error) {
+	for _, queue := range t.Queues {
+		if queue.Name == name {
+			return queue, nil
+		}
+	}
+	return QueueSpec{}, fmt.Errorf("%w: %s", ErrUnknownQueue, name)
+}
+
+// Subscription returns the subscription for a queue with its exchange,
+// binding and retry settings. The caller sets the consumer tag, the handler
+// and the concurrency settings.
+func (t Topology) Subscription(queueName string) (broker.Subscription, error) {
+	queue, err := t.Queue(queueName)
+	if err != nil {
+		return broker.Subscription{}, err
+	}
+	exchange, err := t.Exchange(queue.Exchange)
+	if err != nil {
+		return broker.Subscription{}, err
+	}
+
+	return broker.Subscription{
+		Exchange:    exchange.Name,
+		Kind:        exchange.Kind,
+		Queue:       queue.Name,
+		BindingKey:  queue.BindingKey,
+		MaxRetries:  queue.MaxRetries,
+		RetryDelays: queue.RetryDelays,
+	}, nil
+}
+
+// Publishing resolves an outbox event name to the exchange, its kind and
+// the routing key to publish to.
+func (t Topology) Publishing(eventName string, body []byte) (broker.Publishing, error) {
+	exchangeName, routingKey, _ := strings.Cut(eventName, ":")
+	exchange, err := t.Exchange(exchangeName)
+	if err != nil {
+		return broker.Publishing{}, err
+	}
+	if exchange.Kind != broker.ExchangeFanout && !slices.Contains(exchange.RoutingKeys, routingKey) {
+		return broker.Publishing{}, fmt.Errorf("%w: %q on %s", ErrUnknownRoutingKey, routingKey, exchange.Name)
+	}
+
+	return broker.Publishing{
+		Exchange:        exchange.Name,
+		Kind:            exchange.Kind,
+		RoutingKey:      routingKey,
+		Body:            body,
+		AllowUnroutable: exchange.AllowUnroutable,
+	}, nil
+}
+
+// ForService returns the part of the topology a service publishes to and
+// consumes from, including the exchanges its queues are bound to.
+func (t Topology) ForService(name string) (Topology, error) {
+	idx := slices.IndexFunc(t.Services, func(s ServiceSpec) bool { return s.Name == name })
+	if idx < 0 {
+		return Topology{}, fmt.Errorf("%w: %s", ErrUnknownService, name)
+	}
+	service := t.Services[idx]
+
+	scoped := Topology{Services: []ServiceSpec{service}}
+	addExchange := func(name string) error {
+		if slices.ContainsFunc(scoped.Exchanges, func(e ExchangeSpec) bool { return e.Name == name }) {
+			return nil
+		}
+		exchange, err := t.Exchange(name)
+		if err != nil {
+			return err
+		}
+		scoped.Exchanges = append(scoped.Exchanges, exchange)
+		return nil
+	}
+
+	for _, name := range service.Publishes {
+		if err := addExchange(name); err != nil {
+			return Topology{}, err
+		}
+	}
+	for _, name := range service.Consumes {
+		queue, err := t.Queue(name)
+		if err != nil {
+			return Topology{}, err
+		}
+		if err := addExchange(queue.Exchange); err != nil {
+			return Topology{}, err
+		}
+		scoped.Queues = append(scoped.Queues, queue)
+	}
+	return scoped, nil
+}
+
+// Validate checks that names are unique, that every reference resolves and
+// that every queue binding matches at least one published routing key.
+func (t Topology) Validate() error {
+	var errs []error
+
+	exchanges := make(map[string]ExchangeSpec, len(t.Exchanges))
+	for _, exchange := range t.Exchanges {
+		if _, ok := exchanges[exchange.Name]; ok {
+			errs = append(errs, fmt.Errorf("exchange %s is declared twice", exchange.Name))
+		}
+		exchanges[exchange.Name] = exchange
+
+		switch exchange.Kind {
+		case broker.ExchangeFanout:
+			if len(exchange.RoutingKeys) > 0 {
+				errs = append(errs, fmt.Errorf("fanout exchange %s has routing keys", exchange.Name))
+			}
+		case broker.ExchangeTopic, broker.ExchangeDirect:
+		default:
+			errs = append(errs, fmt.Errorf("exchange %s has invalid kind %q", exchange.Name, exchange.Kind))
+		}
+	}
+
+	queues := make(map[string]bool, len(t.Queues))
+	for _, queue := range t.Queues {
+		if queues[queue.Name] {
+			errs = append(errs, fmt.Errorf("queue %s is declared twice", queue.Name))
+		}
+		queues[queue.Name] = true
+
+		exchange, ok := exchanges[queue.Exchange]
+		if !ok {
+			errs = append(errs, fmt.Errorf("queue %s: %w: %s", queue.Name, ErrUnknownExchange, queue.Exchange))
+			continue
+		}
+		if exchange.Kind != broker.ExchangeFanout && !slices.ContainsFunc(exchange.RoutingKeys, func(key string) bool {
+			return broker.BindingMatches(exchange.Kind, queue.BindingKey, key)
+		}) {
+			errs = append(errs, fmt.Errorf("queue %s: binding key %q matches no routing key of %s", queue.Name, queue.BindingKey, exchange.Name))
+		}
+	}
+
+	for _, service := range t.Services {
+		for _, name := range service.Publishes {
+			if _, ok := exchanges[name]; !ok {
+				errs = append(errs, fmt.Errorf("service %s: %w: %s", service.Name, ErrUnknownExchange, name))
+			}
+		}
+		for _, name := range service.Consumes {
+			if !queues[name] {
+				errs = append(errs, fmt.Errorf("service %s: %w: %s", service.Name, ErrUnknownQueue, name))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckRouting reports routing keys of exchanges without AllowUnroutable
+// that no queue is bound to, whose publishes the broker would return. It only
+// applies to the whole topology, since the part of a single service lacks
+// the queues of the others.
+func (t Topology) CheckRouting() error {
+	var errs []error
+	for _, exchange := range t.Exchanges {
+		if exchange.AllowUnroutable {
+			continue
+		}
+
+		routed := func(key string) bool {
+			return slices.ContainsFunc(t.Queues, func(queue QueueSpec) bool {
+				return queue.Exchange == exchange.Name && broker.BindingMatches(exchange.Kind, queue.BindingKey, key)
+			})
+		}
+		if exchange.Kind == broker.ExchangeFanout {
+			if !routed("") {
+				errs = append(errs, fmt.Errorf("fanout exchange %s reaches no queue", exchange.Name))
+			}
+			continue
+		}
+		for _, key := range exchange.RoutingKeys {
+			if !routed(key) {
+				errs = append(errs, fmt.Errorf("routing key %q of %s reaches no queue", key, exchange.Name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type DriftKind string
+
+const (
+	// DriftMissing means the object is not declared in the broker yet.
+	DriftMissing DriftKind = "missing"
+	// DriftMismatch means the broker has the object with a different type or
+	// arguments than the topology.
+	DriftMismatch DriftKind = "mismatch"
+)
+
+// Drift is a difference between the topology and what is declared in the
+// broker.
+type Drift struct {
+	Kind   DriftKind
+	Object string
+	Name   string
+	Detail string
+}
+
+func (d Drift) String() string {
+	s := fmt.Sprintf("%s %s is %s", d.Object, d.Name, d.Kind)
+	if d.Detail != "" {
+		s += ": " + d.Detail
+	}
+	return s
+}
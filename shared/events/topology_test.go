@@ -0,0 +1,139 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryIsValid(t *testing.T) {
+	require.NoError(t, Registry.Validate())
+	require.NoError(t, Registry.CheckRouting())
+}
+
+func TestTopologyCheckRouting(t *testing.T) {
+	topology := Topology{
+		Exchanges: []ExchangeSpec{
+			{Name: "orders", Kind: broker.ExchangeTopic, RoutingKeys: []string{"order.created", "order.paid"}},
+			{Name: "users", Kind: broker.ExchangeTopic, RoutingKeys: []string{"user.deleted"}, AllowUnroutable: true},
+			{Name: "legacy", Kind: broker.ExchangeFanout},
+		},
+		Queues: []QueueSpec{
+			{Name: "billing", Exchange: "orders", BindingKey: "order.created"},
+		},
+	}
+
+	err := topology.CheckRouting()
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `routing key "order.paid" of orders reaches no queue`)
+	assert.ErrorContains(t, err, "fanout exchange legacy reaches no queue")
+	assert.NotContains(t, err.Error(), "order.created")
+	assert.NotContains(t, err.Error(), "users")
+}
+
+func TestTopologyValidate(t *testing.T) {
+	topology := Topology{
+		Exchanges: []ExchangeSpec{
+			{Name: "orders", Kind: broker.ExchangeTopic, RoutingKeys: []string{"order.created"}},
+			{Name: "legacy", Kind: broker.ExchangeFanout, RoutingKeys: []string{"order.paid"}},
+			{Name: "legacy", Kind: broker.ExchangeFanout},
+			{Name: "payments", Kind: "headers"},
+		},
+		Queues: []QueueSpec{
+			{Name: "billing", Exchange: "orders", BindingKey: "payment.#"},
+			{Name: "audit", Exchange: "missing"},
+		},
+		Services: []ServiceSpec{
+			{Name: "billing-service", Publishes: []string{"invoices"}, Consumes: []string{"ledger"}},
+		},
+	}
+
+	err := topology.Validate()
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "exchange legacy is declared twice")
+	assert.ErrorContains(t, err, "fanout exchange legacy has routing keys")
+	assert.ErrorContains(t, err, `exchange payments has invalid kind "headers"`)
+	assert.ErrorContains(t, err, `binding key "payment.#" matches no routing key of orders`)
+	assert.ErrorIs(t, err, ErrUnknownExchange)
+	assert.ErrorIs(t, err, ErrUnknownQueue)
+}
+
+func TestTopologySubscription(t *testing.T) {
+	topology := Registry
+	topology.Queues = append(topology.Queues, QueueSpec{
+		Name:        "slow_queue",
+		Exchange:    ProductExchangeName,
+		BindingKey:  ProductDeletedRoutingKey,
+		MaxRetries:  2,
+		RetryDelays: []time.Duration{time.Minute},
+	})
+
+	sub, err := topology.Subscription(SearchProductEventsQueueName)
+	require.NoError(t, err)
+	assert.Equal(t, broker.Subscription{
+		Exchange:   ProductExchangeName,
+		Kind:       broker.ExchangeTopic,
+		Queue:      SearchProductEventsQueueName,
+		BindingKey: ProductWildcardRoutingKey,
+	}, sub)
+
+	sub, err = topology.Subscription("slow_queue")
+	require.NoError(t, err)
+	assert.Equal(t, 2, sub.MaxRetries)
+	assert.Equal(t, []time.Duration{time.Minute}, sub.RetryDelays)
+
+	_, err = topology.Subscription("unknown_queue")
+	assert.ErrorIs(t, err, ErrUnknownQueue)
+}
+
+func TestTopologyPublishing(t *testing.T) {
+	publishing, err := Registry.Publishing(ProductDeletedEventName, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, broker.Publishing{
+		Exchange:   ProductExchangeName,
+		Kind:       broker.ExchangeTopic,
+		RoutingKey: ProductDeletedRoutingKey,
+		Body:       []byte(`{}`),
+	}, publishing)
+
+	publishing, err = Registry.Publishing(LegacyOrderCreatedEventName, nil)
+	require.NoError(t, err)
+	assert.Equal(t, broker.ExchangeFanout, publishing.Kind)
+	assert.Empty(t, publishing.RoutingKey)
+	assert.False(t, publishing.AllowUnroutable)
+
+	publishing, err = Registry.Publishing(OrderShippedEventName, nil)
+	require.NoError(t, err)
+	assert.Equal(t, OrderExchangeName, publishing.Exchange)
+	assert.Equal(t, OrderShippedRoutingKey, publishing.RoutingKey)
+	assert.False(t, publishing.AllowUnroutable)
+
+	publishing, err = Registry.Publishing(UserUpdatedEventName, nil)
+	require.NoError(t, err)
+	assert.True(t, publishing.AllowUnroutable)
+
+	_, err = Registry.Publishing(ProductExchangeName, nil)
+	assert.ErrorIs(t, err, ErrUnknownRoutingKey)
+}
+
+func TestTopologyForService(t *testing.T) {
+	topology, err := Registry.ForService("product-service")
+	require.NoError(t, err)
+
+	var exchanges []string
+	for _, exchange := range topology.Exchanges {
+		exchanges = append(exchanges, exchange.Name)
+	}
+	assert.Equal(t, []string{ProductExchangeName, StockUpdateFailedExchangeName, OrderCreatedExchangeName}, exchanges)
+	require.Len(t, topology.Queues, 1)
+	assert.Equal(t, ProductOrderCreatedQueueName, topology.Queues[0].Name)
+	assert.NoError(t, topology.Validate())
+
+	_, err = Registry.ForService("unknown-service")
+	assert.ErrorIs(t, err, ErrUnknownService)
+}
@@ -0,0 +1,9 @@
+package events
+
+// UserEvent is the payload of user.registered, user.updated and
+// user.deleted. Deleted events carry the user as it was before deletion.
+type UserEvent struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+)
+
+// EventPublisher adapts a broker.Publisher to the relayer, resolving outbox
+// event names through the topology. Events whose name is not in the
+// topology fail to publish and are retried like any other failure.
+type EventPublisher struct {
+	publisher broker.Publisher
+	topology  events.Topology
+}
+
+func NewEventPublisher(publisher broker.Publisher, topology events.Topology) *EventPublisher {
+	return &EventPublisher{
+		publisher: publisher,
+		topology:  topology,
+	}
+}
+
+func (ep *EventPublisher) Publish(ctx context.Context, eventName string, body []byte) error {
+	publishing, err := ep.topology.Publishing(eventName, body)
+	if err != nil {
+		return err
+	}
+	return ep.publisher.Publish(ctx, publishing)
+}
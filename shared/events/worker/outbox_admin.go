@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	OutboxStatusUnpublished = "UNPUBLISHED"
+	OutboxStatusFailed      = "FAILED"
+
+	// OutboxAdminPath is the prefix the outbox admin handler serves.
+	OutboxAdminPath = "/admin/outbox/"
+
+	defaultAdminListLimit = 50
+	maxAdminListLimit     = 500
+)
+
+// ErrOutboxEventNotRequeueable is returned when the event does not exist, is
+// neither pending nor failed, or is pending and leased by a relayer.
+var ErrOutboxEventNotRequeueable = errors.New("outbox event is not failed or waiting for a retry")
+
+// OutboxAdminRepository lists outbox events by status and requeues an event,
+// which resets its attempts and error and makes it claimable right away.
+type OutboxAdminRepository interface {
+	ListOutboxEvents(ctx context.Context, statuses []string, limit int32) ([]events.OutboxEvent, error)
+	RequeueOutboxEvent(ctx context.Context, eventID string) (events.OutboxEvent, error)
+}
+
+type outboxEventResponse struct {
+	ID            string          `json:"id"`
+	AggregateID   string          `json:"aggregateId"`
+	EventName     string          `json:"eventName"`
+	Status        any             `json:"status"`
+	Attempts      int32           `json:"attempts"`
+	LastError     string          `json:"lastError,omitempty"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// NewOutboxAdminHandler serves the outbox admin endpoints under
+// OutboxAdminPath:
+//
+//	GET  /admin/outbox/events?status=FAILED&limit=50  lists pending and failed events, oldest first
+//	POST /admin/outbox/events/{id}/requeue            requeues a pending or failed event
+//
+// It is meant for the internal metrics server, which is not exposed through
+// the gateway.
+func NewOutboxAdminHandler(logger logs.Logger, repo OutboxAdminRepository) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+OutboxAdminPath+"events", func(w http.ResponseWriter, r *http.Request) {
+		statuses, err := parseAdminStatuses(r.URL.Query().Get("status"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit, err := parseAdminLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		outboxEvents, err := repo.ListOutboxEvents(r.Context(), statuses, limit)
+		if err != nil {
+			logger.Error("failed to list outbox events", "error", err)
+			http.Error(w, "failed to list outbox events", http.StatusInternalServerError)
+			return
+		}
+
+		response := make([]outboxEventResponse, 0, len(outboxEvents))
+		for _, event := range outboxEvents {
+			response = append(response, toOutboxEventResponse(event))
+		}
+		writeAdminJSON(w, response)
+	})
+	mux.HandleFunc("POST "+OutboxAdminPath+"events/{id}/requeue", func(w http.ResponseWriter, r *http.Request) {
+		eventID := r.PathValue("id")
+		event, err := repo.RequeueOutboxEvent(r.Context(), eventID)
+		if errors.Is(err, ErrOutboxEventNotRequeueable) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to requeue outbox event", "error", err, "eventId", eventID)
+			http.Error(w, "failed to requeue outbox event", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Info("outbox event requeued", "eventId", event.ID, "eventName", event.EventName)
+		writeAdminJSON(w, toOutboxEventResponse(event))
+	})
+	return mux
+}
+
+// parseAdminStatuses accepts a comma separated list of UNPUBLISHED and
+// FAILED and defaults to both.
+func parseAdminStatuses(value string) ([]string, error) {
+	if value == "" {
+		return []string{OutboxStatusUnpublished, OutboxStatusFailed}, nil
+	}
+
+	var statuses []string
+	for _, status := range strings.Split(value, ",") {
+		status = strings.ToUpper(strings.TrimSpace(status))
+		if status != OutboxStatusUnpublished && status != OutboxStatusFailed {
+			return nil, fmt.Errorf("invalid status %q, expected %s or %s", status, OutboxStatusUnpublished, OutboxStatusFailed)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func parseAdminLimit(value string) (int32, error) {
+	if value == "" {
+		return defaultAdminListLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxAdminListLimit {
+		return 0, fmt.Errorf("invalid limit %q, expected 1 to %d", value, maxAdminListLimit)
+	}
+	return int32(limit), nil
+}
+
+func toOutboxEventResponse(event events.OutboxEvent) outboxEventResponse {
+	return outboxEventResponse{
+		ID:            event.ID,
+		AggregateID:   event.AggregateID,
+		EventName:     event.EventName,
+		Status:        event.Status,
+		Attempts:      event.Attempts,
+		LastError:     event.LastError,
+		NextAttemptAt: event.NextAttemptAt,
+		CreatedAt:     event.CreatedAt,
+		Payload:       json.RawMessage(event.Payload),
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(data)
+}
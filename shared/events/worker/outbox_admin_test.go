@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockOutboxAdminRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxAdminRepository) ListOutboxEvents(ctx context.Context, statuses []string, limit int32) ([]events.OutboxEvent, error) {
+	args := m.Called(ctx, statuses, limit)
+	return args.Get(0).([]events.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxAdminRepository) RequeueOutboxEvent(ctx context.Context, eventID string) (events.OutboxEvent, error) {
+	args := m.Called(ctx, eventID)
+	return args.Get(0).(events.OutboxEvent), args.Error(1)
+}
+
+func TestOutboxAdminHandler(t *testing.T) {
+	failedEvent := events.OutboxEvent{
+		ID:        "event-1",
+		EventName: events.OrderPaidEventName,
+		Payload:   []byte(`{"orderId":"order-1"}`),
+		Status:    OutboxStatusFailed,
+		Attempts:  10,
+		LastError: "exchange not found",
+	}
+
+	t.Run("ListsPendingAndFailedByDefault", func(t *testing.T) {
+		repo := new(MockOutboxAdminRepository)
+		repo.On("ListOutboxEvents", mock.Anything, []string{OutboxStatusUnpublished, OutboxStatusFailed}, int32(defaultAdminListLimit)).
+			Return([]events.OutboxEvent{failedEvent}, nil).Once()
+
+		rec := httptest.NewRecorder()
+		NewOutboxAdminHandler(logs.NewSlogLogger(), repo).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/outbox/events", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body []map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body, 1)
+		assert.Equal(t, "event-1", body[0]["id"])
+		assert.Equal(t, "exchange not found", body[0]["lastError"])
+		assert.Equal(t, map[string]any{"orderId": "order-1"}, body[0]["payload"])
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("FiltersByStatus", func(t *testing.T) {
+		repo := new(MockOutboxAdminRepository)
+		repo.On("ListOutboxEvents", mock.Anything, []string{OutboxStatusFailed}, int32(10)).Return([]events.OutboxEvent{}, nil).Once()
+
+		rec := httptest.NewRecorder()
+		NewOutboxAdminHandler(logs.NewSlogLogger(), repo).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/outbox/events?status=failed&limit=10", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[]`, rec.Body.String())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("RejectsInvalidQuery", func(t *testing.T) {
+		repo := new(MockOutboxAdminRepository)
+		handler := NewOutboxAdminHandler(logs.NewSlogLogger(), repo)
+
+		for _, target := range []string{"/admin/outbox/events?status=PUBLISHED", "/admin/outbox/events?limit=0"} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		}
+		repo.AssertNotCalled(t, "ListOutboxEvents", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Requeues", func(t *testing.T) {
+		requeued := failedEvent
+		requeued.Status = OutboxStatusUnpublished
+		requeued.Attempts = 0
+		requeued.LastError = ""
+
+		repo := new(MockOutboxAdminRepository)
+		repo.On("RequeueOutboxEvent", mock.Anything, "event-1").Return(requeued, nil).Once()
+		repo.On("RequeueOutboxEvent", mock.Anything, "event-2").Return(events.OutboxEvent{}, ErrOutboxEventNotRequeueable).Once()
+		handler := NewOutboxAdminHandler(logs.NewSlogLogger(), repo)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/outbox/events/event-1/requeue", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"UNPUBLISHED"`)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/outbox/events/event-2/requeue", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		repo.AssertExpectations(t)
+	})
+}
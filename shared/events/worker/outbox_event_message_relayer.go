@@ -2,15 +2,144 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/sonuudigital/microservices/shared/events"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
+	"github.com/sonuudigital/microservices/shared/postgres"
+	"github.com/sonuudigital/microservices/shared/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	DefaultMaxAttempts = 10
+	DefaultBaseBackoff = 2 * time.Second
+	DefaultMaxBackoff  = 5 * time.Minute
+	DefaultClaimLease  = 30 * time.Second
+
+	// OutboxNotifyChannel is the channel the outbox_events insert trigger
+	// notifies.
+	OutboxNotifyChannel = "outbox_events"
+
+	RelayerModeNotify = "notify"
+	RelayerModePoll   = "poll"
+
+	DefaultRelayerBatchSize          = 25
+	DefaultRelayerPollInterval       = 5 * time.Second
+	DefaultRelayerNotifyPollInterval = 30 * time.Second
+)
+
+// OutboxEventRepository claims events with FOR UPDATE SKIP LOCKED, so several
+// relayer replicas can poll the same table. Claiming increments the attempt
+// counter and pushes next_attempt_at forward by the lease, which makes events
+// claimed by a crashed relayer visible again once the lease expires.
 type OutboxEventRepository interface {
-	GetUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]events.OutboxEvent, error)
+	ClaimUnpublishedOutboxEvents(ctx context.Context, limit int32, lease time.Duration) ([]events.OutboxEvent, error)
 	UpdateOutboxEventStatus(ctx context.Context, eventID string) error
+	RecordOutboxEventFailure(ctx context.Context, eventID, lastError string, nextAttemptAt time.Time, failed bool) error
+	// OutboxBacklog returns the number of unpublished events and the
+	// creation time of the oldest one, zero when there is none.
+	OutboxBacklog(ctx context.Context) (int64, time.Time, error)
+}
+
+type RetryPolicy struct {
+	MaxAttempts int32
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	ClaimLease  time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: DefaultMaxAttempts,
+		BaseBackoff: DefaultBaseBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+		ClaimLease:  DefaultClaimLease,
+	}
+}
+
+// RetryPolicyFromEnv reads MESSAGE_RELAYER_MAX_ATTEMPTS,
+// MESSAGE_RELAYER_BASE_BACKOFF, MESSAGE_RELAYER_MAX_BACKOFF and
+// MESSAGE_RELAYER_CLAIM_LEASE, falling back to the defaults.
+func RetryPolicyFromEnv() (RetryPolicy, error) {
+	policy := DefaultRetryPolicy()
+
+	if value := os.Getenv("MESSAGE_RELAYER_MAX_ATTEMPTS"); value != "" {
+		maxAttempts, err := strconv.Atoi(value)
+		if err != nil || maxAttempts < 1 {
+			return RetryPolicy{}, fmt.Errorf("invalid MESSAGE_RELAYER_MAX_ATTEMPTS: %q", value)
+		}
+		policy.MaxAttempts = int32(maxAttempts)
+	}
+
+	durations := []struct {
+		env    string
+		target *time.Duration
+	}{
+		{"MESSAGE_RELAYER_BASE_BACKOFF", &policy.BaseBackoff},
+		{"MESSAGE_RELAYER_MAX_BACKOFF", &policy.MaxBackoff},
+		{"MESSAGE_RELAYER_CLAIM_LEASE", &policy.ClaimLease},
+	}
+	for _, d := range durations {
+		value := os.Getenv(d.env)
+		if value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return RetryPolicy{}, fmt.Errorf("invalid %s: %w", d.env, err)
+		}
+		*d.target = duration
+	}
+
+	return policy, nil
+}
+
+// Backoff returns the delay before the next attempt of an event that has
+// already been tried attempts times: BaseBackoff * 2^(attempts-1), capped at
+// MaxBackoff.
+func (p RetryPolicy) Backoff(attempts int32) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := p.BaseBackoff
+	for i := int32(1); i < attempts; i++ {
+		backoff *= 2
+		if backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return min(backoff, p.MaxBackoff)
+}
+
+// Listener delivers a signal whenever new outbox events may be available.
+type Listener interface {
+	Listen(ctx context.Context, notify chan<- struct{})
+}
+
+type RelayerOption func(*OutboxEventMessageRelayer)
+
+// WithListener switches the relayer to notify-driven mode: the outbox is
+// drained as soon as the listener signals, and pollInterval only acts as a
+// safety net for missed notifications.
+func WithListener(listener Listener) RelayerOption {
+	return func(oemr *OutboxEventMessageRelayer) {
+		oemr.listener = listener
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) RelayerOption {
+	return func(oemr *OutboxEventMessageRelayer) {
+		oemr.retryPolicy = policy
+	}
 }
 
 type Publisher interface {
@@ -23,6 +152,8 @@ type OutboxEventMessageRelayer struct {
 	repo         OutboxEventRepository
 	pollInterval time.Duration
 	batchSize    int32
+	retryPolicy  RetryPolicy
+	listener     Listener
 }
 
 func NewOutboxEventMessageRelayer(
@@ -31,53 +162,207 @@ func NewOutboxEventMessageRelayer(
 	repo OutboxEventRepository,
 	pollInterval time.Duration,
 	batchSize int32,
+	opts ...RelayerOption,
 ) *OutboxEventMessageRelayer {
-	return &OutboxEventMessageRelayer{
+	oemr := &OutboxEventMessageRelayer{
 		logger:       logger,
 		publisher:    publisher,
 		repo:         repo,
 		pollInterval: pollInterval,
 		batchSize:    batchSize,
+		retryPolicy:  DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(oemr)
 	}
+	return oemr
+}
+
+// NewOutboxEventMessageRelayerFromEnv configures a relayer from
+// MESSAGE_RELAYER_MODE (notify, the default, or poll),
+// MESSAGE_RELAYER_POLL_INTERVAL, MESSAGE_RELAYER_BATCH_SIZE and the retry
+// policy variables. In notify mode it listens on DATABASE_URL.
+func NewOutboxEventMessageRelayerFromEnv(logger logs.Logger, publisher Publisher, repo OutboxEventRepository) (*OutboxEventMessageRelayer, error) {
+	mode := os.Getenv("MESSAGE_RELAYER_MODE")
+	if mode == "" {
+		mode = RelayerModeNotify
+	}
+
+	pollInterval := DefaultRelayerNotifyPollInterval
+	var opts []RelayerOption
+	switch mode {
+	case RelayerModeNotify:
+		opts = append(opts, WithListener(postgres.NewListener(logger, os.Getenv("DATABASE_URL"), OutboxNotifyChannel)))
+	case RelayerModePoll:
+		pollInterval = DefaultRelayerPollInterval
+	default:
+		return nil, fmt.Errorf("invalid MESSAGE_RELAYER_MODE: %s", mode)
+	}
+
+	if value := os.Getenv("MESSAGE_RELAYER_POLL_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid MESSAGE_RELAYER_POLL_INTERVAL: %q", value)
+		}
+		pollInterval = interval
+	}
+
+	batchSize := DefaultRelayerBatchSize
+	if value := os.Getenv("MESSAGE_RELAYER_BATCH_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid MESSAGE_RELAYER_BATCH_SIZE: %q", value)
+		}
+		batchSize = size
+	}
+
+	retryPolicy, err := RetryPolicyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, WithRetryPolicy(retryPolicy))
+
+	return NewOutboxEventMessageRelayer(logger, publisher, repo, pollInterval, int32(batchSize), opts...), nil
 }
 
 func (oemr *OutboxEventMessageRelayer) Start(ctx context.Context) {
-	oemr.logger.Info("starting outbox event message relayer worker")
+	mode := "poll"
+	notifications := make(chan struct{}, 1)
+	if oemr.listener != nil {
+		mode = "notify"
+		go oemr.listener.Listen(ctx, notifications)
+	}
+
+	oemr.logger.Info("starting outbox event message relayer worker", "mode", mode, "pollInterval", oemr.pollInterval)
 	ticker := time.NewTicker(oemr.pollInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			if err := oemr.processEvents(ctx); err != nil {
-				oemr.logger.Error("error processing outbox events", "error", err)
-			}
+		case <-notifications:
 		case <-ctx.Done():
 			oemr.logger.Info("stopping outbox event message relayer worker")
 			return
 		}
+
+		if err := oemr.processEvents(ctx); err != nil {
+			oemr.logger.Error("error processing outbox events", "error", err)
+		}
 	}
 }
 
+// processEvents keeps claiming batches until a partial batch is returned, so a
+// burst of events is drained without waiting for the next tick. Failed events
+// are pushed into the future when claimed, which guarantees progress.
 func (oemr *OutboxEventMessageRelayer) processEvents(ctx context.Context) error {
-	events, err := oemr.repo.GetUnpublishedOutboxEvents(ctx, oemr.batchSize)
+	defer oemr.reportBacklog(ctx)
+
+	for ctx.Err() == nil {
+		claimed, err := oemr.relayBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if claimed < int(oemr.batchSize) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (oemr *OutboxEventMessageRelayer) relayBatch(ctx context.Context) (int, error) {
+	events, err := oemr.repo.ClaimUnpublishedOutboxEvents(ctx, oemr.batchSize, oemr.retryPolicy.ClaimLease)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	// UPDATE ... RETURNING does not keep the ORDER BY of the claim, and the
+	// events of an aggregate have to be published in the order they were
+	// written. Once one of them fails, the later ones are left to the claim
+	// lease; the claim query skips them until the failed event is published.
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+
+	blocked := make(map[string]string)
 	for _, event := range events {
-		if err := oemr.publisher.Publish(ctx, event.EventName, event.Payload); err != nil {
-			oemr.logger.Error("failed to publish outbox event", "eventID", event.ID, "error", err)
+		if failedEventID, ok := blocked[event.AggregateID]; ok {
+			oemr.logger.Warn("skipping outbox event until an earlier event of its aggregate is published", "eventID", event.ID, "aggregateID", event.AggregateID, "blockedBy", failedEventID)
 			continue
 		}
-
-		if err := oemr.repo.UpdateOutboxEventStatus(ctx, event.ID); err != nil {
-			oemr.logger.Error("failed to update outbox event status", "eventID", event.ID, "error", err)
-			continue
+		if !oemr.relayEvent(ctx, event) {
+			blocked[event.AggregateID] = event.ID
 		}
+	}
+
+	return len(events), nil
+}
 
-		oemr.logger.Info("successfully relayed outbox event", "eventID", event.ID)
+// reportBacklog updates the backlog metrics from the whole table, including
+// events that are waiting for a retry or claimed by another relayer.
+func (oemr *OutboxEventMessageRelayer) reportBacklog(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
 	}
 
-	return nil
+	size, oldestCreatedAt, err := oemr.repo.OutboxBacklog(ctx)
+	if err != nil {
+		oemr.logger.Warn("failed to read outbox backlog", "error", err)
+		return
+	}
+	metrics.SetOutboxBacklog(int(size), oldestCreatedAt)
+}
+
+// relayEvent publishes the event and marks it published, reporting whether
+// both succeeded.
+func (oemr *OutboxEventMessageRelayer) relayEvent(ctx context.Context, event events.OutboxEvent) bool {
+	ctx, span := tracing.Tracer().Start(
+		tracing.UnmarshalTraceContext(ctx, event.TraceContext),
+		"outbox relay "+event.EventName,
+		trace.WithAttributes(
+			attribute.String("outbox.event_id", event.ID),
+			attribute.String("outbox.aggregate_id", event.AggregateID),
+		),
+	)
+	defer span.End()
+
+	if err := oemr.publisher.Publish(ctx, event.EventName, event.Payload); err != nil {
+		metrics.IncOutboxRelayed(event.EventName, err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "publish failed")
+		oemr.logger.Error("failed to publish outbox event", "eventID", event.ID, "attempts", event.Attempts, "error", err)
+		oemr.recordFailure(ctx, event, err)
+		return false
+	}
+
+	if err := oemr.repo.UpdateOutboxEventStatus(ctx, event.ID); err != nil {
+		metrics.IncOutboxRelayed(event.EventName, err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "status update failed")
+		oemr.logger.Error("failed to update outbox event status", "eventID", event.ID, "attempts", event.Attempts, "error", err)
+		oemr.recordFailure(ctx, event, err)
+		return false
+	}
+
+	metrics.IncOutboxRelayed(event.EventName, nil)
+	oemr.logger.Info("successfully relayed outbox event", "eventID", event.ID)
+	return true
+}
+
+func (oemr *OutboxEventMessageRelayer) recordFailure(ctx context.Context, event events.OutboxEvent, cause error) {
+	failed := event.Attempts >= oemr.retryPolicy.MaxAttempts
+	nextAttemptAt := time.Now().Add(oemr.retryPolicy.Backoff(event.Attempts))
+
+	if err := oemr.repo.RecordOutboxEventFailure(ctx, event.ID, cause.Error(), nextAttemptAt, failed); err != nil {
+		oemr.logger.Error("failed to record outbox event failure", "eventID", event.ID, "error", err)
+		return
+	}
+
+	if failed {
+		metrics.IncOutboxFailed(event.EventName)
+		oemr.logger.Error("outbox event marked as failed after max attempts", "eventID", event.ID, "attempts", event.Attempts)
+		return
+	}
+
+	oemr.logger.Warn("outbox event scheduled for retry", "eventID", event.ID, "attempts", event.Attempts, "nextAttemptAt", nextAttemptAt)
 }
@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/broker/memory"
 	"github.com/sonuudigital/microservices/shared/events"
 	"github.com/sonuudigital/microservices/shared/logs"
 	"github.com/stretchr/testify/assert"
@@ -15,8 +19,8 @@ type MockOutboxEventRepository struct {
 	mock.Mock
 }
 
-func (m *MockOutboxEventRepository) GetUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]events.OutboxEvent, error) {
-	args := m.Called(ctx, limit)
+func (m *MockOutboxEventRepository) ClaimUnpublishedOutboxEvents(ctx context.Context, limit int32, lease time.Duration) ([]events.OutboxEvent, error) {
+	args := m.Called(ctx, limit, lease)
 	return args.Get(0).([]events.OutboxEvent), args.Error(1)
 }
 
@@ -25,6 +29,24 @@ func (m *MockOutboxEventRepository) UpdateOutboxEventStatus(ctx context.Context,
 	return args.Error(0)
 }
 
+func (m *MockOutboxEventRepository) RecordOutboxEventFailure(ctx context.Context, eventID, lastError string, nextAttemptAt time.Time, failed bool) error {
+	args := m.Called(ctx, eventID, lastError, nextAttemptAt, failed)
+	return args.Error(0)
+}
+
+func (m *MockOutboxEventRepository) OutboxBacklog(ctx context.Context) (int64, time.Time, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Get(1).(time.Time), args.Error(2)
+}
+
+// newMockOutboxEventRepository allows backlog reads, which every relay pass
+// ends with.
+func newMockOutboxEventRepository() *MockOutboxEventRepository {
+	m := new(MockOutboxEventRepository)
+	m.On("OutboxBacklog", mock.Anything).Return(int64(0), time.Time{}, nil).Maybe()
+	return m
+}
+
 type MockRabbitMQPublisher struct {
 	mock.Mock
 }
@@ -39,16 +61,17 @@ func TestProcessEvents(t *testing.T) {
 		ID:        "test-event-id",
 		EventName: "order_created_exchange",
 		Payload:   []byte(`{"order_id":"test-order"}`),
+		Attempts:  1,
 	}
 
 	t.Run("SuccessPath", func(t *testing.T) {
 
-		mockRepo := new(MockOutboxEventRepository)
+		mockRepo := newMockOutboxEventRepository()
 		mockPublisher := new(MockRabbitMQPublisher)
 		relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 10)
 
 		events := []events.OutboxEvent{testEvent}
-		mockRepo.On("GetUnpublishedOutboxEvents", mock.Anything, int32(10)).Return(events, nil).Once()
+		mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(10), DefaultClaimLease).Return(events, nil).Once()
 		mockPublisher.On("Publish", mock.Anything, testEvent.EventName, testEvent.Payload).Return(nil).Once()
 		mockRepo.On("UpdateOutboxEventStatus", mock.Anything, testEvent.ID).Return(nil).Once()
 
@@ -61,13 +84,14 @@ func TestProcessEvents(t *testing.T) {
 
 	t.Run("PublisherError", func(t *testing.T) {
 
-		mockRepo := new(MockOutboxEventRepository)
+		mockRepo := newMockOutboxEventRepository()
 		mockPublisher := new(MockRabbitMQPublisher)
 		relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 10)
 
 		events := []events.OutboxEvent{testEvent}
-		mockRepo.On("GetUnpublishedOutboxEvents", mock.Anything, int32(10)).Return(events, nil).Once()
+		mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(10), DefaultClaimLease).Return(events, nil).Once()
 		mockPublisher.On("Publish", mock.Anything, testEvent.EventName, testEvent.Payload).Return(errors.New("publish error")).Once()
+		mockRepo.On("RecordOutboxEventFailure", mock.Anything, testEvent.ID, "publish error", mock.AnythingOfType("time.Time"), false).Return(nil).Once()
 
 		err := relayer.processEvents(context.Background())
 
@@ -77,14 +101,56 @@ func TestProcessEvents(t *testing.T) {
 	})
 
 	t.Run("UpdateStatusError", func(t *testing.T) {
-		mockRepo := new(MockOutboxEventRepository)
+		mockRepo := newMockOutboxEventRepository()
 		mockPublisher := new(MockRabbitMQPublisher)
 		relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 10)
 
 		events := []events.OutboxEvent{testEvent}
-		mockRepo.On("GetUnpublishedOutboxEvents", mock.Anything, int32(10)).Return(events, nil).Once()
+		mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(10), DefaultClaimLease).Return(events, nil).Once()
 		mockPublisher.On("Publish", mock.Anything, testEvent.EventName, testEvent.Payload).Return(nil).Once()
 		mockRepo.On("UpdateOutboxEventStatus", mock.Anything, testEvent.ID).Return(errors.New("update status error")).Once()
+		mockRepo.On("RecordOutboxEventFailure", mock.Anything, testEvent.ID, "update status error", mock.AnythingOfType("time.Time"), false).Return(nil).Once()
+
+		err := relayer.processEvents(context.Background())
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("PublisherErrorSchedulesRetryWithBackoff", func(t *testing.T) {
+		mockRepo := newMockOutboxEventRepository()
+		mockPublisher := new(MockRabbitMQPublisher)
+		policy := RetryPolicy{MaxAttempts: 5, BaseBackoff: time.Second, MaxBackoff: time.Minute, ClaimLease: 10 * time.Second}
+		relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 10, WithRetryPolicy(policy))
+
+		event := testEvent
+		event.Attempts = 3
+		before := time.Now()
+		mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(10), policy.ClaimLease).Return([]events.OutboxEvent{event}, nil).Once()
+		mockPublisher.On("Publish", mock.Anything, event.EventName, event.Payload).Return(errors.New("publish error")).Once()
+		mockRepo.On("RecordOutboxEventFailure", mock.Anything, event.ID, "publish error", mock.MatchedBy(func(next time.Time) bool {
+			return !next.Before(before.Add(4*time.Second)) && next.Before(time.Now().Add(4*time.Second+time.Second))
+		}), false).Return(nil).Once()
+
+		err := relayer.processEvents(context.Background())
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("PublisherErrorMarksFailedAfterMaxAttempts", func(t *testing.T) {
+		mockRepo := newMockOutboxEventRepository()
+		mockPublisher := new(MockRabbitMQPublisher)
+		policy := RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Second, MaxBackoff: time.Minute, ClaimLease: 10 * time.Second}
+		relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 10, WithRetryPolicy(policy))
+
+		event := testEvent
+		event.Attempts = 3
+		mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(10), policy.ClaimLease).Return([]events.OutboxEvent{event}, nil).Once()
+		mockPublisher.On("Publish", mock.Anything, event.EventName, event.Payload).Return(errors.New("publish error")).Once()
+		mockRepo.On("RecordOutboxEventFailure", mock.Anything, event.ID, "publish error", mock.AnythingOfType("time.Time"), true).Return(nil).Once()
 
 		err := relayer.processEvents(context.Background())
 
@@ -94,11 +160,11 @@ func TestProcessEvents(t *testing.T) {
 	})
 
 	t.Run("NoEvents", func(t *testing.T) {
-		mockRepo := new(MockOutboxEventRepository)
+		mockRepo := newMockOutboxEventRepository()
 		mockPublisher := new(MockRabbitMQPublisher)
 		relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 10)
 
-		mockRepo.On("GetUnpublishedOutboxEvents", mock.Anything, int32(10)).Return([]events.OutboxEvent{}, nil).Once()
+		mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(10), DefaultClaimLease).Return([]events.OutboxEvent{}, nil).Once()
 
 		err := relayer.processEvents(context.Background())
 
@@ -107,3 +173,229 @@ func TestProcessEvents(t *testing.T) {
 		mockPublisher.AssertExpectations(t)
 	})
 }
+
+func TestProcessEventsDrainsFullBatches(t *testing.T) {
+	mockRepo := newMockOutboxEventRepository()
+	mockPublisher := new(MockRabbitMQPublisher)
+	relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 2)
+
+	first := []events.OutboxEvent{{ID: "event-1", EventName: "exchange"}, {ID: "event-2", EventName: "exchange"}}
+	second := []events.OutboxEvent{{ID: "event-3", EventName: "exchange"}}
+	mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(2), DefaultClaimLease).Return(first, nil).Once()
+	mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(2), DefaultClaimLease).Return(second, nil).Once()
+	mockPublisher.On("Publish", mock.Anything, "exchange", mock.Anything).Return(nil).Times(3)
+	mockRepo.On("UpdateOutboxEventStatus", mock.Anything, mock.Anything).Return(nil).Times(3)
+
+	err := relayer.processEvents(context.Background())
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestProcessEventsReportsWholeBacklog(t *testing.T) {
+	mockRepo := new(MockOutboxEventRepository)
+	mockPublisher := new(MockRabbitMQPublisher)
+	relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 2)
+
+	claimed := []events.OutboxEvent{{ID: "event-1", EventName: "exchange"}}
+	mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(2), DefaultClaimLease).Return(claimed, nil).Once()
+	mockPublisher.On("Publish", mock.Anything, "exchange", mock.Anything).Return(nil).Once()
+	mockRepo.On("UpdateOutboxEventStatus", mock.Anything, "event-1").Return(nil).Once()
+	mockRepo.On("OutboxBacklog", mock.Anything).Return(int64(250), time.Now().Add(-time.Hour), nil).Once()
+
+	assert.NoError(t, relayer.processEvents(context.Background()))
+	mockRepo.AssertExpectations(t)
+
+	assert.Equal(t, 250.0, gaugeValue(t, "outbox_backlog_events"))
+	assert.GreaterOrEqual(t, gaugeValue(t, "outbox_oldest_event_age_seconds"), time.Hour.Seconds())
+}
+
+func gaugeValue(t *testing.T, name string) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("metric %s is not registered", name)
+	return 0
+}
+
+type fakeListener struct{}
+
+func (fakeListener) Listen(ctx context.Context, notify chan<- struct{}) {
+	notify <- struct{}{}
+	<-ctx.Done()
+}
+
+func TestProcessEventsKeepsAggregateOrder(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := events.OutboxEvent{ID: "created", AggregateID: "order-1", EventName: "order_created_exchange", Payload: []byte(`"created"`), Attempts: 1, CreatedAt: createdAt}
+	cancelled := events.OutboxEvent{ID: "cancelled", AggregateID: "order-1", EventName: "order_cancelled_exchange", Payload: []byte(`"cancelled"`), Attempts: 1, CreatedAt: createdAt.Add(time.Second)}
+	other := events.OutboxEvent{ID: "other", AggregateID: "order-2", EventName: "order_created_exchange", Payload: []byte(`"other"`), Attempts: 1, CreatedAt: createdAt.Add(2 * time.Second)}
+
+	t.Run("PublishesInCreationOrder", func(t *testing.T) {
+		mockRepo := newMockOutboxEventRepository()
+		mockPublisher := new(MockRabbitMQPublisher)
+		relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 10)
+
+		var published []string
+		mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(10), DefaultClaimLease).Return([]events.OutboxEvent{other, cancelled, created}, nil).Once()
+		mockPublisher.On("Publish", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			published = append(published, string(args.Get(2).([]byte)))
+		}).Return(nil)
+		mockRepo.On("UpdateOutboxEventStatus", mock.Anything, mock.Anything).Return(nil)
+
+		assert.NoError(t, relayer.processEvents(context.Background()))
+		assert.Equal(t, []string{`"created"`, `"cancelled"`, `"other"`}, published)
+	})
+
+	t.Run("SkipsAggregateAfterFailure", func(t *testing.T) {
+		mockRepo := newMockOutboxEventRepository()
+		mockPublisher := new(MockRabbitMQPublisher)
+		relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, 0, 10)
+
+		mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(10), DefaultClaimLease).Return([]events.OutboxEvent{cancelled, other, created}, nil).Once()
+		mockPublisher.On("Publish", mock.Anything, created.EventName, created.Payload).Return(errors.New("publish error")).Once()
+		mockRepo.On("RecordOutboxEventFailure", mock.Anything, created.ID, "publish error", mock.AnythingOfType("time.Time"), false).Return(nil).Once()
+		mockPublisher.On("Publish", mock.Anything, other.EventName, other.Payload).Return(nil).Once()
+		mockRepo.On("UpdateOutboxEventStatus", mock.Anything, other.ID).Return(nil).Once()
+
+		assert.NoError(t, relayer.processEvents(context.Background()))
+		mockRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+		mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, cancelled.EventName, cancelled.Payload)
+		mockRepo.AssertNotCalled(t, "RecordOutboxEventFailure", mock.Anything, cancelled.ID, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestStartDrainsOnNotification(t *testing.T) {
+	mockRepo := newMockOutboxEventRepository()
+	mockPublisher := new(MockRabbitMQPublisher)
+	relayer := NewOutboxEventMessageRelayer(logs.NewSlogLogger(), mockPublisher, mockRepo, time.Hour, 10, WithListener(fakeListener{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockRepo.On("ClaimUnpublishedOutboxEvents", mock.Anything, int32(10), DefaultClaimLease).
+		Run(func(mock.Arguments) { cancel() }).
+		Return([]events.OutboxEvent{}, nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		relayer.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("relayer did not drain the outbox on notification")
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: 2 * time.Second, MaxBackoff: 30 * time.Second}
+
+	assert.Equal(t, 2*time.Second, policy.Backoff(0))
+	assert.Equal(t, 2*time.Second, policy.Backoff(1))
+	assert.Equal(t, 4*time.Second, policy.Backoff(2))
+	assert.Equal(t, 16*time.Second, policy.Backoff(4))
+	assert.Equal(t, 30*time.Second, policy.Backoff(5))
+	assert.Equal(t, 30*time.Second, policy.Backoff(40))
+}
+
+func TestRetryPolicyFromEnv(t *testing.T) {
+	t.Setenv("MESSAGE_RELAYER_MAX_ATTEMPTS", "4")
+	t.Setenv("MESSAGE_RELAYER_BASE_BACKOFF", "500ms")
+	t.Setenv("MESSAGE_RELAYER_MAX_BACKOFF", "1m")
+	t.Setenv("MESSAGE_RELAYER_CLAIM_LEASE", "45s")
+
+	policy, err := RetryPolicyFromEnv()
+
+	assert.NoError(t, err)
+	assert.Equal(t, RetryPolicy{MaxAttempts: 4, BaseBackoff: 500 * time.Millisecond, MaxBackoff: time.Minute, ClaimLease: 45 * time.Second}, policy)
+
+	t.Setenv("MESSAGE_RELAYER_MAX_ATTEMPTS", "zero")
+	_, err = RetryPolicyFromEnv()
+	assert.Error(t, err)
+}
+
+func TestNewOutboxEventMessageRelayerFromEnv(t *testing.T) {
+	tests := []struct {
+		name             string
+		env              map[string]string
+		wantErr          string
+		wantPollInterval time.Duration
+		wantBatchSize    int32
+		wantListener     bool
+	}{
+		{"Defaults", nil, "", DefaultRelayerNotifyPollInterval, DefaultRelayerBatchSize, true},
+		{"PollMode", map[string]string{"MESSAGE_RELAYER_MODE": "poll"}, "", DefaultRelayerPollInterval, DefaultRelayerBatchSize, false},
+		{"Overrides", map[string]string{"MESSAGE_RELAYER_POLL_INTERVAL": "10s", "MESSAGE_RELAYER_BATCH_SIZE": "50"}, "", 10 * time.Second, 50, true},
+		{"InvalidMode", map[string]string{"MESSAGE_RELAYER_MODE": "push"}, "MESSAGE_RELAYER_MODE", 0, 0, false},
+		{"InvalidPollInterval", map[string]string{"MESSAGE_RELAYER_POLL_INTERVAL": "0s"}, "MESSAGE_RELAYER_POLL_INTERVAL", 0, 0, false},
+		{"InvalidBatchSize", map[string]string{"MESSAGE_RELAYER_BATCH_SIZE": "many"}, "MESSAGE_RELAYER_BATCH_SIZE", 0, 0, false},
+		{"InvalidRetryPolicy", map[string]string{"MESSAGE_RELAYER_MAX_ATTEMPTS": "0"}, "MESSAGE_RELAYER_MAX_ATTEMPTS", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"MESSAGE_RELAYER_MODE", "MESSAGE_RELAYER_POLL_INTERVAL", "MESSAGE_RELAYER_BATCH_SIZE", "MESSAGE_RELAYER_MAX_ATTEMPTS"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			relayer, err := NewOutboxEventMessageRelayerFromEnv(logs.NewSlogLogger(), new(MockRabbitMQPublisher), newMockOutboxEventRepository())
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPollInterval, relayer.pollInterval)
+			assert.Equal(t, tt.wantBatchSize, relayer.batchSize)
+			assert.Equal(t, tt.wantListener, relayer.listener != nil)
+		})
+	}
+}
+
+type recordingPublisher struct {
+	published []broker.Publishing
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, publishing broker.Publishing) error {
+	p.published = append(p.published, publishing)
+	return nil
+}
+
+func TestEventPublisher(t *testing.T) {
+	ctx := context.Background()
+	recorder := &recordingPublisher{}
+	publisher := NewEventPublisher(recorder, events.Registry)
+
+	assert.NoError(t, publisher.Publish(ctx, events.ProductUpdatedEventName, []byte(`{}`)))
+	assert.NoError(t, publisher.Publish(ctx, events.LegacyOrderCreatedEventName, []byte(`{}`)))
+	assert.Equal(t, []broker.Publishing{
+		{Exchange: events.ProductExchangeName, Kind: broker.ExchangeTopic, RoutingKey: events.ProductUpdatedRoutingKey, Body: []byte(`{}`)},
+		{Exchange: events.OrderCreatedExchangeName, Kind: broker.ExchangeFanout, Body: []byte(`{}`)},
+	}, recorder.published)
+
+	err := publisher.Publish(ctx, events.ProductExchangeName+":product.renamed", []byte(`{}`))
+	assert.ErrorIs(t, err, events.ErrUnknownRoutingKey)
+
+	err = publisher.Publish(ctx, "unknown_exchange", []byte(`{}`))
+	assert.ErrorIs(t, err, events.ErrUnknownExchange)
+
+	err = NewEventPublisher(memory.New(), events.Registry).Publish(ctx, events.ProductUpdatedEventName, []byte(`{}`))
+	assert.ErrorIs(t, err, broker.ErrUnroutable)
+
+	err = NewEventPublisher(memory.New(), events.Registry).Publish(ctx, events.OrderPaidEventName, []byte(`{}`))
+	assert.ErrorIs(t, err, broker.ErrUnroutable, "orders.events does not drop events no queue is bound to")
+
+	err = NewEventPublisher(memory.New(), events.Registry).Publish(ctx, events.UserUpdatedEventName, []byte(`{}`))
+	assert.NoError(t, err, "users.events accepts events no queue is bound to")
+}
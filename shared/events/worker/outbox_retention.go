@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
+)
+
+const (
+	DefaultRetention          = 30 * 24 * time.Hour
+	DefaultRetentionInterval  = time.Hour
+	DefaultRetentionBatchSize = 500
+)
+
+// OutboxArchiveRepository moves up to limit published (and, where the
+// service cancels events, cancelled) outbox events created before
+// createdBefore to the archive table and deletes them from the outbox, and
+// returns how many were archived.
+type OutboxArchiveRepository interface {
+	ArchiveOutboxEvents(ctx context.Context, createdBefore time.Time, limit int32) (int64, error)
+}
+
+// RetentionPolicy sets how long relayed events stay in the outbox. A zero
+// Retention disables the retention job.
+type RetentionPolicy struct {
+	Retention time.Duration
+	Interval  time.Duration
+	BatchSize int32
+}
+
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		Retention: DefaultRetention,
+		Interval:  DefaultRetentionInterval,
+		BatchSize: DefaultRetentionBatchSize,
+	}
+}
+
+// RetentionPolicyFromEnv reads OUTBOX_RETENTION_DAYS (0 disables the job),
+// OUTBOX_RETENTION_INTERVAL and OUTBOX_RETENTION_BATCH_SIZE, falling back to
+// the defaults.
+func RetentionPolicyFromEnv() (RetentionPolicy, error) {
+	policy := DefaultRetentionPolicy()
+
+	if value := os.Getenv("OUTBOX_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return RetentionPolicy{}, fmt.Errorf("invalid OUTBOX_RETENTION_DAYS: %q", value)
+		}
+		policy.Retention = time.Duration(days) * 24 * time.Hour
+	}
+
+	if value := os.Getenv("OUTBOX_RETENTION_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return RetentionPolicy{}, fmt.Errorf("invalid OUTBOX_RETENTION_INTERVAL: %q", value)
+		}
+		policy.Interval = interval
+	}
+
+	if value := os.Getenv("OUTBOX_RETENTION_BATCH_SIZE"); value != "" {
+		batchSize, err := strconv.Atoi(value)
+		if err != nil || batchSize < 1 {
+			return RetentionPolicy{}, fmt.Errorf("invalid OUTBOX_RETENTION_BATCH_SIZE: %q", value)
+		}
+		policy.BatchSize = int32(batchSize)
+	}
+
+	return policy, nil
+}
+
+// OutboxRetentionJob periodically archives relayed outbox events older than
+// the retention, so the outbox only holds recent and pending events.
+type OutboxRetentionJob struct {
+	logger logs.Logger
+	repo   OutboxArchiveRepository
+	policy RetentionPolicy
+	now    func() time.Time
+}
+
+func NewOutboxRetentionJob(logger logs.Logger, repo OutboxArchiveRepository, policy RetentionPolicy) *OutboxRetentionJob {
+	return &OutboxRetentionJob{
+		logger: logger,
+		repo:   repo,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Start runs the job once right away and then every Interval until ctx is
+// done.
+func (j *OutboxRetentionJob) Start(ctx context.Context) {
+	if j.policy.Retention <= 0 {
+		j.logger.Info("outbox retention job disabled")
+		return
+	}
+
+	j.logger.Info("starting outbox retention job", "retention", j.policy.Retention, "interval", j.policy.Interval)
+	ticker := time.NewTicker(j.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		archived, err := j.RunOnce(ctx)
+		if err != nil {
+			j.logger.Error("error archiving outbox events", "error", err, "archived", archived)
+		} else if archived > 0 {
+			j.logger.Info("archived outbox events", "archived", archived)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			j.logger.Info("stopping outbox retention job")
+			return
+		}
+	}
+}
+
+// RunOnce archives batches until a partial batch is returned and reports how
+// many events were archived.
+func (j *OutboxRetentionJob) RunOnce(ctx context.Context) (int64, error) {
+	createdBefore := j.now().Add(-j.policy.Retention)
+
+	var total int64
+	for ctx.Err() == nil {
+		archived, err := j.repo.ArchiveOutboxEvents(ctx, createdBefore, j.policy.BatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to archive outbox events: %w", err)
+		}
+		total += archived
+		metrics.AddOutboxArchived(archived)
+
+		if archived < int64(j.policy.BatchSize) {
+			break
+		}
+	}
+	return total, nil
+}
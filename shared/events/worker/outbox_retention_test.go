@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockOutboxArchiveRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxArchiveRepository) ArchiveOutboxEvents(ctx context.Context, createdBefore time.Time, limit int32) (int64, error) {
+	args := m.Called(ctx, createdBefore, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestOutboxRetentionJobRunOnce(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	policy := RetentionPolicy{Retention: 7 * 24 * time.Hour, Interval: time.Hour, BatchSize: 100}
+	createdBefore := now.Add(-policy.Retention)
+
+	t.Run("ArchivesUntilPartialBatch", func(t *testing.T) {
+		repo := new(MockOutboxArchiveRepository)
+		job := NewOutboxRetentionJob(logs.NewSlogLogger(), repo, policy)
+		job.now = func() time.Time { return now }
+
+		repo.On("ArchiveOutboxEvents", mock.Anything, createdBefore, int32(100)).Return(int64(100), nil).Twice()
+		repo.On("ArchiveOutboxEvents", mock.Anything, createdBefore, int32(100)).Return(int64(42), nil).Once()
+
+		archived, err := job.RunOnce(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(242), archived)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("StopsOnError", func(t *testing.T) {
+		repo := new(MockOutboxArchiveRepository)
+		job := NewOutboxRetentionJob(logs.NewSlogLogger(), repo, policy)
+		job.now = func() time.Time { return now }
+
+		repo.On("ArchiveOutboxEvents", mock.Anything, createdBefore, int32(100)).Return(int64(100), nil).Once()
+		repo.On("ArchiveOutboxEvents", mock.Anything, createdBefore, int32(100)).Return(int64(0), errors.New("db down")).Once()
+
+		archived, err := job.RunOnce(context.Background())
+
+		assert.Error(t, err)
+		assert.Equal(t, int64(100), archived)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("DisabledJobDoesNothing", func(t *testing.T) {
+		repo := new(MockOutboxArchiveRepository)
+		NewOutboxRetentionJob(logs.NewSlogLogger(), repo, RetentionPolicy{}).Start(context.Background())
+		repo.AssertNotCalled(t, "ArchiveOutboxEvents", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRetentionPolicyFromEnv(t *testing.T) {
+	t.Setenv("OUTBOX_RETENTION_DAYS", "0")
+	t.Setenv("OUTBOX_RETENTION_INTERVAL", "10m")
+	policy, err := RetentionPolicyFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, RetentionPolicy{Retention: 0, Interval: 10 * time.Minute, BatchSize: DefaultRetentionBatchSize}, policy)
+
+	t.Setenv("OUTBOX_RETENTION_DAYS", "-1")
+	_, err = RetentionPolicyFromEnv()
+	assert.Error(t, err)
+}
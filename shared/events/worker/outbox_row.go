@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sonuudigital/microservices/shared/events"
+)
+
+// OutboxEventRow is an outbox_events row as the services' claim, list and
+// requeue queries return it: these columns in this order, with the status
+// cast to text. The row types sqlc generates for the queries have the same
+// fields, so a service converts them with OutboxEventRow(row).
+type OutboxEventRow struct {
+	ID            pgtype.UUID
+	AggregateID   pgtype.UUID
+	EventName     string
+	Payload       []byte
+	Status        string
+	TraceContext  []byte
+	Attempts      int32
+	LastError     pgtype.Text
+	NextAttemptAt pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+}
+
+func (r OutboxEventRow) OutboxEvent() events.OutboxEvent {
+	return events.OutboxEvent{
+		ID:            r.ID.String(),
+		AggregateID:   r.AggregateID.String(),
+		EventName:     r.EventName,
+		Payload:       r.Payload,
+		Status:        r.Status,
+		TraceContext:  r.TraceContext,
+		Attempts:      r.Attempts,
+		LastError:     r.LastError.String,
+		NextAttemptAt: r.NextAttemptAt.Time,
+		CreatedAt:     r.CreatedAt.Time,
+	}
+}
+
+// ParseOutboxEventID parses the ID the relayer or the admin handler passes
+// back into the UUID the queries take.
+func ParseOutboxEventID(id string) (pgtype.UUID, error) {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return pgtype.UUID{}, err
+	}
+	return uuid, nil
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxEventRow(t *testing.T) {
+	id, err := ParseOutboxEventID("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11")
+	require.NoError(t, err)
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	event := OutboxEventRow{
+		ID:            id,
+		AggregateID:   id,
+		EventName:     events.OrderCreatedEventName,
+		Payload:       []byte(`{}`),
+		Status:        OutboxStatusFailed,
+		Attempts:      3,
+		LastError:     pgtype.Text{String: "broker unavailable", Valid: true},
+		NextAttemptAt: pgtype.Timestamptz{Time: createdAt.Add(time.Minute), Valid: true},
+		CreatedAt:     pgtype.Timestamptz{Time: createdAt, Valid: true},
+	}.OutboxEvent()
+
+	assert.Equal(t, events.OutboxEvent{
+		ID:            "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11",
+		AggregateID:   "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11",
+		EventName:     events.OrderCreatedEventName,
+		Payload:       []byte(`{}`),
+		Status:        OutboxStatusFailed,
+		Attempts:      3,
+		LastError:     "broker unavailable",
+		NextAttemptAt: createdAt.Add(time.Minute),
+		CreatedAt:     createdAt,
+	}, event)
+}
+
+func TestParseOutboxEventIDRejectsInvalidID(t *testing.T) {
+	_, err := ParseOutboxEventID("event-1")
+
+	assert.Error(t, err)
+}
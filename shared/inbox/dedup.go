@@ -0,0 +1,160 @@
+package inbox
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const DefaultDedupWindow = 10000
+
+var errEventInFlight = errors.New("event is already being handled")
+
+// SeenStore persists the IDs of handled events, so a Deduplicator still
+// recognises them after a restart or on another replica.
+type SeenStore interface {
+	Seen(ctx context.Context, consumer, eventID string) (bool, error)
+	MarkSeen(ctx context.Context, consumer, eventID string) error
+}
+
+type DedupOption func(*Deduplicator)
+
+// WithSeenStore makes the Deduplicator consult store for IDs it does not
+// remember and record every handled ID in it. Store errors are logged and
+// the event is handled, since a store outage must not stop the consumer.
+func WithSeenStore(store SeenStore) DedupOption {
+	return func(dd *Deduplicator) {
+		dd.store = store
+	}
+}
+
+// Deduplicator skips redeliveries of events that were handled successfully,
+// for consumers without a database whose side effects cannot share a
+// transaction with the inbox. It remembers the last window event IDs in
+// memory; without a SeenStore they are lost on restart, so a redelivery
+// right after a restart is handled again. Recording an ID happens after the
+// side effect, so handlers must still tolerate the occasional duplicate.
+type Deduplicator struct {
+	logger   logs.Logger
+	consumer string
+	window   int
+	store    SeenStore
+
+	mu       sync.Mutex
+	order    *list.List
+	seen     map[string]*list.Element
+	inFlight map[string]struct{}
+}
+
+func NewDeduplicator(logger logs.Logger, consumer string, window int, opts ...DedupOption) *Deduplicator {
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	dd := &Deduplicator{
+		logger:   logger,
+		consumer: consumer,
+		window:   window,
+		order:    list.New(),
+		seen:     make(map[string]*list.Element),
+		inFlight: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(dd)
+	}
+	return dd
+}
+
+func (dd *Deduplicator) Wrap(handler broker.Handler) broker.Handler {
+	return func(ctx context.Context, msg broker.Message) error {
+		envelope, _ := events.ParseEnvelope(msg.Body)
+		eventID := EventID(envelope, msg)
+
+		switch dd.begin(eventID) {
+		case stateSeen:
+			dd.logger.Info("event already processed, acknowledging without reprocessing", "consumer", dd.consumer, "eventId", eventID)
+			return nil
+		case stateInFlight:
+			// The other delivery may still fail, so this one must not be
+			// acknowledged yet.
+			return broker.Retryable(errEventInFlight)
+		}
+
+		if dd.seenInStore(ctx, eventID) {
+			dd.finish(eventID, true)
+			dd.logger.Info("event already processed, acknowledging without reprocessing", "consumer", dd.consumer, "eventId", eventID)
+			return nil
+		}
+
+		err := handler(ctx, msg)
+		if err == nil {
+			dd.markSeenInStore(ctx, eventID)
+		}
+		dd.finish(eventID, err == nil)
+		return err
+	}
+}
+
+func (dd *Deduplicator) seenInStore(ctx context.Context, eventID string) bool {
+	if dd.store == nil {
+		return false
+	}
+	seen, err := dd.store.Seen(ctx, dd.consumer, eventID)
+	if err != nil {
+		dd.logger.Warn("failed to look up event in dedup store", "consumer", dd.consumer, "eventId", eventID, "error", err)
+		return false
+	}
+	return seen
+}
+
+func (dd *Deduplicator) markSeenInStore(ctx context.Context, eventID string) {
+	if dd.store == nil {
+		return
+	}
+	if err := dd.store.MarkSeen(ctx, dd.consumer, eventID); err != nil {
+		dd.logger.Warn("failed to record event in dedup store", "consumer", dd.consumer, "eventId", eventID, "error", err)
+	}
+}
+
+type dedupState int
+
+const (
+	stateNew dedupState = iota
+	stateSeen
+	stateInFlight
+)
+
+func (dd *Deduplicator) begin(eventID string) dedupState {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	if _, ok := dd.seen[eventID]; ok {
+		return stateSeen
+	}
+	if _, ok := dd.inFlight[eventID]; ok {
+		return stateInFlight
+	}
+	dd.inFlight[eventID] = struct{}{}
+	return stateNew
+}
+
+func (dd *Deduplicator) finish(eventID string, handled bool) {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	delete(dd.inFlight, eventID)
+	if !handled {
+		return
+	}
+
+	dd.seen[eventID] = dd.order.PushBack(eventID)
+	if dd.order.Len() > dd.window {
+		oldest := dd.order.Front()
+		dd.order.Remove(oldest)
+		delete(dd.seen, oldest.Value.(string))
+	}
+}
@@ -0,0 +1,132 @@
+package inbox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const recordEventQuery = `INSERT INTO inbox_events (consumer, event_id, event_type)
+VALUES ($1, $2, $3)
+ON CONFLICT (consumer, event_id) DO NOTHING`
+
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Inbox makes a consumer idempotent: the ID of every handled event is stored
+// in inbox_events in the same transaction as the handler's changes, and an
+// event whose ID is already stored is acknowledged without being handled
+// again.
+type Inbox struct {
+	logger   logs.Logger
+	db       TxBeginner
+	consumer string
+}
+
+// New returns an inbox for one consumer. The consumer name scopes the
+// recorded IDs, so several consumers of the same event can share a database.
+func New(logger logs.Logger, db TxBeginner, consumer string) *Inbox {
+	return &Inbox{
+		logger:   logger,
+		db:       db,
+		consumer: consumer,
+	}
+}
+
+// TxHandler holds the business logic of a consumer. Every change must be
+// made through tx; returning an error rolls back both the changes and the
+// inbox record.
+type TxHandler[T any] func(ctx context.Context, tx pgx.Tx, envelope events.Envelope, event T) error
+
+// Handle decodes deliveries with the default schema version of T and runs
+// handler once per event.
+func Handle[T any](in *Inbox, handler TxHandler[T]) broker.Handler {
+	return HandleWith(in, events.NewDecoder[T](), handler)
+}
+
+// HandleWith is Handle with a decoder that accepts several schema versions.
+func HandleWith[T any](in *Inbox, decoder *events.Decoder[T], handler TxHandler[T]) broker.Handler {
+	return handle(in, decoder, nil, handler)
+}
+
+// KeyFunc returns the business key of an event that happens once per
+// aggregate, e.g. the order ID of an OrderCreated event.
+type KeyFunc[T any] func(event T) string
+
+// HandleKeyed is Handle for events that are unique per business key: the
+// key returned by key is recorded instead of the event ID. Redeliveries and
+// replays are then recognised even when the payload has no envelope and the
+// message ID differs on every publish, and rows carried over from an older
+// per-aggregate dedup table keep deduplicating.
+func HandleKeyed[T any](in *Inbox, key KeyFunc[T], handler TxHandler[T]) broker.Handler {
+	return handle(in, events.NewDecoder[T](), key, handler)
+}
+
+func handle[T any](in *Inbox, decoder *events.Decoder[T], key KeyFunc[T], handler TxHandler[T]) broker.Handler {
+	return func(ctx context.Context, msg broker.Message) error {
+		envelope, event, err := decoder.Decode(msg.Body)
+		if err != nil {
+			in.logger.Error("failed to decode event", "consumer", in.consumer, "error", err)
+			return fmt.Errorf("failed to decode event: %w", err)
+		}
+
+		eventID := EventID(envelope, msg)
+		if key != nil {
+			if k := key(event); k != "" {
+				eventID = k
+			}
+		}
+
+		return in.process(ctx, eventID, envelope.Type, func(tx pgx.Tx) error {
+			return handler(ctx, tx, envelope, event)
+		})
+	}
+}
+
+func (in *Inbox) process(ctx context.Context, eventID, eventType string, fn func(tx pgx.Tx) error) error {
+	tx, err := in.db.Begin(ctx)
+	if err != nil {
+		return broker.Retryable(fmt.Errorf("failed to begin inbox transaction: %w", err))
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, recordEventQuery, in.consumer, eventID, eventType)
+	if err != nil {
+		return broker.Retryable(fmt.Errorf("failed to record event %s: %w", eventID, err))
+	}
+	if tag.RowsAffected() == 0 {
+		in.logger.Info("event already processed, acknowledging without reprocessing", "consumer", in.consumer, "eventId", eventID)
+		return nil
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return broker.Retryable(fmt.Errorf("failed to commit inbox transaction: %w", err))
+	}
+	return nil
+}
+
+// EventID identifies an event for deduplication: the envelope ID, or for a
+// bare payload the message ID, or failing that a hash of the body. Message
+// IDs are assigned on every publish, so consumers of bare payloads that must
+// survive a republish should use HandleKeyed.
+func EventID(envelope events.Envelope, msg broker.Message) string {
+	if envelope.ID != "" {
+		return envelope.ID
+	}
+	if msg.ID != "" {
+		return msg.ID
+	}
+	sum := sha256.Sum256(msg.Body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,281 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTx struct {
+	pgx.Tx
+	recorded  map[string]bool
+	execArgs  []any
+	committed bool
+}
+
+func (tx *fakeTx) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	tx.execArgs = args
+	key := args[0].(string) + "/" + args[1].(string)
+	if tx.recorded[key] {
+		return pgconn.NewCommandTag("INSERT 0 0"), nil
+	}
+	tx.recorded[key] = true
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	return nil
+}
+
+type fakeDB struct {
+	recorded map[string]bool
+	lastTx   *fakeTx
+	beginErr error
+}
+
+func (db *fakeDB) Begin(context.Context) (pgx.Tx, error) {
+	if db.beginErr != nil {
+		return nil, db.beginErr
+	}
+	db.lastTx = &fakeTx{recorded: db.recorded}
+	return db.lastTx, nil
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{recorded: map[string]bool{}}
+}
+
+func orderCreatedMessage(t *testing.T) broker.Message {
+	body, err := events.Wrap("/order-service", events.OrderCreatedEventType, "order-1", events.OrderCreatedEvent{OrderID: "order-1"})
+	require.NoError(t, err)
+	return broker.Message{Body: body}
+}
+
+func TestHandle(t *testing.T) {
+	logger := logs.NewSlogLogger()
+
+	t.Run("RecordsEventWithChanges", func(t *testing.T) {
+		db := newFakeDB()
+		var calls int
+		handler := Handle(New(logger, db, "test_consumer"), func(ctx context.Context, tx pgx.Tx, envelope events.Envelope, event events.OrderCreatedEvent) error {
+			calls++
+			assert.Equal(t, db.lastTx, tx)
+			assert.Equal(t, "order-1", event.OrderID)
+			return nil
+		})
+
+		d := orderCreatedMessage(t)
+		require.NoError(t, handler(context.Background(), d))
+		assert.Equal(t, 1, calls)
+		assert.True(t, db.lastTx.committed)
+		assert.Equal(t, "test_consumer", db.lastTx.execArgs[0])
+		assert.Equal(t, events.OrderCreatedEventType, db.lastTx.execArgs[2])
+
+		require.NoError(t, handler(context.Background(), d))
+		assert.Equal(t, 1, calls, "duplicate must not be handled again")
+		assert.False(t, db.lastTx.committed)
+	})
+
+	t.Run("ConsumersAreIndependent", func(t *testing.T) {
+		db := newFakeDB()
+		var calls int
+		handle := func(ctx context.Context, tx pgx.Tx, envelope events.Envelope, event events.OrderCreatedEvent) error {
+			calls++
+			return nil
+		}
+
+		d := orderCreatedMessage(t)
+		require.NoError(t, Handle(New(logger, db, "first"), handle)(context.Background(), d))
+		require.NoError(t, Handle(New(logger, db, "second"), handle)(context.Background(), d))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("HandlerErrorRollsBack", func(t *testing.T) {
+		db := newFakeDB()
+		handlerErr := broker.Retryable(errors.New("boom"))
+		handler := Handle(New(logger, db, "test_consumer"), func(ctx context.Context, tx pgx.Tx, envelope events.Envelope, event events.OrderCreatedEvent) error {
+			return handlerErr
+		})
+
+		err := handler(context.Background(), orderCreatedMessage(t))
+		assert.ErrorIs(t, err, handlerErr)
+		assert.False(t, db.lastTx.committed)
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		db := newFakeDB()
+		handler := Handle(New(logger, db, "test_consumer"), func(ctx context.Context, tx pgx.Tx, envelope events.Envelope, event events.OrderCreatedEvent) error {
+			t.Fatal("handler must not be called")
+			return nil
+		})
+
+		err := handler(context.Background(), broker.Message{Body: []byte(`{invalid`)})
+		assert.Error(t, err)
+		assert.False(t, broker.IsRetryable(err))
+		assert.Nil(t, db.lastTx)
+	})
+
+	t.Run("KeyedRepublishedPayloadIsSkipped", func(t *testing.T) {
+		db := newFakeDB()
+		var calls int
+		orderID := func(event events.OrderCreatedEvent) string { return event.OrderID }
+		handler := HandleKeyed(New(logger, db, "test_consumer"), orderID, func(ctx context.Context, tx pgx.Tx, envelope events.Envelope, event events.OrderCreatedEvent) error {
+			calls++
+			return nil
+		})
+
+		body := []byte(`{"orderId":"order-1"}`)
+		require.NoError(t, handler(context.Background(), broker.Message{ID: "message-1", Body: body}))
+		require.NoError(t, handler(context.Background(), broker.Message{ID: "message-2", Body: body}))
+		require.NoError(t, handler(context.Background(), orderCreatedMessage(t)))
+
+		assert.Equal(t, 1, calls, "the same order must be handled once")
+		assert.Equal(t, "order-1", db.lastTx.execArgs[1])
+	})
+
+	t.Run("BeginErrorIsRetryable", func(t *testing.T) {
+		db := newFakeDB()
+		db.beginErr = errors.New("connection refused")
+		handler := Handle(New(logger, db, "test_consumer"), func(ctx context.Context, tx pgx.Tx, envelope events.Envelope, event events.OrderCreatedEvent) error {
+			return nil
+		})
+
+		err := handler(context.Background(), orderCreatedMessage(t))
+		assert.True(t, broker.IsRetryable(err))
+	})
+}
+
+func TestEventID(t *testing.T) {
+	body := []byte(`{"orderId":"order-1"}`)
+
+	assert.Equal(t, "event-1", EventID(events.Envelope{ID: "event-1"}, broker.Message{ID: "message-1"}))
+	assert.Equal(t, "message-1", EventID(events.Envelope{}, broker.Message{ID: "message-1", Body: body}))
+
+	hashed := EventID(events.Envelope{}, broker.Message{Body: body})
+	assert.Contains(t, hashed, "sha256:")
+	assert.Equal(t, hashed, EventID(events.Envelope{}, broker.Message{Body: body}))
+}
+
+func TestDeduplicator(t *testing.T) {
+	logger := logs.NewSlogLogger()
+
+	t.Run("SkipsHandledEvents", func(t *testing.T) {
+		var calls int
+		handler := NewDeduplicator(logger, "test_consumer", 10).Wrap(func(ctx context.Context, d broker.Message) error {
+			calls++
+			return nil
+		})
+
+		d := orderCreatedMessage(t)
+		require.NoError(t, handler(context.Background(), d))
+		require.NoError(t, handler(context.Background(), d))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("RetriesFailedEvents", func(t *testing.T) {
+		var calls int
+		handler := NewDeduplicator(logger, "test_consumer", 10).Wrap(func(ctx context.Context, d broker.Message) error {
+			calls++
+			if calls == 1 {
+				return errors.New("boom")
+			}
+			return nil
+		})
+
+		d := orderCreatedMessage(t)
+		assert.Error(t, handler(context.Background(), d))
+		require.NoError(t, handler(context.Background(), d))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("ForgetsOldestBeyondWindow", func(t *testing.T) {
+		var calls int
+		handler := NewDeduplicator(logger, "test_consumer", 1).Wrap(func(ctx context.Context, d broker.Message) error {
+			calls++
+			return nil
+		})
+
+		first := orderCreatedMessage(t)
+		require.NoError(t, handler(context.Background(), first))
+		require.NoError(t, handler(context.Background(), orderCreatedMessage(t)))
+		require.NoError(t, handler(context.Background(), first))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("InFlightDuplicateIsRetried", func(t *testing.T) {
+		dedup := NewDeduplicator(logger, "test_consumer", 10)
+		d := orderCreatedMessage(t)
+
+		var nested error
+		handler := dedup.Wrap(func(ctx context.Context, _ broker.Message) error {
+			nested = dedup.Wrap(func(context.Context, broker.Message) error {
+				t.Fatal("duplicate must not run while the first is in flight")
+				return nil
+			})(ctx, d)
+			return nil
+		})
+
+		require.NoError(t, handler(context.Background(), d))
+		assert.True(t, broker.IsRetryable(nested))
+	})
+	t.Run("StoreSurvivesRestart", func(t *testing.T) {
+		store := newFakeSeenStore()
+		var calls int
+		handle := func(ctx context.Context, d broker.Message) error {
+			calls++
+			return nil
+		}
+
+		d := orderCreatedMessage(t)
+		require.NoError(t, NewDeduplicator(logger, "test_consumer", 10, WithSeenStore(store)).Wrap(handle)(context.Background(), d))
+		require.NoError(t, NewDeduplicator(logger, "test_consumer", 10, WithSeenStore(store)).Wrap(handle)(context.Background(), d))
+		assert.Equal(t, 1, calls, "a restarted deduplicator must still skip the event")
+	})
+
+	t.Run("StoreErrorStillHandles", func(t *testing.T) {
+		store := newFakeSeenStore()
+		store.err = errors.New("connection refused")
+		var calls int
+		handler := NewDeduplicator(logger, "test_consumer", 10, WithSeenStore(store)).Wrap(func(ctx context.Context, d broker.Message) error {
+			calls++
+			return nil
+		})
+
+		require.NoError(t, handler(context.Background(), orderCreatedMessage(t)))
+		assert.Equal(t, 1, calls)
+	})
+}
+
+type fakeSeenStore struct {
+	seen map[string]bool
+	err  error
+}
+
+func newFakeSeenStore() *fakeSeenStore {
+	return &fakeSeenStore{seen: map[string]bool{}}
+}
+
+func (s *fakeSeenStore) Seen(_ context.Context, consumer, eventID string) (bool, error) {
+	return s.seen[consumer+"/"+eventID], s.err
+}
+
+func (s *fakeSeenStore) MarkSeen(_ context.Context, consumer, eventID string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.seen[consumer+"/"+eventID] = true
+	return nil
+}
@@ -0,0 +1,47 @@
+package inbox
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// migrationsTable keeps the inbox schema version apart from the service's
+// own schema_migrations, so both can be applied to the same database.
+const migrationsTable = "inbox_schema_migrations"
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate creates the inbox_events table in the database at databaseURL.
+func Migrate(databaseURL string) error {
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return err
+	}
+
+	dbURL, err := url.Parse(databaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid database URL: %w", err)
+	}
+	query := dbURL.Query()
+	query.Set("x-migrations-table", migrationsTable)
+	dbURL.RawQuery = query.Encode()
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, dbURL.String())
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to apply inbox migrations: %w", err)
+	}
+
+	return nil
+}
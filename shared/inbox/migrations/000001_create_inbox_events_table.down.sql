@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_inbox_events_processed_at;
+DROP TABLE IF EXISTS inbox_events;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS inbox_events (
+    consumer VARCHAR(150) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(150) NOT NULL,
+    processed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (consumer, event_id)
+);
+CREATE INDEX IF NOT EXISTS idx_inbox_events_processed_at ON inbox_events (processed_at);
\ No newline at end of file
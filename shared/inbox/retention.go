@@ -0,0 +1,138 @@
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	DefaultRetention          = 30 * 24 * time.Hour
+	DefaultRetentionInterval  = time.Hour
+	DefaultRetentionBatchSize = 1000
+)
+
+const deleteProcessedEventsQuery = `DELETE FROM inbox_events
+WHERE ctid IN (
+    SELECT ctid FROM inbox_events
+    WHERE processed_at < $1
+    LIMIT $2
+)`
+
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// RetentionPolicy sets how long handled event IDs stay in inbox_events. A
+// redelivery or DLQ replay older than Retention is handled again, so it must
+// be longer than messages can stay in a queue or dead letter queue. A zero
+// Retention disables the retention job.
+type RetentionPolicy struct {
+	Retention time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		Retention: DefaultRetention,
+		Interval:  DefaultRetentionInterval,
+		BatchSize: DefaultRetentionBatchSize,
+	}
+}
+
+// RetentionPolicyFromEnv reads INBOX_RETENTION_DAYS (0 disables the job) and
+// INBOX_RETENTION_INTERVAL, falling back to the defaults.
+func RetentionPolicyFromEnv() (RetentionPolicy, error) {
+	policy := DefaultRetentionPolicy()
+
+	if value := os.Getenv("INBOX_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return RetentionPolicy{}, fmt.Errorf("invalid INBOX_RETENTION_DAYS: %q", value)
+		}
+		policy.Retention = time.Duration(days) * 24 * time.Hour
+	}
+
+	if value := os.Getenv("INBOX_RETENTION_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return RetentionPolicy{}, fmt.Errorf("invalid INBOX_RETENTION_INTERVAL: %q", value)
+		}
+		policy.Interval = interval
+	}
+
+	return policy, nil
+}
+
+// RetentionJob periodically deletes inbox records older than the retention.
+type RetentionJob struct {
+	logger logs.Logger
+	db     Execer
+	policy RetentionPolicy
+	now    func() time.Time
+}
+
+func NewRetentionJob(logger logs.Logger, db Execer, policy RetentionPolicy) *RetentionJob {
+	return &RetentionJob{
+		logger: logger,
+		db:     db,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Start runs the job once right away and then every Interval until ctx is
+// done.
+func (j *RetentionJob) Start(ctx context.Context) {
+	if j.policy.Retention <= 0 {
+		j.logger.Info("inbox retention job disabled")
+		return
+	}
+
+	j.logger.Info("starting inbox retention job", "retention", j.policy.Retention, "interval", j.policy.Interval)
+	ticker := time.NewTicker(j.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := j.RunOnce(ctx)
+		if err != nil {
+			j.logger.Error("error deleting inbox events", "error", err, "deleted", deleted)
+		} else if deleted > 0 {
+			j.logger.Info("deleted inbox events", "deleted", deleted)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			j.logger.Info("stopping inbox retention job")
+			return
+		}
+	}
+}
+
+// RunOnce deletes batches until a partial batch is deleted and reports how
+// many records were deleted.
+func (j *RetentionJob) RunOnce(ctx context.Context) (int64, error) {
+	processedBefore := j.now().Add(-j.policy.Retention)
+
+	var total int64
+	for ctx.Err() == nil {
+		tag, err := j.db.Exec(ctx, deleteProcessedEventsQuery, processedBefore, j.policy.BatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to delete inbox events: %w", err)
+		}
+		deleted := tag.RowsAffected()
+		total += deleted
+
+		if deleted < int64(j.policy.BatchSize) {
+			break
+		}
+	}
+	return total, nil
+}
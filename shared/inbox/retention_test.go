@@ -0,0 +1,73 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExecer struct {
+	batches []int64
+	args    [][]any
+	err     error
+}
+
+func (f *fakeExecer) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	f.args = append(f.args, args)
+	if f.err != nil {
+		return pgconn.CommandTag{}, f.err
+	}
+	var deleted int64
+	if len(f.batches) > 0 {
+		deleted, f.batches = f.batches[0], f.batches[1:]
+	}
+	return pgconn.NewCommandTag(fmt.Sprintf("DELETE %d", deleted)), nil
+}
+
+func TestRetentionJobRunOnce(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	policy := RetentionPolicy{Retention: 24 * time.Hour, Interval: time.Hour, BatchSize: 2}
+
+	t.Run("DeletesUntilPartialBatch", func(t *testing.T) {
+		db := &fakeExecer{batches: []int64{2, 2, 1}}
+		job := NewRetentionJob(logs.NewSlogLogger(), db, policy)
+		job.now = func() time.Time { return now }
+
+		deleted, err := job.RunOnce(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), deleted)
+		require.Len(t, db.args, 3)
+		assert.Equal(t, []any{now.Add(-24 * time.Hour), 2}, db.args[0])
+	})
+
+	t.Run("ReturnsError", func(t *testing.T) {
+		db := &fakeExecer{err: errors.New("connection refused")}
+		job := NewRetentionJob(logs.NewSlogLogger(), db, policy)
+
+		_, err := job.RunOnce(context.Background())
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRetentionPolicyFromEnv(t *testing.T) {
+	t.Setenv("INBOX_RETENTION_DAYS", "7")
+	t.Setenv("INBOX_RETENTION_INTERVAL", "30m")
+
+	policy, err := RetentionPolicyFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, policy.Retention)
+	assert.Equal(t, 30*time.Minute, policy.Interval)
+
+	t.Setenv("INBOX_RETENTION_DAYS", "-1")
+	_, err = RetentionPolicyFromEnv()
+	assert.Error(t, err)
+}
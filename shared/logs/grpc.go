@@ -0,0 +1,58 @@
+package logs
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	RequestIDHeader      = "X-Request-ID"
+	requestIDMetadataKey = "x-request-id"
+)
+
+// UnaryServerInterceptor copies the request ID sent by the caller into the
+// context so it is added to log records.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(incomingRequestID(ctx), req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: incomingRequestID(ss.Context())})
+	}
+}
+
+func incomingRequestID(ctx context.Context) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+			ctx = ContextWithRequestID(ctx, values[0])
+		}
+	}
+	return ctx
+}
+
+// contextServerStream replaces the context of a server stream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor forwards the request ID found in the context to the
+// called service.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if requestID, ok := RequestIDFromContext(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, requestIDMetadataKey, requestID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
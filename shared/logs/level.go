@@ -0,0 +1,60 @@
+package logs
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// level is shared by every logger in the process so it can be changed at
+// runtime through LevelHandler.
+var level = new(slog.LevelVar)
+
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+func Level() slog.Level {
+	return level.Level()
+}
+
+// LevelHandler reports the current log level on GET and changes it on PUT,
+// e.g. {"level":"DEBUG"}.
+func LevelHandler(logger Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeLevel(w)
+		case http.MethodPut:
+			var payload levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+
+			var newLevel slog.Level
+			if err := newLevel.UnmarshalText([]byte(strings.ToUpper(payload.Level))); err != nil {
+				http.Error(w, "invalid log level", http.StatusBadRequest)
+				return
+			}
+
+			previous := level.Level()
+			level.Set(newLevel)
+			logger.Info("log level changed", "from", previous.String(), "to", newLevel.String())
+			writeLevel(w)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeLevel(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levelPayload{Level: level.Level().String()})
+}
@@ -0,0 +1,40 @@
+package logs
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// RedactFunc rewrites an attribute before it is written. It has the same
+// contract as slog.HandlerOptions.ReplaceAttr.
+type RedactFunc func(groups []string, a slog.Attr) slog.Attr
+
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "cookie"}
+
+// DefaultRedactor masks e-mail addresses, keeping the first character and the
+// domain, and replaces credentials with a placeholder.
+func DefaultRedactor(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+
+	if strings.Contains(key, "email") && a.Value.Kind() == slog.KindString {
+		return slog.String(a.Key, MaskEmail(a.Value.String()))
+	}
+
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(a.Key, redactedValue)
+		}
+	}
+
+	return a
+}
+
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return redactedValue
+	}
+	return email[:1] + "***" + email[at:]
+}
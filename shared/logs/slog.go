@@ -1,6 +1,8 @@
 package logs
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
 )
@@ -10,19 +12,75 @@ type Logger interface {
 	Info(msg string, args ...any)
 	Warn(msg string, args ...any)
 	Error(msg string, args ...any)
+
+	DebugContext(ctx context.Context, msg string, args ...any)
+	InfoContext(ctx context.Context, msg string, args ...any)
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+
+	With(args ...any) Logger
 }
 
 type SlogLogger struct {
 	logger *slog.Logger
 }
 
-func NewSlogLogger() *SlogLogger {
-	logLevel := configLogLevel(os.Getenv("LOG_LEVEL"))
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
+type options struct {
+	output      io.Writer
+	serviceName string
+	redact      RedactFunc
+}
+
+type Option func(*options)
+
+func WithOutput(w io.Writer) Option {
+	return func(o *options) {
+		o.output = w
+	}
+}
+
+// WithServiceName adds a "service" attribute to every record.
+func WithServiceName(name string) Option {
+	return func(o *options) {
+		o.serviceName = name
+	}
+}
+
+// WithRedaction replaces the default redaction hook. Passing nil disables
+// redaction.
+func WithRedaction(fn RedactFunc) Option {
+	return func(o *options) {
+		o.redact = fn
+	}
+}
+
+func NewSlogLogger(opts ...Option) *SlogLogger {
+	o := options{
+		output: os.Stdout,
+		redact: DefaultRedactor,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	level.Set(configLogLevel(os.Getenv("LOG_LEVEL")))
 
-	logger.Info("logger initialized", "level", logLevel.String())
+	handlerOpts := &slog.HandlerOptions{
+		Level: level,
+	}
+	if o.redact != nil {
+		redact := o.redact
+		handlerOpts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			return redact(groups, a)
+		}
+	}
+
+	logger := slog.New(&contextHandler{Handler: slog.NewJSONHandler(o.output, handlerOpts)})
+	if o.serviceName != "" {
+		logger = logger.With("service", o.serviceName)
+	}
+
+	logger.Info("logger initialized", "level", level.Level().String())
 
 	return &SlogLogger{
 		logger: logger,
@@ -59,3 +117,25 @@ func (s *SlogLogger) Warn(msg string, args ...any) {
 func (s *SlogLogger) Error(msg string, args ...any) {
 	s.logger.Error(msg, args...)
 }
+
+func (s *SlogLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	s.logger.DebugContext(ctx, msg, args...)
+}
+
+func (s *SlogLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	s.logger.InfoContext(ctx, msg, args...)
+}
+
+func (s *SlogLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	s.logger.WarnContext(ctx, msg, args...)
+}
+
+func (s *SlogLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	s.logger.ErrorContext(ctx, msg, args...)
+}
+
+func (s *SlogLogger) With(args ...any) Logger {
+	return &SlogLogger{
+		logger: s.logger.With(args...),
+	}
+}
@@ -0,0 +1,86 @@
+package logs_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lastRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &record))
+	return record
+}
+
+func TestSlogLogger(t *testing.T) {
+	t.Run("ContextAttributes", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logs.NewSlogLogger(logs.WithOutput(&buf), logs.WithServiceName("order-service"))
+
+		ctx := logs.ContextWithRequestID(context.Background(), "req-1")
+		ctx = logs.ContextWithUserID(ctx, "user-1")
+		logger.With("component", "test").InfoContext(ctx, "hello")
+
+		record := lastRecord(t, &buf)
+		assert.Equal(t, "order-service", record["service"])
+		assert.Equal(t, "test", record["component"])
+		assert.Equal(t, "req-1", record["request_id"])
+		assert.Equal(t, "user-1", record["user_id"])
+	})
+
+	t.Run("Redaction", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logs.NewSlogLogger(logs.WithOutput(&buf))
+
+		logger.Info("order created", "userEmail", "john.doe@example.com", "password", "secret")
+
+		record := lastRecord(t, &buf)
+		assert.Equal(t, "j***@example.com", record["userEmail"])
+		assert.Equal(t, "[REDACTED]", record["password"])
+	})
+
+	t.Run("RedactionDisabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logs.NewSlogLogger(logs.WithOutput(&buf), logs.WithRedaction(nil))
+
+		logger.Info("order created", "userEmail", "john.doe@example.com")
+
+		assert.Equal(t, "john.doe@example.com", lastRecord(t, &buf)["userEmail"])
+	})
+}
+
+func TestLevelHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logs.NewSlogLogger(logs.WithOutput(&buf))
+	handler := logs.LevelHandler(logger)
+	t.Cleanup(func() { logs.SetLevel(slog.LevelInfo) })
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level":"debug"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"level":"DEBUG"}`, rr.Body.String())
+	assert.Equal(t, slog.LevelDebug, logs.Level())
+
+	logger.Debug("visible")
+	assert.Equal(t, "visible", lastRecord(t, &buf)["msg"])
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level":"verbose"}`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/admin/log-level", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var cacheRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "cache_requests_total",
+	Help: "Total number of cache lookups, by cache and result (hit, miss or error).",
+}, []string{"cache", "result"})
+
+func CacheHit(cache string) {
+	cacheRequestsTotal.WithLabelValues(cache, "hit").Inc()
+}
+
+func CacheMiss(cache string) {
+	cacheRequestsTotal.WithLabelValues(cache, "miss").Inc()
+}
+
+func CacheError(cache string) {
+	cacheRequestsTotal.WithLabelValues(cache, "error").Inc()
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	consumerProcessedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_consumer_processed_total",
+		Help: "Total number of deliveries handled, by consumer.",
+	}, []string{"consumer"})
+
+	consumerProcessingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rabbitmq_consumer_processing_seconds",
+		Help:    "Time spent handling a delivery, by consumer.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"consumer"})
+
+	consumerAckedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_consumer_acked_total",
+		Help: "Total number of deliveries acknowledged, by consumer.",
+	}, []string{"consumer"})
+
+	consumerNackedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_consumer_nacked_total",
+		Help: "Total number of deliveries negatively acknowledged or rejected, by consumer.",
+	}, []string{"consumer", "requeue"})
+
+	consumerDeadLetteredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_consumer_dead_lettered_total",
+		Help: "Total number of deliveries sent to the dead letter queue, by consumer.",
+	}, []string{"consumer"})
+
+	consumerRetriedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_consumer_retried_total",
+		Help: "Total number of deliveries scheduled on a delay queue for a later retry, by consumer.",
+	}, []string{"consumer"})
+)
+
+func ObserveConsumerProcessed(consumer string, elapsed time.Duration) {
+	consumerProcessedTotal.WithLabelValues(consumer).Inc()
+	consumerProcessingSeconds.WithLabelValues(consumer).Observe(elapsed.Seconds())
+}
+
+func IncConsumerAcked(consumer string) {
+	consumerAckedTotal.WithLabelValues(consumer).Inc()
+}
+
+// IncConsumerNacked counts a nack or reject. Deliveries that are not requeued
+// end up in the consumer's dead letter queue and are counted there as well.
+func IncConsumerNacked(consumer string, requeue bool) {
+	consumerNackedTotal.WithLabelValues(consumer, strconv.FormatBool(requeue)).Inc()
+	if !requeue {
+		consumerDeadLetteredTotal.WithLabelValues(consumer).Inc()
+	}
+}
+
+func IncConsumerRetried(consumer string) {
+	consumerRetriedTotal.WithLabelValues(consumer).Inc()
+}
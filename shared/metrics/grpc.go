@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcLabels = []string{"grpc_service", "grpc_method", "grpc_code"}
+
+	grpcServerHandledTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Total number of RPCs completed on the server, by status code.",
+	}, grpcLabels)
+
+	grpcServerHandlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Latency of RPCs handled by the server.",
+		Buckets: prometheus.DefBuckets,
+	}, grpcLabels)
+
+	grpcClientHandledTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_handled_total",
+		Help: "Total number of RPCs completed by the client, by status code.",
+	}, grpcLabels)
+
+	grpcClientHandlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_handling_seconds",
+		Help:    "Latency of RPCs issued by the client until a response is received.",
+		Buckets: prometheus.DefBuckets,
+	}, grpcLabels)
+)
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeRPC(grpcServerHandledTotal, grpcServerHandlingSeconds, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor observes streaming RPCs like unary ones, from the
+// start of the call until the handler returns.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeRPC(grpcServerHandledTotal, grpcServerHandlingSeconds, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		observeRPC(grpcClientHandledTotal, grpcClientHandlingSeconds, method, err, time.Since(start))
+		return err
+	}
+}
+
+func observeRPC(counter *prometheus.CounterVec, histogram *prometheus.HistogramVec, fullMethod string, err error, elapsed time.Duration) {
+	service, method := splitMethodName(fullMethod)
+	code := status.Code(err).String()
+	counter.WithLabelValues(service, method, code).Inc()
+	histogram.WithLabelValues(service, method, code).Observe(elapsed.Seconds())
+}
+
+func splitMethodName(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests, by route and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpRequestDurationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests, by route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+)
+
+// RouteMatcher resolves the registered pattern for a request. *http.ServeMux
+// satisfies it, which keeps the route label bounded to the declared routes.
+type RouteMatcher interface {
+	Handler(r *http.Request) (http.Handler, string)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	sr.status = code
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+func HTTPMiddleware(routes RouteMatcher, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := routes.Handler(r)
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r)
+
+		code := strconv.Itoa(recorder.status)
+		httpRequestsTotal.WithLabelValues(r.Method, route, code).Inc()
+		httpRequestDurationSeconds.WithLabelValues(r.Method, route, code).Observe(time.Since(start).Seconds())
+	})
+}
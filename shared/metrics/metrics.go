@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	defaultMetricsPort = "9090"
+	metricsPath        = "/metrics"
+	logLevelPath       = "/admin/log-level"
+)
+
+type serverConfig struct {
+	adminHandlers map[string]http.Handler
+	adminAuth     func(http.Handler) http.Handler
+}
+
+type ServerOption func(*serverConfig)
+
+// WithAdminHandler registers a service specific admin endpoint on the
+// metrics server. Like the log level endpoint, it is only served behind the
+// WithAdminAuth middleware.
+func WithAdminHandler(pattern string, handler http.Handler) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.adminHandlers[pattern] = handler
+	}
+}
+
+// WithAdminAuth sets the middleware that authenticates admin requests. A nil
+// middleware leaves the admin endpoints disabled.
+func WithAdminAuth(middleware func(http.Handler) http.Handler) ServerOption {
+	return func(cfg *serverConfig) {
+		cfg.adminAuth = middleware
+	}
+}
+
+// StartServer exposes the default Prometheus registry on METRICS_PORT
+// (defaults to 9090) in a background goroutine. The runtime log level endpoint
+// and the admin handlers are served on the same port when WithAdminAuth is
+// given, and not at all otherwise, since the port is reachable by everything
+// that scrapes it. The returned server should be closed on shutdown.
+func StartServer(logger logs.Logger, opts ...ServerOption) *http.Server {
+	port := os.Getenv("METRICS_PORT")
+	if port == "" {
+		port = defaultMetricsPort
+	}
+
+	cfg := newServerConfig(logger, opts)
+
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           newServeMux(logger, cfg),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logger.Info("metrics server listening", "port", port, "path", metricsPath)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server failed", "error", err)
+		}
+	}()
+
+	return srv
+}
+
+func newServerConfig(logger logs.Logger, opts []ServerOption) *serverConfig {
+	cfg := &serverConfig{adminHandlers: map[string]http.Handler{logLevelPath: logs.LevelHandler(logger)}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+func newServeMux(logger logs.Logger, cfg *serverConfig) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+metricsPath, promhttp.Handler())
+
+	if cfg.adminAuth == nil {
+		logger.Warn("admin endpoints disabled: no admin token verifier configured")
+		return mux
+	}
+	for pattern, handler := range cfg.adminHandlers {
+		mux.Handle(pattern, cfg.adminAuth(handler))
+	}
+	return mux
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+)
+
+func requireHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestServeMuxAdminEndpoints(t *testing.T) {
+	outbox := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name     string
+		opts     []ServerOption
+		method   string
+		path     string
+		authz    string
+		wantCode int
+	}{
+		{"MetricsAreOpen", nil, http.MethodGet, "/metrics", "", http.StatusOK},
+		{"LogLevelDisabledWithoutAuth", nil, http.MethodPut, "/admin/log-level", "", http.StatusNotFound},
+		{"AdminHandlerDisabledWithoutAuth", []ServerOption{WithAdminHandler("/admin/outbox/", outbox)}, http.MethodPost, "/admin/outbox/events/1/requeue", "", http.StatusNotFound},
+		{"LogLevelRejectsAnonymous", []ServerOption{WithAdminAuth(requireHeader)}, http.MethodGet, "/admin/log-level", "", http.StatusUnauthorized},
+		{"LogLevelAllowsAdmin", []ServerOption{WithAdminAuth(requireHeader)}, http.MethodGet, "/admin/log-level", "Bearer admin", http.StatusOK},
+		{"AdminHandlerRejectsAnonymous", []ServerOption{WithAdminAuth(requireHeader), WithAdminHandler("/admin/outbox/", outbox)}, http.MethodPost, "/admin/outbox/events/1/requeue", "", http.StatusUnauthorized},
+		{"AdminHandlerAllowsAdmin", []ServerOption{WithAdminAuth(requireHeader), WithAdminHandler("/admin/outbox/", outbox)}, http.MethodPost, "/admin/outbox/events/1/requeue", "Bearer admin", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newServerConfig(logs.NewSlogLogger(), tt.opts)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authz != "" {
+				req.Header.Set("Authorization", tt.authz)
+			}
+			rr := httptest.NewRecorder()
+			newServeMux(logs.NewSlogLogger(), cfg).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	outboxBacklogEvents = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_backlog_events",
+		Help: "Number of unpublished outbox events after the last relay pass.",
+	})
+
+	outboxOldestEventAgeSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_oldest_event_age_seconds",
+		Help: "Age of the oldest unpublished outbox event after the last relay pass.",
+	})
+
+	outboxRelayedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_relayed_total",
+		Help: "Total number of outbox events relayed, by event name and result.",
+	}, []string{"event_name", "result"})
+
+	outboxFailedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_failed_total",
+		Help: "Total number of outbox events marked as FAILED after exhausting their attempts, by event name.",
+	}, []string{"event_name"})
+
+	outboxArchivedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "outbox_archived_total",
+		Help: "Total number of outbox events moved to the archive by the retention job.",
+	})
+)
+
+func SetOutboxBacklog(size int, oldestCreatedAt time.Time) {
+	outboxBacklogEvents.Set(float64(size))
+	if size == 0 || oldestCreatedAt.IsZero() {
+		outboxOldestEventAgeSeconds.Set(0)
+		return
+	}
+	outboxOldestEventAgeSeconds.Set(time.Since(oldestCreatedAt).Seconds())
+}
+
+func IncOutboxRelayed(eventName string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	outboxRelayedTotal.WithLabelValues(eventName, result).Inc()
+}
+
+func IncOutboxFailed(eventName string) {
+	outboxFailedTotal.WithLabelValues(eventName).Inc()
+}
+
+func AddOutboxArchived(count int64) {
+	outboxArchivedTotal.Add(float64(count))
+}
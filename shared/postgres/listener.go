@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	listenerMinReconnectDelay = time.Second
+	listenerMaxReconnectDelay = 30 * time.Second
+)
+
+// Listener holds a dedicated connection that LISTENs on a Postgres channel.
+// Pooled connections cannot be used because notifications are delivered to
+// the session that issued the LISTEN.
+type Listener struct {
+	logger     logs.Logger
+	connString string
+	channel    string
+}
+
+func NewListener(logger logs.Logger, connString, channel string) *Listener {
+	return &Listener{
+		logger:     logger,
+		connString: connString,
+		channel:    channel,
+	}
+}
+
+// Listen blocks until ctx is done, signalling notify for every notification
+// received on the channel. Signals are coalesced when the receiver is busy. A
+// signal is also sent after every (re)connect, because notifications sent
+// while the connection was down are lost.
+func (l *Listener) Listen(ctx context.Context, notify chan<- struct{}) {
+	delay := listenerMinReconnectDelay
+
+	for {
+		err := l.listen(ctx, notify, func() { delay = listenerMinReconnectDelay })
+		if ctx.Err() != nil {
+			return
+		}
+
+		l.logger.Warn("postgres listener disconnected, reconnecting", "channel", l.channel, "retryIn", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(delay*2, listenerMaxReconnectDelay)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, notify chan<- struct{}, onConnected func()) error {
+	conn, err := pgx.Connect(ctx, l.connString)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", l.channel, err)
+	}
+
+	onConnected()
+	l.logger.Info("postgres listener connected", "channel", l.channel)
+	signal(notify)
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		signal(notify)
+	}
+}
+
+func signal(notify chan<- struct{}) {
+	select {
+	case notify <- struct{}{}:
+	default:
+	}
+}
@@ -3,16 +3,26 @@ package rabbitmq
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
 	"github.com/sonuudigital/microservices/shared/logs"
 )
 
+// Client is the RabbitMQ implementation of broker.Publisher and
+// broker.Subscriber.
 type Client struct {
 	*connectionManager
 }
 
+var (
+	_ broker.Publisher  = (*Client)(nil)
+	_ broker.Subscriber = (*Client)(nil)
+)
+
 func NewClient(logger logs.Logger, url string) (*Client, error) {
 	manager, err := newConnectionManager(logger, url)
 	if err != nil {
@@ -21,19 +31,92 @@ func NewClient(logger logs.Logger, url string) (*Client, error) {
 	return &Client{connectionManager: manager}, nil
 }
 
-func (c *Client) Publish(ctx context.Context, opts PublishOptions) error {
-	return c.retryWithReconnect(ctx, "publish", func() error {
-		if err := c.ensureExchange(opts.Exchange, opts.ExchangeType); err != nil {
+// NewServiceClientFromEnv connects to RABBITMQ_URL and declares the messaging
+// topology of service, so its queues exist before it publishes or consumes.
+func NewServiceClientFromEnv(logger logs.Logger, service string) (*Client, error) {
+	url := os.Getenv("RABBITMQ_URL")
+	if url == "" {
+		return nil, fmt.Errorf("RABBITMQ_URL is not set")
+	}
+
+	client, err := NewClient(logger, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := client.SyncServiceTopology(context.Background(), service); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to sync messaging topology: %w", err)
+	}
+
+	return client, nil
+}
+
+// Publish declares the exchange the first time it is used on the current
+// connection; exchanges of the service's topology are already declared by
+// SyncTopology. A failed publish forgets the exchange, so the next one
+// declares it again in case it was deleted.
+func (c *Client) Publish(ctx context.Context, p broker.Publishing) error {
+	return c.retryWithReconnect(ctx, "publish", func(ch *amqp091.Channel) error {
+		if !c.exchanges.has(p.Exchange) {
+			if err := ensureExchange(ch, p.Exchange, p.Kind); err != nil {
+				return err
+			}
+			c.exchanges.add(p.Exchange)
+		}
+		if err := c.publishMessage(ctx, ch, p); err != nil {
+			c.exchanges.remove(p.Exchange)
 			return err
 		}
-		return c.publishMessage(ctx, opts)
+		return nil
 	})
 }
 
-func (c *Client) ensureExchange(name string, exchangeType ExchangeType) error {
-	return c.channel.ExchangeDeclare(
+// exchangeSet holds the exchanges declared on the current connection.
+type exchangeSet struct {
+	mu    sync.Mutex
+	names map[string]struct{}
+}
+
+func (s *exchangeSet) has(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.names[name]
+	return ok
+}
+
+func (s *exchangeSet) add(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.names == nil {
+		s.names = make(map[string]struct{})
+	}
+	for _, name := range names {
+		s.names[name] = struct{}{}
+	}
+}
+
+func (s *exchangeSet) remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.names, name)
+}
+
+// reset forgets every exchange, so they are declared again on a new
+// connection, e.g. to a broker that was restarted without its definitions.
+func (s *exchangeSet) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.names = nil
+}
+
+func ensureExchange(ch *amqp091.Channel, name string, kind broker.ExchangeKind) error {
+	if kind == "" {
+		kind = broker.ExchangeTopic
+	}
+	return ch.ExchangeDeclare(
 		name,
-		string(exchangeType),
+		string(kind),
 		true,
 		false,
 		false,
@@ -42,102 +125,39 @@ func (c *Client) ensureExchange(name string, exchangeType ExchangeType) error {
 	)
 }
 
-func (c *Client) publishMessage(ctx context.Context, opts PublishOptions) error {
+func (c *Client) publishMessage(ctx context.Context, ch *amqp091.Channel, p broker.Publishing) error {
 	publishing := amqp091.Publishing{
 		ContentType:  "application/json",
 		DeliveryMode: amqp091.Persistent,
-		Body:         opts.Body,
+		Body:         p.Body,
 		Timestamp:    time.Now(),
 	}
-
-	return c.channel.PublishWithContext(
-		ctx,
-		opts.Exchange,
-		opts.RoutingKey,
-		false,
-		false,
-		publishing,
-	)
-}
-
-func (c *Client) Subscribe(ctx context.Context, opts SubscribeOptions) error {
-	for {
-		if err := c.setupSubscription(opts); err != nil {
-			if !c.isConnectionError(err) {
-				return fmt.Errorf("failed to setup subscription: %w", err)
-			}
-			c.logger.Warn("failed to setup subscription, attempting to reconnect...", "error", err)
-			if reconnErr := c.reconnect(ctx); reconnErr != nil {
-				return fmt.Errorf("failed to reconnect during subscription setup: %w", reconnErr)
-			}
-			c.logger.Info("reconnected, retrying subscription setup")
-			continue
-		}
-
-		msgs, err := c.channel.Consume(
-			opts.QueueName,
-			opts.ConsumerTag,
-			false,
-			false,
-			false,
-			false,
-			nil,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to start consuming: %w", err)
+	if len(p.Headers) > 0 {
+		publishing.Headers = amqp091.Table{}
+		for k, v := range p.Headers {
+			publishing.Headers[k] = v
 		}
-
-		c.logger.Info("consumer subscribed", "consumerTag", opts.ConsumerTag, "queue", opts.QueueName)
-
-		err = c.consumeMessages(ctx, opts.ConsumerTag, msgs, opts.Handler)
-
-		if ctx.Err() != nil {
-			c.logger.Info("context cancelled, stopping consumer", "consumerTag", opts.ConsumerTag)
-			return ctx.Err()
-		}
-
-		c.logger.Warn("consumer connection lost, attempting to reconnect...", "consumerTag", opts.ConsumerTag, "error", err)
-
-		if err := c.reconnect(ctx); err != nil {
-			return fmt.Errorf(failedToReconnectMsg, err)
-		}
-
-		c.logger.Info("resubscribing consumer after reconnection", "consumerTag", opts.ConsumerTag)
 	}
-}
-
-func (c *Client) setupSubscription(opts SubscribeOptions) error {
-	if err := c.channel.Qos(10, 0, false); err != nil {
-		return fmt.Errorf("failed to set QoS: %w", err)
-	}
-
-	dlxName := opts.Exchange + ".dlx"
-	dlqName := opts.QueueName + ".dlq"
 
-	if err := c.channel.ExchangeDeclare(dlxName, string(ExchangeTopic), true, false, false, false, nil); err != nil {
-		return fmt.Errorf("failed to declare DLX %s: %w", dlxName, err)
-	}
-
-	if _, err := c.channel.QueueDeclare(dlqName, true, false, false, false, nil); err != nil {
-		return fmt.Errorf("failed to declare DLQ %s: %w", dlqName, err)
-	}
+	applyEnvelope(&publishing)
 
-	if err := c.channel.QueueBind(dlqName, "#", dlxName, false, nil); err != nil {
-		return fmt.Errorf("failed to bind DLQ to DLX: %w", err)
-	}
+	ctx, span := startPublishSpan(ctx, p.Exchange, p.RoutingKey, &publishing)
+	err := c.publishWithConfirm(ctx, ch, p.Exchange, p.RoutingKey, !p.AllowUnroutable, publishing)
+	endSpan(span, err)
 
-	if err := c.ensureExchange(opts.Exchange, opts.ExchangeType); err != nil {
-		return fmt.Errorf("failed to declare main exchange %s: %w", opts.Exchange, err)
-	}
-
-	args := amqp091.Table{"x-dead-letter-exchange": dlxName}
-	if _, err := c.channel.QueueDeclare(opts.QueueName, true, false, false, false, args); err != nil {
-		return fmt.Errorf("failed to declare queue %s: %w", opts.QueueName, err)
-	}
+	return err
+}
 
-	if err := c.channel.QueueBind(opts.QueueName, opts.BindingKey, opts.Exchange, false, nil); err != nil {
-		return fmt.Errorf("failed to bind queue to exchange: %w", err)
-	}
+func (c *Client) Subscribe(ctx context.Context, sub broker.Subscription) error {
+	return c.subscribe(ctx, sub, func(ch *amqp091.Channel) error {
+		return setupSubscription(ch, sub)
+	})
+}
 
-	return nil
+// setupSubscription declares the exchange, the queue with its dead letter
+// exchange and queue, its retry queues and the binding.
+func setupSubscription(ch *amqp091.Channel, sub broker.Subscription) error {
+	var d declarations
+	d.addSubscription(sub)
+	return d.declare(ch)
 }
@@ -0,0 +1,23 @@
+package rabbitmq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeSet(t *testing.T) {
+	var exchanges exchangeSet
+	assert.False(t, exchanges.has("orders.events"))
+
+	exchanges.add("orders.events", "payments.events")
+	assert.True(t, exchanges.has("orders.events"))
+	assert.True(t, exchanges.has("payments.events"))
+
+	exchanges.remove("orders.events")
+	assert.False(t, exchanges.has("orders.events"))
+	assert.True(t, exchanges.has("payments.events"))
+
+	exchanges.reset()
+	assert.False(t, exchanges.has("payments.events"))
+}
@@ -0,0 +1,152 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
+)
+
+const defaultConfirmTimeout = 5 * time.Second
+
+var (
+	ErrPublishNacked         = errors.New("message was nacked by the broker")
+	ErrPublishUnroutable     = broker.ErrUnroutable
+	ErrPublishConfirmTimeout = errors.New("timed out waiting for publisher confirm")
+)
+
+// confirmation is the part of *amqp091.DeferredConfirmation waitForConfirm
+// uses.
+type confirmation interface {
+	Done() <-chan struct{}
+	Acked() bool
+}
+
+// publishWithConfirm publishes a message and waits until the broker confirms
+// it. A mandatory message that no queue is bound to receive is returned by the
+// broker before the confirm, and is reported as ErrPublishUnroutable; a
+// message that is not mandatory is dropped by the broker instead.
+func (cm *connectionManager) publishWithConfirm(ctx context.Context, ch *amqp091.Channel, exchange, routingKey string, mandatory bool, publishing amqp091.Publishing) error {
+	if publishing.MessageId == "" {
+		publishing.MessageId = uuid.NewString()
+	}
+
+	returns := cm.channelReturns(ch)
+	cm.discardStaleReturns(returns)
+
+	deferred, err := ch.PublishWithDeferredConfirmWithContext(
+		ctx,
+		exchange,
+		routingKey,
+		mandatory,
+		false,
+		publishing,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = cm.waitForConfirm(ctx, deferred, returns, publishing.MessageId)
+	if errors.Is(err, ErrPublishNacked) && ch.IsClosed() {
+		// Pending confirmations are released as nacks when the channel
+		// closes; report that as a connection error so it is retried.
+		return fmt.Errorf("%w: %w", ErrPublishNacked, amqp091.ErrClosed)
+	}
+	return err
+}
+
+// waitForConfirm waits for the confirm of the message and checks whether the
+// broker returned it. The client reads frames in order and hands a
+// basic.return to the channel's return listener before it handles the
+// basic.ack that follows it, so once the confirm is done a return of the
+// message is already buffered in returns.
+func (cm *connectionManager) waitForConfirm(ctx context.Context, deferred confirmation, returns <-chan amqp091.Return, messageID string) error {
+	timer := time.NewTimer(cm.confirmTimeout)
+	defer timer.Stop()
+
+wait:
+	for {
+		select {
+		case ret := <-returns:
+			if ret.MessageId == messageID {
+				return unroutableError(ret)
+			}
+			cm.logStaleReturn(ret)
+		case <-deferred.Done():
+			break wait
+		case <-timer.C:
+			return fmt.Errorf("%w after %s", ErrPublishConfirmTimeout, cm.confirmTimeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for {
+		select {
+		case ret := <-returns:
+			if ret.MessageId == messageID {
+				return unroutableError(ret)
+			}
+			cm.logStaleReturn(ret)
+			continue
+		default:
+		}
+		break
+	}
+
+	if !deferred.Acked() {
+		return ErrPublishNacked
+	}
+	return nil
+}
+
+// discardStaleReturns drops returns left by an earlier publish on the channel
+// that gave up before reading them, e.g. after a confirm timeout.
+func (cm *connectionManager) discardStaleReturns(returns <-chan amqp091.Return) {
+	for {
+		select {
+		case ret := <-returns:
+			cm.logStaleReturn(ret)
+		default:
+			return
+		}
+	}
+}
+
+func (cm *connectionManager) logStaleReturn(ret amqp091.Return) {
+	cm.logger.Warn("received returned message with no pending publish", "messageID", ret.MessageId, "exchange", ret.Exchange, "routingKey", ret.RoutingKey)
+}
+
+// registerReturns gives every publish channel its own return listener, which
+// only the goroutine that borrowed the channel reads. The listener is
+// forgotten when the channel closes.
+func (cm *connectionManager) registerReturns(ch *amqp091.Channel) {
+	returns := ch.NotifyReturn(make(chan amqp091.Return, returnBufferSize))
+	closed := ch.NotifyClose(make(chan *amqp091.Error, 1))
+
+	cm.returnsMu.Lock()
+	cm.returns[ch] = returns
+	cm.returnsMu.Unlock()
+
+	go func() {
+		for range closed {
+		}
+		cm.returnsMu.Lock()
+		delete(cm.returns, ch)
+		cm.returnsMu.Unlock()
+	}()
+}
+
+func (cm *connectionManager) channelReturns(ch *amqp091.Channel) <-chan amqp091.Return {
+	cm.returnsMu.Lock()
+	defer cm.returnsMu.Unlock()
+	return cm.returns[ch]
+}
+
+func unroutableError(ret amqp091.Return) error {
+	return fmt.Errorf("%w: exchange %s, routing key %q: %d %s", ErrPublishUnroutable, ret.Exchange, ret.RoutingKey, ret.ReplyCode, ret.ReplyText)
+}
@@ -0,0 +1,110 @@
+package rabbitmq
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConfirmation struct {
+	done  chan struct{}
+	acked bool
+}
+
+func newFakeConfirmation(done, acked bool) *fakeConfirmation {
+	c := &fakeConfirmation{done: make(chan struct{}), acked: acked}
+	if done {
+		close(c.done)
+	}
+	return c
+}
+
+func (c *fakeConfirmation) Done() <-chan struct{} { return c.done }
+func (c *fakeConfirmation) Acked() bool           { return c.acked }
+
+func TestWaitForConfirm(t *testing.T) {
+	returned := amqp091.Return{MessageId: "msg-1", Exchange: "orders", RoutingKey: "created", ReplyCode: 312, ReplyText: "NO_ROUTE"}
+	stale := amqp091.Return{MessageId: "msg-0", Exchange: "orders", RoutingKey: "created", ReplyCode: 312, ReplyText: "NO_ROUTE"}
+
+	tests := []struct {
+		name         string
+		confirmation *fakeConfirmation
+		returns      []amqp091.Return
+		wantErr      error
+	}{
+		{"Acked", newFakeConfirmation(true, true), nil, nil},
+		{"Nacked", newFakeConfirmation(true, false), nil, ErrPublishNacked},
+		// The broker acks a returned message, so the return has to win over
+		// the ack even when both are ready.
+		{"ReturnedAndAcked", newFakeConfirmation(true, true), []amqp091.Return{returned}, broker.ErrUnroutable},
+		{"ReturnedBeforeConfirm", newFakeConfirmation(false, true), []amqp091.Return{returned}, broker.ErrUnroutable},
+		{"StaleReturnIgnored", newFakeConfirmation(true, true), []amqp091.Return{stale}, nil},
+		{"StaleThenReturned", newFakeConfirmation(true, true), []amqp091.Return{stale, returned}, broker.ErrUnroutable},
+		{"Timeout", newFakeConfirmation(false, false), nil, ErrPublishConfirmTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &connectionManager{logger: logs.NewSlogLogger(), confirmTimeout: 50 * time.Millisecond}
+			returns := make(chan amqp091.Return, len(tt.returns))
+			for _, ret := range tt.returns {
+				returns <- ret
+			}
+
+			err := cm.waitForConfirm(context.Background(), tt.confirmation, returns, "msg-1")
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDiscardStaleReturns(t *testing.T) {
+	cm := &connectionManager{logger: logs.NewSlogLogger()}
+	returns := make(chan amqp091.Return, 2)
+	returns <- amqp091.Return{MessageId: "msg-0"}
+	returns <- amqp091.Return{MessageId: "msg-1"}
+
+	cm.discardStaleReturns(returns)
+
+	assert.Empty(t, returns)
+}
+
+// TestPublishUnroutable needs a broker and runs only when RABBITMQ_URL is set.
+func TestPublishUnroutable(t *testing.T) {
+	url := os.Getenv("RABBITMQ_URL")
+	if url == "" {
+		t.Skip("RABBITMQ_URL is not set")
+	}
+
+	client, err := NewClient(logs.NewSlogLogger(), url)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	publishing := broker.Publishing{
+		Exchange:   "rabbitmq_confirm_test_exchange",
+		Kind:       broker.ExchangeDirect,
+		RoutingKey: "unbound",
+		Body:       []byte(`{}`),
+	}
+
+	for range 20 {
+		err = client.Publish(ctx, publishing)
+		assert.ErrorIs(t, err, broker.ErrUnroutable)
+	}
+
+	publishing.AllowUnroutable = true
+	assert.NoError(t, client.Publish(ctx, publishing))
+}
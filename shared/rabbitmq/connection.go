@@ -2,30 +2,68 @@ package rabbitmq
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"sync"
 	"time"
 
 	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
 )
 
 const (
 	maxRetries           = 3
 	backoff              = 100 * time.Millisecond
+	minReconnectBackoff  = 1 * time.Second
+	maxReconnectBackoff  = 30 * time.Second
+	publishPoolSize      = 4
+	returnBufferSize     = 8
+	consumePoolSize      = 4
 	failedToReconnectMsg = "failed to reconnect: %w"
 )
 
+var (
+	errNotConnected  = errors.New("rabbitmq connection is not available")
+	errManagerClosed = errors.New("rabbitmq connection manager is closed")
+)
+
+// connectionManager owns the AMQP connection and hands out channels from two
+// pools: publish channels run in confirm mode, consume channels are held by a
+// single subscription at a time. AMQP channels must not be shared between
+// goroutines, so every operation borrows its own channel. The connection is
+// watched with NotifyClose and re-established in the background; callers wait
+// for it with waitForConnection.
 type connectionManager struct {
-	logger     logs.Logger
-	url        string
-	connection *amqp091.Connection
-	channel    *amqp091.Channel
+	logger         logs.Logger
+	url            string
+	confirmTimeout time.Duration
+
+	mu              sync.RWMutex
+	connection      *amqp091.Connection
+	publishChannels *channelPool
+	consumeChannels *channelPool
+	ready           chan struct{}
+	done            chan struct{}
+	closed          bool
+
+	returnsMu sync.Mutex
+	returns   map[*amqp091.Channel]<-chan amqp091.Return
+
+	exchanges exchangeSet
 }
 
 func newConnectionManager(logger logs.Logger, url string) (*connectionManager, error) {
 	manager := &connectionManager{
-		logger: logger,
-		url:    url,
+		logger:         logger,
+		url:            url,
+		confirmTimeout: defaultConfirmTimeout,
+		ready:          make(chan struct{}),
+		done:           make(chan struct{}),
+		returns:        make(map[*amqp091.Channel]<-chan amqp091.Return),
 	}
 
 	if err := manager.connect(); err != nil {
@@ -41,132 +79,304 @@ func (cm *connectionManager) connect() error {
 		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
 
-	ch, err := conn.Channel()
+	publishChannels := newChannelPool(conn, publishPoolSize, cm.setupPublishChannel)
+	consumeChannels := newChannelPool(conn, consumePoolSize, nil)
+
+	// Fail fast if the broker refuses channels or confirm mode.
+	ch, err := publishChannels.get()
 	if err != nil {
 		conn.Close()
 		return fmt.Errorf("failed to open channel: %w", err)
 	}
+	publishChannels.put(ch)
 
+	closeNotifications := conn.NotifyClose(make(chan *amqp091.Error, 1))
+
+	cm.mu.Lock()
+	if cm.closed {
+		cm.mu.Unlock()
+		conn.Close()
+		return errManagerClosed
+	}
 	cm.connection = conn
-	cm.channel = ch
+	cm.publishChannels = publishChannels
+	cm.consumeChannels = consumeChannels
+	cm.exchanges.reset()
+	close(cm.ready)
+	cm.mu.Unlock()
+
+	go cm.watchConnection(closeNotifications)
+
 	cm.logger.Info("connected to RabbitMQ")
 	return nil
 }
 
-func (cm *connectionManager) reconnect(ctx context.Context) error {
-	backoff := 1 * time.Second
-	maxBackoff := 30 * time.Second
-	maxAttempts := 10
-	attempts := 0
+func (cm *connectionManager) setupPublishChannel(ch *amqp091.Channel) error {
+	if err := ch.Confirm(false); err != nil {
+		return fmt.Errorf("failed to put channel into confirm mode: %w", err)
+	}
+	cm.registerReturns(ch)
+	return nil
+}
+
+func (cm *connectionManager) watchConnection(closeNotifications <-chan *amqp091.Error) {
+	closeErr, ok := <-closeNotifications
 
-	for {
-		attempts++
-		if attempts > maxAttempts {
-			return fmt.Errorf("max reconnection attempts reached: %d", maxAttempts)
-		}
+	cm.mu.Lock()
+	if cm.closed {
+		cm.mu.Unlock()
+		return
+	}
+	cm.publishChannels.close()
+	cm.consumeChannels.close()
+	cm.connection = nil
+	cm.publishChannels = nil
+	cm.consumeChannels = nil
+	cm.ready = make(chan struct{})
+	cm.mu.Unlock()
 
+	if ok && closeErr != nil {
+		cm.logger.Warn("rabbitmq connection closed, reconnecting", "code", closeErr.Code, "reason", closeErr.Reason)
+	} else {
+		cm.logger.Warn("rabbitmq connection closed, reconnecting")
+	}
+
+	cm.reconnectLoop()
+}
+
+func (cm *connectionManager) reconnectLoop() {
+	backoff := minReconnectBackoff
+	for attempt := 1; ; attempt++ {
 		select {
-		case <-ctx.Done():
-			return ctx.Err()
+		case <-cm.done:
+			return
 		case <-time.After(backoff):
-			cm.logger.Info("attempting to reconnect to RabbitMQ", "attempt", attempts, "backoff", backoff)
+		}
 
-			if err := cm.connect(); err != nil {
-				cm.logger.Error("failed to reconnect", "error", err, "attempt", attempts, "nextRetry", backoff*2)
+		cm.logger.Info("attempting to reconnect to RabbitMQ", "attempt", attempt)
+		err := cm.connect()
+		if err == nil {
+			cm.logger.Info("successfully reconnected to RabbitMQ", "attempt", attempt)
+			return
+		}
+		if errors.Is(err, errManagerClosed) {
+			return
+		}
 
-				backoff *= 2
-				if backoff > maxBackoff {
-					backoff = maxBackoff
-				}
-				continue
-			}
+		backoff = min(backoff*2, maxReconnectBackoff)
+		cm.logger.Error("failed to reconnect", "error", err, "attempt", attempt, "nextRetry", backoff)
+	}
+}
 
-			cm.logger.Info("successfully reconnected to RabbitMQ")
-			return nil
-		}
+// waitForConnection blocks until the background reconnect has re-established
+// the connection, ctx is done or the manager is closed.
+func (cm *connectionManager) waitForConnection(ctx context.Context) error {
+	cm.mu.RLock()
+	ready := cm.ready
+	cm.mu.RUnlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-cm.done:
+		return errManagerClosed
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-func (cm *connectionManager) isConnectionError(err error) bool {
+func (cm *connectionManager) acquirePublishChannel() (*amqp091.Channel, *channelPool, error) {
+	return cm.acquire(func() *channelPool { return cm.publishChannels })
+}
+
+func (cm *connectionManager) acquireConsumeChannel() (*amqp091.Channel, *channelPool, error) {
+	return cm.acquire(func() *channelPool { return cm.consumeChannels })
+}
+
+func (cm *connectionManager) acquire(pool func() *channelPool) (*amqp091.Channel, *channelPool, error) {
+	cm.mu.RLock()
+	p := pool()
+	cm.mu.RUnlock()
+
+	if p == nil {
+		return nil, nil, errNotConnected
+	}
+
+	ch, err := p.get()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, p, nil
+}
+
+// isConnectionError reports whether err means the connection or channel is
+// gone, in which case the operation can be retried once reconnected.
+func isConnectionError(err error) bool {
 	if err == nil {
 		return false
 	}
 
-	if cm.connection == nil || cm.connection.IsClosed() {
+	if errors.Is(err, errNotConnected) || errors.Is(err, amqp091.ErrClosed) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
 		return true
 	}
 
-	if cm.channel == nil {
-		return true
+	var amqpErr *amqp091.Error
+	if errors.As(err, &amqpErr) {
+		switch amqpErr.Code {
+		case amqp091.ConnectionForced, amqp091.ChannelError, amqp091.FrameError,
+			amqp091.UnexpectedFrame, amqp091.ResourceError, amqp091.InternalError:
+			return true
+		}
+		return amqpErr.Recover
 	}
 
-	errStr := err.Error()
-	return errStr == "Exception (504) Reason: \"channel/connection is not open\"" ||
-		errStr == "Exception (320) Reason: \"CONNECTION_FORCED\"" ||
-		errStr == "write tcp: broken pipe" ||
-		errStr == "EOF"
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
 
 func (cm *connectionManager) Close() {
-	if cm.channel != nil {
-		cm.channel.Close()
+	cm.mu.Lock()
+	if cm.closed {
+		cm.mu.Unlock()
+		return
+	}
+	cm.closed = true
+	close(cm.done)
+	connection := cm.connection
+	if cm.publishChannels != nil {
+		cm.publishChannels.close()
 	}
-	if cm.connection != nil {
-		cm.connection.Close()
+	if cm.consumeChannels != nil {
+		cm.consumeChannels.close()
+	}
+	cm.mu.Unlock()
+
+	if connection != nil {
+		connection.Close()
 	}
 	cm.logger.Info("rabbitmq connection manager closed")
 }
 
 func (cm *connectionManager) Ping() error {
-	if cm.connection.IsClosed() {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.connection == nil || cm.connection.IsClosed() {
 		return fmt.Errorf("rabbitmq connection is closed")
 	}
 	return nil
 }
 
-func (cm *connectionManager) shouldReturn(err error, attempt, maxRetries int) bool {
-	return !cm.isConnectionError(err) || attempt == maxRetries
-}
+func (cm *connectionManager) retryWithReconnect(ctx context.Context, opName string, op func(ch *amqp091.Channel) error) error {
+	var err error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		err = cm.withPublishChannel(op)
+		if err == nil {
+			return nil
+		}
+		if !isConnectionError(err) || attempt == maxRetries {
+			return err
+		}
 
-func (cm *connectionManager) tryReconnect(ctx context.Context) error {
-	if err := cm.reconnect(ctx); err != nil {
-		return fmt.Errorf(failedToReconnectMsg, err)
+		cm.logger.Warn(opName+": transient error, waiting for reconnect", "attempt", attempt, "error", err)
+		if waitErr := cm.waitForConnection(ctx); waitErr != nil {
+			return fmt.Errorf(failedToReconnectMsg, waitErr)
+		}
+		time.Sleep(backoff * time.Duration(attempt))
 	}
-	return nil
+	return fmt.Errorf("%s failed after %d retries: %w", opName, maxRetries, err)
 }
 
-func (cm *connectionManager) retryWithReconnect(ctx context.Context, opName string, op func() error) error {
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if err := op(); err != nil {
-			if cm.shouldReturn(err, attempt, maxRetries) {
-				return err
-			}
-			cm.logger.Warn(opName+": transient error, attempting reconnect", "attempt", attempt, "error", err)
-			if err := cm.tryReconnect(ctx); err != nil {
-				return err
-			}
-			time.Sleep(backoff * time.Duration(attempt))
-			continue
-		}
-		return nil
+func (cm *connectionManager) withPublishChannel(op func(ch *amqp091.Channel) error) error {
+	ch, pool, err := cm.acquirePublishChannel()
+	if err != nil {
+		return err
 	}
-	return fmt.Errorf("%s failed after %d retries", opName, maxRetries)
+	defer pool.put(ch)
+	return op(ch)
 }
 
-func (cm *connectionManager) consumeMessages(ctx context.Context, consumerTag string, msgs <-chan amqp091.Delivery, handler func(ctx context.Context, d amqp091.Delivery)) error {
+// subscribe runs a consumer on a dedicated channel from the consume pool and
+// resubscribes after the connection is re-established, until ctx is done.
+func (cm *connectionManager) subscribe(ctx context.Context, sub broker.Subscription, setup func(ch *amqp091.Channel) error) error {
+	sub = sub.WithDefaults()
 	for {
-		select {
-		case <-ctx.Done():
+		err := cm.consume(ctx, sub, setup)
+		if ctx.Err() != nil {
+			cm.logger.Info("context cancelled, stopping consumer", "consumerTag", sub.Consumer)
 			return ctx.Err()
-		case d, ok := <-msgs:
-			if !ok {
-				return fmt.Errorf("rabbitmq channel closed for consumer %s", consumerTag)
-			}
-			go func(delivery amqp091.Delivery) {
-				handlerCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-				defer cancel()
-				handler(handlerCtx, delivery)
-			}(d)
 		}
+		if !isConnectionError(err) {
+			return err
+		}
+
+		cm.logger.Warn("consumer connection lost, waiting for reconnect...", "consumerTag", sub.Consumer, "error", err)
+		if err := cm.waitForConnection(ctx); err != nil {
+			return fmt.Errorf(failedToReconnectMsg, err)
+		}
+		cm.logger.Info("resubscribing consumer after reconnection", "consumerTag", sub.Consumer)
+	}
+}
+
+func (cm *connectionManager) consume(ctx context.Context, sub broker.Subscription, setup func(ch *amqp091.Channel) error) error {
+	ch, pool, err := cm.acquireConsumeChannel()
+	if err != nil {
+		return err
+	}
+	defer pool.discard(ch)
+
+	if err := ch.Qos(sub.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	if err := setup(ch); err != nil {
+		return fmt.Errorf("failed to setup subscription: %w", err)
+	}
+
+	msgs, err := ch.Consume(
+		sub.Queue,
+		sub.Consumer,
+		false,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start consuming: %w", err)
+	}
+
+	cm.logger.Info("consumer subscribed", "consumerTag", sub.Consumer, "queue", sub.Queue, "workers", sub.Workers, "prefetch", sub.Prefetch)
+
+	return cm.consumeMessages(ctx, sub, msgs)
+}
+
+type metricsAcknowledger struct {
+	amqp091.Acknowledger
+	consumer string
+}
+
+func (a *metricsAcknowledger) Ack(tag uint64, multiple bool) error {
+	err := a.Acknowledger.Ack(tag, multiple)
+	if err == nil {
+		metrics.IncConsumerAcked(a.consumer)
+	}
+	return err
+}
+
+func (a *metricsAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	err := a.Acknowledger.Nack(tag, multiple, requeue)
+	if err == nil {
+		metrics.IncConsumerNacked(a.consumer, requeue)
+	}
+	return err
+}
+
+func (a *metricsAcknowledger) Reject(tag uint64, requeue bool) error {
+	err := a.Acknowledger.Reject(tag, requeue)
+	if err == nil {
+		metrics.IncConsumerNacked(a.consumer, requeue)
 	}
+	return err
 }
@@ -0,0 +1,39 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Nil", nil, false},
+		{"NotConnected", errNotConnected, true},
+		{"ClosedWrapped", fmt.Errorf("publish: %w", amqp091.ErrClosed), true},
+		{"EOF", io.EOF, true},
+		{"ConnectionForced", &amqp091.Error{Code: amqp091.ConnectionForced, Reason: "CONNECTION_FORCED", Server: true}, true},
+		{"RecoverableServerError", &amqp091.Error{Code: amqp091.NotFound, Reason: "NOT_FOUND", Recover: true}, true},
+		{"NetworkError", &net.OpError{Op: "write", Err: errors.New("broken pipe")}, true},
+		{"PreconditionFailed", &amqp091.Error{Code: amqp091.PreconditionFailed, Reason: "PRECONDITION_FAILED"}, false},
+		{"Unroutable", ErrPublishUnroutable, false},
+		{"NackedOnOpenChannel", ErrPublishNacked, false},
+		{"NackedOnClosedChannel", fmt.Errorf("%w: %w", ErrPublishNacked, amqp091.ErrClosed), true},
+		{"Other", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isConnectionError(tt.err))
+		})
+	}
+}
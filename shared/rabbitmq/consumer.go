@@ -0,0 +1,111 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/metrics"
+)
+
+const handlerTimeout = 30 * time.Second
+
+// consumeMessages hands deliveries to a fixed set of workers. Without a
+// partition key the workers share one queue; with a key every worker has its
+// own queue and a key always maps to the same worker, which preserves the
+// order of deliveries with that key until one of them is retried (see settle).
+// The dispatcher blocks while the target worker is busy, so together with the
+// prefetch this applies backpressure.
+// When consumption stops, it waits for the in-flight handlers to finish.
+func (cm *connectionManager) consumeMessages(ctx context.Context, sub broker.Subscription, msgs <-chan amqp091.Delivery) error {
+	queues := make([]chan amqp091.Delivery, 1)
+	if sub.PartitionKey != nil {
+		queues = make([]chan amqp091.Delivery, sub.Workers)
+	}
+	for i := range queues {
+		queues[i] = make(chan amqp091.Delivery)
+	}
+
+	var wg sync.WaitGroup
+	for i := range sub.Workers {
+		queue := queues[i%len(queues)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range queue {
+				cm.handleDelivery(ctx, sub, d)
+			}
+		}()
+	}
+
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("rabbitmq channel closed for consumer %s: %w", sub.Consumer, amqp091.ErrClosed)
+			}
+
+			restoreOriginalRouting(&d)
+			queue := queues[0]
+			if sub.PartitionKey != nil {
+				queue = queues[partitionIndex(sub.PartitionKey(newMessage(d)), len(queues))]
+			}
+
+			select {
+			case queue <- d:
+			case <-ctx.Done():
+				// The delivery is left unacknowledged and is redelivered
+				// once the channel closes.
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func (cm *connectionManager) handleDelivery(ctx context.Context, sub broker.Subscription, delivery amqp091.Delivery) {
+	// In-flight handlers are allowed to finish during shutdown.
+	handlerCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), handlerTimeout)
+	defer cancel()
+	handlerCtx, span := startConsumeSpan(handlerCtx, sub.Consumer, delivery)
+
+	delivery.Acknowledger = &metricsAcknowledger{Acknowledger: delivery.Acknowledger, consumer: sub.Consumer}
+	start := time.Now()
+	err := sub.Handler(handlerCtx, newMessage(delivery))
+	metrics.ObserveConsumerProcessed(sub.Consumer, time.Since(start))
+	endSpan(span, err)
+
+	cm.settle(handlerCtx, sub, delivery, err)
+}
+
+func newMessage(d amqp091.Delivery) broker.Message {
+	return broker.Message{
+		ID:          d.MessageId,
+		Exchange:    d.Exchange,
+		RoutingKey:  d.RoutingKey,
+		ContentType: d.ContentType,
+		Type:        d.Type,
+		Timestamp:   d.Timestamp,
+		Headers:     d.Headers,
+		Body:        d.Body,
+		Retries:     RetryCount(d),
+	}
+}
+
+func partitionIndex(key string, partitions int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(partitions))
+}
@@ -0,0 +1,199 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAcknowledger struct {
+	mu     sync.Mutex
+	acked  []uint64
+	nacked []uint64
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, _ bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acked = append(a.acked, tag)
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, _, _ bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nacked = append(a.nacked, tag)
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func TestConsumeMessagesPreservesOrderPerPartitionKey(t *testing.T) {
+	cm := &connectionManager{logger: logs.NewSlogLogger()}
+	msgs := make(chan amqp091.Delivery)
+
+	var mu sync.Mutex
+	handled := map[string][]uint64{}
+	var count atomic.Int32
+	handler := func(_ context.Context, msg broker.Message) error {
+		tag := msg.Timestamp.UnixNano()
+		if tag%3 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		handled[msg.ID] = append(handled[msg.ID], uint64(tag))
+		mu.Unlock()
+		count.Add(1)
+		return nil
+	}
+
+	sub := broker.Subscription{
+		Consumer:     "test",
+		Handler:      handler,
+		Workers:      4,
+		PartitionKey: func(msg broker.Message) string { return msg.ID },
+	}.WithDefaults()
+	done := make(chan error, 1)
+	go func() { done <- cm.consumeMessages(context.Background(), sub, msgs) }()
+
+	acknowledger := &fakeAcknowledger{}
+	keys := []string{"a", "b", "c"}
+	for tag := uint64(1); tag <= 60; tag++ {
+		msgs <- amqp091.Delivery{Acknowledger: acknowledger, MessageId: keys[tag%3], DeliveryTag: tag, Timestamp: time.Unix(0, int64(tag))}
+	}
+	close(msgs)
+
+	require.Error(t, <-done)
+	assert.Equal(t, int32(60), count.Load())
+	assert.Len(t, acknowledger.acked, 60)
+	for _, key := range keys {
+		tags := handled[key]
+		require.Len(t, tags, 20)
+		for i := 1; i < len(tags); i++ {
+			assert.Less(t, tags[i-1], tags[i], "deliveries for key %s handled out of order", key)
+		}
+	}
+}
+
+func TestConsumeMessagesWaitsForInFlightHandlersOnShutdown(t *testing.T) {
+	cm := &connectionManager{logger: logs.NewSlogLogger()}
+	msgs := make(chan amqp091.Delivery)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	handler := func(handlerCtx context.Context, _ broker.Message) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(handlerCtx.Err() == nil)
+		return nil
+	}
+
+	done := make(chan error, 1)
+	sub := broker.Subscription{Consumer: "test", Handler: handler}.WithDefaults()
+	go func() { done <- cm.consumeMessages(ctx, sub, msgs) }()
+
+	msgs <- amqp091.Delivery{Acknowledger: &fakeAcknowledger{}, DeliveryTag: 1}
+	<-started
+	cancel()
+
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.True(t, finished.Load(), "consumeMessages returned before the in-flight handler finished")
+}
+
+func TestPartitionIndexIsStable(t *testing.T) {
+	for _, key := range []string{"", "product-1", "product-2"} {
+		first := partitionIndex(key, 8)
+		assert.GreaterOrEqual(t, first, 0)
+		assert.Less(t, first, 8)
+		assert.Equal(t, first, partitionIndex(key, 8))
+	}
+}
+
+func TestSettle(t *testing.T) {
+	cm := &connectionManager{logger: logs.NewSlogLogger()}
+	sub := broker.Subscription{Queue: "queue", Consumer: "test", MaxRetries: 2}.WithDefaults()
+
+	t.Run("SuccessIsAcked", func(t *testing.T) {
+		acknowledger := &fakeAcknowledger{}
+		cm.settle(context.Background(), sub, amqp091.Delivery{Acknowledger: acknowledger, DeliveryTag: 1}, nil)
+
+		assert.Equal(t, []uint64{1}, acknowledger.acked)
+		assert.Empty(t, acknowledger.nacked)
+	})
+
+	t.Run("PermanentErrorIsDeadLettered", func(t *testing.T) {
+		acknowledger := &fakeAcknowledger{}
+		cm.settle(context.Background(), sub, amqp091.Delivery{Acknowledger: acknowledger, DeliveryTag: 1}, errors.New("bad payload"))
+
+		assert.Empty(t, acknowledger.acked)
+		assert.Equal(t, []uint64{1}, acknowledger.nacked)
+	})
+
+	t.Run("RetryableErrorIsDeadLetteredAfterMaxRetries", func(t *testing.T) {
+		acknowledger := &fakeAcknowledger{}
+		d := amqp091.Delivery{
+			Acknowledger: acknowledger,
+			DeliveryTag:  1,
+			Headers:      amqp091.Table{RetryCountHeader: int32(2)},
+		}
+		cm.settle(context.Background(), sub, d, broker.Retryable(errors.New("database down")))
+
+		assert.Empty(t, acknowledger.acked)
+		assert.Equal(t, []uint64{1}, acknowledger.nacked)
+	})
+}
+
+func TestNewMessage(t *testing.T) {
+	d := amqp091.Delivery{
+		MessageId:  "event-1",
+		Exchange:   "products.events",
+		RoutingKey: "product.created",
+		Headers:    amqp091.Table{RetryCountHeader: int32(3)},
+		Body:       []byte(`{}`),
+	}
+
+	msg := newMessage(d)
+
+	assert.Equal(t, "event-1", msg.ID)
+	assert.Equal(t, "products.events", msg.Exchange)
+	assert.Equal(t, "product.created", msg.RoutingKey)
+	assert.Equal(t, 3, msg.Retries)
+	assert.Equal(t, []byte(`{}`), msg.Body)
+}
+
+func TestRestoreOriginalRouting(t *testing.T) {
+	d := amqp091.Delivery{
+		Exchange:   "",
+		RoutingKey: "queue",
+		Headers: amqp091.Table{
+			OriginalExchangeHeader:   "products",
+			OriginalRoutingKeyHeader: "product.deleted",
+			RetryCountHeader:         int32(1),
+		},
+	}
+
+	restoreOriginalRouting(&d)
+
+	assert.Equal(t, "products", d.Exchange)
+	assert.Equal(t, "product.deleted", d.RoutingKey)
+	assert.Equal(t, 1, RetryCount(d))
+}
+
+func TestRetryQueueName(t *testing.T) {
+	assert.Equal(t, "queue.retry.5s", retryQueueName("queue", 5*time.Second))
+	assert.Equal(t, "queue.retry.2m", retryQueueName("queue", 2*time.Minute))
+	assert.Equal(t, "queue.retry.1h", retryQueueName("queue", time.Hour))
+	assert.Equal(t, "queue.retry.1500ms", retryQueueName("queue", 1500*time.Millisecond))
+}
@@ -0,0 +1,230 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const DeadLetterQueueSuffix = broker.DeadLetterQueueSuffix
+
+// DeadLetter is a message read from a dead letter queue together with the
+// exchange and routing key it was originally published to.
+type DeadLetter struct {
+	DeliveryTag        uint64        `json:"-"`
+	MessageID          string        `json:"messageId,omitempty"`
+	Timestamp          time.Time     `json:"timestamp"`
+	ContentType        string        `json:"contentType,omitempty"`
+	Headers            amqp091.Table `json:"headers,omitempty"`
+	OriginalExchange   string        `json:"originalExchange"`
+	OriginalRoutingKey string        `json:"originalRoutingKey"`
+	SourceQueue        string        `json:"sourceQueue,omitempty"`
+	Reason             string        `json:"reason,omitempty"`
+	Body               []byte        `json:"body"`
+	publishing         amqp091.Publishing
+}
+
+// DeadLetterInspector reads, replays and purges dead letter queues. Reading
+// is done with basic.get without acknowledging, so messages that are not
+// replayed go back to the queue when the inspection channel is closed.
+type DeadLetterInspector struct {
+	*connectionManager
+}
+
+func NewDeadLetterInspector(logger logs.Logger, url string) (*DeadLetterInspector, error) {
+	manager, err := newConnectionManager(logger, url)
+	if err != nil {
+		return nil, err
+	}
+	return &DeadLetterInspector{connectionManager: manager}, nil
+}
+
+// MessageCount returns the number of ready messages in queue.
+func (i *DeadLetterInspector) MessageCount(queue string) (int, error) {
+	var count int
+	err := i.withInspectionChannel(func(ch *amqp091.Channel) error {
+		q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+		if err != nil {
+			return fmt.Errorf("failed to inspect queue %s: %w", queue, err)
+		}
+		count = q.Messages
+		return nil
+	})
+	return count, err
+}
+
+// Peek returns up to limit messages from queue and leaves them in the queue.
+// A limit of zero reads every ready message.
+func (i *DeadLetterInspector) Peek(queue string, limit int) ([]DeadLetter, error) {
+	var letters []DeadLetter
+	err := i.withInspectionChannel(func(ch *amqp091.Channel) error {
+		var err error
+		letters, err = getDeadLetters(ch, queue, limit)
+		return err
+	})
+	return letters, err
+}
+
+// Replay republishes the messages accepted by match to their original
+// exchange and routing key, or straight to the source queue when direct is
+// set, and removes them from the dead letter queue. The retry headers are
+// reset so the consumer gets a fresh set of retries.
+func (i *DeadLetterInspector) Replay(ctx context.Context, queue string, limit int, direct bool, match func(DeadLetter) bool) (int, error) {
+	replayed := 0
+	err := i.withInspectionChannel(func(ch *amqp091.Channel) error {
+		letters, err := getDeadLetters(ch, queue, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, letter := range letters {
+			if match != nil && !match(letter) {
+				continue
+			}
+
+			exchange, routingKey := letter.OriginalExchange, letter.OriginalRoutingKey
+			if direct {
+				exchange, routingKey = "", letter.SourceQueue
+				if routingKey == "" {
+					routingKey = strings.TrimSuffix(queue, DeadLetterQueueSuffix)
+				}
+			}
+
+			publishing := letter.publishing
+			publishing.Headers = resetRetryHeaders(letter.Headers)
+			err := i.retryWithReconnect(ctx, "replay", func(pubCh *amqp091.Channel) error {
+				return i.publishWithConfirm(ctx, pubCh, exchange, routingKey, true, publishing)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to replay message %s: %w", letter.MessageID, err)
+			}
+
+			if err := ch.Ack(letter.DeliveryTag, false); err != nil {
+				return fmt.Errorf("failed to remove replayed message %s: %w", letter.MessageID, err)
+			}
+			replayed++
+		}
+		return nil
+	})
+	return replayed, err
+}
+
+// Purge deletes every ready message in queue and returns how many were
+// removed.
+func (i *DeadLetterInspector) Purge(queue string) (int, error) {
+	var purged int
+	err := i.withInspectionChannel(func(ch *amqp091.Channel) error {
+		var err error
+		purged, err = ch.QueuePurge(queue, false)
+		if err != nil {
+			return fmt.Errorf("failed to purge queue %s: %w", queue, err)
+		}
+		return nil
+	})
+	return purged, err
+}
+
+func (i *DeadLetterInspector) withInspectionChannel(fn func(ch *amqp091.Channel) error) error {
+	ch, pool, err := i.acquireConsumeChannel()
+	if err != nil {
+		return err
+	}
+	// Unacknowledged messages are requeued when the channel closes.
+	defer pool.discard(ch)
+	return fn(ch)
+}
+
+func getDeadLetters(ch *amqp091.Channel, queue string, limit int) ([]DeadLetter, error) {
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect queue %s: %w", queue, err)
+	}
+
+	// Only read the messages that were ready when we started, so messages
+	// requeued by a concurrent reader are not read twice.
+	count := q.Messages
+	if limit > 0 {
+		count = min(count, limit)
+	}
+
+	letters := make([]DeadLetter, 0, count)
+	for range count {
+		d, ok, err := ch.Get(queue, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from %s: %w", queue, err)
+		}
+		if !ok {
+			break
+		}
+		letters = append(letters, newDeadLetter(d))
+	}
+	return letters, nil
+}
+
+func newDeadLetter(d amqp091.Delivery) DeadLetter {
+	letter := DeadLetter{
+		DeliveryTag:        d.DeliveryTag,
+		MessageID:          d.MessageId,
+		Timestamp:          d.Timestamp,
+		ContentType:        d.ContentType,
+		Headers:            d.Headers,
+		OriginalExchange:   d.Exchange,
+		OriginalRoutingKey: d.RoutingKey,
+		Body:               d.Body,
+		publishing: amqp091.Publishing{
+			ContentType:   d.ContentType,
+			DeliveryMode:  amqp091.Persistent,
+			CorrelationId: d.CorrelationId,
+			MessageId:     d.MessageId,
+			Timestamp:     d.Timestamp,
+			Type:          d.Type,
+			Body:          d.Body,
+		},
+	}
+
+	// x-death is ordered most recent first; that entry is the rejection by the
+	// consumer's queue and carries the exchange and routing key the message
+	// was delivered with.
+	if deaths, ok := d.Headers["x-death"].([]any); ok && len(deaths) > 0 {
+		if death, ok := deaths[0].(amqp091.Table); ok {
+			letter.SourceQueue, _ = death["queue"].(string)
+			letter.Reason, _ = death["reason"].(string)
+			if exchange, ok := death["exchange"].(string); ok {
+				letter.OriginalExchange = exchange
+			}
+			if keys, ok := death["routing-keys"].([]any); ok && len(keys) > 0 {
+				letter.OriginalRoutingKey, _ = keys[0].(string)
+			}
+		}
+	}
+
+	// Messages that went through a retry queue come back through the
+	// default exchange; the consumer recorded where they came from.
+	if exchange, ok := d.Headers[OriginalExchangeHeader].(string); ok {
+		letter.OriginalExchange = exchange
+	}
+	if routingKey, ok := d.Headers[OriginalRoutingKeyHeader].(string); ok {
+		letter.OriginalRoutingKey = routingKey
+	}
+
+	return letter
+}
+
+func resetRetryHeaders(headers amqp091.Table) amqp091.Table {
+	reset := amqp091.Table{}
+	for k, v := range headers {
+		switch k {
+		case "x-death", "x-first-death-exchange", "x-first-death-queue", "x-first-death-reason",
+			"x-last-death-exchange", "x-last-death-queue", "x-last-death-reason",
+			RetryCountHeader, OriginalExchangeHeader, OriginalRoutingKeyHeader:
+			continue
+		}
+		reset[k] = v
+	}
+	return reset
+}
@@ -0,0 +1,58 @@
+package rabbitmq
+
+import (
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/events"
+)
+
+// CloudEventsHeaderPrefix prefixes the envelope attributes copied to the
+// message headers, as in the CloudEvents AMQP binding.
+const CloudEventsHeaderPrefix = "cloudEvents:"
+
+// applyEnvelope copies the attributes of a CloudEvents body to the message
+// properties and headers, so the broker, the dlq tool and consumers can see
+// them without decoding the body. The event ID becomes the message ID, which
+// stays the same when the outbox publishes the event again.
+func applyEnvelope(publishing *amqp091.Publishing) {
+	envelope, err := events.ParseEnvelope(publishing.Body)
+	if err != nil || envelope.SpecVersion == "" {
+		return
+	}
+
+	publishing.ContentType = events.CloudEventsContentType
+	publishing.MessageId = envelope.ID
+	publishing.Type = envelope.Type
+	publishing.Timestamp = envelope.Time
+
+	if publishing.Headers == nil {
+		publishing.Headers = amqp091.Table{}
+	}
+	for k, v := range EnvelopeHeaders(envelope) {
+		publishing.Headers[k] = v
+	}
+}
+
+// EnvelopeHeaders maps the envelope attributes, without the data, to AMQP
+// headers.
+func EnvelopeHeaders(envelope events.Envelope) amqp091.Table {
+	headers := amqp091.Table{
+		CloudEventsHeaderPrefix + "specversion": envelope.SpecVersion,
+		CloudEventsHeaderPrefix + "id":          envelope.ID,
+		CloudEventsHeaderPrefix + "source":      envelope.Source,
+		CloudEventsHeaderPrefix + "type":        envelope.Type,
+		CloudEventsHeaderPrefix + "time":        envelope.Time.Format(time.RFC3339Nano),
+	}
+	optional := map[string]string{
+		"subject":         envelope.Subject,
+		"datacontenttype": envelope.DataContentType,
+		"dataversion":     envelope.DataVersion,
+	}
+	for name, value := range optional {
+		if value != "" {
+			headers[CloudEventsHeaderPrefix+name] = value
+		}
+	}
+	return headers
+}
@@ -0,0 +1,44 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEnvelope(t *testing.T) {
+	t.Run("Envelope", func(t *testing.T) {
+		envelope, err := events.NewEnvelope("/order-service", events.OrderCreatedEventType, "order-1", events.OrderCreatedEvent{OrderID: "order-1"})
+		require.NoError(t, err)
+		body, err := json.Marshal(envelope)
+		require.NoError(t, err)
+
+		publishing := amqp091.Publishing{ContentType: "application/json", Body: body}
+		applyEnvelope(&publishing)
+
+		assert.Equal(t, events.CloudEventsContentType, publishing.ContentType)
+		assert.Equal(t, envelope.ID, publishing.MessageId)
+		assert.Equal(t, events.OrderCreatedEventType, publishing.Type)
+		assert.True(t, envelope.Time.Equal(publishing.Timestamp))
+		assert.Equal(t, envelope.ID, publishing.Headers["cloudEvents:id"])
+		assert.Equal(t, "1.0", publishing.Headers["cloudEvents:specversion"])
+		assert.Equal(t, "/order-service", publishing.Headers["cloudEvents:source"])
+		assert.Equal(t, "order-1", publishing.Headers["cloudEvents:subject"])
+		assert.Equal(t, events.DefaultDataVersion, publishing.Headers["cloudEvents:dataversion"])
+		assert.Equal(t, envelope.Time.Format(time.RFC3339Nano), publishing.Headers["cloudEvents:time"])
+	})
+
+	t.Run("BarePayload", func(t *testing.T) {
+		publishing := amqp091.Publishing{ContentType: "application/json", Body: []byte(`{"orderId":"order-1"}`)}
+		applyEnvelope(&publishing)
+
+		assert.Equal(t, "application/json", publishing.ContentType)
+		assert.Empty(t, publishing.MessageId)
+		assert.Nil(t, publishing.Headers)
+	})
+}
@@ -0,0 +1,105 @@
+package rabbitmq
+
+import (
+	"sync"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// channelPool keeps idle channels of one connection for reuse. Channels that
+// were closed, e.g. by a channel-level exception, are dropped on put.
+type channelPool struct {
+	conn  *amqp091.Connection
+	setup func(ch *amqp091.Channel) error
+
+	mu     sync.Mutex
+	idle   []*amqp091.Channel
+	inUse  map[*amqp091.Channel]struct{}
+	size   int
+	closed bool
+}
+
+func newChannelPool(conn *amqp091.Connection, size int, setup func(ch *amqp091.Channel) error) *channelPool {
+	return &channelPool{
+		conn:  conn,
+		setup: setup,
+		inUse: make(map[*amqp091.Channel]struct{}),
+		size:  size,
+	}
+}
+
+func (p *channelPool) get() (*amqp091.Channel, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errNotConnected
+	}
+	for len(p.idle) > 0 {
+		ch := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if !ch.IsClosed() {
+			p.inUse[ch] = struct{}{}
+			p.mu.Unlock()
+			return ch, nil
+		}
+	}
+	p.mu.Unlock()
+
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	if p.setup != nil {
+		if err := p.setup(ch); err != nil {
+			ch.Close()
+			return nil, err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		ch.Close()
+		return nil, errNotConnected
+	}
+	p.inUse[ch] = struct{}{}
+	return ch, nil
+}
+
+func (p *channelPool) put(ch *amqp091.Channel) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.inUse, ch)
+	if p.closed || ch.IsClosed() || len(p.idle) >= p.size {
+		ch.Close()
+		return
+	}
+	p.idle = append(p.idle, ch)
+}
+
+func (p *channelPool) discard(ch *amqp091.Channel) {
+	p.mu.Lock()
+	delete(p.inUse, ch)
+	p.mu.Unlock()
+
+	ch.Close()
+}
+
+func (p *channelPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+	for _, ch := range p.idle {
+		ch.Close()
+	}
+	for ch := range p.inUse {
+		ch.Close()
+	}
+	p.idle = nil
+	p.inUse = nil
+}
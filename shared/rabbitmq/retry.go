@@ -0,0 +1,120 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/metrics"
+)
+
+const (
+	RetryCountHeader         = "x-retry-count"
+	OriginalExchangeHeader   = "x-original-exchange"
+	OriginalRoutingKeyHeader = "x-original-routing-key"
+)
+
+// RetryCount returns how many times the delivery has already been retried.
+func RetryCount(d amqp091.Delivery) int {
+	switch v := d.Headers[RetryCountHeader].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func retryQueueName(queueName string, delay time.Duration) string {
+	suffix := fmt.Sprintf("%dms", delay.Milliseconds())
+	switch {
+	case delay%time.Hour == 0:
+		suffix = fmt.Sprintf("%dh", delay/time.Hour)
+	case delay%time.Minute == 0:
+		suffix = fmt.Sprintf("%dm", delay/time.Minute)
+	case delay%time.Second == 0:
+		suffix = fmt.Sprintf("%ds", delay/time.Second)
+	}
+	return queueName + ".retry." + suffix
+}
+
+// restoreOriginalRouting makes a retried delivery look like the original one to
+// the handler.
+func restoreOriginalRouting(d *amqp091.Delivery) {
+	if exchange, ok := d.Headers[OriginalExchangeHeader].(string); ok {
+		d.Exchange = exchange
+	}
+	if routingKey, ok := d.Headers[OriginalRoutingKeyHeader].(string); ok {
+		d.RoutingKey = routingKey
+	}
+}
+
+// settle acknowledges the delivery according to the handler result: success
+// is acked, a retryable failure is copied to the next delay queue, and any
+// other failure, or one that exhausted its retries, is rejected to the .dlq.
+// Later deliveries with the same partition key are not held back while a
+// message waits in a delay queue, so a retry breaks the partition order.
+func (cm *connectionManager) settle(ctx context.Context, sub broker.Subscription, d amqp091.Delivery, err error) {
+	consumerTag := sub.Consumer
+	if err == nil {
+		if ackErr := d.Ack(false); ackErr != nil {
+			cm.logger.Error("failed to ack message", "consumerTag", consumerTag, "error", ackErr)
+		}
+		return
+	}
+
+	attempt := RetryCount(d) + 1
+	if !broker.IsRetryable(err) || attempt > sub.MaxRetries {
+		cm.logger.Error("message handling failed, dead-lettering", "consumerTag", consumerTag, "retries", attempt-1, "retryable", broker.IsRetryable(err), "error", err)
+		if nackErr := d.Nack(false, false); nackErr != nil {
+			cm.logger.Error("failed to nack message", "consumerTag", consumerTag, "error", nackErr)
+		}
+		return
+	}
+
+	delay := sub.RetryDelay(attempt)
+	if pubErr := cm.publishRetry(ctx, sub.Queue, delay, attempt, d); pubErr != nil {
+		cm.logger.Error("failed to schedule retry, requeueing", "consumerTag", consumerTag, "error", pubErr)
+		if nackErr := d.Nack(false, true); nackErr != nil {
+			cm.logger.Error("failed to nack message", "consumerTag", consumerTag, "error", nackErr)
+		}
+		return
+	}
+
+	metrics.IncConsumerRetried(consumerTag)
+	cm.logger.Warn("message handling failed, retrying later", "consumerTag", consumerTag, "attempt", attempt, "delay", delay, "error", err)
+	if ackErr := d.Ack(false); ackErr != nil {
+		cm.logger.Error("failed to ack message", "consumerTag", consumerTag, "error", ackErr)
+	}
+}
+
+func (cm *connectionManager) publishRetry(ctx context.Context, queueName string, delay time.Duration, attempt int, d amqp091.Delivery) error {
+	headers := amqp091.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[RetryCountHeader] = int32(attempt)
+	headers[OriginalExchangeHeader] = d.Exchange
+	headers[OriginalRoutingKeyHeader] = d.RoutingKey
+	delete(headers, "x-death")
+
+	publishing := amqp091.Publishing{
+		Headers:       headers,
+		ContentType:   d.ContentType,
+		DeliveryMode:  amqp091.Persistent,
+		CorrelationId: d.CorrelationId,
+		MessageId:     d.MessageId,
+		Timestamp:     d.Timestamp,
+		Type:          d.Type,
+		Body:          d.Body,
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	return cm.retryWithReconnect(ctx, "retry publish", func(ch *amqp091.Channel) error {
+		return cm.publishWithConfirm(ctx, ch, "", retryQueueName(queueName, delay), true, publishing)
+	})
+}
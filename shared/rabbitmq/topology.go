@@ -0,0 +1,252 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+)
+
+type exchangeDeclaration struct {
+	name string
+	kind broker.ExchangeKind
+}
+
+type queueDeclaration struct {
+	name string
+	args amqp091.Table
+}
+
+type bindingDeclaration struct {
+	queue    string
+	key      string
+	exchange string
+}
+
+// declarations are the exchanges, queues and bindings behind a set of
+// subscriptions, declared in that order.
+type declarations struct {
+	exchanges []exchangeDeclaration
+	queues    []queueDeclaration
+	bindings  []bindingDeclaration
+}
+
+func (d *declarations) addExchange(name string, kind broker.ExchangeKind) {
+	if kind == "" {
+		kind = broker.ExchangeTopic
+	}
+	for _, exchange := range d.exchanges {
+		if exchange.name == name {
+			return
+		}
+	}
+	d.exchanges = append(d.exchanges, exchangeDeclaration{name: name, kind: kind})
+}
+
+// addSubscription adds the queue of a subscription with its dead letter
+// exchange and queue and its retry queues. Fanout exchanges get a fanout dead
+// letter exchange, every other kind a topic one that forwards all keys.
+//
+// Retry queues have one delay each: messages expire after the TTL and are
+// dead-lettered through the default exchange straight back to the main
+// queue, so other queues bound to the same exchange do not see the retry.
+func (d *declarations) addSubscription(sub broker.Subscription) {
+	sub = sub.WithDefaults()
+	dlxName := sub.Exchange + broker.DeadLetterExchangeSuffix
+	dlqName := sub.Queue + broker.DeadLetterQueueSuffix
+
+	dlxKind, dlqBindingKey := broker.ExchangeTopic, "#"
+	if sub.Kind == broker.ExchangeFanout {
+		dlxKind, dlqBindingKey = broker.ExchangeFanout, ""
+	}
+
+	d.addExchange(dlxName, dlxKind)
+	d.addExchange(sub.Exchange, sub.Kind)
+
+	d.queues = append(d.queues,
+		queueDeclaration{name: dlqName},
+		queueDeclaration{name: sub.Queue, args: amqp091.Table{"x-dead-letter-exchange": dlxName}},
+	)
+	for _, delay := range sub.RetryDelays {
+		d.queues = append(d.queues, queueDeclaration{
+			name: retryQueueName(sub.Queue, delay),
+			args: amqp091.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": sub.Queue,
+			},
+		})
+	}
+
+	d.bindings = append(d.bindings,
+		bindingDeclaration{queue: dlqName, key: dlqBindingKey, exchange: dlxName},
+		bindingDeclaration{queue: sub.Queue, key: sub.BindingKey, exchange: sub.Exchange},
+	)
+}
+
+func topologyDeclarations(topology events.Topology) (declarations, error) {
+	var d declarations
+	for _, exchange := range topology.Exchanges {
+		d.addExchange(exchange.Name, exchange.Kind)
+	}
+	for _, queue := range topology.Queues {
+		sub, err := topology.Subscription(queue.Name)
+		if err != nil {
+			return declarations{}, err
+		}
+		d.addSubscription(sub)
+	}
+	return d, nil
+}
+
+func (e exchangeDeclaration) declare(ch *amqp091.Channel, passive bool) error {
+	declare := ch.ExchangeDeclare
+	if passive {
+		declare = ch.ExchangeDeclarePassive
+	}
+	return declare(e.name, string(e.kind), true, false, false, false, nil)
+}
+
+func (q queueDeclaration) declare(ch *amqp091.Channel, passive bool) error {
+	declare := ch.QueueDeclare
+	if passive {
+		declare = ch.QueueDeclarePassive
+	}
+	_, err := declare(q.name, true, false, false, false, q.args)
+	return err
+}
+
+func (d declarations) declare(ch *amqp091.Channel) error {
+	for _, exchange := range d.exchanges {
+		if err := exchange.declare(ch, false); err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %w", exchange.name, err)
+		}
+	}
+	for _, queue := range d.queues {
+		if err := queue.declare(ch, false); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", queue.name, err)
+		}
+	}
+	return d.bind(ch)
+}
+
+func (d declarations) bind(ch *amqp091.Channel) error {
+	for _, binding := range d.bindings {
+		if err := ch.QueueBind(binding.queue, binding.key, binding.exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind queue %s to exchange %s: %w", binding.queue, binding.exchange, err)
+		}
+	}
+	return nil
+}
+
+// SyncTopology compares the topology with what is declared in the broker,
+// declares the exchanges and queues that are missing and returns the
+// differences. Objects declared with another type or other arguments are
+// reported as events.DriftMismatch and left as they are, since redeclaring
+// them fails until they are deleted. Bindings cannot be read over AMQP, so
+// they are declared again without being compared.
+func (c *Client) SyncTopology(ctx context.Context, topology events.Topology) ([]events.Drift, error) {
+	d, err := topologyDeclarations(topology)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []events.Drift
+	for _, exchange := range d.exchanges {
+		drift, err := c.syncObject(ctx, "exchange", exchange.name, exchange.declare)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, drift...)
+	}
+	for _, queue := range d.queues {
+		drift, err := c.syncObject(ctx, "queue", queue.name, queue.declare)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, drift...)
+	}
+
+	if err := c.withProbeChannel(d.bind); err != nil {
+		return nil, err
+	}
+	for _, exchange := range d.exchanges {
+		c.exchanges.add(exchange.name)
+	}
+
+	for _, drift := range drifts {
+		if drift.Kind == events.DriftMismatch {
+			c.logger.Warn("messaging topology drift", "object", drift.Object, "name", drift.Name, "detail", drift.Detail)
+		} else {
+			c.logger.Info("declared missing messaging topology object", "object", drift.Object, "name", drift.Name)
+		}
+	}
+	return drifts, nil
+}
+
+// SyncServiceTopology syncs the part of events.Registry the service
+// publishes to and consumes from.
+func (c *Client) SyncServiceTopology(ctx context.Context, service string) ([]events.Drift, error) {
+	topology, err := events.Registry.ForService(service)
+	if err != nil {
+		return nil, err
+	}
+	return c.SyncTopology(ctx, topology)
+}
+
+// syncObject declares an object passively to find out whether it exists,
+// then either declares it or redeclares it with the expected settings, which
+// the broker rejects with PRECONDITION_FAILED when they differ.
+func (c *Client) syncObject(ctx context.Context, object, name string, declare func(ch *amqp091.Channel, passive bool) error) ([]events.Drift, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	err := c.withProbeChannel(func(ch *amqp091.Channel) error {
+		return declare(ch, true)
+	})
+	if amqpErr := amqpError(err); amqpErr != nil && amqpErr.Code == amqp091.NotFound {
+		if err := c.withProbeChannel(func(ch *amqp091.Channel) error {
+			return declare(ch, false)
+		}); err != nil {
+			return nil, fmt.Errorf("failed to declare %s %s: %w", object, name, err)
+		}
+		return []events.Drift{{Kind: events.DriftMissing, Object: object, Name: name}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s %s: %w", object, name, err)
+	}
+
+	err = c.withProbeChannel(func(ch *amqp091.Channel) error {
+		return declare(ch, false)
+	})
+	if amqpErr := amqpError(err); amqpErr != nil && amqpErr.Code == amqp091.PreconditionFailed {
+		return []events.Drift{{Kind: events.DriftMismatch, Object: object, Name: name, Detail: amqpErr.Reason}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s %s: %w", object, name, err)
+	}
+	return nil, nil
+}
+
+// withProbeChannel runs op on a channel that is discarded afterwards, since a
+// failed declaration closes the channel.
+func (c *Client) withProbeChannel(op func(ch *amqp091.Channel) error) error {
+	ch, pool, err := c.acquireConsumeChannel()
+	if err != nil {
+		return err
+	}
+	defer pool.discard(ch)
+	return op(ch)
+}
+
+func amqpError(err error) *amqp091.Error {
+	var amqpErr *amqp091.Error
+	if errors.As(err, &amqpErr) {
+		return amqpErr
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package rabbitmq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/broker"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddSubscription(t *testing.T) {
+	var d declarations
+	d.addSubscription(broker.Subscription{
+		Exchange:    "products.events",
+		Kind:        broker.ExchangeTopic,
+		Queue:       "search",
+		BindingKey:  "product.#",
+		RetryDelays: []time.Duration{5 * time.Second, time.Minute},
+	})
+
+	assert.Equal(t, []exchangeDeclaration{
+		{name: "products.events.dlx", kind: broker.ExchangeTopic},
+		{name: "products.events", kind: broker.ExchangeTopic},
+	}, d.exchanges)
+	assert.Equal(t, []queueDeclaration{
+		{name: "search.dlq"},
+		{name: "search", args: amqp091.Table{"x-dead-letter-exchange": "products.events.dlx"}},
+		{name: "search.retry.5s", args: amqp091.Table{"x-message-ttl": int64(5000), "x-dead-letter-exchange": "", "x-dead-letter-routing-key": "search"}},
+		{name: "search.retry.1m", args: amqp091.Table{"x-message-ttl": int64(60000), "x-dead-letter-exchange": "", "x-dead-letter-routing-key": "search"}},
+	}, d.queues)
+	assert.Equal(t, []bindingDeclaration{
+		{queue: "search.dlq", key: "#", exchange: "products.events.dlx"},
+		{queue: "search", key: "product.#", exchange: "products.events"},
+	}, d.bindings)
+}
+
+func TestTopologyDeclarations(t *testing.T) {
+	topology, err := events.Registry.ForService("order-service")
+	require.NoError(t, err)
+
+	d, err := topologyDeclarations(topology)
+	require.NoError(t, err)
+
+	assert.Equal(t, []exchangeDeclaration{
+		{name: events.OrderCreatedExchangeName, kind: broker.ExchangeFanout},
+		{name: events.OrderExchangeName, kind: broker.ExchangeTopic},
+		{name: events.StockUpdateFailedExchangeName, kind: broker.ExchangeFanout},
+		{name: events.UserExchangeName, kind: broker.ExchangeTopic},
+		{name: events.PaymentExchangeName, kind: broker.ExchangeTopic},
+		{name: events.StockUpdateFailedExchangeName + ".dlx", kind: broker.ExchangeFanout},
+		{name: events.UserExchangeName + ".dlx", kind: broker.ExchangeTopic},
+		{name: events.PaymentExchangeName + ".dlx", kind: broker.ExchangeTopic},
+	}, d.exchanges)
+	assert.Len(t, d.queues, 3*(2+len(broker.DefaultRetryDelays)))
+	assert.Contains(t, d.bindings, bindingDeclaration{queue: events.OrderStockUpdateFailedQueueName, exchange: events.StockUpdateFailedExchangeName})
+	assert.Contains(t, d.bindings, bindingDeclaration{queue: events.OrderUserEventsQueueName, key: events.UserWildcardRoutingKey, exchange: events.UserExchangeName})
+	assert.Contains(t, d.bindings, bindingDeclaration{queue: events.OrderPaymentEventsQueueName, key: events.PaymentWildcardRoutingKey, exchange: events.PaymentExchangeName})
+}
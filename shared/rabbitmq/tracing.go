@@ -0,0 +1,78 @@
+package rabbitmq
+
+import (
+	"context"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/sonuudigital/microservices/shared/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type amqpHeadersCarrier amqp091.Table
+
+func (c amqpHeadersCarrier) Get(key string) string {
+	value, ok := c[key].(string)
+	if !ok {
+		return ""
+	}
+	return value
+}
+
+func (c amqpHeadersCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c amqpHeadersCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func startPublishSpan(ctx context.Context, exchange, routingKey string, publishing *amqp091.Publishing) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ctx, "publish "+exchange,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "publish"),
+			attribute.String("messaging.destination.name", exchange),
+			attribute.String("messaging.rabbitmq.destination.routing_key", routingKey),
+		),
+	)
+
+	if publishing.Headers == nil {
+		publishing.Headers = amqp091.Table{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeadersCarrier(publishing.Headers))
+
+	return ctx, span
+}
+
+func startConsumeSpan(ctx context.Context, consumerTag string, d amqp091.Delivery) (context.Context, trace.Span) {
+	if d.Headers != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, amqpHeadersCarrier(d.Headers))
+	}
+
+	return tracing.Tracer().Start(ctx, "process "+d.Exchange,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("messaging.destination.name", d.Exchange),
+			attribute.String("messaging.rabbitmq.destination.routing_key", d.RoutingKey),
+			attribute.String("messaging.consumer.group.name", consumerTag),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+
+	instrumentationName = "github.com/sonuudigital/microservices"
+)
+
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup configures the global tracer provider and propagator. The exporter is
+// selected with OTEL_TRACES_EXPORTER (otlp, stdout or none). The OTLP exporter
+// reads the standard OTEL_EXPORTER_OTLP_* variables.
+func Setup(ctx context.Context, serviceName string, logger logs.Logger) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporterName := strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER"))
+	if exporterName == "" {
+		exporterName = ExporterNone
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" {
+			exporterName = ExporterOTLP
+		}
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterName {
+	case ExporterOTLP:
+		exporter, err = otlptracegrpc.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterNone:
+		logger.Info("tracing exporter disabled", "service", serviceName)
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, fmt.Errorf("unknown OTEL_TRACES_EXPORTER: %s", exporterName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", exporterName, err)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	logger.Info("tracing initialized", "service", serviceName, "exporter", exporterName)
+	return provider.Shutdown, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+func GRPCServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
+
+func GRPCDialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
+
+// HTTPHandler starts a server span for every request, named after the method
+// until RouteSpanName renames it after the matched route.
+func HTTPHandler(handler http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(handler, operation, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return r.Method
+	}))
+}
+
+// RouteSpanName names the server span after the route pattern, such as
+// "GET /api/products/{id}", once mux has routed the request. The pattern is
+// only known after routing, so it wraps the mux itself rather than the
+// middleware chain, which may pass on a copy of the request.
+func RouteSpanName(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if r.Pattern != "" {
+			trace.SpanFromContext(r.Context()).SetName(r.Pattern)
+		}
+	})
+}
+
+func HTTPTransport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
+
+// MarshalTraceContext serializes the propagation fields of ctx so they can be
+// persisted, e.g. alongside an outbox event. It returns nil when ctx carries
+// no span.
+func MarshalTraceContext(ctx context.Context) []byte {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	data, err := json.Marshal(carrier)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func UnmarshalTraceContext(ctx context.Context, data []byte) context.Context {
+	if len(data) == 0 {
+		return ctx
+	}
+
+	carrier := propagation.MapCarrier{}
+	if err := json.Unmarshal(data, &carrier); err != nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
@@ -0,0 +1,86 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sonuudigital/microservices/shared/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestMarshalTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+
+	t.Run("NoSpan", func(t *testing.T) {
+		assert.Nil(t, tracing.MarshalTraceContext(context.Background()))
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		ctx, span := provider.Tracer("test").Start(context.Background(), "test")
+		defer span.End()
+
+		data := tracing.MarshalTraceContext(ctx)
+		assert.NotEmpty(t, data)
+
+		restored := trace.SpanContextFromContext(tracing.UnmarshalTraceContext(context.Background(), data))
+		assert.Equal(t, span.SpanContext().TraceID(), restored.TraceID())
+		assert.Equal(t, span.SpanContext().SpanID(), restored.SpanID())
+		assert.True(t, restored.IsRemote())
+	})
+
+	t.Run("InvalidData", func(t *testing.T) {
+		ctx := tracing.UnmarshalTraceContext(context.Background(), []byte("not-json"))
+		assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+	})
+}
+
+type requestCopyKey struct{}
+
+func TestRouteSpanName(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// A middleware between the span and the mux passes on a copy of the
+	// request, as the gateway's request ID middleware does.
+	var handler http.Handler = tracing.RouteSpanName(mux)
+	next := handler
+	handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestCopyKey{}, true)))
+	})
+	handler = tracing.HTTPHandler(handler, "test")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/products/42", want: "GET /api/products/{id}"},
+		{path: "/unknown/42", want: http.MethodGet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			spans := recorder.Ended()
+			assert.Equal(t, tt.want, spans[len(spans)-1].Name())
+		})
+	}
+}
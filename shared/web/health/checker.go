@@ -0,0 +1,164 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+)
+
+const (
+	DefaultCheckInterval = 10 * time.Second
+	DefaultCheckTimeout  = 3 * time.Second
+
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+// Check is a named dependency check. Each run is bounded by Timeout, or
+// DefaultCheckTimeout when it is zero.
+type Check struct {
+	Name    string
+	Timeout time.Duration
+	Fn      CheckFunc
+}
+
+func NewCheck(name string, timeout time.Duration, fn CheckFunc) Check {
+	return Check{Name: name, Timeout: timeout, Fn: fn}
+}
+
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+type Report struct {
+	Status    string        `json:"status"`
+	CheckedAt time.Time     `json:"checkedAt"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// Checker runs its checks periodically and keeps the latest report. It is not
+// ready until the first round of checks has completed successfully.
+type Checker struct {
+	logger   logs.Logger
+	interval time.Duration
+	checks   []Check
+
+	mu        sync.RWMutex
+	report    Report
+	checked   bool
+	listeners []func(ready bool)
+}
+
+func NewChecker(logger logs.Logger, interval time.Duration, checks ...Check) *Checker {
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+
+	return &Checker{
+		logger:   logger,
+		interval: interval,
+		checks:   checks,
+		report:   Report{Status: StatusDown, Checks: []CheckResult{}},
+	}
+}
+
+// OnChange registers fn to be called whenever readiness changes. It must be
+// called before Start.
+func (c *Checker) OnChange(fn func(ready bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+func (c *Checker) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Checker) RunOnce(ctx context.Context) Report {
+	results := make([]CheckResult, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, CheckedAt: time.Now().UTC(), Checks: results}
+	for _, result := range results {
+		if result.Status == StatusDown {
+			report.Status = StatusDown
+			c.logger.Warn("health check failed", "check", result.Name, "error", result.Error)
+		}
+	}
+
+	c.mu.Lock()
+	changed := !c.checked || c.report.Status != report.Status
+	c.report = report
+	c.checked = true
+	listeners := c.listeners
+	c.mu.Unlock()
+
+	if changed {
+		c.logger.Info("readiness changed", "status", report.Status)
+		for _, listener := range listeners {
+			listener(report.Status == StatusUp)
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) Report() Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.report
+}
+
+func (c *Checker) Ready() bool {
+	return c.Report().Status == StatusUp
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Fn(ctx)
+	result := CheckResult{
+		Name:       check.Name,
+		Status:     StatusUp,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
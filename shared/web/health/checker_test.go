@@ -0,0 +1,94 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/web/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker(t *testing.T) {
+	logger := logs.NewSlogLogger()
+
+	t.Run("AllChecksUp", func(t *testing.T) {
+		checker := health.NewChecker(logger, time.Second,
+			health.NewCheck("postgres", time.Second, func(context.Context) error { return nil }),
+			health.NewCheck("redis", time.Second, func(context.Context) error { return nil }),
+		)
+
+		var changes []bool
+		checker.OnChange(func(ready bool) { changes = append(changes, ready) })
+
+		assert.False(t, checker.Ready())
+		report := checker.RunOnce(context.Background())
+
+		assert.Equal(t, health.StatusUp, report.Status)
+		assert.Len(t, report.Checks, 2)
+		assert.True(t, checker.Ready())
+		assert.Equal(t, []bool{true}, changes)
+	})
+
+	t.Run("CheckTimesOut", func(t *testing.T) {
+		checker := health.NewChecker(logger, time.Second,
+			health.NewCheck("postgres", time.Second, func(context.Context) error { return nil }),
+			health.NewCheck("rabbitmq", 10*time.Millisecond, func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+		)
+
+		report := checker.RunOnce(context.Background())
+
+		assert.Equal(t, health.StatusDown, report.Status)
+		assert.Equal(t, health.StatusUp, report.Checks[0].Status)
+		assert.Equal(t, health.StatusDown, report.Checks[1].Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[1].Error)
+	})
+
+	t.Run("RecoversOnNextRun", func(t *testing.T) {
+		failing := true
+		checker := health.NewChecker(logger, time.Second,
+			health.NewCheck("postgres", time.Second, func(context.Context) error {
+				if failing {
+					return errors.New("connection refused")
+				}
+				return nil
+			}),
+		)
+
+		var changes []bool
+		checker.OnChange(func(ready bool) { changes = append(changes, ready) })
+
+		checker.RunOnce(context.Background())
+		checker.RunOnce(context.Background())
+		failing = false
+		checker.RunOnce(context.Background())
+
+		assert.True(t, checker.Ready())
+		assert.Equal(t, []bool{false, true}, changes)
+	})
+}
+
+func TestReadinessHandler(t *testing.T) {
+	checker := health.NewChecker(logs.NewSlogLogger(), time.Second,
+		health.NewCheck("postgres", time.Second, func(context.Context) error { return errors.New("down") }),
+	)
+	handler := health.ReadinessHandler(checker)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	checker.RunOnce(context.Background())
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"name":"postgres"`)
+	assert.Contains(t, rr.Body.String(), `"error":"down"`)
+}
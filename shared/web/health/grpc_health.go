@@ -2,22 +2,50 @@ package health
 
 import (
 	"context"
+	"fmt"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
-type healthCheckFunc func(ctx context.Context) error
-
-func StartGRPCHealthCheckService(grpcServer *grpc.Server, service string, healthCheckFn healthCheckFunc) {
+// StartGRPCHealthCheckService registers the standard gRPC health service.
+// The empty service name reports liveness and is SERVING as long as the
+// server runs; the named service reports readiness and follows the checker,
+// which is run in the background until ctx is done.
+func StartGRPCHealthCheckService(ctx context.Context, grpcServer *grpc.Server, service string, checker *Checker) {
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 
-	go func() {
-		healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
-		if err := healthCheckFn(context.Background()); err == nil {
-			healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	checker.OnChange(func(ready bool) {
+		status := grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		if ready {
+			status = grpc_health_v1.HealthCheckResponse_SERVING
 		}
+		healthServer.SetServingStatus(service, status)
+	})
+
+	go func() {
+		checker.Start(ctx)
+		healthServer.Shutdown()
 	}()
 }
+
+// GRPCCheck reports the readiness of a downstream service through the
+// standard gRPC health protocol.
+func GRPCCheck(name string, conn grpc.ClientConnInterface, service string) Check {
+	client := grpc_health_v1.NewHealthClient(conn)
+	return NewCheck(name, DefaultCheckTimeout, func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+			return fmt.Errorf("%s is %s", service, resp.GetStatus())
+		}
+		return nil
+	})
+}
@@ -83,7 +83,7 @@ func RespondWithError(w http.ResponseWriter, logger logs.Logger, r *http.Request
 
 	if err := json.NewEncoder(w).Encode(problem); err != nil {
 		if logger != nil {
-			logger.Error(failedToEncodeErrRspMsg, "error", err)
+			logger.ErrorContext(r.Context(), failedToEncodeErrRspMsg, "error", err)
 		}
 		http.Error(w, failedToEncodeErrRspMsg, http.StatusInternalServerError)
 	}
@@ -118,7 +118,7 @@ func RespondWithGRPCError(w http.ResponseWriter, r *http.Request, grpcStatus *st
 
 	if err := json.NewEncoder(w).Encode(problem); err != nil {
 		if logger != nil {
-			logger.Error(failedToEncodeErrRspMsg, "error", err)
+			logger.ErrorContext(r.Context(), failedToEncodeErrRspMsg, "error", err)
 		}
 		http.Error(w, failedToEncodeErrRspMsg, http.StatusInternalServerError)
 	}
@@ -129,13 +129,13 @@ func CheckContext(ctx context.Context, w http.ResponseWriter, r *http.Request, l
 		ctxErr := ctx.Err()
 		switch ctxErr {
 		case context.Canceled:
-			logger.Warn("request canceled by the client", "error", ctxErr)
+			logger.WarnContext(ctx, "request canceled by the client", "error", ctxErr)
 			RespondWithError(w, logger, r, httpStatusClientClosedRequest, "Request Canceled", "the request was canceled by the client")
 		case context.DeadlineExceeded:
-			logger.Warn("request deadline exceeded", "error", ctxErr)
+			logger.WarnContext(ctx, "request deadline exceeded", "error", ctxErr)
 			RespondWithError(w, logger, r, http.StatusGatewayTimeout, "Deadline Exceeded", "the request deadline was exceeded")
 		default:
-			logger.Error("context error", "error", ctxErr)
+			logger.ErrorContext(ctx, "context error", "error", ctxErr)
 			RespondWithError(w, logger, r, http.StatusInternalServerError, "Internal Server Error", "an internal server error occurred")
 		}
 		return false
@@ -0,0 +1,229 @@
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate for 127.0.0.1 signed by the CA, its key and the
+// CA certificate to dir and returns the TLS config pointing at them.
+func (ca *testCA) issue(t *testing.T, dir, name string, serial int64) TLSConfig {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cfg := TLSConfig{
+		Enabled:        true,
+		CertFile:       filepath.Join(dir, name+".pem"),
+		KeyFile:        filepath.Join(dir, name+"-key.pem"),
+		CAFile:         filepath.Join(dir, "ca.pem"),
+		ReloadInterval: time.Hour,
+	}
+	require.NoError(t, os.WriteFile(cfg.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(cfg.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.WriteFile(cfg.CAFile, ca.pem, 0o600))
+	return cfg
+}
+
+func startTLSHealthServer(t *testing.T, cfg TLSConfig, logger logs.Logger) string {
+	t.Helper()
+
+	opts, err := NewGRPCServerCredentials(cfg, logger)
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+func checkHealth(t *testing.T, addr string, cfg TLSConfig, logger logs.Logger) error {
+	t.Helper()
+
+	creds, err := NewGRPCClientCredentials(cfg, logger)
+	require.NoError(t, err)
+
+	conn, err := grpc.NewClient(addr, creds)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLSHandshake(t *testing.T) {
+	logger := logs.NewSlogLogger()
+	ca := newTestCA(t, "test-ca")
+	otherCA := newTestCA(t, "other-ca")
+
+	serverCfg := ca.issue(t, t.TempDir(), "server", 2)
+	addr := startTLSHealthServer(t, serverCfg, logger)
+
+	tests := []struct {
+		name     string
+		client   TLSConfig
+		wantCode codes.Code
+	}{
+		{
+			name:     "SameCA",
+			client:   ca.issue(t, t.TempDir(), "client", 3),
+			wantCode: codes.OK,
+		},
+		{
+			// The client does not trust the server, and the server does not
+			// trust the client certificate.
+			name:     "UnknownCA",
+			client:   otherCA.issue(t, t.TempDir(), "client", 4),
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "Plaintext",
+			client:   TLSConfig{},
+			wantCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHealth(t, addr, tt.client, logger)
+
+			assert.Equal(t, tt.wantCode, status.Code(err), "error: %v", err)
+		})
+	}
+}
+
+func TestServerRejectsClientCertificateFromUnknownCA(t *testing.T) {
+	logger := logs.NewSlogLogger()
+	ca := newTestCA(t, "test-ca")
+	otherCA := newTestCA(t, "other-ca")
+
+	addr := startTLSHealthServer(t, ca.issue(t, t.TempDir(), "server", 2), logger)
+
+	// The client trusts the server's CA but presents a certificate of
+	// another CA.
+	clientCfg := otherCA.issue(t, t.TempDir(), "client", 3)
+	require.NoError(t, os.WriteFile(clientCfg.CAFile, ca.pem, 0o600))
+
+	err := checkHealth(t, addr, clientCfg, logger)
+
+	assert.Equal(t, codes.Unavailable, status.Code(err), "error: %v", err)
+}
+
+func TestCertificateReloaderPicksUpRotatedCertificate(t *testing.T) {
+	logger := logs.NewSlogLogger()
+	ca := newTestCA(t, "test-ca")
+	dir := t.TempDir()
+
+	cfg := ca.issue(t, dir, "server", 2)
+	cfg.ReloadInterval = 0
+	reloader, err := newCertificateReloader(cfg, logger)
+	require.NoError(t, err)
+
+	cert, _ := reloader.current()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), leaf.SerialNumber.Int64())
+
+	ca.issue(t, dir, "server", 5)
+	// Move the modification time forward, since the rewrite may happen within
+	// the file system's timestamp resolution.
+	future := time.Now().Add(time.Minute)
+	for _, path := range []string{cfg.CertFile, cfg.KeyFile, cfg.CAFile} {
+		require.NoError(t, os.Chtimes(path, future, future))
+	}
+
+	cert, _ = reloader.current()
+	leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), leaf.SerialNumber.Int64())
+}
+
+func TestCertificateReloaderKeepsCertificateOnInvalidFiles(t *testing.T) {
+	logger := logs.NewSlogLogger()
+	ca := newTestCA(t, "test-ca")
+
+	cfg := ca.issue(t, t.TempDir(), "server", 2)
+	cfg.ReloadInterval = 0
+	reloader, err := newCertificateReloader(cfg, logger)
+	require.NoError(t, err)
+	before, _ := reloader.current()
+
+	require.NoError(t, os.WriteFile(cfg.CertFile, []byte("not a certificate"), 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(cfg.CertFile, future, future))
+
+	after, _ := reloader.current()
+	assert.Same(t, before, after)
+}
@@ -28,6 +28,11 @@ type SearchResultProduct struct {
 	StockQuantity int32  `json:"stockQuantity"`
 }
 
+type SearchResult struct {
+	Total int                   `json:"total"`
+	Items []SearchResultProduct `json:"items"`
+}
+
 type testCase struct {
 	name          string
 	query         string
@@ -162,7 +167,7 @@ func executeSearchTest(t *testing.T, client *http.Client, apiGatewayURL string,
 	statusCode, results := performSearch(req, client, apiGatewayURL, tt.query, tt.expectedCount)
 
 	if tt.expectEmpty {
-		asrt.Equal(http.StatusNotFound, statusCode)
+		asrt.Equal(http.StatusOK, statusCode)
 		asrt.Empty(results)
 	} else {
 		asrt.Equal(http.StatusOK, statusCode)
@@ -180,13 +185,13 @@ func performSearch(req *require.Assertions, client *http.Client, apiGatewayURL,
 	req.NoError(err)
 	defer resp.Body.Close()
 
-	var results []SearchResultProduct
+	var result SearchResult
 	if resp.StatusCode == http.StatusOK {
-		err = json.NewDecoder(resp.Body).Decode(&results)
+		err = json.NewDecoder(resp.Body).Decode(&result)
 		req.NoError(err)
 	}
 
-	return resp.StatusCode, results
+	return resp.StatusCode, result.Items
 }
 
 func buildSearchURL(apiGatewayURL, query string, expectedCount int) string {
@@ -299,14 +304,14 @@ func checkIndexingComplete(t *testing.T, client *http.Client, reqURL, query stri
 		return false
 	}
 
-	var results []SearchResultProduct
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+	var result SearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		t.Logf("Retry %d/%d: Decode failed: %v", attempt+1, maxRetries, err)
 		return false
 	}
 
-	if len(results) > 0 {
-		t.Logf("Found %d results for query '%s'", len(results), query)
+	if len(result.Items) > 0 {
+		t.Logf("Found %d results for query '%s'", len(result.Items), query)
 		return true
 	}
 
@@ -326,24 +331,19 @@ func checkDeletionComplete(t *testing.T, client *http.Client, reqURL, query, pro
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		t.Logf("Product '%s' (ID: %s) successfully removed from index (Status 404)", query, productID)
-		return true
-	}
-
 	if resp.StatusCode != http.StatusOK {
 		t.Logf("Retry %d/%d: Status %d", attempt+1, maxRetries, resp.StatusCode)
 		return false
 	}
 
-	var results []SearchResultProduct
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+	var result SearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		t.Logf("Retry %d/%d: Decode failed: %v", attempt+1, maxRetries, err)
 		return false
 	}
 
 	found := false
-	for _, p := range results {
+	for _, p := range result.Items {
 		if p.ID == productID {
 			found = true
 			break
@@ -0,0 +1,3 @@
+module github.com/sonuudigital/microservices/tools/certgen
+
+go 1.25.4
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultServices = "api-gateway,user-service,product-service,cart-service,order-service,payment-service,search-service,notification-service"
+	caCertFile      = "ca.pem"
+	caKeyFile       = "ca-key.pem"
+)
+
+func main() {
+	outDir := flag.String("out", "certs/tls", "directory where the certificates are written")
+	services := flag.String("services", defaultServices, "comma separated list of service hostnames")
+	validDays := flag.Int("days", 365, "certificate validity in days")
+	flag.Parse()
+
+	if err := run(*outDir, strings.Split(*services, ","), time.Duration(*validDays)*24*time.Hour); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(outDir string, services []string, validity time.Duration) error {
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	caCert, caKey, err := loadOrCreateCA(outDir, validity)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		service = strings.TrimSpace(service)
+		if service == "" {
+			continue
+		}
+		if err := createServiceCertificate(outDir, service, caCert, caKey, validity); err != nil {
+			return fmt.Errorf("failed to create certificate for %s: %w", service, err)
+		}
+		fmt.Printf("generated certificate for %s\n", service)
+	}
+
+	return nil
+}
+
+func loadOrCreateCA(outDir string, validity time.Duration) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPath := filepath.Join(outDir, caCertFile)
+	keyPath := filepath.Join(outDir, caKeyFile)
+
+	if certPEM, err := os.ReadFile(certPath); err == nil {
+		keyPEM, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("found %s but failed to read %s: %w", certPath, keyPath, err)
+		}
+		fmt.Printf("reusing existing CA from %s\n", certPath)
+		return parseCA(certPEM, keyPEM)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          newSerialNumber(),
+		Subject:               pkix.Name{CommonName: "microservices development CA", Organization: []string{"microservices"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+
+	if err := writeCertificateAndKey(certPath, keyPath, der, key); err != nil {
+		return nil, nil, err
+	}
+	fmt.Printf("generated development CA in %s\n", certPath)
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cert, key, nil
+}
+
+func parseCA(certPEM, keyPEM []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, fmt.Errorf("invalid CA certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("invalid CA key PEM")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA key: %w", err)
+	}
+
+	return cert, key, nil
+}
+
+func createServiceCertificate(outDir, service string, caCert *x509.Certificate, caKey *ecdsa.PrivateKey, validity time.Duration) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: newSerialNumber(),
+		Subject:      pkix.Name{CommonName: service, Organization: []string{"microservices"}},
+		DNSNames:     []string{service, "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign certificate: %w", err)
+	}
+
+	return writeCertificateAndKey(
+		filepath.Join(outDir, service+".pem"),
+		filepath.Join(outDir, service+"-key.pem"),
+		der,
+		key,
+	)
+}
+
+func writeCertificateAndKey(certPath, keyPath string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	if err := os.WriteFile(certPath, certPEM, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", certPath, err)
+	}
+	// Keys must be readable by the distroless nonroot user through the bind
+	// mount, which is acceptable for development certificates only.
+	if err := os.WriteFile(keyPath, keyPEM, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", keyPath, err)
+	}
+
+	return nil
+}
+
+func newSerialNumber() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		panic(err)
+	}
+	return serial
+}
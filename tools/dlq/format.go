@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sonuudigital/microservices/shared/rabbitmq"
+)
+
+type filter struct {
+	field string
+	value string
+}
+
+type filters []filter
+
+func (f *filters) String() string {
+	parts := make([]string, 0, len(*f))
+	for _, fl := range *f {
+		parts = append(parts, fl.field+"="+fl.value)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *filters) Set(value string) error {
+	field, expected, ok := strings.Cut(value, "=")
+	if !ok || field == "" {
+		return fmt.Errorf("invalid filter %q, expected field=value", value)
+	}
+	*f = append(*f, filter{field: field, value: expected})
+	return nil
+}
+
+// match reports whether the letter satisfies every filter.
+func (f filters) match(letter rabbitmq.DeadLetter) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	var body any
+	bodyDecoded := false
+
+	for _, fl := range f {
+		var actual any
+		var found bool
+
+		switch {
+		case fl.field == "exchange":
+			actual, found = letter.OriginalExchange, true
+		case fl.field == "routingKey":
+			actual, found = letter.OriginalRoutingKey, true
+		case fl.field == "reason":
+			actual, found = letter.Reason, true
+		case fl.field == "messageId":
+			actual, found = letter.MessageID, true
+		case strings.HasPrefix(fl.field, "header."):
+			actual, found = letter.Headers[strings.TrimPrefix(fl.field, "header.")]
+		default:
+			if !bodyDecoded {
+				_ = json.Unmarshal(letter.Body, &body)
+				bodyDecoded = true
+			}
+			actual, found = lookupPath(body, fl.field)
+		}
+
+		if !found || formatValue(actual) != fl.value {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupPath walks a dotted path such as products.0.productId through a
+// decoded JSON document.
+func lookupPath(doc any, path string) (any, bool) {
+	current := doc
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return "null"
+	case float64:
+		// JSON numbers decode to float64; print them as written instead of
+		// in exponent form, so large IDs and quantities can be matched.
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any, []any:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type exportRecord struct {
+	rabbitmq.DeadLetter
+	Body    json.RawMessage `json:"body,omitempty"`
+	RawBody string          `json:"rawBody,omitempty"`
+}
+
+// newExportRecord keeps JSON bodies readable in the export and falls back to
+// the raw text for anything else.
+func newExportRecord(letter rabbitmq.DeadLetter) exportRecord {
+	record := exportRecord{DeadLetter: letter}
+	if json.Valid(letter.Body) {
+		record.Body = json.RawMessage(letter.Body)
+	} else {
+		record.RawBody = string(letter.Body)
+	}
+	return record
+}
+
+func printLetter(out io.Writer, n int, letter rabbitmq.DeadLetter) {
+	fmt.Fprintf(out, "--- message %d ---\n", n)
+	fmt.Fprintf(out, "message id:  %s\n", letter.MessageID)
+	if !letter.Timestamp.IsZero() {
+		fmt.Fprintf(out, "timestamp:   %s\n", letter.Timestamp.Format(time.RFC3339))
+	}
+	fmt.Fprintf(out, "exchange:    %s\n", letter.OriginalExchange)
+	fmt.Fprintf(out, "routing key: %s\n", letter.OriginalRoutingKey)
+	if letter.SourceQueue != "" {
+		fmt.Fprintf(out, "queue:       %s\n", letter.SourceQueue)
+	}
+	if letter.Reason != "" {
+		fmt.Fprintf(out, "reason:      %s\n", letter.Reason)
+	}
+
+	if len(letter.Headers) > 0 {
+		fmt.Fprintln(out, "headers:")
+		keys := make([]string, 0, len(letter.Headers))
+		for k := range letter.Headers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if k == "x-death" {
+				continue
+			}
+			fmt.Fprintf(out, "  %s: %s\n", k, formatValue(letter.Headers[k]))
+		}
+	}
+
+	fmt.Fprintln(out, "body:")
+	var decoded any
+	if err := json.Unmarshal(letter.Body, &decoded); err == nil {
+		pretty, _ := json.MarshalIndent(decoded, "  ", "  ")
+		fmt.Fprintf(out, "  %s\n", pretty)
+	} else {
+		fmt.Fprintf(out, "  %s\n", letter.Body)
+	}
+}
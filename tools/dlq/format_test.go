@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sonuudigital/microservices/shared/rabbitmq"
+)
+
+func TestLookupPath(t *testing.T) {
+	var doc any
+	body := `{"orderId":"o-1","amount":1234567,"products":[{"productId":"p-1","quantity":2}],"meta":null}`
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		want  any
+		found bool
+	}{
+		{path: "orderId", want: "o-1", found: true},
+		{path: "amount", want: float64(1234567), found: true},
+		{path: "products.0.productId", want: "p-1", found: true},
+		{path: "products.0.quantity", want: float64(2), found: true},
+		{path: "meta", want: nil, found: true},
+		{path: "missing", found: false},
+		{path: "products.1.productId", found: false},
+		{path: "products.-1", found: false},
+		{path: "products.first", found: false},
+		{path: "orderId.value", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, found := lookupPath(doc, tt.path)
+			if found != tt.found {
+				t.Fatalf("found = %v, want %v", found, tt.found)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("value = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiltersMatch(t *testing.T) {
+	letter := rabbitmq.DeadLetter{
+		MessageID:          "m-1",
+		OriginalExchange:   "orders.events",
+		OriginalRoutingKey: "order.created",
+		Reason:             "rejected",
+		Headers:            map[string]any{"cloudEvents:type": "order.created"},
+		Body:               []byte(`{"orderId":"o-1","amount":12345678,"price":19.99,"products":[{"productId":"p-1"}]}`),
+	}
+
+	tests := []struct {
+		name    string
+		filters filters
+		want    bool
+	}{
+		{name: "NoFilters", want: true},
+		{name: "Exchange", filters: filters{{field: "exchange", value: "orders.events"}}, want: true},
+		{name: "RoutingKey", filters: filters{{field: "routingKey", value: "order.created"}}, want: true},
+		{name: "Reason", filters: filters{{field: "reason", value: "expired"}}, want: false},
+		{name: "MessageID", filters: filters{{field: "messageId", value: "m-1"}}, want: true},
+		{name: "Header", filters: filters{{field: "header.cloudEvents:type", value: "order.created"}}, want: true},
+		{name: "MissingHeader", filters: filters{{field: "header.x-unknown", value: ""}}, want: false},
+		{name: "BodyField", filters: filters{{field: "orderId", value: "o-1"}}, want: true},
+		{name: "LargeNumber", filters: filters{{field: "amount", value: "12345678"}}, want: true},
+		{name: "Decimal", filters: filters{{field: "price", value: "19.99"}}, want: true},
+		{name: "NestedField", filters: filters{{field: "products.0.productId", value: "p-1"}}, want: true},
+		{name: "AllMustMatch", filters: filters{{field: "orderId", value: "o-1"}, {field: "exchange", value: "payments.events"}}, want: false},
+		{name: "MissingBodyField", filters: filters{{field: "userId", value: "u-1"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filters.match(letter); got != tt.want {
+				t.Fatalf("match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{value: "text", want: "text"},
+		{value: []byte("raw"), want: "raw"},
+		{value: nil, want: "null"},
+		{value: float64(12345678), want: "12345678"},
+		{value: 0.1, want: "0.1"},
+		{value: true, want: "true"},
+		{value: map[string]any{"a": float64(1)}, want: `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		if got := formatValue(tt.value); got != tt.want {
+			t.Errorf("formatValue(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
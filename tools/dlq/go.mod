@@ -0,0 +1,5 @@
+module github.com/sonuudigital/microservices/tools/dlq
+
+go 1.25.4
+
+require github.com/sonuudigital/microservices/shared v0.0.0-20251022201705-f3843615e342
@@ -1,34 +1,99 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/web"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	checkTimeout = 3 * time.Second
 )
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: health-checker <url>")
+		fmt.Fprintln(os.Stderr, "Usage: health-checker <url> | health-checker grpc <addr> <service>")
 		os.Exit(1)
 	}
-	url := os.Args[1]
 
+	if os.Args[1] == "grpc" {
+		if len(os.Args) < 4 {
+			fmt.Fprintln(os.Stderr, "Usage: health-checker grpc <addr> <service>")
+			os.Exit(1)
+		}
+		os.Exit(checkGRPC(os.Args[2], os.Args[3]))
+	}
+
+	os.Exit(checkHTTP(os.Args[1]))
+}
+
+func checkHTTP(url string) int {
 	client := &http.Client{
-		Timeout: 3 * time.Second,
+		Timeout: checkTimeout,
 	}
 
 	resp, err := client.Get(url)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		os.Exit(0)
+		return 0
 	}
 
 	fmt.Fprintf(os.Stderr, "Received status code: %d\n", resp.StatusCode)
-	os.Exit(1)
+	return 1
+}
+
+func checkGRPC(addr, service string) int {
+	tlsConfig, err := web.LoadTLSConfigFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	creds, err := web.NewGRPCClientCredentials(tlsConfig, logs.NewSlogLogger())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	conn, err := grpc.NewClient(localTarget(addr), creds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if resp.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING {
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Received status: %s\n", resp.GetStatus())
+	return 1
+}
+
+func localTarget(addr string) string {
+	if len(addr) > 0 && addr[0] == ':' {
+		return "localhost" + addr
+	}
+	return addr
 }
@@ -6,25 +6,33 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
 	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/events/worker"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"github.com/sonuudigital/microservices/shared/postgres"
+	"github.com/sonuudigital/microservices/shared/rabbitmq"
+	"github.com/sonuudigital/microservices/shared/tracing"
 	"github.com/sonuudigital/microservices/shared/web"
 	"github.com/sonuudigital/microservices/shared/web/health"
 	grpc_server "github.com/sonuudigital/microservices/user-service/internal/grpc"
 	"github.com/sonuudigital/microservices/user-service/internal/repository"
+	repo_postgres "github.com/sonuudigital/microservices/user-service/internal/repository/postgres"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
-
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	logger := logs.NewSlogLogger()
-
+	logger := logs.NewSlogLogger(logs.WithServiceName("user-service"))
 	err := godotenv.Load()
 	if err == nil {
 		logger.Info("loaded environment variables from .env file")
@@ -32,6 +40,19 @@ func main() {
 		logger.Info("no .env file found, using environment variables")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "user-service", logger)
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	adminAuth, err := auth.LoadAdminAuthFromEnv(logger)
+	if err != nil {
+		logger.Error("failed to load admin auth", "error", err)
+		os.Exit(1)
+	}
+
 	pgDb, err := postgres.InitializePostgresDB()
 	if err != nil {
 		logger.Error("error connecting to database", "error", err)
@@ -40,6 +61,12 @@ func main() {
 	logger.Info("database connected successfully")
 	defer pgDb.Close()
 
+	metricsServer := metrics.StartServer(logger, metrics.WithAdminAuth(adminAuth), metrics.WithAdminHandler(
+		worker.OutboxAdminPath,
+		worker.NewOutboxAdminHandler(logger, repo_postgres.NewOutboxEventMessageRelayerRepository(pgDb)),
+	))
+	defer metricsServer.Close()
+
 	redisClient, err := initializeRedisClient()
 	if err != nil {
 		logger.Error("error connecting to redis", "error", err)
@@ -48,61 +75,116 @@ func main() {
 	logger.Info("redis connected successfully")
 	defer redisClient.Close()
 
-	startGRPCServer(pgDb, redisClient, logger)
+	rabbitmqClient, err := rabbitmq.NewServiceClientFromEnv(logger, "user-service")
+	if err != nil {
+		logger.Error("failed to initialize RabbitMQ", "error", err)
+		os.Exit(1)
+	}
+	defer rabbitmqClient.Close()
+
+	initializeServicesAndWaitForShutdown(logger, rabbitmqClient, pgDb, redisClient)
 }
 
-func initializeRedisClient() (*redis.Client, error) {
-	redisURL := os.Getenv("REDIS_URL")
-	if redisURL == "" {
-		return nil, fmt.Errorf("REDIS_URL is not set")
+func initializeServicesAndWaitForShutdown(logger logs.Logger, rabbitmqClient *rabbitmq.Client, pgDb *pgxpool.Pool, redisClient *redis.Client) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return startGRPCServer(gCtx, logger, pgDb, redisClient, rabbitmqClient)
+	})
+
+	relayer, err := worker.NewOutboxEventMessageRelayerFromEnv(
+		logger,
+		worker.NewEventPublisher(rabbitmqClient, events.Registry),
+		repo_postgres.NewOutboxEventMessageRelayerRepository(pgDb),
+	)
+	if err != nil {
+		logger.Error("failed to configure message relayer", "error", err)
+		os.Exit(1)
 	}
+	go relayer.Start(gCtx)
 
-	opts, err := redis.ParseURL(redisURL)
+	retentionPolicy, err := worker.RetentionPolicyFromEnv()
 	if err != nil {
-		return nil, fmt.Errorf("could not parse redis URL: %w", err)
+		logger.Error("failed to get outbox retention policy", "error", err)
+		os.Exit(1)
 	}
+	go worker.NewOutboxRetentionJob(logger, repo_postgres.NewOutboxEventMessageRelayerRepository(pgDb), retentionPolicy).Start(gCtx)
 
-	client := redis.NewClient(opts)
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
-	defer cancel()
-	if _, err := client.Ping(ctx).Result(); err != nil {
-		return nil, fmt.Errorf("could not ping redis: %w", err)
+	if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+		logger.Error("application exited with error", "error", err)
+		os.Exit(1)
 	}
 
-	return client, nil
+	logger.Info("application shut down gracefully")
 }
 
-func startGRPCServer(pgDb *pgxpool.Pool, redisClient *redis.Client, logger logs.Logger) {
+func startGRPCServer(ctx context.Context, logger logs.Logger, pgDb *pgxpool.Pool, redisClient *redis.Client, rabbitmq *rabbitmq.Client) error {
 	grpcPort := os.Getenv("USER_SERVICE_GRPC_PORT")
 	if grpcPort == "" {
-		logger.Error("USER_SERVICE_GRPC_PORT is not set")
-		os.Exit(1)
+		return fmt.Errorf("USER_SERVICE_GRPC_PORT is not set")
 	}
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", grpcPort))
 	if err != nil {
-		logger.Error("failed to listen for gRPC", "error", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to listen on gRPC port: %w", err)
 	}
 
-	queries := repository.New(pgDb)
-	grpcServer := grpc.NewServer()
-	userServer := grpc_server.NewGRPCServer(queries, redisClient, logger)
+	tlsConfig, err := web.LoadTLSConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load TLS config: %w", err)
+	}
+
+	serverOpts, err := web.NewGRPCServerCredentials(tlsConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC server credentials: %w", err)
+	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logs.UnaryServerInterceptor()))
+
+	identityVerifier, err := auth.LoadIdentityTokenVerifierFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load identity token verifier: %w", err)
+	}
+	serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(identityVerifier, logger)))
+
+	serverOpts = append(serverOpts, tracing.GRPCServerOption())
+	grpcServer := grpc.NewServer(serverOpts...)
+	userServer := grpc_server.NewGRPCServer(repository.NewPostgreSQLUserRepository(pgDb), redisClient, logger)
 	userv1.RegisterUserServiceServer(grpcServer, userServer)
 
-	health.StartGRPCHealthCheckService(grpcServer, "user-service", func(ctx context.Context) error {
-		dbErr := pgDb.Ping(ctx)
-		redisErr := redisClient.Ping(ctx).Err()
-
-		if dbErr == nil && redisErr == nil {
-			logger.Info("service is healthy and serving")
-			return nil
-		} else {
-			errors := errors.Join(dbErr, redisErr)
-			logger.Error("service is not healthy", "errors", errors)
-			return errors
-		}
-	})
+	checker := health.NewChecker(logger, health.DefaultCheckInterval,
+		health.NewCheck("postgres", health.DefaultCheckTimeout, pgDb.Ping),
+		health.NewCheck("redis", health.DefaultCheckTimeout, func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}),
+		health.NewCheck("rabbitmq", health.DefaultCheckTimeout, func(context.Context) error {
+			return rabbitmq.Ping()
+		}),
+	)
+	health.StartGRPCHealthCheckService(ctx, grpcServer, "user-service", checker)
+
+	return web.StartGRPCServerAndWaitForShutdown(ctx, grpcServer, lis, logger)
+}
+
+func initializeRedisClient() (*redis.Client, error) {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		return nil, fmt.Errorf("REDIS_URL is not set")
+	}
+
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse redis URL: %w", err)
+	}
 
-	web.StartGRPCServerAndWaitForShutdown(context.Background(), grpcServer, lis, logger)
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		return nil, fmt.Errorf("could not ping redis: %w", err)
+	}
+
+	return client, nil
 }
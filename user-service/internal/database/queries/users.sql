@@ -12,3 +12,17 @@ WHERE email = $1;
 INSERT INTO users (username, email, password)
 VALUES ($1, $2, $3)
 RETURNING *;
+
+-- name: UpdateUser :one
+UPDATE users
+SET
+    username = $2,
+    email = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUser :one
+DELETE FROM users
+WHERE id = $1
+RETURNING *;
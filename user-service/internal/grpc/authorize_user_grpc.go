@@ -71,7 +71,7 @@ func (s *GRPCServer) getUserFromCacheByEmail(ctx context.Context, email string)
 }
 
 func (s *GRPCServer) getUserFromDatabase(ctx context.Context, email string) (repository.User, error) {
-	user, err := s.queries.GetUserByEmail(ctx, email)
+	user, err := s.repository.GetUserByEmail(ctx, email)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return repository.User{}, status.Errorf(codes.Unauthenticated, "invalid email or password")
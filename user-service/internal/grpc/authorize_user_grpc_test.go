@@ -28,13 +28,13 @@ func TestAuthorizeUser(t *testing.T) {
 	}
 
 	t.Run("Success - Cache Miss", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		redisMock.ExpectGet(redisEmailToUserIDKey + testEmail).RedisNil()
 
-		mockQuerier.On("GetUserByEmail", mock.Anything, testEmail).
+		mockRepository.On("GetUserByEmail", mock.Anything, testEmail).
 			Return(repository.User{
 				Email:    testEmail,
 				Password: hashedPassword,
@@ -50,13 +50,13 @@ func TestAuthorizeUser(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, testEmail, res.Email)
-		mockQuerier.AssertExpectations(t)
+		mockRepository.AssertExpectations(t)
 	})
 
 	t.Run("Success - Cache Hit", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		redisMock.ExpectGet(redisEmailToUserIDKey + testEmail).SetVal(testUUID)
 
@@ -75,17 +75,17 @@ func TestAuthorizeUser(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, testEmail, res.Email)
-		mockQuerier.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+		mockRepository.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
 	})
 
 	t.Run("User Not Found", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		redisMock.ExpectGet(redisEmailToUserIDKey + testEmail).RedisNil()
 
-		mockQuerier.On("GetUserByEmail", mock.Anything, testEmail).
+		mockRepository.On("GetUserByEmail", mock.Anything, testEmail).
 			Return(repository.User{}, pgx.ErrNoRows).Once()
 
 		res, err := server.AuthorizeUser(context.Background(), req)
@@ -95,17 +95,17 @@ func TestAuthorizeUser(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.Unauthenticated, st.Code())
-		mockQuerier.AssertExpectations(t)
+		mockRepository.AssertExpectations(t)
 	})
 
 	t.Run("Wrong Password", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		redisMock.ExpectGet(redisEmailToUserIDKey + testEmail).RedisNil()
 
-		mockQuerier.On("GetUserByEmail", mock.Anything, testEmail).
+		mockRepository.On("GetUserByEmail", mock.Anything, testEmail).
 			Return(repository.User{
 				Email:    testEmail,
 				Password: hashedPassword,
@@ -122,17 +122,17 @@ func TestAuthorizeUser(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.Unauthenticated, st.Code())
-		mockQuerier.AssertExpectations(t)
+		mockRepository.AssertExpectations(t)
 	})
 
 	t.Run("DB Error", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		redisMock.ExpectGet(redisEmailToUserIDKey + testEmail).RedisNil()
 
-		mockQuerier.On("GetUserByEmail", mock.Anything, testEmail).
+		mockRepository.On("GetUserByEmail", mock.Anything, testEmail).
 			Return(repository.User{}, errors.New("db error")).Once()
 
 		res, err := server.AuthorizeUser(context.Background(), req)
@@ -142,13 +142,13 @@ func TestAuthorizeUser(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.Internal, st.Code())
-		mockQuerier.AssertExpectations(t)
+		mockRepository.AssertExpectations(t)
 	})
 
 	t.Run("Context Canceled", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, _ := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
@@ -159,6 +159,6 @@ func TestAuthorizeUser(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.Canceled, st.Code())
-		mockQuerier.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+		mockRepository.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
 	})
 }
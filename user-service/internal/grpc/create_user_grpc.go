@@ -16,19 +16,28 @@ func (s *GRPCServer) CreateUser(ctx context.Context, req *userv1.CreateUserReque
 		return nil, status.FromContextError(err).Err()
 	}
 
+	username := strings.TrimSpace(req.Username)
+	email := strings.TrimSpace(req.Email)
+	if err := validateUser(username, email); err != nil {
+		return nil, err
+	}
+
 	hashedPassword, err := argon2id.CreateHash(strings.TrimSpace(req.Password), argon2id.DefaultParams)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
 	}
 
 	params := repository.CreateUserParams{
-		Username: strings.TrimSpace(req.Username),
-		Email:    strings.TrimSpace(req.Email),
+		Username: username,
+		Email:    email,
 		Password: hashedPassword,
 	}
 
-	user, err := s.queries.CreateUser(ctx, params)
+	user, err := s.repository.CreateUser(ctx, params)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, status.Error(codes.AlreadyExists, "username or email is already taken")
+		}
 		return nil, status.Errorf(codes.Internal, "failed to create user: %v", err)
 	}
 
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-redis/redismock/v9"
+	"github.com/jackc/pgx/v5/pgconn"
 	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
 	"github.com/sonuudigital/microservices/shared/logs"
 	grpc_server "github.com/sonuudigital/microservices/user-service/internal/grpc"
@@ -25,10 +26,10 @@ func TestCreateUser(t *testing.T) {
 	}
 
 	t.Run("Success", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
-		mockQuerier.On("CreateUser", mock.Anything, mock.AnythingOfType("repository.CreateUserParams")).
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+		mockRepository.On("CreateUser", mock.Anything, mock.AnythingOfType("repository.CreateUserParams")).
 			Return(repository.User{
 				Username: req.Username,
 				Email:    req.Email,
@@ -44,14 +45,14 @@ func TestCreateUser(t *testing.T) {
 		assert.NotNil(t, res)
 		assert.Equal(t, req.Username, res.Username)
 		assert.Equal(t, req.Email, res.Email)
-		mockQuerier.AssertExpectations(t)
+		mockRepository.AssertExpectations(t)
 	})
 
 	t.Run("DB Error", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, _ := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
-		mockQuerier.On("CreateUser", mock.Anything, mock.AnythingOfType("repository.CreateUserParams")).
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+		mockRepository.On("CreateUser", mock.Anything, mock.AnythingOfType("repository.CreateUserParams")).
 			Return(repository.User{}, errors.New("db error")).Once()
 
 		res, err := server.CreateUser(context.Background(), req)
@@ -61,13 +62,43 @@ func TestCreateUser(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.Internal, st.Code())
-		mockQuerier.AssertExpectations(t)
+		mockRepository.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Email", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		res, err := server.CreateUser(context.Background(), &userv1.CreateUserRequest{
+			Username: "testuser",
+			Email:    "Test User <test@example.com>",
+			Password: "password",
+		})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		mockRepository.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already Exists", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+		mockRepository.On("CreateUser", mock.Anything, mock.AnythingOfType("repository.CreateUserParams")).
+			Return(repository.User{}, &pgconn.PgError{Code: "23505"}).Once()
+
+		res, err := server.CreateUser(context.Background(), req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		mockRepository.AssertExpectations(t)
 	})
 
 	t.Run("Context Canceled", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, _ := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
@@ -78,6 +109,6 @@ func TestCreateUser(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.Canceled, st.Code())
-		mockQuerier.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+		mockRepository.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
 	})
 }
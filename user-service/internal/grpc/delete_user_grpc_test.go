@@ -0,0 +1,81 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/jackc/pgx/v5/pgtype"
+	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/logs"
+	grpc_server "github.com/sonuudigital/microservices/user-service/internal/grpc"
+	"github.com/sonuudigital/microservices/user-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeleteUser(t *testing.T) {
+	var pgUUID pgtype.UUID
+	_ = pgUUID.Scan(testUUID)
+
+	ctx := auth.ContextWithPrincipal(context.Background(), auth.Principal{UserID: testUUID, Email: testEmail})
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, redisMock := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		mockRepository.On("DeleteUser", mock.Anything, pgUUID).
+			Return(repository.User{ID: pgUUID, Email: testEmail}, nil).Once()
+		redisMock.ExpectDel(redisUserKeyPrefix+testUUID, redisEmailToUserIDKey+testEmail).SetVal(2)
+
+		res, err := server.DeleteUser(ctx, &userv1.DeleteUserRequest{})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+		mockRepository.AssertExpectations(t)
+		assert.NoError(t, redisMock.ExpectationsWereMet())
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		res, err := server.DeleteUser(context.Background(), &userv1.DeleteUserRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		mockRepository.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		mockRepository.On("DeleteUser", mock.Anything, pgUUID).Return(repository.User{}, repository.ErrUserNotFound).Once()
+
+		res, err := server.DeleteUser(ctx, &userv1.DeleteUserRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("DB Error", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		mockRepository.On("DeleteUser", mock.Anything, pgUUID).Return(repository.User{}, errors.New("db error")).Once()
+
+		res, err := server.DeleteUser(ctx, &userv1.DeleteUserRequest{})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
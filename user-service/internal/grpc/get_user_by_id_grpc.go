@@ -40,7 +40,7 @@ func (s *GRPCServer) GetUserByID(ctx context.Context, req *userv1.GetUserByIDReq
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user id format: %s", req.Id)
 	}
 
-	user, err := s.queries.GetUserByID(ctx, uid)
+	user, err := s.repository.GetUserByID(ctx, uid)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, status.Errorf(codes.NotFound, "user not found")
@@ -25,13 +25,13 @@ func TestGetUserByID(t *testing.T) {
 	req := &userv1.GetUserByIDRequest{Id: testUUID}
 
 	t.Run("Success - Cache Miss", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		redisMock.ExpectHGetAll(redisUserKeyPrefix + testUUID).SetVal(map[string]string{})
 
-		mockQuerier.On("GetUserByID", mock.Anything, pgUUID).
+		mockRepository.On("GetUserByID", mock.Anything, pgUUID).
 			Return(repository.User{
 				ID:    pgUUID,
 				Email: testEmail,
@@ -47,13 +47,13 @@ func TestGetUserByID(t *testing.T) {
 		assert.NotNil(t, res)
 		assert.Equal(t, testUUID, res.Id)
 		assert.Equal(t, testEmail, res.Email)
-		mockQuerier.AssertExpectations(t)
+		mockRepository.AssertExpectations(t)
 	})
 
 	t.Run("Success - Cache Hit", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		cachedData := map[string]string{
 			"id":             testUUID,
@@ -73,17 +73,17 @@ func TestGetUserByID(t *testing.T) {
 		assert.Equal(t, testUUID, res.Id)
 		assert.Equal(t, testEmail, res.Email)
 		assert.Equal(t, "testuser", res.Username)
-		mockQuerier.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+		mockRepository.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		redisMock.ExpectHGetAll(redisUserKeyPrefix + testUUID).SetVal(map[string]string{})
 
-		mockQuerier.On("GetUserByID", mock.Anything, pgUUID).
+		mockRepository.On("GetUserByID", mock.Anything, pgUUID).
 			Return(repository.User{}, pgx.ErrNoRows).Once()
 
 		res, err := server.GetUserByID(context.Background(), req)
@@ -93,13 +93,13 @@ func TestGetUserByID(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.NotFound, st.Code())
-		mockQuerier.AssertExpectations(t)
+		mockRepository.AssertExpectations(t)
 	})
 
 	t.Run("Invalid ID", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		redisMock.ExpectHGetAll(redisUserKeyPrefix + "invalid-uuid").SetVal(map[string]string{})
 
@@ -114,9 +114,9 @@ func TestGetUserByID(t *testing.T) {
 	})
 
 	t.Run("Context Canceled", func(t *testing.T) {
-		mockQuerier := new(MockQuerier)
+		mockRepository := new(MockUserRepository)
 		redisClient, redisMock := redismock.NewClientMock()
-		server := grpc_server.NewGRPCServer(mockQuerier, redisClient, logs.NewSlogLogger())
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
 
 		redisMock.MatchExpectationsInOrder(false)
 
@@ -130,6 +130,6 @@ func TestGetUserByID(t *testing.T) {
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
 		assert.Equal(t, codes.Canceled, st.Code())
-		mockQuerier.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+		mockRepository.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
 	})
 }
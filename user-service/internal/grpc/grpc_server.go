@@ -2,11 +2,14 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/redis/go-redis/v9"
 	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
 	"github.com/sonuudigital/microservices/shared/logs"
+	"github.com/sonuudigital/microservices/shared/metrics"
 	"github.com/sonuudigital/microservices/user-service/internal/repository"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -14,20 +17,30 @@ import (
 const (
 	redisUserKeyPrefix          = "user:"
 	redisEmailToUserIDKeyPrefix = "user_email_to_id:"
+	userCacheName               = "user"
+	userEmailCacheName          = "user_email"
 	redisUserExpirationTime     = time.Hour * 24
 	redisContextTimeout         = time.Second * 3
 )
 
+type UserRepository interface {
+	CreateUser(ctx context.Context, arg repository.CreateUserParams) (repository.User, error)
+	GetUserByID(ctx context.Context, id pgtype.UUID) (repository.User, error)
+	GetUserByEmail(ctx context.Context, email string) (repository.User, error)
+	UpdateUser(ctx context.Context, arg repository.UpdateUserParams) (repository.User, error)
+	DeleteUser(ctx context.Context, id pgtype.UUID) (repository.User, error)
+}
+
 type GRPCServer struct {
 	userv1.UnimplementedUserServiceServer
-	queries     repository.Querier
+	repository  UserRepository
 	redisClient *redis.Client
 	logger      logs.Logger
 }
 
-func NewGRPCServer(queries repository.Querier, redisClient *redis.Client, logger logs.Logger) *GRPCServer {
+func NewGRPCServer(repository UserRepository, redisClient *redis.Client, logger logs.Logger) *GRPCServer {
 	return &GRPCServer{
-		queries:     queries,
+		repository:  repository,
 		redisClient: redisClient,
 		logger:      logger,
 	}
@@ -40,14 +53,22 @@ func (s *GRPCServer) checkUserCache(ctx context.Context, userID string) (*Cached
 	cacheKey := redisUserKeyPrefix + userID
 	data, err := s.redisClient.HGetAll(ctx, cacheKey).Result()
 	if err != nil {
+		metrics.CacheError(userCacheName)
 		return nil, err
 	}
 
+	if len(data) == 0 {
+		metrics.CacheMiss(userCacheName)
+		return nil, redis.Nil
+	}
+
 	user, err := mapToUser(data)
 	if err != nil {
+		metrics.CacheError(userCacheName)
 		return nil, err
 	}
 
+	metrics.CacheHit(userCacheName)
 	return user, nil
 }
 
@@ -56,7 +77,16 @@ func (s *GRPCServer) checkUserIDByEmailCache(ctx context.Context, email string)
 	defer cancel()
 
 	cacheKey := redisEmailToUserIDKeyPrefix + email
-	return s.redisClient.Get(ctx, cacheKey).Result()
+	userID, err := s.redisClient.Get(ctx, cacheKey).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+		metrics.CacheMiss(userEmailCacheName)
+	case err != nil:
+		metrics.CacheError(userEmailCacheName)
+	default:
+		metrics.CacheHit(userEmailCacheName)
+	}
+	return userID, err
 }
 
 func (s *GRPCServer) cacheUser(user *userv1.User, hashedPassword string) error {
@@ -81,6 +111,18 @@ func (s *GRPCServer) cacheUserEmailToID(email, userID string) error {
 	return s.redisClient.Set(ctx, cacheKey, userID, redisUserExpirationTime).Err()
 }
 
+// invalidateUserCache removes a user and its email mapping from the cache so
+// reads after an update or a deletion go to the database.
+func (s *GRPCServer) invalidateUserCache(ctx context.Context, userID, email string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), redisContextTimeout)
+	defer cancel()
+
+	if err := s.redisClient.Del(ctx, redisUserKeyPrefix+userID, redisEmailToUserIDKeyPrefix+email).Err(); err != nil {
+		metrics.CacheError(userCacheName)
+		s.logger.Error("failed to invalidate user cache", "userID", userID, "error", err)
+	}
+}
+
 func toGRPCUser(u repository.User) *userv1.User {
 	var updatedAt *timestamppb.Timestamp
 	if u.UpdatedAt.Valid {
@@ -15,11 +15,11 @@ const (
 	redisEmailToUserIDKey = "user_email_to_id:"
 )
 
-type MockQuerier struct {
+type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockQuerier) CreateUser(ctx context.Context, arg repository.CreateUserParams) (repository.User, error) {
+func (m *MockUserRepository) CreateUser(ctx context.Context, arg repository.CreateUserParams) (repository.User, error) {
 	args := m.Called(ctx, arg)
 	if u, ok := args.Get(0).(repository.User); ok {
 		return u, args.Error(1)
@@ -27,7 +27,7 @@ func (m *MockQuerier) CreateUser(ctx context.Context, arg repository.CreateUserP
 	return repository.User{}, args.Error(1)
 }
 
-func (m *MockQuerier) GetUserByEmail(ctx context.Context, email string) (repository.User, error) {
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (repository.User, error) {
 	args := m.Called(ctx, email)
 	if u, ok := args.Get(0).(repository.User); ok {
 		return u, args.Error(1)
@@ -35,7 +35,23 @@ func (m *MockQuerier) GetUserByEmail(ctx context.Context, email string) (reposit
 	return repository.User{}, args.Error(1)
 }
 
-func (m *MockQuerier) GetUserByID(ctx context.Context, id pgtype.UUID) (repository.User, error) {
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id pgtype.UUID) (repository.User, error) {
+	args := m.Called(ctx, id)
+	if u, ok := args.Get(0).(repository.User); ok {
+		return u, args.Error(1)
+	}
+	return repository.User{}, args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUser(ctx context.Context, arg repository.UpdateUserParams) (repository.User, error) {
+	args := m.Called(ctx, arg)
+	if u, ok := args.Get(0).(repository.User); ok {
+		return u, args.Error(1)
+	}
+	return repository.User{}, args.Error(1)
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id pgtype.UUID) (repository.User, error) {
 	args := m.Called(ctx, id)
 	if u, ok := args.Get(0).(repository.User); ok {
 		return u, args.Error(1)
@@ -0,0 +1,115 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	userv1 "github.com/sonuudigital/microservices/gen/user/v1"
+	"github.com/sonuudigital/microservices/shared/auth"
+	"github.com/sonuudigital/microservices/shared/logs"
+	grpc_server "github.com/sonuudigital/microservices/user-service/internal/grpc"
+	"github.com/sonuudigital/microservices/user-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateUser(t *testing.T) {
+	var pgUUID pgtype.UUID
+	_ = pgUUID.Scan(testUUID)
+
+	ctx := auth.ContextWithPrincipal(context.Background(), auth.Principal{UserID: testUUID, Email: testEmail})
+	req := &userv1.UpdateUserRequest{Username: " newuser ", Email: "new@example.com"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, redisMock := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		mockRepository.On("GetUserByID", mock.Anything, pgUUID).
+			Return(repository.User{ID: pgUUID, Username: "testuser", Email: testEmail}, nil).Once()
+		mockRepository.On("UpdateUser", mock.Anything, repository.UpdateUserParams{ID: pgUUID, Username: "newuser", Email: "new@example.com"}).
+			Return(repository.User{ID: pgUUID, Username: "newuser", Email: "new@example.com"}, nil).Once()
+		redisMock.ExpectDel(redisUserKeyPrefix+testUUID, redisEmailToUserIDKey+testEmail).SetVal(2)
+
+		res, err := server.UpdateUser(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "newuser", res.Username)
+		assert.Equal(t, "new@example.com", res.Email)
+		mockRepository.AssertExpectations(t)
+		assert.NoError(t, redisMock.ExpectationsWereMet())
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		res, err := server.UpdateUser(context.Background(), req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		mockRepository.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Missing Fields", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		res, err := server.UpdateUser(ctx, &userv1.UpdateUserRequest{Username: "newuser"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		mockRepository.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid Email", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		res, err := server.UpdateUser(ctx, &userv1.UpdateUserRequest{Username: "newuser", Email: "not-an-email"})
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		mockRepository.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Email Taken", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		mockRepository.On("GetUserByID", mock.Anything, pgUUID).
+			Return(repository.User{ID: pgUUID, Username: "testuser", Email: testEmail}, nil).Once()
+		mockRepository.On("UpdateUser", mock.Anything, mock.Anything).
+			Return(repository.User{}, &pgconn.PgError{Code: "23505"}).Once()
+
+		res, err := server.UpdateUser(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		mockRepository.AssertExpectations(t)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockRepository := new(MockUserRepository)
+		redisClient, _ := redismock.NewClientMock()
+		server := grpc_server.NewGRPCServer(mockRepository, redisClient, logs.NewSlogLogger())
+
+		mockRepository.On("GetUserByID", mock.Anything, pgUUID).Return(repository.User{}, pgx.ErrNoRows).Once()
+
+		res, err := server.UpdateUser(ctx, req)
+
+		assert.Nil(t, res)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mockRepository.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+	})
+}
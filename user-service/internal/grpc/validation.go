@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"errors"
+	"net/mail"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const uniqueViolationCode = "23505"
+
+// validateUser checks the username and email of a new or updated user,
+// after they have been trimmed.
+func validateUser(username, email string) error {
+	if username == "" || email == "" {
+		return status.Error(codes.InvalidArgument, "username and email are required")
+	}
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return status.Errorf(codes.InvalidArgument, "invalid email: %s", email)
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a violation of the unique
+// username or email of the users table.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
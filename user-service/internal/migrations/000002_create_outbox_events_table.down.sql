@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS outbox_events_notify ON outbox_events;
+DROP FUNCTION IF EXISTS notify_outbox_event();
+DROP TABLE IF EXISTS outbox_events;
+DROP TYPE IF EXISTS outbox_event_status;
\ No newline at end of file
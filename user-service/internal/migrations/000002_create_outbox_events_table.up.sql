@@ -0,0 +1,27 @@
+CREATE TYPE outbox_event_status AS ENUM ('UNPUBLISHED', 'PUBLISHED', 'CANCELLED', 'FAILED');
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    aggregate_id UUID NOT NULL,
+    event_name VARCHAR(150) NOT NULL,
+    payload JSONB NOT NULL,
+    status outbox_event_status DEFAULT 'UNPUBLISHED' NOT NULL,
+    trace_context JSONB NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE NULL
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_claim ON outbox_events (next_attempt_at, created_at) WHERE status = 'UNPUBLISHED';
+
+CREATE OR REPLACE FUNCTION notify_outbox_event() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('outbox_events', NEW.event_name);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_events_notify
+    AFTER INSERT ON outbox_events
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_outbox_event();
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS outbox_events_archive (
+    id UUID PRIMARY KEY,
+    aggregate_id UUID NOT NULL,
+    event_name VARCHAR(150) NOT NULL,
+    payload JSONB NOT NULL,
+    status outbox_event_status NOT NULL,
+    trace_context JSONB NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE NULL,
+    archived_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_archive_aggregate_id ON outbox_events_archive (aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_archivable ON outbox_events (created_at) WHERE status = 'PUBLISHED';
\ No newline at end of file
@@ -0,0 +1,3 @@
+DELETE FROM outbox_events
+WHERE status = 'UNPUBLISHED'
+    AND payload->>'source' = '/user-service/backfill';
\ No newline at end of file
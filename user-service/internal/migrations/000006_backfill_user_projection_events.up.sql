@@ -0,0 +1,20 @@
+-- Users registered before user events were published are unknown to the
+-- projections other services build from them. Record a user.updated event for
+-- every user so the relayer publishes them; consumers that already saw a newer
+-- event keep it, since the event time is the user's last change.
+INSERT INTO outbox_events (aggregate_id, event_name, payload)
+SELECT
+    u.id,
+    'users.events:user.updated',
+    jsonb_build_object(
+        'specversion', '1.0',
+        'id', uuid_generate_v4(),
+        'source', '/user-service/backfill',
+        'type', 'user.updated',
+        'subject', u.id,
+        'time', to_char(COALESCE(u.updated_at, u.created_at) AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+        'datacontenttype', 'application/json',
+        'dataversion', '1',
+        'data', jsonb_build_object('id', u.id, 'username', u.username, 'email', u.email)
+    )
+FROM users AS u;
\ No newline at end of file
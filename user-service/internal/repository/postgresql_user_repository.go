@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sonuudigital/microservices/shared/events"
+	"github.com/sonuudigital/microservices/shared/tracing"
+)
+
+const eventSource = "/user-service"
+
+var ErrUserNotFound = errors.New("user not found")
+
+// PostgreSQLUserRepository writes users together with their user events in
+// the outbox, so every registration, update and deletion is published.
+type PostgreSQLUserRepository struct {
+	*Queries
+	db *pgxpool.Pool
+}
+
+func NewPostgreSQLUserRepository(db *pgxpool.Pool) *PostgreSQLUserRepository {
+	return &PostgreSQLUserRepository{
+		db:      db,
+		Queries: New(db),
+	}
+}
+
+func (r *PostgreSQLUserRepository) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateUser creates a user and records user.registered.
+func (r *PostgreSQLUserRepository) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+	err := r.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, q, user, events.UserRegisteredEventName, events.UserRegisteredEventType)
+	})
+	return user, err
+}
+
+// UpdateUser changes the username and email of a user and records
+// user.updated.
+func (r *PostgreSQLUserRepository) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	var user User
+	err := r.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.UpdateUser(ctx, arg)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, q, user, events.UserUpdatedEventName, events.UserUpdatedEventType)
+	})
+	return user, err
+}
+
+// DeleteUser deletes a user and records user.deleted with the deleted user.
+func (r *PostgreSQLUserRepository) DeleteUser(ctx context.Context, id pgtype.UUID) (User, error) {
+	var user User
+	err := r.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.DeleteUser(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, q, user, events.UserDeletedEventName, events.UserDeletedEventType)
+	})
+	return user, err
+}
+
+func recordEvent(ctx context.Context, q *Queries, user User, eventName, eventType string) error {
+	payload, err := events.Wrap(eventSource, eventType, user.ID.String(), events.UserEvent{
+		ID:       user.ID.String(),
+		Username: user.Username,
+		Email:    user.Email,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateID:  user.ID,
+		EventName:    eventName,
+		Payload:      payload,
+		TraceContext: tracing.MarshalTraceContext(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+	return nil
+}
@@ -11,9 +11,21 @@ import (
 )
 
 type Querier interface {
+	ArchiveOutboxEvents(ctx context.Context, arg ArchiveOutboxEventsParams) (int64, error)
+	ClaimUnpublishedOutboxEvents(ctx context.Context, arg ClaimUnpublishedOutboxEventsParams) ([]ClaimUnpublishedOutboxEventsRow, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteUser(ctx context.Context, id pgtype.UUID) (User, error)
+	GetOutboxBacklog(ctx context.Context) (GetOutboxBacklogRow, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id pgtype.UUID) (User, error)
+	ListOutboxEventsByStatus(ctx context.Context, arg ListOutboxEventsByStatusParams) ([]ListOutboxEventsByStatusRow, error)
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) error
+	// A pending event is only requeued while no relayer holds its lease, so a
+	// publish in flight is not reset underneath the relayer.
+	RequeueOutboxEvent(ctx context.Context, id pgtype.UUID) (RequeueOutboxEventRow, error)
+	UpdateOutboxEventStatus(ctx context.Context, arg UpdateOutboxEventStatusParams) error
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)